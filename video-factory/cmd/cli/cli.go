@@ -3,13 +3,19 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strings"
 	"video-factory/internal/api"
 	"video-factory/internal/api/handler"
 	"video-factory/internal/db"
+	"video-factory/internal/lineprobe"
+	"video-factory/internal/recorder"
 	"video-factory/internal/repository"
 	"video-factory/internal/service"
+	"video-factory/internal/site/bili"
+	"video-factory/internal/site/missevan"
 	"video-factory/pkg/config"
 	"video-factory/pkg/fetcher"
+	"video-factory/pkg/logger"
 	"video-factory/pkg/pool"
 	"video-factory/pkg/util"
 
@@ -20,9 +26,12 @@ import (
 // CliFlags 用于在 CLI 解析后临时存储 Flag 值
 type CliFlags struct {
 	ConfigFile     string
+	DBPath         string
 	Port           int
 	BiliCookie     string
 	MissevanCookie string
+	Node           string
+	Advertise      string
 }
 
 func Execute() error {
@@ -40,6 +49,13 @@ func Execute() error {
 				Destination: &cliValues.ConfigFile,
 				Value:       "./conf/config.json",
 			},
+			&cli.StringFlag{
+				Name:        "db",
+				Usage:       "数据库文件路径",
+				EnvVars:     []string{"VIDEO_FACTORY_DB"},
+				Destination: &cliValues.DBPath,
+				Value:       db.DefaultFilePath,
+			},
 			&cli.IntFlag{
 				Name:        "port",
 				Aliases:     []string{"p"},
@@ -59,6 +75,26 @@ func Execute() error {
 				Destination: &cliValues.MissevanCookie,
 				Value:       "",
 			},
+			&cli.StringFlag{
+				Name:        "node",
+				Usage:       "集群节点名，设置后以集群模式运行，各节点需要使用同一个数据库文件",
+				EnvVars:     []string{"VIDEO_FACTORY_NODE"},
+				Destination: &cliValues.Node,
+			},
+			&cli.StringFlag{
+				Name:        "advertise",
+				Usage:       "其他节点访问本节点的地址，默认为 http://<主机名>:<端口>，该主机解析出的 IP 用于识别节点间转发的请求",
+				EnvVars:     []string{"VIDEO_FACTORY_ADVERTISE"},
+				Destination: &cliValues.Advertise,
+			},
+		},
+		Commands: []*cli.Command{
+			{
+				Name:      "restore",
+				Usage:     "用备份文件恢复数据库，需要先停止服务",
+				ArgsUsage: "<备份文件>",
+				Action:    restore(&cliValues),
+			},
 		},
 		Action: start(&cliValues),
 	}
@@ -81,14 +117,14 @@ func start(cliValues *CliFlags) cli.ActionFunc {
 		}
 
 		// 初始化数据库
-		db.InitDB()
-
-		// 初始化 ID 生成器
-		util.InitIDGenerator(1)
+		db.InitDB(cliValues.DBPath)
 
 		// 先初始化 repo，去加载数据库中的配置
 		repos := repository.NewRepository(db.DB)
 
+		// 登记 config 包无法感知的业务校验，加载配置时生效
+		recorder.RegisterValidators()
+
 		// 加载配置
 		configMap, err := repos.Config.ListConfigsMap()
 		if err != nil {
@@ -97,6 +133,11 @@ func start(cliValues *CliFlags) cli.ActionFunc {
 		if err := config.InitViper(cliValues.ConfigFile, flagMap, configMap); err != nil {
 			return err
 		}
+		// 按配置初始化日志，日志级别支持运行时修改
+		if err := logger.Setup(config.GlobalConfig.Log); err != nil {
+			return err
+		}
+		config.GlobalConfig.AddSubscriber(logger.Subscriber{})
 
 		// 打印最终配置（用于验证）
 		log.Info().Msgf("服务将监听端口: %d", config.GlobalConfig.Port)
@@ -108,6 +149,15 @@ func start(cliValues *CliFlags) cli.ActionFunc {
 
 		// 初始化 http 客户端
 		fetcher.Init(&config.GlobalConfig)
+		// 平台接口地址
+		if err := bili.SetBaseURL(config.GlobalConfig.Bili.APIBase); err != nil {
+			return err
+		}
+		if err := missevan.SetBaseURL(config.GlobalConfig.Missevan.APIBase); err != nil {
+			return err
+		}
+		// 加载 CDN 线路评分
+		lineprobe.Init(service.NewLineScoreStore(repos.LineScore))
 		// 初始化 ManagerPool
 		p := pool.NewManagerPool(&config.GlobalConfig)
 
@@ -115,8 +165,27 @@ func start(cliValues *CliFlags) cli.ActionFunc {
 		services := service.NewService(p, &config.GlobalConfig, repos)
 		handlers := handler.NewHandler(p, &config.GlobalConfig, services)
 
+		// 初始化 ID 生成器，集群模式下各节点使用不同的节点号，避免共享数据库中的 ID 冲突
+		workerID := int64(1)
+		if cliValues.Node != "" {
+			node, err := services.ClusterService.Join(cliValues.Node, advertiseAddr(cliValues.Advertise, config.GlobalConfig.Port))
+			if err != nil {
+				return err
+			}
+			workerID = node.WorkerID
+		}
+		util.InitIDGenerator(workerID)
+
 		// 启动全局监控
 		go services.MonitorService.Start(c.Context)
+		// 定期刷新房间信息
+		go services.MetadataService.Run(c.Context)
+		// 检查磁盘空间并发送通知
+		go services.NotifyService.Run(c.Context)
+		// 定期备份数据库
+		go services.BackupService.Run(c.Context)
+		// 集群模式下心跳并分配房间
+		go services.ClusterService.Run(c.Context)
 
 		// 通过 NewEngine 创建配置好的 Gin 引擎，并将 Pool 注入
 		routerEngine := api.NewEngine(p, handlers)
@@ -125,3 +194,34 @@ func start(cliValues *CliFlags) cli.ActionFunc {
 		return routerEngine.Run(fmt.Sprintf(":%d", config.GlobalConfig.Port))
 	}
 }
+
+// advertiseAddr 其他节点访问本节点的地址，未指定时使用主机名与监听端口
+func advertiseAddr(addr string, port int) string {
+	if addr != "" {
+		return strings.TrimRight(addr, "/")
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+	return fmt.Sprintf("http://%s:%d", host, port)
+}
+
+// restore 用备份文件替换数据库，当前数据库会先复制一份以便撤销
+func restore(cliValues *CliFlags) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		backupFile := c.Args().First()
+		if backupFile == "" {
+			return cli.Exit("请指定备份文件", 1)
+		}
+		saved, err := db.Restore(cliValues.DBPath, backupFile)
+		if err != nil {
+			return err
+		}
+		if saved != "" {
+			log.Info().Msgf("恢复前的数据库已保存到: %s", saved)
+		}
+		log.Info().Msgf("数据库已恢复: %s -> %s", backupFile, cliValues.DBPath)
+		return nil
+	}
+}
@@ -0,0 +1,49 @@
+// openapi-gen 生成 docs/openapi.json 与 pkg/client 的客户端代码
+//
+// 在 pkg/client 目录下由 go generate 调用，也可以单独运行：
+//
+//	go run ./cmd/openapi-gen -spec docs/openapi.json -client pkg/client/client_gen.go
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"video-factory/internal/api/openapi"
+)
+
+func main() {
+	specFile := flag.String("spec", "docs/openapi.json", "接口文档输出路径")
+	clientFile := flag.String("client", "", "客户端代码输出路径，为空时不生成")
+	pkg := flag.String("package", "client", "客户端代码的包名")
+	flag.Parse()
+
+	if err := run(*specFile, *clientFile, *pkg); err != nil {
+		fmt.Fprintln(os.Stderr, "openapi-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(specFile, clientFile, pkg string) error {
+	spec, err := openapi.Marshal(openapi.Spec())
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(specFile, spec, 0644); err != nil {
+		return err
+	}
+	if clientFile == "" {
+		return nil
+	}
+	// 客户端从写出的文档生成，保证两者一致
+	var doc openapi.Document
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return err
+	}
+	src, err := openapi.GenerateClient(&doc, pkg)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(clientFile, src, 0644)
+}
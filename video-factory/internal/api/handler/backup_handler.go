@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"video-factory/internal/api/response"
+	"video-factory/internal/service"
+	"video-factory/pkg/config"
+	"video-factory/pkg/pool"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type BackupHandler struct {
+	pool          *pool.ManagerPool
+	config        *config.AppConfig
+	backupService *service.BackupService
+}
+
+func NewBackupHandler(pool *pool.ManagerPool, config *config.AppConfig, backupService *service.BackupService) *BackupHandler {
+	return &BackupHandler{
+		pool:          pool,
+		config:        config,
+		backupService: backupService,
+	}
+}
+
+// BackupCreateHandler 立即备份数据库
+func (h *BackupHandler) BackupCreateHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		backup, err := h.backupService.Backup()
+		if err != nil {
+			log.Err(err).Msg("备份数据库失败")
+			response.Error(c, fmt.Sprintf("备份数据库失败: %v", err))
+			return
+		}
+		response.OkWithData(c, backup)
+	}
+}
+
+// BackupListHandler 获取备份列表，按时间倒序
+func (h *BackupHandler) BackupListHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, err := h.backupService.ListBackups()
+		if err != nil {
+			response.Error(c, fmt.Sprintf("获取备份列表失败: %v", err))
+			return
+		}
+		response.OkWithList(c, list, int64(len(list)), 0, 0)
+	}
+}
+
+// BackupFileHandler 下载备份文件，恢复需要停止服务后使用命令行 restore
+func (h *BackupHandler) BackupFileHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path, ok := h.backupService.BackupPath(c.Param("name"))
+		if !ok {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.FileAttachment(path, c.Param("name"))
+	}
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"video-factory/internal/api/response"
+	"video-factory/internal/domain/vo"
+	"video-factory/internal/service"
+	"video-factory/pkg/config"
+	"video-factory/pkg/pool"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type ClipHandler struct {
+	pool        *pool.ManagerPool
+	config      *config.AppConfig
+	clipService *service.ClipService
+}
+
+func NewClipHandler(pool *pool.ManagerPool, config *config.AppConfig, clipService *service.ClipService) *ClipHandler {
+	return &ClipHandler{
+		pool:        pool,
+		config:      config,
+		clipService: clipService,
+	}
+}
+
+// ClipLiveHandler 保存直播中房间最近一段时间的内容
+func (h *ClipHandler) ClipLiveHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req vo.ClipLiveVO
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "请求参数有误")
+			return
+		}
+		if req.RoomId == "" {
+			response.Error(c, "房间 id 为空")
+			return
+		}
+		clip, err := h.clipService.ClipFromLive(req.RoomId, req.Seconds)
+		if err != nil {
+			log.Err(err).Str("roomId", req.RoomId).Msg("保存直播片段失败")
+			response.Error(c, fmt.Sprintf("保存直播片段失败: %v", err))
+			return
+		}
+		response.OkWithData(c, clip)
+	}
+}
+
+// ClipRecordingHandler 从录制文件截取片段，start / end 支持 01:23:00 或秒数
+func (h *ClipHandler) ClipRecordingHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req vo.ClipRecordingVO
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "请求参数有误")
+			return
+		}
+		if req.RecordingId == "" {
+			response.Error(c, "录制记录 id 为空")
+			return
+		}
+		clip, err := h.clipService.ClipFromRecording(req.RecordingId, req.Start, req.End)
+		if err != nil {
+			log.Err(err).Str("recordingId", req.RecordingId).Msg("截取片段失败")
+			response.Error(c, fmt.Sprintf("截取片段失败: %v", err))
+			return
+		}
+		response.OkWithData(c, clip)
+	}
+}
+
+// ClipListHandler 获取片段列表，可通过 roomId 过滤
+func (h *ClipHandler) ClipListHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roomId, err := strconv.ParseInt(c.DefaultQuery("roomId", "0"), 10, 64)
+		if err != nil {
+			response.Error(c, "roomId 格式不正确")
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+		if err != nil {
+			response.Error(c, "limit 格式不正确")
+			return
+		}
+		list, err := h.clipService.ListClips(roomId, limit)
+		if err != nil {
+			response.Error(c, fmt.Sprintf("获取片段列表失败: %v", err))
+			return
+		}
+		response.OkWithList(c, list, int64(len(list)), 0, 0)
+	}
+}
+
+// ClipDetailHandler 获取片段详情，用于查询导出状态
+func (h *ClipHandler) ClipDetailHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			response.Error(c, "id 格式不正确")
+			return
+		}
+		clip, err := h.clipService.GetClip(id)
+		if err != nil {
+			response.Error(c, fmt.Sprintf("获取片段失败: %v", err))
+			return
+		}
+		response.OkWithData(c, clip)
+	}
+}
+
+// ClipFileHandler 下载片段文件
+func (h *ClipHandler) ClipFileHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			response.Error(c, "id 格式不正确")
+			return
+		}
+		path, err := h.clipService.GetClipFile(id)
+		if err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.FileAttachment(path, filepath.Base(path))
+	}
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"fmt"
+	"video-factory/internal/api/response"
+	"video-factory/internal/service"
+	"video-factory/pkg/config"
+	"video-factory/pkg/pool"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ClusterHandler struct {
+	pool           *pool.ManagerPool
+	config         *config.AppConfig
+	clusterService *service.ClusterService
+}
+
+func NewClusterHandler(pool *pool.ManagerPool, config *config.AppConfig, clusterService *service.ClusterService) *ClusterHandler {
+	return &ClusterHandler{
+		pool:           pool,
+		config:         config,
+		clusterService: clusterService,
+	}
+}
+
+// NodeListHandler 获取集群节点，未开启集群模式时返回空列表
+func (h *ClusterHandler) NodeListHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, err := h.clusterService.ListNodes()
+		if err != nil {
+			response.Error(c, fmt.Sprintf("获取集群节点失败: %v", err))
+			return
+		}
+		response.OkWithList(c, list, int64(len(list)), 0, 0)
+	}
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"fmt"
+	"strconv"
 	"video-factory/internal/api/response"
 	"video-factory/internal/domain/model"
 	"video-factory/internal/domain/vo"
@@ -63,6 +64,10 @@ func (ch *ConfigHandler) ConfigUpdateHandler() gin.HandlerFunc {
 			response.Error(c, fmt.Sprintf("更新配置失败: %v", err))
 			return
 		}
+		if schema, ok := config.LookupSchema(req.Key); ok && schema.RequiresRestart {
+			response.OkWithMsg(c, "更新配置成功，重启后生效")
+			return
+		}
 		response.OkWithMsg(c, "更新配置成功")
 	}
 }
@@ -78,3 +83,69 @@ func (ch *ConfigHandler) ConfigListHandler() gin.HandlerFunc {
 		response.OkWithList(c, configs, int64(len(configs)), 0, 0)
 	}
 }
+
+// ConfigSchemaHandler 获取所有配置项的声明
+func (ch *ConfigHandler) ConfigSchemaHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		schemas := config.ListSchemas()
+		response.OkWithList(c, schemas, int64(len(schemas)), 0, 0)
+	}
+}
+
+// ConfigHistoryHandler 获取配置变更历史，可通过 key 过滤
+func (ch *ConfigHandler) ConfigHistoryHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.Query("key")
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+		if err != nil {
+			response.Error(c, "limit 格式不正确")
+			return
+		}
+		histories, err := ch.configService.ListHistories(key, limit)
+		if err != nil {
+			log.Err(err).Msg("获取配置变更历史失败")
+			response.Error(c, fmt.Sprintf("获取配置变更历史失败: %v", err))
+			return
+		}
+		response.OkWithList(c, histories, int64(len(histories)), 0, 0)
+	}
+}
+
+// ConfigRollbackHandler 将配置回滚到某条变更记录之前的值
+func (ch *ConfigHandler) ConfigRollbackHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req vo.ConfigRollbackVO
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "请求参数有误")
+			return
+		}
+		cfg, err := ch.configService.Rollback(req.HistoryId)
+		if err != nil {
+			log.Err(err).Msg("回滚配置失败")
+			response.Error(c, fmt.Sprintf("回滚配置失败: %v", err))
+			return
+		}
+		if schema, ok := config.LookupSchema(cfg.Key); ok && schema.RequiresRestart {
+			response.OkWithMsg(c, "回滚配置成功，重启后生效")
+			return
+		}
+		response.OkWithMsg(c, "回滚配置成功")
+	}
+}
+
+// FilenamePreviewHandler 使用示例数据预览文件名格式
+func (ch *ConfigHandler) FilenamePreviewHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req vo.FilenamePreviewVO
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "请求参数有误")
+			return
+		}
+		filename, err := ch.configService.PreviewFilename(req.Pattern)
+		if err != nil {
+			response.Error(c, fmt.Sprintf("文件名格式有误: %v", err))
+			return
+		}
+		response.OkWithData(c, vo.FilenamePreviewResultVO{Filename: filename})
+	}
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"video-factory/internal/api/response"
+	"video-factory/internal/service"
+	"video-factory/internal/vod"
+	"video-factory/pkg/config"
+	"video-factory/pkg/pool"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FileHandler struct {
+	pool        *pool.ManagerPool
+	config      *config.AppConfig
+	fileService *service.FileService
+}
+
+func NewFileHandler(pool *pool.ManagerPool, config *config.AppConfig, fileService *service.FileService) *FileHandler {
+	return &FileHandler{
+		pool:        pool,
+		config:      config,
+		fileService: fileService,
+	}
+}
+
+// contentTypes 浏览器播放需要正确的 Content-Type，mime 包不一定认识这些扩展名
+var contentTypes = map[string]string{
+	".ts":  "video/mp2t",
+	".flv": "video/x-flv",
+	".mp4": "video/mp4",
+	".m4a": "audio/mp4",
+	".aac": "audio/aac",
+}
+
+// BrowseHandler 浏览录制目录，path 为相对录制根目录的路径
+func (f *FileHandler) BrowseHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entries, err := f.fileService.Browse(c.Query("path"))
+		if err != nil {
+			if errors.Is(err, vod.ErrOutsideRoot) {
+				response.Error(c, err.Error())
+				return
+			}
+			response.Error(c, fmt.Sprintf("读取目录失败: %v", err))
+			return
+		}
+		response.OkWithList(c, entries, int64(len(entries)), 0, 0)
+	}
+}
+
+// ListHandler 按房间与日期列出录制文件，date 格式 2006-01-02
+func (f *FileHandler) ListHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roomId, err := strconv.ParseInt(c.DefaultQuery("roomId", "0"), 10, 64)
+		if err != nil {
+			response.Error(c, "roomId 格式不正确")
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "200"))
+		if err != nil {
+			response.Error(c, "limit 格式不正确")
+			return
+		}
+		list, err := f.fileService.ListRecordingFiles(roomId, c.Query("date"), limit)
+		if err != nil {
+			response.Error(c, fmt.Sprintf("获取录制文件失败: %v", err))
+			return
+		}
+		response.OkWithList(c, list, int64(len(list)), 0, 0)
+	}
+}
+
+// StreamHandler 下载或播放录制文件，支持 Range 请求
+func (f *FileHandler) StreamHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		file, err := f.fileService.ResolveFile(c.Param("path"))
+		if err != nil {
+			f.abort(c, err)
+			return
+		}
+		if contentType, ok := contentTypes[strings.ToLower(filepath.Ext(file))]; ok {
+			c.Header("Content-Type", contentType)
+		}
+		// http.ServeFile 处理 Range、If-Modified-Since 等
+		c.File(file)
+	}
+}
+
+// PlaylistHandler 为 TS 文件生成 HLS VOD 播放列表，地址为文件路径加 .m3u8
+func (f *FileHandler) PlaylistHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		playlist, err := f.fileService.Playlist(c.Param("path"))
+		if err != nil {
+			f.abort(c, err)
+			return
+		}
+		c.Header("Cache-Control", "no-cache")
+		c.Data(http.StatusOK, "application/vnd.apple.mpegurl", []byte(playlist))
+	}
+}
+
+func (f *FileHandler) abort(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, vod.ErrOutsideRoot):
+		c.AbortWithStatus(http.StatusForbidden)
+	case errors.Is(err, os.ErrNotExist):
+		c.AbortWithStatus(http.StatusNotFound)
+	default:
+		c.String(http.StatusBadRequest, err.Error())
+		c.Abort()
+	}
+}
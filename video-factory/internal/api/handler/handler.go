@@ -7,17 +7,31 @@ import (
 )
 
 type Handler struct {
-	RoomHandler    *RoomHandler
-	ConfigHandler  *ConfigHandler
-	StreamHandler  *StreamHandler
-	MonitorHandler *MonitorHandler
+	RoomHandler      *RoomHandler
+	ConfigHandler    *ConfigHandler
+	StreamHandler    *StreamHandler
+	MonitorHandler   *MonitorHandler
+	RecordingHandler *RecordingHandler
+	ClipHandler      *ClipHandler
+	FileHandler      *FileHandler
+	UploadHandler    *UploadHandler
+	NotifyHandler    *NotifyHandler
+	BackupHandler    *BackupHandler
+	ClusterHandler   *ClusterHandler
 }
 
 func NewHandler(pool *pool.ManagerPool, config *config.AppConfig, service *service.Service) *Handler {
 	return &Handler{
-		RoomHandler:    NewRoomHandler(pool, config, service.RoomService),
-		ConfigHandler:  NewConfigHandler(pool, config, service.ConfigService),
-		StreamHandler:  NewStreamHandler(pool, config, service.RoomService, service.MonitorService),
-		MonitorHandler: NewMonitorHandler(pool, config, service.MonitorService),
+		RoomHandler:      NewRoomHandler(pool, config, service.RoomService, service.MetadataService),
+		ConfigHandler:    NewConfigHandler(pool, config, service.ConfigService),
+		StreamHandler:    NewStreamHandler(pool, config, service.RoomService, service.MonitorService, service.ClusterService),
+		MonitorHandler:   NewMonitorHandler(pool, config, service.MonitorService),
+		RecordingHandler: NewRecordingHandler(pool, config, service.RecordingService, service.ThumbnailService),
+		ClipHandler:      NewClipHandler(pool, config, service.ClipService),
+		FileHandler:      NewFileHandler(pool, config, service.FileService),
+		UploadHandler:    NewUploadHandler(pool, config, service.StorageService, service.UploadService),
+		NotifyHandler:    NewNotifyHandler(pool, config, service.NotifyService),
+		BackupHandler:    NewBackupHandler(pool, config, service.BackupService),
+		ClusterHandler:   NewClusterHandler(pool, config, service.ClusterService),
 	}
 }
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"fmt"
+	"strconv"
 	"video-factory/internal/api/response"
 	"video-factory/internal/service"
 	"video-factory/pkg/config"
@@ -48,3 +50,44 @@ func (m *MonitorHandler) Refresh(c *gin.Context) {
 	m.monitorService.TriggerRefresh()
 	response.Ok(c)
 }
+
+// PlatformStatus 获取各平台熔断/限流状态
+func (m *MonitorHandler) PlatformStatus(c *gin.Context) {
+	response.OkWithData(c, m.monitorService.GetPlatformStatus())
+}
+
+// LineScores 获取各平台 CDN 线路评分
+func (m *MonitorHandler) LineScores(c *gin.Context) {
+	scores := m.monitorService.GetLineScores()
+	response.OkWithList(c, scores, int64(len(scores)), 0, 0)
+}
+
+// Session 获取开播记录及断流区间
+func (m *MonitorHandler) Session(c *gin.Context) {
+	sessionId, err := strconv.ParseInt(c.Param("sessionId"), 10, 64)
+	if err != nil {
+		response.Error(c, "sessionId 格式不正确")
+		return
+	}
+	session, err := m.monitorService.GetSession(sessionId)
+	if err != nil {
+		response.Error(c, fmt.Sprintf("获取开播记录失败: %v", err))
+		return
+	}
+	response.OkWithData(c, session)
+}
+
+// SessionTransitions 获取一次开播期间 Manager 的状态变更记录
+func (m *MonitorHandler) SessionTransitions(c *gin.Context) {
+	sessionId, err := strconv.ParseInt(c.Param("sessionId"), 10, 64)
+	if err != nil {
+		response.Error(c, "sessionId 格式不正确")
+		return
+	}
+	transitions, err := m.monitorService.ListTransitions(sessionId)
+	if err != nil {
+		response.Error(c, fmt.Sprintf("获取状态变更记录失败: %v", err))
+		return
+	}
+	response.OkWithList(c, transitions, int64(len(transitions)), 0, 0)
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"video-factory/internal/api/response"
+	"video-factory/internal/domain/vo"
+	"video-factory/internal/service"
+	"video-factory/pkg/config"
+	"video-factory/pkg/pool"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type NotifyHandler struct {
+	pool          *pool.ManagerPool
+	config        *config.AppConfig
+	notifyService *service.NotifyService
+}
+
+func NewNotifyHandler(pool *pool.ManagerPool, config *config.AppConfig, notifyService *service.NotifyService) *NotifyHandler {
+	return &NotifyHandler{
+		pool:          pool,
+		config:        config,
+		notifyService: notifyService,
+	}
+}
+
+// ChannelListHandler 获取通知渠道列表
+func (h *NotifyHandler) ChannelListHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, err := h.notifyService.ListChannels()
+		if err != nil {
+			response.Error(c, fmt.Sprintf("获取通知渠道失败: %v", err))
+			return
+		}
+		response.OkWithList(c, list, int64(len(list)), 0, 0)
+	}
+}
+
+// ChannelAddHandler 添加通知渠道，templates 为事件到模板的映射，未填写的事件使用默认模板
+func (h *NotifyHandler) ChannelAddHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req vo.NotifyChannelAddVO
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "请求参数有误")
+			return
+		}
+		ch, err := h.notifyService.AddChannel(req.Name, req.Type, req.Config, req.Templates)
+		if err != nil {
+			log.Err(err).Str("type", req.Type).Msg("添加通知渠道失败")
+			response.Error(c, fmt.Sprintf("添加通知渠道失败: %v", err))
+			return
+		}
+		response.OkWithData(c, ch)
+	}
+}
+
+// ChannelUpdateHandler 更新通知渠道，未提交的字段保持不变，密钥未修改时提交脱敏后的值即可
+func (h *NotifyHandler) ChannelUpdateHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req vo.NotifyChannelUpdateVO
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "请求参数有误")
+			return
+		}
+		err := h.notifyService.UpdateChannel(c.Param("id"), req.Name, req.Config, req.Templates, req.Enabled)
+		if err != nil {
+			response.Error(c, fmt.Sprintf("更新通知渠道失败: %v", err))
+			return
+		}
+		response.Ok(c)
+	}
+}
+
+// ChannelRemoveHandler 删除通知渠道及其订阅
+func (h *NotifyHandler) ChannelRemoveHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := h.notifyService.RemoveChannel(c.Param("id")); err != nil {
+			response.Error(c, fmt.Sprintf("删除通知渠道失败: %v", err))
+			return
+		}
+		response.Ok(c)
+	}
+}
+
+// ChannelTestHandler 用示例数据发送一条测试通知，可通过 event 指定模板
+func (h *NotifyHandler) ChannelTestHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := h.notifyService.TestChannel(c.Param("id"), c.Query("event")); err != nil {
+			response.Error(c, fmt.Sprintf("发送测试通知失败: %v", err))
+			return
+		}
+		response.Ok(c)
+	}
+}
+
+// SubscriptionListHandler 获取订阅列表，可通过 channelId 过滤
+func (h *NotifyHandler) SubscriptionListHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		channelId, err := strconv.ParseInt(c.DefaultQuery("channelId", "0"), 10, 64)
+		if err != nil {
+			response.Error(c, "channelId 格式不正确")
+			return
+		}
+		list, err := h.notifyService.ListSubscriptions(channelId)
+		if err != nil {
+			response.Error(c, fmt.Sprintf("获取订阅失败: %v", err))
+			return
+		}
+		response.OkWithList(c, list, int64(len(list)), 0, 0)
+	}
+}
+
+// SubscriptionSaveHandler 保存渠道对房间的订阅，roomId 为 0 时订阅所有房间及磁盘空间通知
+func (h *NotifyHandler) SubscriptionSaveHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req vo.NotifySubscriptionSaveVO
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "请求参数有误")
+			return
+		}
+		if req.RoomId == "" {
+			req.RoomId = "0"
+		}
+		if err := h.notifyService.SaveSubscription(req.ChannelId, req.RoomId, req.Events); err != nil {
+			response.Error(c, fmt.Sprintf("保存订阅失败: %v", err))
+			return
+		}
+		response.Ok(c)
+	}
+}
+
+// SubscriptionRemoveHandler 删除订阅
+func (h *NotifyHandler) SubscriptionRemoveHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := h.notifyService.RemoveSubscription(c.Param("id")); err != nil {
+			response.Error(c, fmt.Sprintf("删除订阅失败: %v", err))
+			return
+		}
+		response.Ok(c)
+	}
+}
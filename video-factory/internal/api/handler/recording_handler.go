@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"video-factory/internal/api/response"
+	"video-factory/internal/service"
+	"video-factory/pkg/config"
+	"video-factory/pkg/pool"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type RecordingHandler struct {
+	pool             *pool.ManagerPool
+	config           *config.AppConfig
+	recordingService *service.RecordingService
+	thumbnailService *service.ThumbnailService
+}
+
+func NewRecordingHandler(pool *pool.ManagerPool, config *config.AppConfig, recordingService *service.RecordingService,
+	thumbnailService *service.ThumbnailService) *RecordingHandler {
+	return &RecordingHandler{
+		pool:             pool,
+		config:           config,
+		recordingService: recordingService,
+		thumbnailService: thumbnailService,
+	}
+}
+
+// RecordingListHandler 获取录制记录列表，可通过 roomId 过滤
+func (r *RecordingHandler) RecordingListHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roomId, err := strconv.ParseInt(c.DefaultQuery("roomId", "0"), 10, 64)
+		if err != nil {
+			response.Error(c, "roomId 格式不正确")
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+		if err != nil {
+			response.Error(c, "limit 格式不正确")
+			return
+		}
+		list, err := r.recordingService.ListRecordings(roomId, limit)
+		if err != nil {
+			log.Err(err).Msg("获取录制记录失败")
+			response.Error(c, fmt.Sprintf("获取录制记录失败: %v", err))
+			return
+		}
+		response.OkWithList(c, list, int64(len(list)), 0, 0)
+	}
+}
+
+// RecordingDetailHandler 获取录制记录详情，包含健康采样序列
+func (r *RecordingHandler) RecordingDetailHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			response.Error(c, "id 格式不正确")
+			return
+		}
+		recording, err := r.recordingService.GetRecording(id)
+		if err != nil {
+			response.Error(c, fmt.Sprintf("获取录制记录失败: %v", err))
+			return
+		}
+		response.OkWithData(c, recording)
+	}
+}
+
+// ThumbnailHandler 返回第 index 张缩略图
+func (r *RecordingHandler) ThumbnailHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			response.Error(c, "id 格式不正确")
+			return
+		}
+		index, err := strconv.Atoi(c.Param("index"))
+		if err != nil {
+			response.Error(c, "index 格式不正确")
+			return
+		}
+		path, err := r.recordingService.GetThumbnailPath(id, index)
+		if err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.File(path)
+	}
+}
+
+// ContactSheetHandler 返回拼图
+func (r *RecordingHandler) ContactSheetHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			response.Error(c, "id 格式不正确")
+			return
+		}
+		path, err := r.recordingService.GetContactSheetPath(id)
+		if err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.File(path)
+	}
+}
+
+// RegenerateThumbnailHandler 重新生成缩略图
+func (r *RecordingHandler) RegenerateThumbnailHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			response.Error(c, "id 格式不正确")
+			return
+		}
+		if err := r.thumbnailService.Regenerate(id); err != nil {
+			response.Error(c, fmt.Sprintf("生成缩略图失败: %v", err))
+			return
+		}
+		response.Ok(c)
+	}
+}
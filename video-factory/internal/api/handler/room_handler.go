@@ -3,10 +3,14 @@ package handler
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 	"video-factory/internal/api/response"
+	"video-factory/internal/domain/vo"
+	"video-factory/internal/recorder"
 	"video-factory/internal/service"
 	"video-factory/pkg/config"
+	"video-factory/pkg/logger"
 	"video-factory/pkg/pool"
 
 	"github.com/gin-gonic/gin"
@@ -15,26 +19,26 @@ import (
 )
 
 type RoomHandler struct {
-	pool        *pool.ManagerPool
-	config      *config.AppConfig
-	roomService *service.RoomService
+	pool            *pool.ManagerPool
+	config          *config.AppConfig
+	roomService     *service.RoomService
+	metadataService *service.MetadataService
 }
 
-func NewRoomHandler(pool *pool.ManagerPool, config *config.AppConfig, roomService *service.RoomService) *RoomHandler {
+func NewRoomHandler(pool *pool.ManagerPool, config *config.AppConfig, roomService *service.RoomService,
+	metadataService *service.MetadataService) *RoomHandler {
 	return &RoomHandler{
-		pool:        pool,
-		config:      config,
-		roomService: roomService,
+		pool:            pool,
+		config:          config,
+		roomService:     roomService,
+		metadataService: metadataService,
 	}
 }
 
 // RoomAddHandler 添加直播间
 func (r *RoomHandler) RoomAddHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var req struct {
-			RoomInput string `json:"roomInput" binding:"required"`
-			Platform  string `json:"platform" binding:"oneof=bili missevan"`
-		}
+		var req vo.RoomCreateVO
 		if err := c.ShouldBindJSON(&req); err != nil {
 			var ve validator.ValidationErrors
 			if errors.As(err, &ve) {
@@ -117,26 +121,161 @@ func (r *RoomHandler) RoomDetailHandler() gin.HandlerFunc {
 	}
 }
 
-// RoomListHandler 获取房间列表
+// RoomListHandler 获取房间列表，支持关键字、平台、状态、分组、标签筛选
+// 传 page 时分页，pageSize 默认 20；不传时返回全部
 func (r *RoomHandler) RoomListHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		rooms, err := r.roomService.ListRooms()
+		var query vo.RoomQueryVO
+		if err := c.ShouldBindQuery(&query); err != nil {
+			response.Error(c, "查询参数有误")
+			return
+		}
+		query.Page, query.PageSize = pageParams(query.Page, query.PageSize)
+		rooms, total, err := r.roomService.ListRooms(&query)
 		if err != nil {
 			log.Err(err).Msg("获取房间列表失败")
 			response.Error(c, "获取房间列表失败")
 			return
 		}
 
-		response.OkWithList(c, rooms, int64(len(rooms)), 0, 0)
+		response.OkWithList(c, rooms, total, query.Page, query.PageSize)
 	}
 }
 
-func (r *RoomHandler) RoomStatusHandler() gin.HandlerFunc {
+// RoomFacetsHandler 获取所有分组与标签，用于筛选
+func (r *RoomHandler) RoomFacetsHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var req struct {
-			RoomId       string `json:"roomId"`
-			TargetStatus int    `json:"status"`
+		groups, tags, err := r.roomService.ListFacets()
+		if err != nil {
+			response.Error(c, fmt.Sprintf("获取分组与标签失败: %v", err))
+			return
+		}
+		response.OkWithData(c, vo.RoomFacetsVO{Groups: groups, Tags: tags})
+	}
+}
+
+// RoomLabelsHandler 设置房间的分组与标签
+func (r *RoomHandler) RoomLabelsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req vo.RoomLabelsVO
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "请求参数有误")
+			return
+		}
+		if req.RoomId == "" {
+			response.Error(c, "房间 id 为空")
+			return
+		}
+		if err := r.roomService.SetRoomLabels(req.RoomId, req.Group, req.Tags); err != nil {
+			response.Error(c, fmt.Sprintf("设置分组与标签失败: %v", err))
+			return
 		}
+		response.Ok(c)
+	}
+}
+
+// RoomBulkHandler 批量启用、停用房间或开关录制，filter 与列表的筛选条件相同，也可以通过 roomIds 指定房间
+func (r *RoomHandler) RoomBulkHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req vo.RoomBulkVO
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "请求参数有误")
+			return
+		}
+		result, err := r.roomService.BulkAction(&req.Filter, req.Action)
+		if err != nil {
+			log.Err(err).Str("action", req.Action).Msg("批量操作房间失败")
+			response.Error(c, fmt.Sprintf("批量操作失败: %v", err))
+			return
+		}
+		response.OkWithData(c, result)
+	}
+}
+
+// RoomRefreshHandler 立即刷新房间标题、封面、主播名与头像
+func (r *RoomHandler) RoomRefreshHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roomIdStr := c.Param("roomId")
+		if err := r.metadataService.Refresh(roomIdStr); err != nil {
+			log.Err(err).Str("roomId", roomIdStr).Msg("刷新房间信息失败")
+			response.Error(c, fmt.Sprintf("刷新房间信息失败: %v", err))
+			return
+		}
+		roomId, _ := strconv.ParseInt(roomIdStr, 10, 64)
+		roomVO, err := r.roomService.GetRoomVO(roomId)
+		if err != nil {
+			response.Error(c, "获取详情失败")
+			return
+		}
+		response.OkWithData(c, roomVO)
+	}
+}
+
+// RoomTitlesHandler 获取房间的标题变更记录，可通过 sessionId 查询某一场直播
+func (r *RoomHandler) RoomTitlesHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roomId, err := strconv.ParseInt(c.Param("roomId"), 10, 64)
+		if err != nil {
+			response.Error(c, "roomId 格式有误")
+			return
+		}
+		sessionId, err := strconv.ParseInt(c.DefaultQuery("sessionId", "0"), 10, 64)
+		if err != nil {
+			response.Error(c, "sessionId 格式有误")
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+		if err != nil {
+			response.Error(c, "limit 格式有误")
+			return
+		}
+		list, err := r.metadataService.ListTitles(roomId, sessionId, limit)
+		if err != nil {
+			response.Error(c, fmt.Sprintf("获取标题记录失败: %v", err))
+			return
+		}
+		response.OkWithList(c, list, int64(len(list)), 0, 0)
+	}
+}
+
+// RoomLogsHandler 查看房间日志文件的最后若干行，包含录制引擎与 ffmpeg 的输出
+func (r *RoomHandler) RoomLogsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roomId, err := strconv.ParseInt(c.Param("roomId"), 10, 64)
+		if err != nil {
+			response.Error(c, "roomId 格式有误")
+			return
+		}
+		lines, err := strconv.Atoi(c.DefaultQuery("lines", "200"))
+		if err != nil {
+			response.Error(c, "lines 格式有误")
+			return
+		}
+		list, err := logger.TailRoom(roomId, lines)
+		if err != nil {
+			response.Error(c, fmt.Sprintf("读取房间日志失败: %v", err))
+			return
+		}
+		response.OkWithList(c, list, int64(len(list)), 0, 0)
+	}
+}
+
+// ImageHandler 本地缓存的封面与头像，文件名由图片地址决定，内容不会变化
+func (r *RoomHandler) ImageHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path, ok := r.metadataService.ImagePath(c.Param("name"))
+		if !ok {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=604800, immutable")
+		c.File(path)
+	}
+}
+
+func (r *RoomHandler) RoomStatusHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req vo.RoomStatusVO
 
 		if err := c.ShouldBindJSON(&req); err != nil {
 			response.Error(c, "请求参数有误")
@@ -164,10 +303,7 @@ func (r *RoomHandler) RoomStatusHandler() gin.HandlerFunc {
 
 func (r *RoomHandler) RoomRecordStatusHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var req struct {
-			RoomId       string `json:"roomId"`
-			TargetStatus int    `json:"status"`
-		}
+		var req vo.RoomStatusVO
 
 		if err := c.ShouldBindJSON(&req); err != nil {
 			response.Error(c, "请求参数有误")
@@ -192,3 +328,74 @@ func (r *RoomHandler) RoomRecordStatusHandler() gin.HandlerFunc {
 		response.Ok(c)
 	}
 }
+
+// RoomRecordEngineHandler 修改房间录制引擎，engine 为空时使用 ffmpeg
+func (r *RoomHandler) RoomRecordEngineHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req vo.RoomRecordEngineVO
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "请求参数有误")
+			return
+		}
+
+		if req.RoomId == "" {
+			response.Error(c, "房间 id 为空")
+			return
+		}
+
+		if err := r.roomService.ChangeRecordEngine(req.RoomId, req.Engine); err != nil {
+			log.Err(err).Msgf("修改房间录制引擎失败")
+			response.Error(c, fmt.Sprintf("修改房间录制引擎失败: %v", err))
+			return
+		}
+
+		response.Ok(c)
+	}
+}
+
+// RecordEnginesHandler 获取可用的录制引擎
+func (r *RoomHandler) RecordEnginesHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.OkWithData(c, recorder.EngineNames())
+	}
+}
+
+// RoomRecordModeHandler 修改房间录制模式 video | audio
+func (r *RoomHandler) RoomRecordModeHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req vo.RoomRecordModeVO
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "请求参数有误")
+			return
+		}
+
+		if req.RoomId == "" {
+			response.Error(c, "房间 id 为空")
+			return
+		}
+
+		if err := r.roomService.ChangeRecordMode(req.RoomId, req.Mode); err != nil {
+			log.Err(err).Msgf("修改房间录制模式失败")
+			response.Error(c, fmt.Sprintf("修改房间录制模式失败: %v", err))
+			return
+		}
+
+		response.Ok(c)
+	}
+}
+
+// maxPageSize 列表每页最多条数
+const maxPageSize = 200
+
+// pageParams 规范化分页参数，page 为 0 时不分页
+func pageParams(page int, pageSize int) (int, int) {
+	if page <= 0 {
+		return 0, 0
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	return page, min(pageSize, maxPageSize)
+}
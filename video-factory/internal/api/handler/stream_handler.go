@@ -3,12 +3,17 @@ package handler
 import (
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
 	"strconv"
 	"strings"
 	"video-factory/internal/api/response"
+	"video-factory/internal/manager"
 	"video-factory/internal/service"
 	"video-factory/pkg/config"
 	"video-factory/pkg/pool"
+	"video-factory/pkg/util"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
@@ -19,20 +24,51 @@ type StreamHandler struct {
 	config         *config.AppConfig
 	roomService    *service.RoomService
 	monitorService *service.MonitorService
+	clusterService *service.ClusterService
 }
 
+// forwardedHeader 转发到其他节点的请求带上来源节点，避免节点间循环转发
+// 只认可来自其他存活节点的请求中的该请求头，客户端自行添加时忽略
+const forwardedHeader = "X-Video-Factory-Forwarded"
+
 func NewStreamHandler(pool *pool.ManagerPool, config *config.AppConfig,
 	roomService *service.RoomService,
 	monitorService *service.MonitorService,
+	clusterService *service.ClusterService,
 ) *StreamHandler {
 	return &StreamHandler{
 		pool:           pool,
 		config:         config,
 		roomService:    roomService,
 		monitorService: monitorService,
+		clusterService: clusterService,
 	}
 }
 
+// forward 集群模式下房间由其他节点负责时，将请求原样转发给该节点，返回是否已转发
+func (s *StreamHandler) forward(c *gin.Context, roomId int64) bool {
+	if c.GetHeader(forwardedHeader) != "" && s.clusterService.IsPeer(c.RemoteIP()) {
+		return false
+	}
+	addr, ok := s.clusterService.OwnerAddr(roomId)
+	if !ok {
+		return false
+	}
+	target, err := url.Parse(addr)
+	if err != nil {
+		log.Err(err).Str("addr", addr).Msg("节点地址格式不正确")
+		return false
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		log.Err(err).Int64("roomId", roomId).Str("addr", addr).Msg("转发请求到负责节点失败")
+		w.WriteHeader(http.StatusBadGateway)
+	}
+	c.Request.Header.Set(forwardedHeader, s.clusterService.NodeID())
+	proxy.ServeHTTP(c.Writer, c.Request)
+	return true
+}
+
 // ProxyHandler 代理客户端请求到服务器
 func (s *StreamHandler) ProxyHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -53,21 +89,26 @@ func (s *StreamHandler) ProxyHandler() gin.HandlerFunc {
 
 		managerPtr, ok := s.pool.Get(managerID)
 		if !ok {
-			response.Error(c, fmt.Sprintf("直播间[%d]未启用", managerID))
+			if s.forward(c, managerID) {
+				return
+			}
+			response.Error(c, s.unavailableMessage(managerID))
+			return
+		}
+		if state, _ := managerPtr.State(); state == manager.StateStarting && managerPtr.GetCurrentURL() == "" {
+			c.Header("Retry-After", "3")
+			c.String(http.StatusServiceUnavailable, "直播流地址获取中，请稍后重试")
 			return
 		}
 
-		targetURL, err := managerPtr.ResolveTargetURL(filename)
-		if err != nil {
+		if _, err := managerPtr.ResolveTargetURL(filename); err != nil {
 			log.Err(err).Msgf("解析目标URL失败")
 			response.Error(c, "Internal server error")
 			return
 		}
 
-		// log.Printf("代理请求: %s -> %s", c.Request.RequestURI, targetURL)
-
-		// 转发请求
-		resp, err := managerPtr.Fetch(c.Request.Context(), targetURL, nil)
+		// 转发请求，链接过期刷新后按新链接重试
+		resp, err := managerPtr.FetchFile(c.Request.Context(), filename)
 		if err != nil {
 			log.Err(err).Msg("错误: 执行 HTTP 请求失败")
 			response.Error(c, "Error fetching stream db")
@@ -92,6 +133,53 @@ func (s *StreamHandler) ProxyHandler() gin.HandlerFunc {
 	}
 }
 
+// unavailableMessage 直播间没有运行中的 Manager 时的提示，区分未启用与未开播
+func (s *StreamHandler) unavailableMessage(roomId int64) string {
+	room, err := s.roomService.GetRoom(roomId)
+	if err != nil || room == nil || room.Status == 0 {
+		return fmt.Sprintf("直播间[%d]未启用", roomId)
+	}
+	return fmt.Sprintf("直播间[%d]未开播", roomId)
+}
+
+// DVRHandler 回看播放列表与本地分片，index.m3u8 为保留回看窗口内分片的直播播放列表
+func (s *StreamHandler) DVRHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		managerID, err := strconv.ParseInt(c.Param("managerId"), 10, 64)
+		if err != nil {
+			response.Error(c, "roomId 格式不正确")
+			return
+		}
+		managerPtr, ok := s.pool.Get(managerID)
+		if !ok {
+			if s.forward(c, managerID) {
+				return
+			}
+			response.Error(c, s.unavailableMessage(managerID))
+			return
+		}
+		if managerPtr.DVR == nil {
+			response.Error(c, "未开启直播回看")
+			return
+		}
+
+		filename := strings.TrimPrefix(c.Param("file"), "/")
+		if filename == "" || filename == "index.m3u8" {
+			c.Header("Cache-Control", "no-cache")
+			c.Data(http.StatusOK, "application/vnd.apple.mpegurl", []byte(managerPtr.DVR.Playlist()))
+			return
+		}
+
+		// 只允许访问播放列表中的分片
+		path, ok := managerPtr.DVR.File(filename)
+		if !ok {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.File(path)
+	}
+}
+
 func (s *StreamHandler) StartHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		roomIdStr := c.Param("roomId")
@@ -105,8 +193,12 @@ func (s *StreamHandler) StartHandler() gin.HandlerFunc {
 			return
 		}
 
+		if s.forward(c, roomId) {
+			return
+		}
 		if err = s.monitorService.StartManager(roomId); err != nil {
 			response.Error(c, err.Error())
+			return
 		}
 
 		response.Ok(c)
@@ -127,6 +219,9 @@ func (s *StreamHandler) RefreshHandler() gin.HandlerFunc {
 		}
 		managerObj, ok := s.pool.Get(roomId)
 		if !ok {
+			if s.forward(c, roomId) {
+				return
+			}
 			response.Error(c, "房间不存在或状态有误")
 			return
 		}
@@ -149,6 +244,9 @@ func (s *StreamHandler) StopHandler() gin.HandlerFunc {
 		}
 		managerObj, ok := s.pool.Get(roomId)
 		if !ok {
+			if s.forward(c, roomId) {
+				return
+			}
 			response.Error(c, "房间不存在或状态有误")
 			return
 		}
@@ -165,5 +263,12 @@ func (s *StreamHandler) ListManager(c *gin.Context) {
 		response.Error(c, err.Error())
 		return
 	}
-	response.OkWithList(c, list, int64(len(list)), 0, 0)
+	// 集群模式下汇总各节点的状态，local 用于节点间互相查询
+	if local, _ := strconv.ParseBool(c.Query("local")); !local {
+		list = s.clusterService.MergeManagers(c.Request.Context(), list)
+	}
+	page, _ := strconv.Atoi(c.Query("page"))
+	pageSize, _ := strconv.Atoi(c.Query("pageSize"))
+	page, pageSize = pageParams(page, pageSize)
+	response.OkWithList(c, util.Paginate(list, page, pageSize), int64(len(list)), page, pageSize)
 }
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"video-factory/internal/api/response"
+	"video-factory/internal/domain/vo"
+	"video-factory/internal/service"
+	"video-factory/pkg/config"
+	"video-factory/pkg/pool"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type UploadHandler struct {
+	pool           *pool.ManagerPool
+	config         *config.AppConfig
+	storageService *service.StorageService
+	uploadService  *service.UploadService
+}
+
+func NewUploadHandler(pool *pool.ManagerPool, config *config.AppConfig, storageService *service.StorageService,
+	uploadService *service.UploadService) *UploadHandler {
+	return &UploadHandler{
+		pool:           pool,
+		config:         config,
+		storageService: storageService,
+		uploadService:  uploadService,
+	}
+}
+
+// StorageListHandler 获取存储列表
+func (h *UploadHandler) StorageListHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, err := h.storageService.ListStorages()
+		if err != nil {
+			response.Error(c, fmt.Sprintf("获取存储列表失败: %v", err))
+			return
+		}
+		response.OkWithList(c, list, int64(len(list)), 0, 0)
+	}
+}
+
+// StorageAddHandler 添加存储，config 为对应类型的配置
+func (h *UploadHandler) StorageAddHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req vo.StorageAddVO
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "请求参数有误")
+			return
+		}
+		st, err := h.storageService.AddStorage(req.Name, req.Type, req.Config)
+		if err != nil {
+			log.Err(err).Str("type", req.Type).Msg("添加存储失败")
+			response.Error(c, fmt.Sprintf("添加存储失败: %v", err))
+			return
+		}
+		response.OkWithData(c, st)
+	}
+}
+
+// StorageUpdateHandler 更新存储，密钥未修改时提交脱敏后的值即可
+func (h *UploadHandler) StorageUpdateHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req vo.StorageUpdateVO
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "请求参数有误")
+			return
+		}
+		if err := h.storageService.UpdateStorage(c.Param("id"), req.Name, req.Config); err != nil {
+			response.Error(c, fmt.Sprintf("更新存储失败: %v", err))
+			return
+		}
+		response.Ok(c)
+	}
+}
+
+// StorageRemoveHandler 删除存储
+func (h *UploadHandler) StorageRemoveHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := h.storageService.RemoveStorage(c.Param("id")); err != nil {
+			response.Error(c, fmt.Sprintf("删除存储失败: %v", err))
+			return
+		}
+		response.Ok(c)
+	}
+}
+
+// RuleListHandler 获取上传规则列表
+func (h *UploadHandler) RuleListHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, err := h.storageService.ListRules()
+		if err != nil {
+			response.Error(c, fmt.Sprintf("获取上传规则失败: %v", err))
+			return
+		}
+		response.OkWithList(c, list, int64(len(list)), 0, 0)
+	}
+}
+
+// RuleSaveHandler 保存房间的上传规则，roomId 为 0 时为默认规则
+func (h *UploadHandler) RuleSaveHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req vo.UploadRuleSaveVO
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "请求参数有误")
+			return
+		}
+		if req.RoomId == "" {
+			req.RoomId = "0"
+		}
+		err := h.storageService.SaveRule(req.RoomId, req.StorageId, req.PathPrefix, req.DeleteLocal, req.Enabled)
+		if err != nil {
+			response.Error(c, fmt.Sprintf("保存上传规则失败: %v", err))
+			return
+		}
+		response.Ok(c)
+	}
+}
+
+// RuleRemoveHandler 删除上传规则
+func (h *UploadHandler) RuleRemoveHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := h.storageService.RemoveRule(c.Param("id")); err != nil {
+			response.Error(c, fmt.Sprintf("删除上传规则失败: %v", err))
+			return
+		}
+		response.Ok(c)
+	}
+}
+
+// UploadListHandler 获取上传任务列表，可通过 roomId、status 过滤
+func (h *UploadHandler) UploadListHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roomId, err := strconv.ParseInt(c.DefaultQuery("roomId", "0"), 10, 64)
+		if err != nil {
+			response.Error(c, "roomId 格式不正确")
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+		if err != nil {
+			response.Error(c, "limit 格式不正确")
+			return
+		}
+		list, err := h.uploadService.ListUploads(roomId, c.Query("status"), limit)
+		if err != nil {
+			response.Error(c, fmt.Sprintf("获取上传任务失败: %v", err))
+			return
+		}
+		response.OkWithList(c, list, int64(len(list)), 0, 0)
+	}
+}
+
+// UploadRecordingHandler 手动上传录制文件，未指定存储时使用房间规则
+func (h *UploadHandler) UploadRecordingHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req vo.UploadRecordingVO
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "请求参数有误")
+			return
+		}
+		if req.RecordingId == "" {
+			response.Error(c, "录制记录 id 为空")
+			return
+		}
+		upload, err := h.uploadService.UploadRecording(req.RecordingId, req.StorageId)
+		if err != nil {
+			log.Err(err).Str("recordingId", req.RecordingId).Msg("创建上传任务失败")
+			response.Error(c, fmt.Sprintf("创建上传任务失败: %v", err))
+			return
+		}
+		response.OkWithData(c, upload)
+	}
+}
+
+// UploadRetryHandler 重试失败的上传任务
+func (h *UploadHandler) UploadRetryHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			response.Error(c, "id 格式不正确")
+			return
+		}
+		if err := h.uploadService.Retry(id); err != nil {
+			response.Error(c, fmt.Sprintf("重试失败: %v", err))
+			return
+		}
+		response.Ok(c)
+	}
+}
@@ -0,0 +1,338 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// initialisms 生成 Go 标识符时转为全大写的单词
+var initialisms = map[string]string{
+	"Id":   "ID",
+	"Ids":  "IDs",
+	"Url":  "URL",
+	"Api":  "API",
+	"Http": "HTTP",
+	"Ip":   "IP",
+}
+
+// GenerateClient 根据接口文档生成客户端代码：组件生成结构体，每个接口生成一个 Client 方法
+// 生成的代码依赖同一个包中手写的 Client、Page、do 与 raw
+func GenerateClient(doc *Document, pkg string) ([]byte, error) {
+	g := &clientGen{doc: doc, imports: map[string]bool{"context": true, "net/http": true}}
+
+	var body bytes.Buffer
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g.writeStruct(&body, name, doc.Components.Schemas[name])
+	}
+
+	var ops []pathOperation
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			ops = append(ops, pathOperation{Path: path, Method: strings.ToUpper(method), Operation: op})
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].OperationID < ops[j].OperationID })
+	for _, op := range ops {
+		if err := g.writeOperation(&body, op); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by openapi-gen from docs/openapi.json. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	imports := make([]string, 0, len(g.imports))
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	for _, imp := range imports {
+		fmt.Fprintf(&out, "\t%q\n", imp)
+	}
+	out.WriteString(")\n\n")
+	out.Write(body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("格式化生成的代码失败: %w", err)
+	}
+	return src, nil
+}
+
+type pathOperation struct {
+	Path   string
+	Method string
+	*Operation
+}
+
+type clientGen struct {
+	doc     *Document
+	imports map[string]bool
+}
+
+func (g *clientGen) writeStruct(w *bytes.Buffer, name string, schema *Schema) {
+	fmt.Fprintf(w, "type %s struct {\n", name)
+	for _, prop := range schema.Order {
+		fieldType, tag := g.fieldType(schema.Properties[prop])
+		fmt.Fprintf(w, "\t%s %s `json:\"%s%s\"`\n", goName(prop), fieldType, prop, tag)
+	}
+	w.WriteString("}\n\n")
+}
+
+// fieldType 结构体字段的类型，format 为 int64 的字符串对应 json:",string" 的整数
+func (g *clientGen) fieldType(s *Schema) (string, string) {
+	if s.Type == "string" && s.Format == "int64" {
+		if s.Nullable {
+			return "*int64", ",string"
+		}
+		return "int64", ",string"
+	}
+	return g.goType(s), ""
+}
+
+func (g *clientGen) goType(s *Schema) string {
+	if s.Ref != "" {
+		return s.RefName()
+	}
+	var t string
+	switch s.Type {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			g.imports["time"] = true
+			t = "time.Time"
+		case "byte":
+			return "[]byte"
+		default:
+			t = "string"
+		}
+	case "integer":
+		t = "int"
+		if s.Format == "int64" {
+			t = "int64"
+		}
+	case "number":
+		t = "float64"
+	case "boolean":
+		t = "bool"
+	case "array":
+		return "[]" + g.goType(s.Items)
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "map[string]" + g.goType(s.AdditionalProperties)
+		}
+		return "map[string]any"
+	default:
+		return "any"
+	}
+	if s.Nullable {
+		return "*" + t
+	}
+	return t
+}
+
+func (g *clientGen) writeOperation(w *bytes.Buffer, op pathOperation) error {
+	var pathParams, queryParams []*Parameter
+	for _, param := range op.Parameters {
+		if param.In == "path" {
+			pathParams = append(pathParams, param)
+		} else {
+			queryParams = append(queryParams, param)
+		}
+	}
+	paramsType := op.OperationID + "Params"
+	if len(queryParams) > 0 {
+		g.writeParams(w, paramsType, queryParams)
+	}
+
+	args := []string{"ctx context.Context"}
+	for _, param := range pathParams {
+		args = append(args, lowerFirst(goName(param.Name))+" "+g.goType(param.Schema))
+	}
+	query := "nil"
+	if len(queryParams) > 0 {
+		args = append(args, "params *"+paramsType)
+		query = "params.values()"
+	}
+	body := "nil"
+	if op.RequestBody != nil {
+		schema := op.RequestBody.Content["application/json"].Schema
+		args = append(args, "body *"+g.goType(schema))
+		body = "body"
+	}
+	path, err := g.pathExpr(op.Path, pathParams)
+	if err != nil {
+		return fmt.Errorf("接口 %s: %w", op.OperationID, err)
+	}
+
+	fmt.Fprintf(w, "// %s %s\n//\n// %s %s\n", op.OperationID, op.Summary, op.Method, op.Path)
+	call := fmt.Sprintf("c.%%s(ctx, %s, %s, %s, %s", methodConst(op.Method), path, query, body)
+	signature := fmt.Sprintf("func (c *Client) %s(%s)", op.OperationID, strings.Join(args, ", "))
+
+	resp := op.Responses["200"]
+	if resp == nil {
+		return fmt.Errorf("接口 %s 缺少 200 响应", op.OperationID)
+	}
+	if _, ok := resp.Content["application/json"]; !ok {
+		// 文件等非 JSON 响应返回原始响应，由调用方读取并关闭 Body
+		fmt.Fprintf(w, "//\n// 调用方需要关闭返回的 Body\n%s (*http.Response, error) {\n\treturn %s)\n}\n\n",
+			signature, fmt.Sprintf(call, "raw"))
+		return nil
+	}
+
+	data := resp.Content["application/json"].Schema.Properties["data"]
+	switch {
+	case data.Type == "" && data.Ref == "":
+		fmt.Fprintf(w, "%s error {\n\treturn %s, nil)\n}\n\n", signature, fmt.Sprintf(call, "do"))
+	case data.Paging:
+		itemType := g.goType(data.Properties["list"].Items)
+		if itemType == "any" {
+			g.imports["encoding/json"] = true
+			itemType = "json.RawMessage"
+		}
+		fmt.Fprintf(w, "%s (*Page[%s], error) {\n\tvar out Page[%s]\n\tif err := %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n\treturn &out, nil\n}\n\n",
+			signature, itemType, itemType, fmt.Sprintf(call, "do"))
+	case data.Ref != "":
+		dataType := g.goType(data)
+		fmt.Fprintf(w, "%s (*%s, error) {\n\tvar out %s\n\tif err := %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n\treturn &out, nil\n}\n\n",
+			signature, dataType, dataType, fmt.Sprintf(call, "do"))
+	default:
+		dataType := g.goType(data)
+		fmt.Fprintf(w, "%s (%s, error) {\n\tvar out %s\n\tif err := %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n\treturn out, nil\n}\n\n",
+			signature, dataType, dataType, fmt.Sprintf(call, "do"))
+	}
+	return nil
+}
+
+// writeParams 查询参数结构体，零值的参数不发送
+func (g *clientGen) writeParams(w *bytes.Buffer, name string, params []*Parameter) {
+	g.imports["net/url"] = true
+	fmt.Fprintf(w, "// %s 查询参数，零值的字段不发送\ntype %s struct {\n", name, name)
+	for _, param := range params {
+		if param.Description != "" {
+			fmt.Fprintf(w, "\t// %s\n", param.Description)
+		}
+		fmt.Fprintf(w, "\t%s %s\n", goName(param.Name), g.goType(param.Schema))
+	}
+	fmt.Fprintf(w, "}\n\nfunc (p *%s) values() url.Values {\n\tq := url.Values{}\n\tif p == nil {\n\t\treturn q\n\t}\n", name)
+	for _, param := range params {
+		field := "p." + goName(param.Name)
+		value := field
+		if param.Schema.Nullable {
+			fmt.Fprintf(w, "\tif %s != nil {\n", field)
+			value = "*" + field
+		} else if param.Schema.Type == "boolean" {
+			fmt.Fprintf(w, "\tif %s {\n", field)
+		} else {
+			fmt.Fprintf(w, "\tif %s != %s {\n", field, zeroValue(param.Schema))
+		}
+		fmt.Fprintf(w, "\t\tq.Set(%q, %s)\n\t}\n", param.Name, g.formatValue(param.Schema, value))
+	}
+	w.WriteString("\treturn q\n}\n\n")
+}
+
+// pathExpr 拼接路径的表达式，路径参数按类型转为字符串
+func (g *clientGen) pathExpr(path string, params []*Parameter) (string, error) {
+	var parts []string
+	rest := path
+	for _, param := range params {
+		placeholder := "{" + param.Name + "}"
+		before, after, ok := strings.Cut(rest, placeholder)
+		if !ok {
+			return "", fmt.Errorf("路径 %s 中没有参数 %s", path, param.Name)
+		}
+		parts = append(parts, fmt.Sprintf("%q", before))
+		value := lowerFirst(goName(param.Name))
+		if param.Schema.Type == "string" {
+			parts = append(parts, "escapePath("+value+")")
+		} else {
+			parts = append(parts, g.formatValue(param.Schema, value))
+		}
+		rest = after
+	}
+	if rest != "" || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%q", rest))
+	}
+	return strings.Join(parts, " + "), nil
+}
+
+func (g *clientGen) formatValue(s *Schema, value string) string {
+	switch s.Type {
+	case "integer":
+		g.imports["strconv"] = true
+		if s.Format == "int64" {
+			return "strconv.FormatInt(" + value + ", 10)"
+		}
+		return "strconv.Itoa(" + value + ")"
+	case "boolean":
+		g.imports["strconv"] = true
+		return "strconv.FormatBool(" + value + ")"
+	default:
+		return value
+	}
+}
+
+func zeroValue(s *Schema) string {
+	switch s.Type {
+	case "integer", "number":
+		return "0"
+	default:
+		return `""`
+	}
+}
+
+func methodConst(method string) string {
+	switch method {
+	case http.MethodGet:
+		return "http.MethodGet"
+	case http.MethodPost:
+		return "http.MethodPost"
+	case http.MethodPut:
+		return "http.MethodPut"
+	case http.MethodDelete:
+		return "http.MethodDelete"
+	default:
+		return fmt.Sprintf("%q", method)
+	}
+}
+
+// goName 将 JSON 字段名转为导出的 Go 标识符，如 roomId -> RoomID
+func goName(name string) string {
+	var words []string
+	start := 0
+	runes := []rune(name)
+	for i := 1; i < len(runes); i++ {
+		if unicode.IsUpper(runes[i]) && !unicode.IsUpper(runes[i-1]) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	words = append(words, string(runes[start:]))
+	for i, word := range words {
+		word = exportName(word)
+		if initialism, ok := initialisms[word]; ok {
+			word = initialism
+		}
+		words[i] = word
+	}
+	return strings.Join(words, "")
+}
+
+// lowerFirst 参数名，整个名称为缩写时全部小写，如 ID -> id
+func lowerFirst(name string) string {
+	if strings.ToUpper(name) == name {
+		return strings.ToLower(name)
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
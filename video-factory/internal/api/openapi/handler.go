@@ -0,0 +1,48 @@
+package openapi
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// specJSON 接口文档只依赖路由表，首次请求时生成一次
+var specJSON = sync.OnceValues(func() ([]byte, error) {
+	return Marshal(Spec())
+})
+
+// SpecHandler 返回 OpenAPI 文档
+func SpecHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		data, err := specJSON()
+		if err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+	}
+}
+
+// UIHandler 使用 Swagger UI 展示接口文档，页面资源从 CDN 加载
+func UIHandler(specURL string) gin.HandlerFunc {
+	page := []byte(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+  <meta charset="utf-8">
+  <title>Video Factory API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "` + specURL + `", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`)
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+	}
+}
@@ -0,0 +1,69 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// TestGenerated docs/openapi.json 与 pkg/client/client_gen.go 需要与路由表一致，不一致时执行 go generate ./pkg/client
+func TestGenerated(t *testing.T) {
+	spec, err := Marshal(Spec())
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile("../../../docs/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(spec, saved) {
+		t.Error("docs/openapi.json 已过期，请执行 go generate ./pkg/client")
+	}
+
+	var doc Document
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		t.Fatal(err)
+	}
+	src, err := GenerateClient(&doc, "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	savedSrc, err := os.ReadFile("../../../pkg/client/client_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, savedSrc) {
+		t.Error("pkg/client/client_gen.go 已过期，请执行 go generate ./pkg/client")
+	}
+}
+
+func TestConvertPath(t *testing.T) {
+	path, params := convertPath("/recording/:id/thumbnail/:index")
+	if path != "/api/v1/recording/{id}/thumbnail/{index}" {
+		t.Errorf("path = %s", path)
+	}
+	if len(params) != 2 || params[0].Schema.Format != "int64" || params[1].Schema.Type != "integer" {
+		t.Errorf("params = %+v", params)
+	}
+	path, params = convertPath("/stream/proxy/:managerId/*file")
+	if path != "/api/v1/stream/proxy/{managerId}/{file}" || params[1].Schema.Type != "string" {
+		t.Errorf("path = %s, params = %+v", path, params)
+	}
+}
+
+func TestGoName(t *testing.T) {
+	cases := map[string]string{
+		"id":         "ID",
+		"roomId":     "RoomID",
+		"roomIds":    "RoomIDs",
+		"coverUrl":   "CoverURL",
+		"pageSize":   "PageSize",
+		"createTime": "CreateTime",
+	}
+	for in, want := range cases {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
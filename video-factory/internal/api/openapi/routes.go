@@ -0,0 +1,217 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"video-factory/internal/domain/vo"
+	"video-factory/internal/lineprobe"
+	"video-factory/internal/vod"
+	"video-factory/pkg/config"
+)
+
+const (
+	contentBinary   = "application/octet-stream"
+	contentImage    = "image/jpeg"
+	contentPlaylist = "application/vnd.apple.mpegurl"
+)
+
+// Route 一个 /api/v1 接口的描述，需要与 api.setupRoutes 保持一致
+type Route struct {
+	Tag     string
+	Method  string
+	Path    string // gin 路由，不含 /api/v1 前缀
+	ID      string // operationId，也是客户端的方法名
+	Summary string
+	QueryOf any     // 根据 form 标签生成查询参数
+	Query   []Param // 其余查询参数
+	Body    any     // JSON 请求体类型
+	Data    any     // 响应 data 的类型，nil 表示没有数据
+	List    bool    // data 为分页列表，Data 为列表元素类型
+	Content string  // 非 JSON 响应的内容类型，如文件下载
+}
+
+// Param 查询参数，Type 为该参数类型的零值
+type Param struct {
+	Name        string
+	Type        any
+	Description string
+}
+
+var (
+	roomIdParam = Param{Name: "roomId", Type: int64(0), Description: "房间 ID，0 表示全部"}
+	limitParam  = Param{Name: "limit", Type: 0, Description: "最多返回条数"}
+)
+
+var Tags = []Tag{
+	{Name: "room", Description: "直播间"},
+	{Name: "stream", Description: "直播流代理与 Manager"},
+	{Name: "monitor", Description: "开播监控"},
+	{Name: "recording", Description: "录制记录"},
+	{Name: "clip", Description: "片段"},
+	{Name: "files", Description: "录制文件浏览与播放"},
+	{Name: "storage", Description: "上传存储"},
+	{Name: "upload", Description: "上传规则与任务"},
+	{Name: "notify", Description: "通知渠道与订阅"},
+	{Name: "config", Description: "配置"},
+	{Name: "backup", Description: "数据库备份"},
+	{Name: "cluster", Description: "集群节点"},
+}
+
+var Routes = []Route{
+	// room
+	{Tag: "room", Method: http.MethodGet, Path: "/room/list", ID: "ListRooms", Summary: "按条件分页查询房间",
+		QueryOf: vo.RoomQueryVO{}, Data: vo.RoomVO{}, List: true},
+	{Tag: "room", Method: http.MethodGet, Path: "/room/facets", ID: "GetRoomFacets", Summary: "所有分组与标签及其房间数",
+		Data: vo.RoomFacetsVO{}},
+	{Tag: "room", Method: http.MethodPost, Path: "/room/labels", ID: "SetRoomLabels", Summary: "设置房间的分组与标签",
+		Body: vo.RoomLabelsVO{}},
+	{Tag: "room", Method: http.MethodPost, Path: "/room/bulk", ID: "BulkRooms", Summary: "对符合条件的房间批量启用、停用、开关录制",
+		Body: vo.RoomBulkVO{}, Data: vo.RoomBulkResultVO{}},
+	{Tag: "room", Method: http.MethodPost, Path: "/room/:roomId/refresh", ID: "RefreshRoom", Summary: "立即刷新房间标题、封面、主播名与头像",
+		Data: vo.RoomVO{}},
+	{Tag: "room", Method: http.MethodGet, Path: "/room/:roomId/titles", ID: "ListRoomTitles", Summary: "房间的标题变更记录",
+		Query: []Param{{Name: "sessionId", Type: int64(0), Description: "开播记录 ID，0 表示全部"}, limitParam}, Data: vo.RoomTitleVO{}, List: true},
+	{Tag: "room", Method: http.MethodGet, Path: "/room/:roomId/logs", ID: "TailRoomLogs", Summary: "房间日志文件的最后若干行，包含 ffmpeg 输出",
+		Query: []Param{{Name: "lines", Type: 0, Description: "行数，默认 200"}}, Data: json.RawMessage{}, List: true},
+	{Tag: "room", Method: http.MethodGet, Path: "/room/:roomId", ID: "GetRoom", Summary: "房间详情",
+		Data: vo.RoomVO{}},
+	{Tag: "room", Method: http.MethodDelete, Path: "/room/:roomId", ID: "RemoveRoom", Summary: "删除房间"},
+	{Tag: "room", Method: http.MethodPost, Path: "/room/add", ID: "AddRoom", Summary: "添加房间",
+		Body: vo.RoomCreateVO{}},
+	{Tag: "room", Method: http.MethodPost, Path: "/room/status", ID: "SetRoomStatus", Summary: "启用或停用房间",
+		Body: vo.RoomStatusVO{}},
+	{Tag: "room", Method: http.MethodPost, Path: "/room/recordStatus", ID: "SetRoomRecordStatus", Summary: "开启或关闭房间录制",
+		Body: vo.RoomStatusVO{}},
+	{Tag: "room", Method: http.MethodPost, Path: "/room/recordMode", ID: "SetRoomRecordMode", Summary: "修改房间的录制模式",
+		Body: vo.RoomRecordModeVO{}},
+	{Tag: "room", Method: http.MethodPost, Path: "/room/recordEngine", ID: "SetRoomRecordEngine", Summary: "修改房间的录制引擎",
+		Body: vo.RoomRecordEngineVO{}},
+	{Tag: "room", Method: http.MethodGet, Path: "/room/recordEngines", ID: "ListRecordEngines", Summary: "可用的录制引擎",
+		Data: []string{}},
+	{Tag: "room", Method: http.MethodGet, Path: "/image/:name", ID: "GetImage", Summary: "本地缓存的封面与头像",
+		Content: contentImage},
+
+	// stream
+	{Tag: "stream", Method: http.MethodGet, Path: "/stream/proxy/:managerId/*file", ID: "ProxyStream", Summary: "代理直播流，file 为播放列表或分片",
+		Content: contentBinary},
+	{Tag: "stream", Method: http.MethodGet, Path: "/stream/dvr/:managerId/*file", ID: "GetDVR", Summary: "回看的播放列表 index.m3u8 及其中的分片",
+		Content: contentBinary},
+	{Tag: "stream", Method: http.MethodPost, Path: "/stream/start/:roomId", ID: "StartStream", Summary: "启动房间的 Manager"},
+	{Tag: "stream", Method: http.MethodPost, Path: "/stream/refresh/:roomId", ID: "RefreshStream", Summary: "立即刷新直播流地址"},
+	{Tag: "stream", Method: http.MethodPost, Path: "/stream/stop/:roomId", ID: "StopStream", Summary: "停止房间的 Manager"},
+	{Tag: "stream", Method: http.MethodGet, Path: "/stream/list", ID: "ListManagers", Summary: "所有启用房间的 Manager 状态，集群模式下汇总各节点",
+		Query: []Param{{Name: "page", Type: 0}, {Name: "pageSize", Type: 0}, {Name: "local", Type: false, Description: "集群模式下只返回本节点的状态"}},
+		Data:  vo.ManagerVO{}, List: true},
+
+	// monitor
+	{Tag: "monitor", Method: http.MethodPost, Path: "/monitor/start", ID: "StartMonitor", Summary: "启动开播监控"},
+	{Tag: "monitor", Method: http.MethodPost, Path: "/monitor/stop", ID: "StopMonitor", Summary: "停止开播监控"},
+	{Tag: "monitor", Method: http.MethodPost, Path: "/monitor/restart", ID: "RestartMonitor", Summary: "重启开播监控"},
+	{Tag: "monitor", Method: http.MethodPost, Path: "/monitor/refresh", ID: "RefreshMonitor", Summary: "立即检查所有房间的开播状态"},
+	{Tag: "monitor", Method: http.MethodGet, Path: "/monitor/platform", ID: "GetPlatformStatus", Summary: "各平台的熔断与退避状态",
+		Data: vo.PlatformStatusVO{}},
+	{Tag: "monitor", Method: http.MethodGet, Path: "/monitor/lines", ID: "ListLineScores", Summary: "各平台 CDN 线路评分",
+		Data: lineprobe.Score{}, List: true},
+	{Tag: "monitor", Method: http.MethodGet, Path: "/monitor/session/:sessionId", ID: "GetSession", Summary: "开播记录及断流区间",
+		Data: vo.SessionVO{}},
+	{Tag: "monitor", Method: http.MethodGet, Path: "/monitor/session/:sessionId/transitions", ID: "ListSessionTransitions", Summary: "开播记录的状态变更历史",
+		Data: vo.TransitionVO{}, List: true},
+
+	// recording
+	{Tag: "recording", Method: http.MethodGet, Path: "/recording/list", ID: "ListRecordings", Summary: "录制记录",
+		Query: []Param{roomIdParam, limitParam}, Data: vo.RecordingVO{}, List: true},
+	{Tag: "recording", Method: http.MethodGet, Path: "/recording/:id", ID: "GetRecording", Summary: "录制记录详情",
+		Data: vo.RecordingVO{}},
+	{Tag: "recording", Method: http.MethodGet, Path: "/recording/:id/thumbnail/:index", ID: "GetThumbnail", Summary: "录制文件的缩略图",
+		Content: contentImage},
+	{Tag: "recording", Method: http.MethodGet, Path: "/recording/:id/sheet", ID: "GetContactSheet", Summary: "录制文件的缩略图拼图",
+		Content: contentImage},
+	{Tag: "recording", Method: http.MethodPost, Path: "/recording/:id/thumbnails", ID: "RegenerateThumbnails", Summary: "重新生成缩略图"},
+
+	// clip
+	{Tag: "clip", Method: http.MethodPost, Path: "/clip/live", ID: "ClipLive", Summary: "保存直播中最近一段时间的片段",
+		Body: vo.ClipLiveVO{}, Data: vo.ClipVO{}},
+	{Tag: "clip", Method: http.MethodPost, Path: "/clip/recording", ID: "ClipRecording", Summary: "从录制文件中截取片段",
+		Body: vo.ClipRecordingVO{}, Data: vo.ClipVO{}},
+	{Tag: "clip", Method: http.MethodGet, Path: "/clip/list", ID: "ListClips", Summary: "片段列表",
+		Query: []Param{roomIdParam, limitParam}, Data: vo.ClipVO{}, List: true},
+	{Tag: "clip", Method: http.MethodGet, Path: "/clip/:id", ID: "GetClip", Summary: "片段详情",
+		Data: vo.ClipVO{}},
+	{Tag: "clip", Method: http.MethodGet, Path: "/clip/:id/file", ID: "DownloadClip", Summary: "下载片段文件",
+		Content: contentBinary},
+
+	// files
+	{Tag: "files", Method: http.MethodGet, Path: "/files/browse", ID: "BrowseFiles", Summary: "浏览录制目录",
+		Query: []Param{{Name: "path", Type: "", Description: "相对录制根目录的路径"}}, Data: vod.Entry{}, List: true},
+	{Tag: "files", Method: http.MethodGet, Path: "/files/list", ID: "ListRecordingFiles", Summary: "可在线播放的录制文件",
+		Query: []Param{roomIdParam, {Name: "date", Type: "", Description: "录制日期 2006-01-02"}, limitParam}, Data: vo.RecordingFileVO{}, List: true},
+	{Tag: "files", Method: http.MethodGet, Path: "/files/stream/*path", ID: "StreamFile", Summary: "播放录制文件，支持 Range 请求",
+		Content: contentBinary},
+	{Tag: "files", Method: http.MethodGet, Path: "/files/hls/*path", ID: "GetFilePlaylist", Summary: "TS 文件的 HLS 播放列表",
+		Content: contentPlaylist},
+
+	// storage
+	{Tag: "storage", Method: http.MethodGet, Path: "/storage/list", ID: "ListStorages", Summary: "存储列表",
+		Data: vo.StorageVO{}, List: true},
+	{Tag: "storage", Method: http.MethodPost, Path: "/storage/add", ID: "AddStorage", Summary: "添加存储",
+		Body: vo.StorageAddVO{}, Data: vo.StorageVO{}},
+	{Tag: "storage", Method: http.MethodPost, Path: "/storage/:id", ID: "UpdateStorage", Summary: "修改存储",
+		Body: vo.StorageUpdateVO{}},
+	{Tag: "storage", Method: http.MethodDelete, Path: "/storage/:id", ID: "RemoveStorage", Summary: "删除存储"},
+
+	// upload
+	{Tag: "upload", Method: http.MethodGet, Path: "/upload/rule/list", ID: "ListUploadRules", Summary: "上传规则列表",
+		Data: vo.UploadRuleVO{}, List: true},
+	{Tag: "upload", Method: http.MethodPost, Path: "/upload/rule", ID: "SaveUploadRule", Summary: "保存房间的上传规则",
+		Body: vo.UploadRuleSaveVO{}},
+	{Tag: "upload", Method: http.MethodDelete, Path: "/upload/rule/:id", ID: "RemoveUploadRule", Summary: "删除上传规则"},
+	{Tag: "upload", Method: http.MethodGet, Path: "/upload/list", ID: "ListUploads", Summary: "上传任务",
+		Query: []Param{roomIdParam, {Name: "status", Type: ""}, limitParam}, Data: vo.UploadVO{}, List: true},
+	{Tag: "upload", Method: http.MethodPost, Path: "/upload/recording", ID: "UploadRecording", Summary: "手动上传录制文件",
+		Body: vo.UploadRecordingVO{}, Data: vo.UploadVO{}},
+	{Tag: "upload", Method: http.MethodPost, Path: "/upload/:id/retry", ID: "RetryUpload", Summary: "重试失败的上传任务"},
+
+	// notify
+	{Tag: "notify", Method: http.MethodGet, Path: "/notify/channel/list", ID: "ListNotifyChannels", Summary: "通知渠道列表",
+		Data: vo.NotifyChannelVO{}, List: true},
+	{Tag: "notify", Method: http.MethodPost, Path: "/notify/channel/add", ID: "AddNotifyChannel", Summary: "添加通知渠道",
+		Body: vo.NotifyChannelAddVO{}, Data: vo.NotifyChannelVO{}},
+	{Tag: "notify", Method: http.MethodPost, Path: "/notify/channel/:id", ID: "UpdateNotifyChannel", Summary: "修改通知渠道",
+		Body: vo.NotifyChannelUpdateVO{}},
+	{Tag: "notify", Method: http.MethodDelete, Path: "/notify/channel/:id", ID: "RemoveNotifyChannel", Summary: "删除通知渠道"},
+	{Tag: "notify", Method: http.MethodPost, Path: "/notify/channel/:id/test", ID: "TestNotifyChannel", Summary: "发送测试通知",
+		Query: []Param{{Name: "event", Type: "", Description: "使用该事件的模板"}}},
+	{Tag: "notify", Method: http.MethodGet, Path: "/notify/subscription/list", ID: "ListNotifySubscriptions", Summary: "订阅列表",
+		Query: []Param{{Name: "channelId", Type: int64(0), Description: "渠道 ID，0 表示全部"}}, Data: vo.NotifySubscriptionVO{}, List: true},
+	{Tag: "notify", Method: http.MethodPost, Path: "/notify/subscription", ID: "SaveNotifySubscription", Summary: "保存渠道对房间的订阅",
+		Body: vo.NotifySubscriptionSaveVO{}},
+	{Tag: "notify", Method: http.MethodDelete, Path: "/notify/subscription/:id", ID: "RemoveNotifySubscription", Summary: "删除订阅"},
+
+	// config
+	{Tag: "config", Method: http.MethodGet, Path: "/config/list", ID: "ListConfigs", Summary: "配置列表，敏感项脱敏",
+		Data: vo.ConfigVO{}, List: true},
+	{Tag: "config", Method: http.MethodPost, Path: "/config/add", ID: "AddConfig", Summary: "添加配置",
+		Body: vo.ConfigAddVO{}},
+	{Tag: "config", Method: http.MethodPost, Path: "/config/update", ID: "UpdateConfig", Summary: "修改配置",
+		Body: vo.ConfigUpdateVO{}},
+	{Tag: "config", Method: http.MethodGet, Path: "/config/schema", ID: "ListConfigSchemas", Summary: "所有配置项的声明",
+		Data: config.FieldSchema{}, List: true},
+	{Tag: "config", Method: http.MethodGet, Path: "/config/history", ID: "ListConfigHistories", Summary: "配置变更历史",
+		Query: []Param{{Name: "key", Type: ""}, limitParam}, Data: vo.ConfigHistoryVO{}, List: true},
+	{Tag: "config", Method: http.MethodPost, Path: "/config/rollback", ID: "RollbackConfig", Summary: "回滚到某条变更记录之前的值",
+		Body: vo.ConfigRollbackVO{}},
+	{Tag: "config", Method: http.MethodPost, Path: "/config/filename/preview", ID: "PreviewFilename", Summary: "预览录制文件名",
+		Body: vo.FilenamePreviewVO{}, Data: vo.FilenamePreviewResultVO{}},
+
+	// backup
+	{Tag: "backup", Method: http.MethodGet, Path: "/backup/list", ID: "ListBackups", Summary: "数据库备份列表",
+		Data: vo.BackupVO{}, List: true},
+	{Tag: "backup", Method: http.MethodPost, Path: "/backup/create", ID: "CreateBackup", Summary: "立即备份数据库",
+		Data: vo.BackupVO{}},
+	{Tag: "backup", Method: http.MethodGet, Path: "/backup/:name/file", ID: "DownloadBackup", Summary: "下载备份文件",
+		Content: contentBinary},
+
+	// cluster
+	{Tag: "cluster", Method: http.MethodGet, Path: "/cluster/nodes", ID: "ListClusterNodes", Summary: "集群节点及其持有的房间数",
+		Data: vo.ClusterNodeVO{}, List: true},
+}
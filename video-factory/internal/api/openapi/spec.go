@@ -0,0 +1,233 @@
+// Package openapi 根据路由表和 VO 类型生成 /api/v1 的 OpenAPI 3 文档，并由文档生成 Go 客户端
+//
+// 新增或修改接口后需要同步 Routes，并执行 go generate ./pkg/client 更新 docs/openapi.json 与客户端
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Version 接口文档版本
+const Version = "1.0.0"
+
+// APIPrefix 所有接口的前缀
+const APIPrefix = "/api/v1"
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem 同一路径下各请求方法的接口，key 为小写的请求方法
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path | query
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	// Order 属性的声明顺序，JSON 对象无序，生成客户端时按该顺序生成字段
+	Order []string `json:"x-order,omitempty"`
+	// Paging data 为分页列表 response.PagingData
+	Paging bool `json:"x-paging,omitempty"`
+}
+
+// RefName $ref 指向的组件名
+func (s *Schema) RefName() string {
+	return strings.TrimPrefix(s.Ref, "#/components/schemas/")
+}
+
+// Spec 根据 Routes 生成接口文档
+func Spec() *Document {
+	b := newSchemaBuilder()
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "Video Factory API",
+			Description: "所有 JSON 接口都返回 {code, message, data}，code 为 0 表示成功",
+			Version:     Version,
+		},
+		Tags:  Tags,
+		Paths: make(map[string]PathItem),
+	}
+	for _, route := range Routes {
+		path, params := convertPath(route.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = b.operation(route, params)
+	}
+	doc.Components.Schemas = b.schemas
+	return doc
+}
+
+// Marshal 格式化输出，生成的文件与接口返回的内容一致
+func Marshal(doc *Document) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// convertPath 将 gin 路由转为 OpenAPI 路径，返回路径参数
+func convertPath(ginPath string) (string, []*Parameter) {
+	segments := strings.Split(APIPrefix+ginPath, "/")
+	var params []*Parameter
+	for i, segment := range segments {
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+		name := segment[1:]
+		segments[i] = "{" + name + "}"
+		params = append(params, &Parameter{Name: name, In: "path", Required: true, Schema: pathParamSchema(name)})
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// pathParamSchema ID 类路径参数为 int64，其余为字符串
+func pathParamSchema(name string) *Schema {
+	switch {
+	case name == "id" || strings.HasSuffix(name, "Id"):
+		return &Schema{Type: "integer", Format: "int64"}
+	case name == "index":
+		return &Schema{Type: "integer"}
+	default:
+		return &Schema{Type: "string"}
+	}
+}
+
+func (b *schemaBuilder) operation(route Route, params []*Parameter) *Operation {
+	op := &Operation{
+		OperationID: route.ID,
+		Summary:     route.Summary,
+		Tags:        []string{route.Tag},
+		Parameters:  params,
+		Responses:   make(map[string]*Response),
+	}
+	if route.QueryOf != nil {
+		op.Parameters = append(op.Parameters, b.queryParams(reflect.TypeOf(route.QueryOf))...)
+	}
+	for _, param := range route.Query {
+		op.Parameters = append(op.Parameters, &Parameter{
+			Name:        param.Name,
+			In:          "query",
+			Description: param.Description,
+			Schema:      b.schemaOf(reflect.TypeOf(param.Type)),
+		})
+	}
+	if route.Body != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"application/json": {Schema: b.schemaOf(reflect.TypeOf(route.Body))}},
+		}
+	}
+
+	if route.Content != "" {
+		op.Responses["200"] = &Response{
+			Description: "文件内容",
+			Content:     map[string]*MediaType{route.Content: {Schema: &Schema{Type: "string", Format: "binary"}}},
+		}
+		return op
+	}
+	var data *Schema
+	switch {
+	case route.Data == nil:
+		data = &Schema{Nullable: true}
+	case route.List:
+		data = pagingSchema(b.schemaOf(reflect.TypeOf(route.Data)))
+	default:
+		data = b.schemaOf(reflect.TypeOf(route.Data))
+	}
+	op.Responses["200"] = &Response{
+		Description: "code 为 0 时成功，否则 message 为错误信息",
+		Content:     map[string]*MediaType{"application/json": {Schema: envelopeSchema(data)}},
+	}
+	return op
+}
+
+// envelopeSchema 统一响应 response.Response
+func envelopeSchema(data *Schema) *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code":    {Type: "integer"},
+			"data":    data,
+			"message": {Type: "string"},
+		},
+		Order: []string{"code", "data", "message"},
+	}
+}
+
+// pagingSchema 列表数据 response.PagingData
+func pagingSchema(item *Schema) *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"list":     {Type: "array", Items: item},
+			"total":    {Type: "integer", Format: "int64"},
+			"page":     {Type: "integer"},
+			"pageSize": {Type: "integer"},
+		},
+		Order:  []string{"list", "total", "page", "pageSize"},
+		Paging: true,
+	}
+}
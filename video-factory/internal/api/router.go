@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"time"
 	"video-factory/internal/api/handler"
+	"video-factory/internal/api/openapi"
 	"video-factory/pkg/pool"
 	"video-factory/web"
 
@@ -49,7 +50,9 @@ func NewEngine(p *pool.ManagerPool, handler *handler.Handler) *gin.Engine {
 	r.Use(gin.Recovery())
 	// 日志拦截
 	r.Use(LoggerSkipPaths([]string{
-		`^(/[^/]+)*/proxy/\d+/.*`, // 拦截代理请求
+		`^(/[^/]+)*/proxy/\d+/.*`,    // 拦截代理请求
+		`^(/[^/]+)*/dvr/\d+/.*`,      // 拦截回看请求
+		`^(/[^/]+)*/files/stream/.*`, // 拦截录制文件 Range 请求
 	}))
 	// 跨域
 	r.Use(cors.New(cors.Config{
@@ -77,17 +80,28 @@ func setupRoutes(r *gin.Engine, p *pool.ManagerPool, handler *handler.Handler) {
 		roomGroup := api.Group("/room")
 		{
 			roomGroup.GET("/list", handler.RoomHandler.RoomListHandler())
+			roomGroup.GET("/facets", handler.RoomHandler.RoomFacetsHandler())
+			roomGroup.POST("/labels", handler.RoomHandler.RoomLabelsHandler())
+			roomGroup.POST("/bulk", handler.RoomHandler.RoomBulkHandler())
+			roomGroup.POST("/:roomId/refresh", handler.RoomHandler.RoomRefreshHandler())
+			roomGroup.GET("/:roomId/titles", handler.RoomHandler.RoomTitlesHandler())
+			roomGroup.GET("/:roomId/logs", handler.RoomHandler.RoomLogsHandler())
 			roomGroup.GET("/:roomId", handler.RoomHandler.RoomDetailHandler())
 			roomGroup.DELETE("/:roomId", handler.RoomHandler.RoomRemoveHandler())
 			roomGroup.POST("/add", handler.RoomHandler.RoomAddHandler())
 			roomGroup.POST("/status", handler.RoomHandler.RoomStatusHandler())
 			roomGroup.POST("/recordStatus", handler.RoomHandler.RoomRecordStatusHandler())
+			roomGroup.POST("/recordMode", handler.RoomHandler.RoomRecordModeHandler())
+			roomGroup.POST("/recordEngine", handler.RoomHandler.RoomRecordEngineHandler())
+			roomGroup.GET("/recordEngines", handler.RoomHandler.RecordEnginesHandler())
 		}
 
 		streamGroup := api.Group("/stream")
 		{
 			// 代理流服务 (GET) :managerId 是路径参数 *file 是通配符，会匹配后面的所有内容（包含斜杠）
 			streamGroup.GET("/proxy/:managerId/*file", handler.StreamHandler.ProxyHandler())
+			// 回看：/dvr/:managerId/index.m3u8 及其中的分片
+			streamGroup.GET("/dvr/:managerId/*file", handler.StreamHandler.DVRHandler())
 			streamGroup.POST("/start/:roomId", handler.StreamHandler.StartHandler())
 			streamGroup.POST("/refresh/:roomId", handler.StreamHandler.RefreshHandler())
 			streamGroup.POST("/stop/:roomId", handler.StreamHandler.StopHandler())
@@ -100,6 +114,70 @@ func setupRoutes(r *gin.Engine, p *pool.ManagerPool, handler *handler.Handler) {
 			monitorGroup.POST("/stop", handler.MonitorHandler.Stop)
 			monitorGroup.POST("/restart", handler.MonitorHandler.Restart)
 			monitorGroup.POST("/refresh", handler.MonitorHandler.Refresh)
+			monitorGroup.GET("/platform", handler.MonitorHandler.PlatformStatus)
+			monitorGroup.GET("/lines", handler.MonitorHandler.LineScores)
+			monitorGroup.GET("/session/:sessionId", handler.MonitorHandler.Session)
+			monitorGroup.GET("/session/:sessionId/transitions", handler.MonitorHandler.SessionTransitions)
+		}
+
+		recordingGroup := api.Group("/recording")
+		{
+			recordingGroup.GET("/list", handler.RecordingHandler.RecordingListHandler())
+			recordingGroup.GET("/:id", handler.RecordingHandler.RecordingDetailHandler())
+			recordingGroup.GET("/:id/thumbnail/:index", handler.RecordingHandler.ThumbnailHandler())
+			recordingGroup.GET("/:id/sheet", handler.RecordingHandler.ContactSheetHandler())
+			recordingGroup.POST("/:id/thumbnails", handler.RecordingHandler.RegenerateThumbnailHandler())
+		}
+
+		clipGroup := api.Group("/clip")
+		{
+			clipGroup.POST("/live", handler.ClipHandler.ClipLiveHandler())
+			clipGroup.POST("/recording", handler.ClipHandler.ClipRecordingHandler())
+			clipGroup.GET("/list", handler.ClipHandler.ClipListHandler())
+			clipGroup.GET("/:id", handler.ClipHandler.ClipDetailHandler())
+			clipGroup.GET("/:id/file", handler.ClipHandler.ClipFileHandler())
+		}
+
+		fileGroup := api.Group("/files")
+		{
+			fileGroup.GET("/browse", handler.FileHandler.BrowseHandler())
+			fileGroup.GET("/list", handler.FileHandler.ListHandler())
+			fileGroup.GET("/stream/*path", handler.FileHandler.StreamHandler())
+			// TS 文件的 HLS 播放列表：/hls/<文件路径>.m3u8
+			fileGroup.GET("/hls/*path", handler.FileHandler.PlaylistHandler())
+		}
+
+		// 本地缓存的封面与头像
+		api.GET("/image/:name", handler.RoomHandler.ImageHandler())
+
+		storageGroup := api.Group("/storage")
+		{
+			storageGroup.GET("/list", handler.UploadHandler.StorageListHandler())
+			storageGroup.POST("/add", handler.UploadHandler.StorageAddHandler())
+			storageGroup.POST("/:id", handler.UploadHandler.StorageUpdateHandler())
+			storageGroup.DELETE("/:id", handler.UploadHandler.StorageRemoveHandler())
+		}
+
+		uploadGroup := api.Group("/upload")
+		{
+			uploadGroup.GET("/rule/list", handler.UploadHandler.RuleListHandler())
+			uploadGroup.POST("/rule", handler.UploadHandler.RuleSaveHandler())
+			uploadGroup.DELETE("/rule/:id", handler.UploadHandler.RuleRemoveHandler())
+			uploadGroup.GET("/list", handler.UploadHandler.UploadListHandler())
+			uploadGroup.POST("/recording", handler.UploadHandler.UploadRecordingHandler())
+			uploadGroup.POST("/:id/retry", handler.UploadHandler.UploadRetryHandler())
+		}
+
+		notifyGroup := api.Group("/notify")
+		{
+			notifyGroup.GET("/channel/list", handler.NotifyHandler.ChannelListHandler())
+			notifyGroup.POST("/channel/add", handler.NotifyHandler.ChannelAddHandler())
+			notifyGroup.POST("/channel/:id", handler.NotifyHandler.ChannelUpdateHandler())
+			notifyGroup.DELETE("/channel/:id", handler.NotifyHandler.ChannelRemoveHandler())
+			notifyGroup.POST("/channel/:id/test", handler.NotifyHandler.ChannelTestHandler())
+			notifyGroup.GET("/subscription/list", handler.NotifyHandler.SubscriptionListHandler())
+			notifyGroup.POST("/subscription", handler.NotifyHandler.SubscriptionSaveHandler())
+			notifyGroup.DELETE("/subscription/:id", handler.NotifyHandler.SubscriptionRemoveHandler())
 		}
 
 		configGroup := api.Group("/config")
@@ -107,9 +185,29 @@ func setupRoutes(r *gin.Engine, p *pool.ManagerPool, handler *handler.Handler) {
 			configGroup.GET("/list", handler.ConfigHandler.ConfigListHandler())
 			configGroup.POST("/add", handler.ConfigHandler.ConfigAddHandler())
 			configGroup.POST("/update", handler.ConfigHandler.ConfigUpdateHandler())
+			configGroup.GET("/schema", handler.ConfigHandler.ConfigSchemaHandler())
+			configGroup.GET("/history", handler.ConfigHandler.ConfigHistoryHandler())
+			configGroup.POST("/rollback", handler.ConfigHandler.ConfigRollbackHandler())
+			configGroup.POST("/filename/preview", handler.ConfigHandler.FilenamePreviewHandler())
+		}
+
+		backupGroup := api.Group("/backup")
+		{
+			backupGroup.GET("/list", handler.BackupHandler.BackupListHandler())
+			backupGroup.POST("/create", handler.BackupHandler.BackupCreateHandler())
+			backupGroup.GET("/:name/file", handler.BackupHandler.BackupFileHandler())
+		}
+
+		clusterGroup := api.Group("/cluster")
+		{
+			clusterGroup.GET("/nodes", handler.ClusterHandler.NodeListHandler())
 		}
 	}
 
+	// 接口文档
+	r.GET("/api/docs", openapi.UIHandler("/api/docs/openapi.json"))
+	r.GET("/api/docs/openapi.json", openapi.SpecHandler())
+
 	// =================================================================
 	// 网页后台管理分组 (Group 2: /admin)
 	// =================================================================
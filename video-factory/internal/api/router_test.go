@@ -0,0 +1,44 @@
+package api
+
+import (
+	"strings"
+	"testing"
+	"video-factory/internal/api/handler"
+	"video-factory/internal/api/openapi"
+	"video-factory/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestOpenAPIRoutes 接口文档的路由表需要与实际注册的 /api/v1 路由一致
+func TestOpenAPIRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	setupRoutes(r, nil, handler.NewHandler(nil, nil, &service.Service{}))
+
+	registered := make(map[string]bool)
+	for _, route := range r.Routes() {
+		path, ok := strings.CutPrefix(route.Path, openapi.APIPrefix)
+		if !ok {
+			continue
+		}
+		registered[route.Method+" "+path] = true
+	}
+
+	documented := make(map[string]bool)
+	for _, route := range openapi.Routes {
+		key := route.Method + " " + route.Path
+		if documented[key] {
+			t.Errorf("接口文档重复: %s", key)
+		}
+		documented[key] = true
+		if !registered[key] {
+			t.Errorf("接口文档中的路由未注册: %s", key)
+		}
+	}
+	for key := range registered {
+		if !documented[key] {
+			t.Errorf("路由缺少接口文档，需要添加到 openapi.Routes: %s", key)
+		}
+	}
+}
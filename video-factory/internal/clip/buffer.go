@@ -0,0 +1,183 @@
+package clip
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// segmentDuration 单个分段的时长，导出的精度也由此决定
+	segmentDuration = 2 * time.Second
+	// tsPacketSize MPEG-TS 包大小
+	tsPacketSize = 188
+)
+
+// ErrBufferEmpty 缓冲区中没有数据
+var ErrBufferEmpty = errors.New("缓冲区中没有数据")
+
+// segment 缓冲中的一段数据
+type segment struct {
+	start time.Time
+	end   time.Time
+	data  []byte
+}
+
+// Buffer 直播流的滚动缓冲，只保留最近一段时间的数据
+// 由录制器写入，实现 io.Writer
+type Buffer struct {
+	window   time.Duration // 保留时长
+	maxBytes int           // 保留的最大字节数，防止高码率时占用过多内存
+
+	format   string // 数据格式，即录制文件的扩展名 ts | aac
+	segments []*segment
+	size     int
+	now      func() time.Time
+	mu       sync.Mutex
+}
+
+func NewBuffer(window time.Duration, maxBytes int) *Buffer {
+	return &Buffer{
+		window:   window,
+		maxBytes: maxBytes,
+		now:      time.Now,
+	}
+}
+
+// SetFormat 设置数据格式，格式变化时清空缓冲，避免不同格式的数据拼在一起
+func (b *Buffer) SetFormat(format string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.format != format {
+		b.format = format
+		b.segments = nil
+		b.size = 0
+	}
+}
+
+// Format 数据格式
+func (b *Buffer) Format() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.format
+}
+
+// Write 写入数据，数据会被复制
+func (b *Buffer) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	last := b.last()
+	if last == nil || now.Sub(last.start) >= segmentDuration {
+		last = &segment{start: now}
+		b.segments = append(b.segments, last)
+	}
+	last.data = append(last.data, p...)
+	last.end = now
+	b.size += len(p)
+	b.trim(now)
+	return len(p), nil
+}
+
+// Reset 清空缓冲
+func (b *Buffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.segments = nil
+	b.size = 0
+}
+
+// Span 缓冲中数据的时间范围
+func (b *Buffer) Span() (time.Time, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.segments) == 0 {
+		return time.Time{}, time.Time{}
+	}
+	return b.segments[0].start, b.segments[len(b.segments)-1].end
+}
+
+// Size 缓冲中的字节数
+func (b *Buffer) Size() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.size
+}
+
+// Last 导出最近 d 时长的数据，已按格式对齐到第一个完整的包
+// 同时返回导出数据实际覆盖的时长，缓冲不足或按分段取整时与 d 不同
+func (b *Buffer) Last(d time.Duration) ([]byte, time.Duration, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.segments) == 0 {
+		return nil, 0, ErrBufferEmpty
+	}
+	end := b.segments[len(b.segments)-1].end
+	from := end.Add(-d)
+	var data []byte
+	var start time.Time
+	for _, s := range b.segments {
+		if s.end.Before(from) {
+			continue
+		}
+		if start.IsZero() {
+			start = s.start
+		}
+		data = append(data, s.data...)
+	}
+	data = align(b.format, data)
+	if len(data) == 0 {
+		return nil, 0, ErrBufferEmpty
+	}
+	return data, end.Sub(start), nil
+}
+
+func (b *Buffer) last() *segment {
+	if len(b.segments) == 0 {
+		return nil
+	}
+	return b.segments[len(b.segments)-1]
+}
+
+// trim 丢弃超出保留时长或大小的分段，至少保留最后一段
+func (b *Buffer) trim(now time.Time) {
+	drop := 0
+	for drop < len(b.segments)-1 {
+		s := b.segments[drop]
+		if now.Sub(s.end) <= b.window && (b.maxBytes <= 0 || b.size <= b.maxBytes) {
+			break
+		}
+		b.size -= len(s.data)
+		drop++
+	}
+	if drop > 0 {
+		b.segments = append([]*segment(nil), b.segments[drop:]...)
+	}
+}
+
+// align 跳过开头不完整的包，使导出的数据可以直接播放
+func align(format string, data []byte) []byte {
+	switch format {
+	case "ts":
+		for i := 0; i+tsPacketSize < len(data); i++ {
+			if data[i] == 0x47 && data[i+tsPacketSize] == 0x47 {
+				return data[i:]
+			}
+		}
+		return nil
+	case "aac":
+		// ADTS 同步字 0xFFF
+		for i := 0; i+1 < len(data); i++ {
+			if data[i] == 0xFF && data[i+1]&0xF6 == 0xF0 {
+				return data[i:]
+			}
+		}
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,122 @@
+package clip
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func tsPackets(n int, marker byte) []byte {
+	data := make([]byte, 0, n*tsPacketSize)
+	for i := 0; i < n; i++ {
+		packet := bytes.Repeat([]byte{marker}, tsPacketSize)
+		packet[0] = 0x47
+		data = append(data, packet...)
+	}
+	return data
+}
+
+func TestBufferWindow(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	b := NewBuffer(10*time.Second, 0)
+	b.now = clock.now
+	b.SetFormat("ts")
+
+	for i := 0; i < 30; i++ {
+		if _, err := b.Write(tsPackets(1, byte(i))); err != nil {
+			t.Fatal(err)
+		}
+		clock.t = clock.t.Add(time.Second)
+	}
+
+	start, end := b.Span()
+	if span := end.Sub(start); span > 12*time.Second {
+		t.Errorf("span = %v, want <= 12s", span)
+	}
+
+	data, span, err := b.Last(4 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if span < 4*time.Second || span > 6*time.Second {
+		t.Errorf("span = %v, want about 4~6s", span)
+	}
+	if len(data)%tsPacketSize != 0 {
+		t.Errorf("len(data) = %d, not aligned", len(data))
+	}
+	// 最后一个包必须是最后写入的
+	if last := data[len(data)-1]; last != 29 {
+		t.Errorf("last byte = %d, want 29", last)
+	}
+	if n := len(data) / tsPacketSize; n < 4 || n > 6 {
+		t.Errorf("packets = %d, want about 4~6", n)
+	}
+}
+
+func TestBufferMaxBytes(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	b := NewBuffer(time.Hour, 10*tsPacketSize)
+	b.now = clock.now
+	b.SetFormat("ts")
+
+	for i := 0; i < 50; i++ {
+		_, _ = b.Write(tsPackets(1, byte(i)))
+		clock.t = clock.t.Add(time.Second)
+	}
+	if size := b.Size(); size > 12*tsPacketSize {
+		t.Errorf("size = %d, want <= %d", size, 12*tsPacketSize)
+	}
+}
+
+func TestBufferAlign(t *testing.T) {
+	b := NewBuffer(time.Minute, 0)
+	b.SetFormat("ts")
+	// 开头是半个包
+	_, _ = b.Write(append([]byte{1, 2, 3}, tsPackets(3, 9)...))
+	data, span, err := b.Last(time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[0] != 0x47 || len(data) != 3*tsPacketSize {
+		t.Errorf("data not aligned, len = %d", len(data))
+	}
+	// 只有一次写入，实际时长为 0 而不是请求的一分钟
+	if span != 0 {
+		t.Errorf("span = %v, want 0", span)
+	}
+
+	// 格式变化时清空
+	b.SetFormat("aac")
+	if _, _, err := b.Last(time.Minute); err != ErrBufferEmpty {
+		t.Errorf("Last() error = %v, want ErrBufferEmpty", err)
+	}
+}
+
+func TestParseTimecode(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"01:23:00", 4980, true},
+		{"23:00", 1380, true},
+		{"83.5", 83.5, true},
+		{"00:00:01.5", 1.5, true},
+		{"1:60:00", 0, false},
+		{"a:b", 0, false},
+		{"", 0, false},
+		{"1:2:3:4", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimecode(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseTimecode(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package clip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportTimeout 单次从文件截取片段的超时
+const exportTimeout = 10 * time.Minute
+
+// ParseTimecode 解析时间点，支持 "01:23:00"、"23:00"、"83.5" 三种写法，返回秒
+func ParseTimecode(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errors.New("时间为空")
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("时间格式不正确: %s", s)
+	}
+	var seconds float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("时间格式不正确: %s", s)
+		}
+		// 除最后一段外必须是整数，且分、秒不超过 59
+		if i < len(parts)-1 && v != float64(int(v)) {
+			return 0, fmt.Errorf("时间格式不正确: %s", s)
+		}
+		if i > 0 && v >= 60 {
+			return 0, fmt.Errorf("时间格式不正确: %s", s)
+		}
+		seconds = seconds*60 + v
+	}
+	return seconds, nil
+}
+
+// WriteFile 将缓冲导出的数据写入文件
+func WriteFile(output string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(output), 0777); err != nil {
+		return fmt.Errorf("mkdir all: %w", err)
+	}
+	return os.WriteFile(output, data, 0666)
+}
+
+// ExtractFromFile 使用 ffmpeg 无损截取录制文件中 [start, start+duration) 的部分
+// 不转码，起点会落在 start 之前最近的关键帧上
+func ExtractFromFile(ctx context.Context, input string, start float64, duration float64, output string) error {
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return errors.New("未安装 ffmpeg，无法从录制文件截取片段")
+	}
+	if _, err := os.Stat(input); err != nil {
+		return fmt.Errorf("录制文件不存在: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0777); err != nil {
+		return fmt.Errorf("mkdir all: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, ffmpeg,
+		"-y", "-hide_banner", "-loglevel", "error",
+		"-ss", strconv.FormatFloat(start, 'f', 3, 64),
+		"-i", input,
+		"-t", strconv.FormatFloat(duration, 'f', 3, 64),
+		"-c", "copy",
+		"-avoid_negative_ts", "make_zero",
+		output,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		_ = os.Remove(output)
+		return fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package consts
+
+// 房间录制模式
+const (
+	RecordModeVideo = "video" // 音视频，MPEG-TS
+	RecordModeAudio = "audio" // 纯音频，AAC/M4A
+)
+
+// 纯音频录制的输出格式
+const (
+	AudioFormatAAC = "aac"
+	AudioFormatM4A = "m4a"
+)
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Restore 用备份文件替换数据库，需要先停止服务
+// 替换前会校验备份文件，并把当前数据库复制为 <数据库>.before-restore-时间，返回该路径
+func Restore(dbPath string, backupFile string) (string, error) {
+	if dbPath == "" {
+		dbPath = DefaultFilePath
+	}
+	if err := checkBackup(backupFile); err != nil {
+		return "", err
+	}
+
+	var saved string
+	if _, err := os.Stat(dbPath); err == nil {
+		saved = fmt.Sprintf("%s.before-restore-%s", dbPath, time.Now().Format("20060102-150405"))
+		if err := copyFile(dbPath, saved); err != nil {
+			return "", fmt.Errorf("保存当前数据库失败: %w", err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	// 先写临时文件再重命名，避免中途失败留下不完整的数据库
+	tmp := dbPath + ".restore"
+	if err := copyFile(backupFile, tmp); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("复制备份文件失败: %w", err)
+	}
+	// 旧数据库的 WAL 文件不属于备份，保留会在下次打开时被回放
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			os.Remove(tmp)
+			return "", err
+		}
+	}
+	if err := os.Rename(tmp, dbPath); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("替换数据库失败: %w", err)
+	}
+	return saved, nil
+}
+
+// checkBackup 校验备份文件是完整的 SQLite 数据库，并且由本程序创建
+func checkBackup(backupFile string) error {
+	info, err := os.Stat(backupFile)
+	if err != nil {
+		return fmt.Errorf("备份文件不存在: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("备份文件是目录: %s", backupFile)
+	}
+	conn, err := gorm.Open(sqlite.Open("file:"+filepath.ToSlash(backupFile)+"?mode=ro"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		return fmt.Errorf("打开备份文件失败: %w", err)
+	}
+	if sqlDB, err := conn.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	var result string
+	if err := conn.Raw("PRAGMA integrity_check").Scan(&result).Error; err != nil {
+		return fmt.Errorf("备份文件不是有效的数据库: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("备份文件已损坏: %s", result)
+	}
+	if !conn.Migrator().HasTable("t_config") {
+		return errors.New("备份文件缺少配置表，不是本程序的数据库")
+	}
+	return nil
+}
+
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
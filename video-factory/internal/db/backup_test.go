@@ -0,0 +1,51 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"video-factory/internal/domain/model"
+)
+
+func TestRestore(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "test.db")
+	conn := openTestDB(t, dbPath)
+	if err := Migrate(conn); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Create(&model.Config{ID: 1, Key: "port", Value: "8090"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	backup := filepath.Join(dir, "backup.db")
+	if err := conn.Exec("VACUUM INTO ?", backup).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Model(&model.Config{}).Where("id = ?", 1).Update("value", "9000").Error; err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := conn.DB()
+	sqlDB.Close()
+
+	// 不是数据库的文件不能用于恢复
+	invalid := filepath.Join(dir, "invalid.db")
+	os.WriteFile(invalid, []byte("not a database"), 0644)
+	if _, err := Restore(dbPath, invalid); err == nil {
+		t.Fatal("应当拒绝无效的备份文件")
+	}
+
+	saved, err := Restore(dbPath, backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(saved, dbPath+".before-restore-") {
+		t.Fatalf("saved = %s", saved)
+	}
+	for path, want := range map[string]string{dbPath: "8090", saved: "9000"} {
+		var cfg model.Config
+		if err := openTestDB(t, path).First(&cfg, 1).Error; err != nil || cfg.Value != want {
+			t.Fatalf("%s: value = %s, err = %v", path, cfg.Value, err)
+		}
+	}
+}
@@ -7,13 +7,21 @@ import (
 	"os"
 	"path/filepath"
 	"video-factory/internal/domain/model"
+	"video-factory/pkg/config"
 )
 
 var DB *gorm.DB
 
-const FilePath = "./db/video-factory.db"
+// DefaultFilePath 默认的数据库文件路径，可通过命令行参数修改
+const DefaultFilePath = "./db/video-factory.db"
 
-func InitDB() {
+// FilePath 当前使用的数据库文件路径
+var FilePath = DefaultFilePath
+
+func InitDB(path string) {
+	if path != "" {
+		FilePath = path
+	}
 	// 确保父目录存在
 	dbDir := filepath.Dir(FilePath)
 	if err := os.MkdirAll(dbDir, 0755); err != nil {
@@ -22,22 +30,21 @@ func InitDB() {
 	log.Info().Msgf("[InitDB] 数据库目录： %s", FilePath)
 
 	var err error
-	DB, err = gorm.Open(sqlite.Open(FilePath), &gorm.Config{})
+	// 集群模式下多个进程共用数据库文件，写冲突时等待而不是立即返回 database is locked
+	DB, err = gorm.Open(sqlite.Open(FilePath+"?_busy_timeout=5000"), &gorm.Config{})
 	if err != nil {
 		log.Fatal().Err(err).Msg("[InitDB] 数据库连接失败")
 	}
 	log.Info().Msg("[InitDB] 数据库连接成功！")
 
-	// 自动迁移表结构
-	if err := DB.AutoMigrate(&model.Room{}); err != nil {
-		log.Fatal().Err(err).Msg("[InitDB] 表[t_room]迁移失败")
+	// 按版本迁移表结构
+	if err := Migrate(DB); err != nil {
+		log.Fatal().Err(err).Msg("[InitDB] 数据库迁移失败")
 	}
-	if err := DB.AutoMigrate(&model.Config{}); err != nil {
-		log.Fatal().Err(err).Msg("[InitDB] 表[t_config]迁移失败")
-	}
-	log.Info().Msg("[InitDB] 数据库存在或已迁移成功！")
+	version, _ := SchemaVersion(DB)
+	log.Info().Msgf("[InitDB] 数据库存在或已迁移成功！版本: %d", version)
 
-	err = initConfigData()
+	err = initConfigData(DB)
 	if err != nil {
 		log.Fatal().Err(err).Msg("[InitConfig] 初始化config数据失败")
 	}
@@ -45,19 +52,48 @@ func InitDB() {
 	log.Info().Msg("[InitDB] 数据库初始化完成！")
 }
 
-func initConfigData() error {
-	// 检查是否有数据，如果没有，则批量插入
-	var count int64
-	DB.Model(&model.Config{}).Count(&count)
+// initConfigData 插入数据库里还没有的 key，已有的配置保持不变
+// 先按 InitialConfigs 的预设 ID 插入，其余 config.Schemas 中登记的配置项使用默认值与说明补齐，
+// 新版本增加的配置项在升级后也能出现在配置列表中并通过 ID 修改
+func initConfigData(db *gorm.DB) error {
+	var existing []model.Config
+	if err := db.Select("id", "key").Find(&existing).Error; err != nil {
+		return err
+	}
+	keys := make(map[string]bool, len(existing))
+	ids := make(map[int64]bool, len(existing))
+	for _, cfg := range existing {
+		keys[cfg.Key] = true
+		ids[cfg.ID] = true
+	}
 
-	if count == 0 {
-		log.Info().Msg("[InitConfig] 初始化config数据..")
-		result := DB.CreateInBatches(&InitialConfigs, len(InitialConfigs))
-		affected := result.RowsAffected
-		log.Info().Msgf("[InitConfig] 批量插入 %d 条数据，受影响行数: %d", len(InitialConfigs), affected)
-		return result.Error
+	var missing []model.Config
+	for _, cfg := range InitialConfigs {
+		if keys[cfg.Key] {
+			continue
+		}
+		// 预设 ID 已被手动添加的配置占用时使用自增 ID
+		if ids[cfg.ID] {
+			cfg.ID = 0
+		}
+		keys[cfg.Key] = true
+		missing = append(missing, cfg)
+	}
+	for _, schema := range config.ListSchemas() {
+		if keys[schema.Key] {
+			continue
+		}
+		keys[schema.Key] = true
+		missing = append(missing, model.Config{Key: schema.Key, Value: schema.Default, Description: schema.Description})
+	}
+	if len(missing) == 0 {
+		return nil
 	}
-	return nil
+	log.Info().Msg("[InitConfig] 初始化config数据..")
+	result := db.CreateInBatches(&missing, len(missing))
+	affected := result.RowsAffected
+	log.Info().Msgf("[InitConfig] 批量插入 %d 条数据，受影响行数: %d", len(missing), affected)
+	return result.Error
 }
 
 var InitialConfigs = []model.Config{
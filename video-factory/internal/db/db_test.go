@@ -6,6 +6,7 @@ import (
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"log"
+	"os"
 	"strings"
 	"testing"
 	"text/template"
@@ -13,8 +14,13 @@ import (
 )
 
 func TestGenerateConfigInit(t *testing.T) {
+	const path = "E:\\TLX\\Documents\\project\\003_Go\\go-oasis\\video-factory\\db\\video-factory.db"
+	// 仅用于从本地数据库生成代码，数据库不存在时跳过，避免 sqlite 在当前目录创建空文件
+	if _, err := os.Stat(path); err != nil {
+		t.Skipf("数据库不存在: %s", path)
+	}
 	var err error
-	DB, err = gorm.Open(sqlite.Open("E:\\TLX\\Documents\\project\\003_Go\\go-oasis\\video-factory\\db\\video-factory.db"), &gorm.Config{})
+	DB, err = gorm.Open(sqlite.Open(path), &gorm.Config{})
 	if err != nil {
 		log.Printf("[InitDB] 数据库连接失败")
 	}
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"video-factory/internal/domain/model"
+	"video-factory/pkg/config"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// Migration 一次数据库结构变更
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+}
+
+// Migrations 按版本递增排列，已发布的迁移不能修改，结构变更追加新的版本
+// 版本 1 使用 AutoMigrate 建立基线，兼容未记录版本的旧数据库；
+// 之后的迁移需要判断列或表是否已存在，新库执行基线时已经是最新结构
+var Migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "初始表结构",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(
+				&model.Room{},
+				&model.Config{},
+				&model.ConfigHistory{},
+				&model.LiveSession{},
+				&model.LiveSessionGap{},
+				&model.SessionTransition{},
+				&model.Recording{},
+				&model.LineScore{},
+				&model.Clip{},
+				&model.Storage{},
+				&model.UploadRule{},
+				&model.Upload{},
+				&model.RoomTitle{},
+				&model.NotifyChannel{},
+				&model.NotifySubscription{},
+			)
+		},
+	},
+	{
+		Version: 2,
+		Name:    "集群节点与房间租约",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&model.ClusterNode{}, &model.RoomLease{})
+		},
+	},
+	{
+		Version: 3,
+		Name:    "文件名格式中的绝对路径移到录制根目录",
+		Up:      migrateAbsFilenamePattern,
+	},
+}
+
+// migrateAbsFilenamePattern 旧版本的文件名格式可以是绝对路径，现在路径需要相对录制根目录
+// 将开头不含模板变量的目录移到 recorder.output_dir，其余部分保留为文件名格式
+func migrateAbsFilenamePattern(tx *gorm.DB) error {
+	var pattern model.Config
+	err := tx.Where("key = ?", "recorder.filename_pattern").Limit(1).Find(&pattern).Error
+	if err != nil || pattern.ID == 0 {
+		return err
+	}
+	dir, rest, ok := splitAbsPattern(pattern.Value)
+	if !ok {
+		return nil
+	}
+
+	var outputDir model.Config
+	if err := tx.Where("key = ?", "recorder.output_dir").Limit(1).Find(&outputDir).Error; err != nil {
+		return err
+	}
+	if outputDir.ID == 0 {
+		schema, _ := config.LookupSchema("recorder.output_dir")
+		outputDir = model.Config{Key: schema.Key, Description: schema.Description}
+	}
+	log.Warn().Str("pattern", pattern.Value).Str("output_dir", dir).Str("old_output_dir", outputDir.Value).
+		Msg("[InitDB] 文件名格式为绝对路径，目录部分移到录制根目录")
+	outputDir.Value = dir
+	if err := tx.Save(&outputDir).Error; err != nil {
+		return err
+	}
+	return tx.Model(&pattern).Update("value", rest).Error
+}
+
+// splitAbsPattern 拆分绝对路径的文件名格式，dir 为第一个含模板变量的部分之前的目录
+// 文件名部分始终保留在 rest 中，不是绝对路径时返回 false
+func splitAbsPattern(pattern string) (dir string, rest string, ok bool) {
+	if !filepath.IsAbs(pattern) && !strings.HasPrefix(pattern, "/") {
+		return "", "", false
+	}
+	volume := filepath.VolumeName(pattern)
+	segments := strings.Split(strings.TrimPrefix(filepath.ToSlash(pattern[len(volume):]), "/"), "/")
+	fixed := 0
+	for fixed < len(segments)-1 && !strings.Contains(segments[fixed], "{{") {
+		fixed++
+	}
+	dir = filepath.Join(append([]string{volume + string(filepath.Separator)}, segments[:fixed]...)...)
+	return dir, strings.Join(segments[fixed:], "/"), true
+}
+
+// Migrate 依次执行未执行过的迁移，每个版本在单独的事务中执行并记录到 t_schema_version
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(&model.SchemaVersion{}); err != nil {
+		return fmt.Errorf("表[t_schema_version]迁移失败: %w", err)
+	}
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	for _, migration := range Migrations {
+		if migration.Version <= current {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&model.SchemaVersion{Version: migration.Version, Name: migration.Name}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("迁移[%d %s]失败: %w", migration.Version, migration.Name, err)
+		}
+		log.Info().Int("version", migration.Version).Str("name", migration.Name).Msg("[InitDB] 数据库迁移完成")
+	}
+	return nil
+}
+
+// SchemaVersion 当前数据库的结构版本，未执行过迁移时为 0
+func SchemaVersion(db *gorm.DB) (int, error) {
+	var version int
+	err := db.Model(&model.SchemaVersion{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	if err != nil {
+		return 0, fmt.Errorf("查询数据库版本失败: %w", err)
+	}
+	return version, nil
+}
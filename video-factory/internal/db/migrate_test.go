@@ -0,0 +1,165 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+	"video-factory/internal/domain/model"
+	"video-factory/pkg/config"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T, path string) *gorm.DB {
+	t.Helper()
+	conn, err := gorm.Open(sqlite.Open(path), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := conn.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return conn
+}
+
+func TestMigrate(t *testing.T) {
+	conn := openTestDB(t, filepath.Join(t.TempDir(), "test.db"))
+	for range 2 {
+		if err := Migrate(conn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	version, err := SchemaVersion(conn)
+	if err != nil || version != Migrations[len(Migrations)-1].Version {
+		t.Fatalf("version = %d, err = %v", version, err)
+	}
+	var count int64
+	conn.Model(&model.SchemaVersion{}).Count(&count)
+	if count != int64(len(Migrations)) {
+		t.Fatalf("count = %d", count)
+	}
+	for i := 1; i < len(Migrations); i++ {
+		if Migrations[i].Version <= Migrations[i-1].Version {
+			t.Fatalf("迁移版本必须递增: %d", Migrations[i].Version)
+		}
+	}
+}
+
+func TestInitConfigData(t *testing.T) {
+	conn := openTestDB(t, filepath.Join(t.TempDir(), "test.db"))
+	if err := Migrate(conn); err != nil {
+		t.Fatal(err)
+	}
+	// 旧版本的数据库：只有部分默认配置，并且手动添加的配置占用了新默认配置的 ID
+	last := InitialConfigs[len(InitialConfigs)-1]
+	rows := []model.Config{
+		{ID: 1, Key: "port", Value: "9000"},
+		{ID: last.ID, Key: "custom.key", Value: "x"},
+	}
+	if err := conn.Create(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if err := initConfigData(conn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var configs []model.Config
+	conn.Find(&configs)
+	values := make(map[string]string)
+	for _, cfg := range configs {
+		if _, ok := values[cfg.Key]; ok {
+			t.Fatalf("重复的 key: %s", cfg.Key)
+		}
+		values[cfg.Key] = cfg.Value
+	}
+	if values["port"] != "9000" || values[last.Key] != last.Value || values["custom.key"] != "x" {
+		t.Fatalf("configs = %+v", configs)
+	}
+	for _, cfg := range InitialConfigs {
+		if _, ok := values[cfg.Key]; !ok {
+			t.Fatalf("缺少默认配置: %s", cfg.Key)
+		}
+	}
+}
+
+func TestInitDBSeedsSchemas(t *testing.T) {
+	oldPath, oldDB := FilePath, DB
+	InitDB(filepath.Join(t.TempDir(), "test.db"))
+	t.Cleanup(func() {
+		if sqlDB, err := DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+		FilePath, DB = oldPath, oldDB
+	})
+	var configs []model.Config
+	if err := DB.Find(&configs).Error; err != nil {
+		t.Fatal(err)
+	}
+	rows := make(map[string]model.Config, len(configs))
+	for _, cfg := range configs {
+		rows[cfg.Key] = cfg
+	}
+	preset := make(map[string]bool, len(InitialConfigs))
+	for _, cfg := range InitialConfigs {
+		preset[cfg.Key] = true
+	}
+	// 每个登记的配置项都要有对应的行，否则无法按 ID 修改
+	for key, schema := range config.Schemas {
+		row, ok := rows[key]
+		if !ok {
+			t.Fatalf("缺少配置项: %s", key)
+		}
+		if row.ID == 0 || row.Description == "" {
+			t.Fatalf("%s = %+v", key, row)
+		}
+		if !preset[key] && row.Value != schema.Default {
+			t.Fatalf("%s = %s, 默认值 %s", key, row.Value, schema.Default)
+		}
+	}
+}
+
+func TestMigrateAbsFilenamePattern(t *testing.T) {
+	conn := openTestDB(t, filepath.Join(t.TempDir(), "test.db"))
+	// 版本 2 的数据库，文件名格式为绝对路径
+	if err := conn.AutoMigrate(&model.SchemaVersion{}, &model.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	conn.Create(&model.SchemaVersion{Version: 2})
+	root := filepath.Join(t.TempDir(), "rec")
+	conn.Create(&model.Config{ID: 1, Key: "recorder.filename_pattern", Value: filepath.ToSlash(root) + "/{{.Username}}/{{.Year}}_{{.Sequence}}"})
+	if err := Migrate(conn); err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string]string)
+	var configs []model.Config
+	conn.Find(&configs)
+	for _, cfg := range configs {
+		values[cfg.Key] = cfg.Value
+	}
+	if values["recorder.output_dir"] != root || values["recorder.filename_pattern"] != "{{.Username}}/{{.Year}}_{{.Sequence}}" {
+		t.Fatalf("configs = %+v", configs)
+	}
+}
+
+func TestSplitAbsPattern(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "data")
+	cases := map[string][2]string{
+		"/data/rec/{{.Username}}_{{.Sequence}}": {filepath.Join(root, "rec"), "{{.Username}}_{{.Sequence}}"},
+		"/data/{{.Username}}/{{.Sequence}}.flv": {root, "{{.Username}}/{{.Sequence}}.flv"},
+		"/{{.Username}}":                        {string(filepath.Separator), "{{.Username}}"},
+	}
+	for pattern, want := range cases {
+		dir, rest, ok := splitAbsPattern(pattern)
+		if !ok || dir != want[0] || rest != want[1] {
+			t.Errorf("%s: dir = %s, rest = %s, ok = %v", pattern, dir, rest, ok)
+		}
+	}
+	if _, _, ok := splitAbsPattern("{{.Username}}/{{.Sequence}}"); ok {
+		t.Error("相对路径不需要迁移")
+	}
+}
@@ -0,0 +1,35 @@
+package model
+
+// Clip 从直播缓冲或录制文件导出的片段
+type Clip struct {
+	ID          int64   `gorm:"column:id;primaryKey"`
+	RoomID      int64   `gorm:"column:room_id;index"`
+	SessionID   int64   `gorm:"column:session_id"`   // 来源为直播缓冲时的开播记录 ID
+	RecordingID int64   `gorm:"column:recording_id"` // 来源为录制文件时的录制记录 ID
+	Source      string  `gorm:"column:source"`       // live | recording
+	Start       float64 `gorm:"column:start"`        // 在录制文件中的起点（秒）
+	Duration    float64 `gorm:"column:duration"`     // 秒
+	Filename    string  `gorm:"column:filename"`
+	Filesize    int64   `gorm:"column:filesize"`
+	Status      string  `gorm:"column:status"`
+	Error       string  `gorm:"column:error"`
+	CreateTime  int64   `gorm:"column:create_time;autoCreateTime:milli;type:integer"`
+	UpdateTime  int64   `gorm:"column:update_time;autoUpdateTime:milli;type:integer"`
+}
+
+// 片段来源
+const (
+	ClipSourceLive      = "live"
+	ClipSourceRecording = "recording"
+)
+
+// 片段状态
+const (
+	ClipStatusPending = "pending" // 等待导出
+	ClipStatusDone    = "done"    // 已导出
+	ClipStatusFailed  = "failed"  // 导出失败
+)
+
+func (Clip) TableName() string {
+	return "t_clip"
+}
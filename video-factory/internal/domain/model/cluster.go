@@ -0,0 +1,26 @@
+package model
+
+// ClusterNode 集群中的节点，节点定期更新心跳时间
+type ClusterNode struct {
+	ID            string `gorm:"column:id;primaryKey"`               // 节点名，启动参数 --node 指定
+	WorkerID      int64  `gorm:"column:worker_id;uniqueIndex"`       // ID 生成器的节点号，各节点不同
+	Addr          string `gorm:"column:addr"`                        // 其他节点访问本节点的地址
+	StartTime     int64  `gorm:"column:start_time;type:integer"`     // 毫秒
+	HeartbeatTime int64  `gorm:"column:heartbeat_time;type:integer"` // 毫秒
+}
+
+func (ClusterNode) TableName() string {
+	return "t_cluster_node"
+}
+
+// RoomLease 房间的租约，持有未过期租约的节点负责监控和录制该房间
+type RoomLease struct {
+	RoomID     int64  `gorm:"column:room_id;primaryKey;autoIncrement:false"`
+	NodeID     string `gorm:"column:node_id;index"`
+	ExpireTime int64  `gorm:"column:expire_time;type:integer"` // 毫秒
+	UpdateTime int64  `gorm:"column:update_time;autoUpdateTime:milli;type:integer"`
+}
+
+func (RoomLease) TableName() string {
+	return "t_room_lease"
+}
@@ -0,0 +1,22 @@
+package model
+
+// 配置变更动作
+const (
+	ConfigActionAdd      = "add"
+	ConfigActionUpdate   = "update"
+	ConfigActionRollback = "rollback"
+)
+
+type ConfigHistory struct {
+	ID         int64  `gorm:"column:id;primaryKey"`
+	ConfigID   int64  `gorm:"column:config_id;index"`
+	Key        string `gorm:"column:key;index"`
+	OldValue   string `gorm:"column:old_value"`
+	NewValue   string `gorm:"column:new_value"`
+	Action     string `gorm:"column:action"` // add | update | rollback
+	CreateTime int64  `gorm:"column:create_time;autoCreateTime:milli;type:integer"`
+}
+
+func (ConfigHistory) TableName() string {
+	return "t_config_history"
+}
@@ -0,0 +1,20 @@
+package model
+
+// LineScore 平台 CDN 线路评分，按平台 + 域名唯一
+type LineScore struct {
+	ID          int64   `gorm:"column:id;primaryKey"`
+	Platform    string  `gorm:"column:platform;uniqueIndex:idx_line_score_platform_host"`
+	Host        string  `gorm:"column:host;uniqueIndex:idx_line_score_platform_host"`
+	TTFB        float64 `gorm:"column:ttfb"`       // 首字节时间 ms
+	Throughput  float64 `gorm:"column:throughput"` // 吞吐 kbps
+	Successes   int     `gorm:"column:successes"`
+	Failures    int     `gorm:"column:failures"`     // 连续失败次数
+	LastProbe   int64   `gorm:"column:last_probe"`   // 毫秒
+	LastFailure int64   `gorm:"column:last_failure"` // 毫秒
+	CreateTime  int64   `gorm:"column:create_time;autoCreateTime:milli;type:integer"`
+	UpdateTime  int64   `gorm:"column:update_time;autoUpdateTime:milli;type:integer"`
+}
+
+func (LineScore) TableName() string {
+	return "t_line_score"
+}
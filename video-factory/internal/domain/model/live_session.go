@@ -0,0 +1,48 @@
+package model
+
+// LiveSession 一次开播记录，由 Manager 启动/停止时维护
+type LiveSession struct {
+	ID         int64 `gorm:"column:id;primaryKey"`
+	RoomID     int64 `gorm:"column:room_id;index"`
+	OpenTime   int64 `gorm:"column:open_time"`  // 平台返回的开播时间，秒
+	StartTime  int64 `gorm:"column:start_time"` // Manager 启动时间，毫秒
+	EndTime    int64 `gorm:"column:end_time"`   // Manager 停止时间，毫秒，0 表示进行中
+	Sequence   int   `gorm:"column:sequence"`   // 下一个录制文件的序号，续接开播时沿用
+	Gaps       int   `gorm:"column:gaps"`       // 断流后续接的次数
+	CreateTime int64 `gorm:"column:create_time;autoCreateTime:milli;type:integer"`
+	UpdateTime int64 `gorm:"column:update_time;autoUpdateTime:milli;type:integer"`
+}
+
+func (LiveSession) TableName() string {
+	return "t_live_session"
+}
+
+// LiveSessionGap 开播期间的断流区间，下播后在续接窗口内重新开播时记录
+type LiveSessionGap struct {
+	ID         int64 `gorm:"column:id;primaryKey"`
+	SessionID  int64 `gorm:"column:session_id;index"`
+	RoomID     int64 `gorm:"column:room_id"`
+	StartTime  int64 `gorm:"column:start_time"` // 上一段 Manager 停止时间，毫秒
+	EndTime    int64 `gorm:"column:end_time"`   // 续接的 Manager 启动时间，毫秒
+	CreateTime int64 `gorm:"column:create_time;autoCreateTime:milli;type:integer"`
+}
+
+func (LiveSessionGap) TableName() string {
+	return "t_live_session_gap"
+}
+
+// SessionTransition 开播期间 Manager 的状态变更记录
+type SessionTransition struct {
+	ID         int64  `gorm:"column:id;primaryKey"`
+	SessionID  int64  `gorm:"column:session_id;index"`
+	RoomID     int64  `gorm:"column:room_id"`
+	FromState  string `gorm:"column:from_state"` // 首次进入时为空
+	ToState    string `gorm:"column:to_state"`
+	Reason     string `gorm:"column:reason"`
+	Time       int64  `gorm:"column:time"` // 状态变更时间，毫秒
+	CreateTime int64  `gorm:"column:create_time;autoCreateTime:milli;type:integer"`
+}
+
+func (SessionTransition) TableName() string {
+	return "t_session_transition"
+}
@@ -0,0 +1,31 @@
+package model
+
+// NotifyChannel 通知渠道，Config 为对应类型的 JSON 配置
+type NotifyChannel struct {
+	ID         int64  `gorm:"column:id;primaryKey"`
+	Name       string `gorm:"column:name"`
+	Type       string `gorm:"column:type"`                // telegram | email | bark | ntfy
+	Config     string `gorm:"column:config;type:text"`    // JSON
+	Templates  string `gorm:"column:templates;type:text"` // JSON，事件 -> 模板，未配置的事件使用默认模板
+	Enabled    int    `gorm:"column:enabled"`             // 0-关闭 1-开启
+	CreateTime int64  `gorm:"column:create_time;autoCreateTime:milli;type:integer"`
+	UpdateTime int64  `gorm:"column:update_time;autoUpdateTime:milli;type:integer"`
+}
+
+func (NotifyChannel) TableName() string {
+	return "t_notify_channel"
+}
+
+// NotifySubscription 渠道对房间的订阅，RoomID 为 0 时订阅所有房间以及磁盘空间等全局事件
+type NotifySubscription struct {
+	ID         int64  `gorm:"column:id;primaryKey"`
+	ChannelID  int64  `gorm:"column:channel_id;uniqueIndex:idx_notify_sub"`
+	RoomID     int64  `gorm:"column:room_id;uniqueIndex:idx_notify_sub"`
+	Events     string `gorm:"column:events"` // 订阅的事件，格式为 ,live,record_failed,
+	CreateTime int64  `gorm:"column:create_time;autoCreateTime:milli;type:integer"`
+	UpdateTime int64  `gorm:"column:update_time;autoUpdateTime:milli;type:integer"`
+}
+
+func (NotifySubscription) TableName() string {
+	return "t_notify_subscription"
+}
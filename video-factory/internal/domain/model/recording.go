@@ -0,0 +1,37 @@
+package model
+
+// Recording 一个录制完成的文件及其直播流健康指标
+type Recording struct {
+	ID             int64   `gorm:"column:id;primaryKey"`
+	RoomID         int64   `gorm:"column:room_id;index"`
+	SessionID      int64   `gorm:"column:session_id;index"` // 对应 t_live_session.id
+	Filename       string  `gorm:"column:filename"`
+	Filesize       int64   `gorm:"column:filesize"`
+	Duration       float64 `gorm:"column:duration"`    // 秒
+	StartTime      int64   `gorm:"column:start_time"`  // 毫秒
+	EndTime        int64   `gorm:"column:end_time"`    // 毫秒
+	AvgBitrate     float64 `gorm:"column:avg_bitrate"` // kbps
+	AvgFps         float64 `gorm:"column:avg_fps"`
+	GapCount       int     `gorm:"column:gap_count"`
+	StallCount     int     `gorm:"column:stall_count"`
+	URLSwitchCount int     `gorm:"column:url_switch_count"`
+	Lines          string  `gorm:"column:lines;type:text"`      // 各线路统计，JSON
+	Samples        string  `gorm:"column:samples;type:text"`    // 采样序列，JSON
+	ThumbStatus    string  `gorm:"column:thumb_status"`         // 缩略图状态
+	Thumbnails     string  `gorm:"column:thumbnails;type:text"` // 缩略图路径，JSON
+	ContactSheet   string  `gorm:"column:contact_sheet"`        // 拼图路径
+	CreateTime     int64   `gorm:"column:create_time;autoCreateTime:milli;type:integer"`
+	UpdateTime     int64   `gorm:"column:update_time;autoUpdateTime:milli;type:integer"`
+}
+
+// 缩略图状态
+const (
+	ThumbStatusPending = "pending" // 等待生成
+	ThumbStatusDone    = "done"    // 已生成
+	ThumbStatusFailed  = "failed"  // 生成失败
+	ThumbStatusSkipped = "skipped" // 未安装 ffmpeg 或纯音频文件，跳过
+)
+
+func (Recording) TableName() string {
+	return "t_recording"
+}
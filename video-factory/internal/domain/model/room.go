@@ -12,8 +12,15 @@ type Room struct {
 	AnchorID     string `gorm:"column:anchor_id"`
 	AnchorName   string `gorm:"column:anchor_name"`
 	AnchorAvatar string `gorm:"column:anchor_avatar"`
-	Status       int    `gorm:"column:status;not null;default:0"`        // 0: 禁用 1: 启用
-	RecordStatus int    `gorm:"column:record_status;not null;default:0"` // 录制状态，0：禁用 1：启用
+	Status       int    `gorm:"column:status;not null;default:0"`          // 0: 禁用 1: 启用
+	RecordStatus int    `gorm:"column:record_status;not null;default:0"`   // 录制状态，0：禁用 1：启用
+	RecordMode   string `gorm:"column:record_mode;not null;default:video"` // 录制模式，video：音视频 audio：纯音频
+	RecordEngine string `gorm:"column:record_engine"`                      // 录制引擎，为空时使用 ffmpeg
+	GroupName    string `gorm:"column:group_name;index"`                   // 分组
+	Tags         string `gorm:"column:tags"`                               // 标签，以 ,a,b, 形式保存便于按标签查询
+	CoverCache   string `gorm:"column:cover_cache"`                        // 本地缓存的封面文件名
+	AvatarCache  string `gorm:"column:avatar_cache"`                       // 本地缓存的头像文件名
+	MetaTime     int64  `gorm:"column:meta_time"`                          // 最近一次刷新房间信息的时间，毫秒
 	CreateTime   int64  `gorm:"column:create_time;autoCreateTime:milli;type:integer"`
 	UpdateTime   int64  `gorm:"column:update_time;autoUpdateTime:milli;type:integer"`
 }
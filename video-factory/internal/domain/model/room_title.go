@@ -0,0 +1,14 @@
+package model
+
+// RoomTitle 房间标题变更记录
+type RoomTitle struct {
+	ID         int64  `gorm:"column:id;primaryKey"`
+	RoomID     int64  `gorm:"column:room_id;index"`
+	SessionID  int64  `gorm:"column:session_id"` // 变更时所在的开播记录，未开播时为 0
+	Title      string `gorm:"column:title"`
+	CreateTime int64  `gorm:"column:create_time;autoCreateTime:milli;type:integer"`
+}
+
+func (RoomTitle) TableName() string {
+	return "t_room_title"
+}
@@ -0,0 +1,12 @@
+package model
+
+// SchemaVersion 已执行的数据库迁移，每个版本一条记录
+type SchemaVersion struct {
+	Version    int    `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name       string `gorm:"column:name"`
+	CreateTime int64  `gorm:"column:create_time;autoCreateTime:milli;type:integer"`
+}
+
+func (SchemaVersion) TableName() string {
+	return "t_schema_version"
+}
@@ -0,0 +1,62 @@
+package model
+
+// Storage 上传目标，Config 为对应类型的 JSON 配置
+type Storage struct {
+	ID         int64  `gorm:"column:id;primaryKey"`
+	Name       string `gorm:"column:name"`
+	Type       string `gorm:"column:type"`             // local | s3 | webdav
+	Config     string `gorm:"column:config;type:text"` // JSON
+	CreateTime int64  `gorm:"column:create_time;autoCreateTime:milli;type:integer"`
+	UpdateTime int64  `gorm:"column:update_time;autoUpdateTime:milli;type:integer"`
+}
+
+func (Storage) TableName() string {
+	return "t_storage"
+}
+
+// UploadRule 房间的上传规则，RoomID 为 0 的规则对没有单独配置的房间生效
+type UploadRule struct {
+	ID          int64  `gorm:"column:id;primaryKey"`
+	RoomID      int64  `gorm:"column:room_id;uniqueIndex"`
+	StorageID   int64  `gorm:"column:storage_id"`
+	PathPrefix  string `gorm:"column:path_prefix"`  // 远端路径前缀
+	DeleteLocal int    `gorm:"column:delete_local"` // 上传成功后是否删除本地文件 0-否 1-是
+	Enabled     int    `gorm:"column:enabled"`      // 0-关闭 1-开启
+	CreateTime  int64  `gorm:"column:create_time;autoCreateTime:milli;type:integer"`
+	UpdateTime  int64  `gorm:"column:update_time;autoUpdateTime:milli;type:integer"`
+}
+
+func (UploadRule) TableName() string {
+	return "t_upload_rule"
+}
+
+// Upload 一个录制文件的上传任务
+type Upload struct {
+	ID          int64  `gorm:"column:id;primaryKey"`
+	RecordingID int64  `gorm:"column:recording_id;index"`
+	RoomID      int64  `gorm:"column:room_id;index"`
+	StorageID   int64  `gorm:"column:storage_id"`
+	LocalPath   string `gorm:"column:local_path"`
+	RemoteKey   string `gorm:"column:remote_key"`
+	Size        int64  `gorm:"column:size"`
+	Checksum    string `gorm:"column:checksum"` // sha256
+	Status      string `gorm:"column:status;index"`
+	Attempts    int    `gorm:"column:attempts"`
+	State       string `gorm:"column:state;type:text"` // 断点续传状态，格式由存储类型决定
+	Error       string `gorm:"column:error"`
+	DeleteLocal int    `gorm:"column:delete_local"` // 上传成功后是否删除本地文件 0-否 1-是
+	CreateTime  int64  `gorm:"column:create_time;autoCreateTime:milli;type:integer"`
+	UpdateTime  int64  `gorm:"column:update_time;autoUpdateTime:milli;type:integer"`
+}
+
+// 上传状态
+const (
+	UploadStatusPending   = "pending"   // 等待上传
+	UploadStatusUploading = "uploading" // 上传中
+	UploadStatusDone      = "done"      // 已上传并校验
+	UploadStatusFailed    = "failed"    // 重试次数用尽
+)
+
+func (Upload) TableName() string {
+	return "t_upload"
+}
@@ -12,10 +12,16 @@ type ManagerVO struct {
 	AnchorName   string     `json:"anchorName"`
 	AnchorID     string     `json:"anchorId"`
 	AnchorAvatar string     `json:"anchor_avatar"`
-	LiveStatus   int        `json:"liveStatus"`  // 0：未开播 1：直播中 2：轮播中
+	LiveStatus   int        `json:"liveStatus"` // 0：未开播 1：直播中 2：轮播中
+	SessionID    int64      `json:"sessionId,string"`
+	State        string     `json:"state"`       // Manager 状态：starting/live/refreshing/degraded/offline_grace/stopped
+	StateSince   *time.Time `json:"stateSince"`  // 进入当前状态的时间
 	URL          string     `json:"url"`         // 直播间地址
 	ProxyURL     string     `json:"proxyUrl"`    // 代理地址
 	CurrentURL   string     `json:"currentUrl"`  // 当前解析到的流地址
+	CurrentLine  string     `json:"currentLine"` // 当前使用的线路
+	Quality      string     `json:"quality"`     // 当前清晰度名称
+	Channel      string     `json:"channel"`     // 当前直播通道
 	LastRefresh  *time.Time `json:"lastRefresh"` // 最后刷新时间
 	ExpireTime   *time.Time `json:"expireTime"`  // URL 过期时间
 
@@ -25,4 +31,9 @@ type ManagerVO struct {
 	RecordSizeStr     string  `json:"recordSizeStr"`     // 当前文件大小字符串
 	RecordDuration    float64 `json:"recordDuration"`    // 当前分片时长
 	RecordDurationStr string  `json:"recordDurationStr"` // 当前分片时长字符串
+
+	RecordLine   string    `json:"recordLine"`   // 当前录制线路
+	RecordHealth *HealthVO `json:"recordHealth"` // 当前文件的直播流健康指标
+
+	NodeID string `json:"nodeId"` // 集群模式下负责该房间的节点
 }
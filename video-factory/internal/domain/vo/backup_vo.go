@@ -0,0 +1,11 @@
+package vo
+
+import "time"
+
+// BackupVO 数据库备份文件
+type BackupVO struct {
+	Name        string    `json:"name"`
+	Filesize    int64     `json:"filesize"`
+	FilesizeStr string    `json:"filesizeStr"`
+	CreateTime  time.Time `json:"createTime"`
+}
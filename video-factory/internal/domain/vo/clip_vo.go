@@ -0,0 +1,36 @@
+package vo
+
+import "time"
+
+// ClipVO 片段
+type ClipVO struct {
+	ID          int64     `json:"id,string"`
+	RoomID      int64     `json:"roomId,string"`
+	SessionID   int64     `json:"sessionId,string"`
+	RecordingID int64     `json:"recordingId,string"`
+	Source      string    `json:"source"` // live | recording
+	Start       float64   `json:"start"`
+	StartStr    string    `json:"startStr"`
+	Duration    float64   `json:"duration"`
+	DurationStr string    `json:"durationStr"`
+	Filename    string    `json:"filename"`
+	Filesize    int64     `json:"filesize"`
+	FilesizeStr string    `json:"filesizeStr"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error"`
+	FileURL     string    `json:"fileUrl"` // 导出完成后可下载
+	CreateTime  time.Time `json:"createTime"`
+}
+
+// ClipLiveVO 保存直播中最近一段时间的片段
+type ClipLiveVO struct {
+	RoomId  string `json:"roomId"`
+	Seconds int    `json:"seconds"`
+}
+
+// ClipRecordingVO 从录制文件中截取片段，start、end 为 时:分:秒 或秒数
+type ClipRecordingVO struct {
+	RecordingId string `json:"recordingId"`
+	Start       string `json:"start"`
+	End         string `json:"end"`
+}
@@ -0,0 +1,15 @@
+package vo
+
+import "time"
+
+// ClusterNodeVO 集群节点及其持有的房间数
+type ClusterNodeVO struct {
+	ID            string    `json:"id"`
+	WorkerID      int64     `json:"workerId"`
+	Addr          string    `json:"addr"`
+	StartTime     time.Time `json:"startTime"`
+	HeartbeatTime time.Time `json:"heartbeatTime"`
+	Alive         bool      `json:"alive"` // 心跳未超过租约有效期
+	Self          bool      `json:"self"`  // 是否为处理本次请求的节点
+	Rooms         int       `json:"rooms"` // 持有未过期租约的房间数
+}
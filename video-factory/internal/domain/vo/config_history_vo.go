@@ -0,0 +1,13 @@
+package vo
+
+import "time"
+
+type ConfigHistoryVO struct {
+	ID         int64     `json:"id,string"`
+	ConfigID   int64     `json:"configId,string"`
+	Key        string    `json:"key"`
+	OldValue   string    `json:"oldValue"`
+	NewValue   string    `json:"newValue"`
+	Action     string    `json:"action"`
+	CreateTime time.Time `json:"create_time"`
+}
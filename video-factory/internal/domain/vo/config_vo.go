@@ -3,10 +3,28 @@ package vo
 import "time"
 
 type ConfigVO struct {
-	ID          int64     `json:"id,string"`
-	Key         string    `json:"key"`
-	Value       string    `json:"value"`
-	Description string    `json:"description"`
-	CreateTime  time.Time `json:"create_time"`
-	UpdateTime  time.Time `json:"update_time"`
+	ID              int64     `json:"id,string"`
+	Key             string    `json:"key"`
+	Value           string    `json:"value"`
+	Description     string    `json:"description"`
+	Type            string    `json:"type"`            // 值类型
+	Secret          bool      `json:"secret"`          // 是否脱敏
+	RequiresRestart bool      `json:"requiresRestart"` // 是否需要重启生效
+	CreateTime      time.Time `json:"create_time"`
+	UpdateTime      time.Time `json:"update_time"`
+}
+
+// ConfigRollbackVO 回滚到某条变更记录之前的值
+type ConfigRollbackVO struct {
+	HistoryId int64 `json:"historyId,string" binding:"required"`
+}
+
+// FilenamePreviewVO 预览录制文件名
+type FilenamePreviewVO struct {
+	Pattern string `json:"pattern"`
+}
+
+// FilenamePreviewResultVO 文件名预览结果
+type FilenamePreviewResultVO struct {
+	Filename string `json:"filename"`
 }
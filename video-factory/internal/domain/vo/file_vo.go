@@ -0,0 +1,20 @@
+package vo
+
+import "time"
+
+// RecordingFileVO 可在线播放的录制文件
+type RecordingFileVO struct {
+	ID          int64     `json:"id,string"`
+	RoomID      int64     `json:"roomId,string"`
+	Date        string    `json:"date"` // 录制日期 2006-01-02，用于按天分组
+	Name        string    `json:"name"`
+	Path        string    `json:"path"` // 相对录制根目录
+	Filesize    int64     `json:"filesize"`
+	FilesizeStr string    `json:"filesizeStr"`
+	Duration    float64   `json:"duration"`
+	DurationStr string    `json:"durationStr"`
+	StartTime   time.Time `json:"startTime"`
+	Exists      bool      `json:"exists"`      // 文件是否仍在录制目录下
+	StreamURL   string    `json:"streamUrl"`   // 支持 Range 请求
+	PlaylistURL string    `json:"playlistUrl"` // 仅 TS 文件提供 HLS 播放列表
+}
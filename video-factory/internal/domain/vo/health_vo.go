@@ -0,0 +1,37 @@
+package vo
+
+// HealthSampleVO 健康指标的单次采样
+type HealthSampleVO struct {
+	At      int64   `json:"at"`      // 采样时间，毫秒
+	Bitrate float64 `json:"bitrate"` // 采样周期内的实际码率 kbps
+	Fps     float64 `json:"fps"`
+	Speed   float64 `json:"speed"`
+	Line    string  `json:"line"` // 当前线路
+}
+
+// LineStatsVO 单条线路在一个文件内的表现
+type LineStatsVO struct {
+	Name     string  `json:"name"`
+	Bytes    int64   `json:"bytes"`
+	Seconds  float64 `json:"seconds"` // 在该线路上录制的时长
+	Bitrate  float64 `json:"bitrate"` // 平均码率 kbps
+	Stalls   int     `json:"stalls"`
+	Gaps     int     `json:"gaps"`
+	Failures int     `json:"failures"` // 因该线路异常而切换的次数
+}
+
+// HealthVO 当前录制文件的直播流健康指标
+type HealthVO struct {
+	StartTime      int64            `json:"startTime"`      // 毫秒
+	Bitrate        float64          `json:"bitrate"`        // 最近一个采样周期的码率 kbps
+	AvgBitrate     float64          `json:"avgBitrate"`     // 平均码率 kbps
+	FfmpegBitrate  float64          `json:"ffmpegBitrate"`  // ffmpeg 统计行中的码率 kbps
+	Fps            float64          `json:"fps"`            // 最近的帧率
+	AvgFps         float64          `json:"avgFps"`         // 平均帧率
+	Speed          float64          `json:"speed"`          // 最近的速度倍率，长期低于 1 说明拉流跟不上
+	GapCount       int              `json:"gapCount"`       // 时间戳跳变次数
+	StallCount     int              `json:"stallCount"`     // 卡顿次数
+	URLSwitchCount int              `json:"urlSwitchCount"` // 线路切换次数
+	Lines          []LineStatsVO    `json:"lines"`
+	Samples        []HealthSampleVO `json:"samples,omitempty"`
+}
@@ -0,0 +1,47 @@
+package vo
+
+import "time"
+
+// NotifyChannelVO 通知渠道，配置中的密钥已脱敏
+type NotifyChannelVO struct {
+	ID         int64             `json:"id,string"`
+	Name       string            `json:"name"`
+	Type       string            `json:"type"` // telegram | email | bark | ntfy
+	Config     map[string]any    `json:"config"`
+	Templates  map[string]string `json:"templates"` // 事件 -> 模板，第一行为标题
+	Enabled    int               `json:"enabled"`   // 0: 关闭 1: 开启
+	CreateTime time.Time         `json:"createTime"`
+}
+
+// NotifySubscriptionVO 通知订阅，roomId 为 0 表示所有房间及全局事件
+type NotifySubscriptionVO struct {
+	ID          int64    `json:"id,string"`
+	ChannelID   int64    `json:"channelId,string"`
+	ChannelName string   `json:"channelName"`
+	RoomID      int64    `json:"roomId,string"`
+	AnchorName  string   `json:"anchorName"`
+	Events      []string `json:"events"` // live | record_failed | disk_low
+}
+
+// NotifyChannelAddVO 添加通知渠道，templates 为事件到模板的映射
+type NotifyChannelAddVO struct {
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	Config    map[string]any    `json:"config"`
+	Templates map[string]string `json:"templates"`
+}
+
+// NotifyChannelUpdateVO 修改通知渠道，enabled 为空时不修改
+type NotifyChannelUpdateVO struct {
+	Name      string            `json:"name"`
+	Config    map[string]any    `json:"config"`
+	Templates map[string]string `json:"templates"`
+	Enabled   *int              `json:"enabled"`
+}
+
+// NotifySubscriptionSaveVO 保存渠道对房间的事件订阅
+type NotifySubscriptionSaveVO struct {
+	ChannelId string   `json:"channelId"`
+	RoomId    string   `json:"roomId"`
+	Events    []string `json:"events"`
+}
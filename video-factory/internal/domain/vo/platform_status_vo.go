@@ -0,0 +1,12 @@
+package vo
+
+import (
+	"time"
+	"video-factory/pkg/fetcher"
+)
+
+// PlatformStatusVO 平台请求状态，用于排查房间不刷新的原因
+type PlatformStatusVO struct {
+	Breakers            []fetcher.BreakerState `json:"breakers"`            // 各平台熔断状态
+	MonitorBackoffUntil *time.Time             `json:"monitorBackoffUntil"` // 开播状态轮询退避截止时间
+}
@@ -0,0 +1,27 @@
+package vo
+
+import "time"
+
+// RecordingVO 录制记录及其直播流健康指标
+type RecordingVO struct {
+	ID             int64            `json:"id,string"`
+	RoomID         int64            `json:"roomId,string"`
+	SessionID      int64            `json:"sessionId,string"`
+	Filename       string           `json:"filename"`
+	Filesize       int64            `json:"filesize"`
+	FilesizeStr    string           `json:"filesizeStr"`
+	Duration       float64          `json:"duration"`
+	DurationStr    string           `json:"durationStr"`
+	StartTime      time.Time        `json:"startTime"`
+	EndTime        time.Time        `json:"endTime"`
+	AvgBitrate     float64          `json:"avgBitrate"` // kbps
+	AvgFps         float64          `json:"avgFps"`
+	GapCount       int              `json:"gapCount"`
+	StallCount     int              `json:"stallCount"`
+	URLSwitchCount int              `json:"urlSwitchCount"`
+	Lines          []LineStatsVO    `json:"lines"`
+	Samples        []HealthSampleVO `json:"samples,omitempty"` // 仅详情返回
+	ThumbStatus    string           `json:"thumbStatus"`
+	ThumbnailURLs  []string         `json:"thumbnailUrls"`
+	ContactSheet   string           `json:"contactSheetUrl"`
+}
@@ -15,9 +15,90 @@ type RoomVO struct {
 	AnchorAvatar string `json:"anchorAvatar"`
 	LiveStatus   int    `json:"liveStatus"` // 0: 未开播 1: 正在直播 2: 轮播中
 	// StreamStatus    int       `json:"streamStatus"` // 0: 未启动 1: 运行中
-	Status       int `json:"status"`       // 0: 禁用 1: 启用
-	RecordStatus int `json:"recordStatus"` // 0: 禁用 1: 启用
+	Status       int      `json:"status"`       // 0: 禁用 1: 启用
+	RecordStatus int      `json:"recordStatus"` // 0: 禁用 1: 启用
+	RecordMode   string   `json:"recordMode"`   // video: 音视频 audio: 纯音频
+	RecordEngine string   `json:"recordEngine"` // 录制引擎，为空时使用 ffmpeg
+	Group        string   `json:"group"`
+	Tags         []string `json:"tags"`
 	// LastRefreshTime time.Time `json:"lastRefreshTime"`
 	CreateTime time.Time `json:"createTime"`
 	UpdateTime time.Time `json:"updateTime"`
 }
+
+// RoomQueryVO 房间列表查询条件，字段为空时不过滤，page 为 0 时返回全部
+type RoomQueryVO struct {
+	RoomIDs      []string `form:"-" json:"roomIds"` // 批量操作时直接指定房间
+	Keyword      string   `form:"keyword" json:"keyword"`
+	Platform     string   `form:"platform" json:"platform"`
+	Status       *int     `form:"status" json:"status"`
+	RecordStatus *int     `form:"recordStatus" json:"recordStatus"`
+	LiveStatus   *int     `form:"liveStatus" json:"liveStatus"`
+	Group        string   `form:"group" json:"group"`
+	Tag          string   `form:"tag" json:"tag"`
+	Page         int      `form:"page" json:"-"`
+	PageSize     int      `form:"pageSize" json:"-"`
+}
+
+// RoomFacetVO 分组或标签及其房间数
+type RoomFacetVO struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// RoomBulkResultVO 批量操作结果
+type RoomBulkResultVO struct {
+	Matched int      `json:"matched"` // 符合条件的房间数
+	Changed int      `json:"changed"` // 实际变更的房间数
+	Failed  []string `json:"failed"`  // 失败的房间 id
+}
+
+// RoomTitleVO 房间标题记录
+type RoomTitleVO struct {
+	SessionID  int64     `json:"sessionId,string"`
+	Title      string    `json:"title"`
+	CreateTime time.Time `json:"createTime"`
+}
+
+// RoomCreateVO 添加房间，roomInput 可以是房间号或直播间链接
+type RoomCreateVO struct {
+	RoomInput string `json:"roomInput" binding:"required"`
+	Platform  string `json:"platform" binding:"oneof=bili missevan"`
+}
+
+// RoomLabelsVO 设置房间的分组与标签
+type RoomLabelsVO struct {
+	RoomId string   `json:"roomId"`
+	Group  string   `json:"group"`
+	Tags   []string `json:"tags"`
+}
+
+// RoomBulkVO 对符合条件的房间批量操作
+type RoomBulkVO struct {
+	Action string      `json:"action" binding:"oneof=enable disable record_on record_off"`
+	Filter RoomQueryVO `json:"filter"`
+}
+
+// RoomStatusVO 修改房间的启用或录制状态
+type RoomStatusVO struct {
+	RoomId       string `json:"roomId"`
+	TargetStatus int    `json:"status"`
+}
+
+// RoomRecordEngineVO 修改房间的录制引擎
+type RoomRecordEngineVO struct {
+	RoomId string `json:"roomId"`
+	Engine string `json:"engine"`
+}
+
+// RoomRecordModeVO 修改房间的录制模式
+type RoomRecordModeVO struct {
+	RoomId string `json:"roomId"`
+	Mode   string `json:"mode"`
+}
+
+// RoomFacetsVO 所有分组与标签
+type RoomFacetsVO struct {
+	Groups []RoomFacetVO `json:"groups"`
+	Tags   []RoomFacetVO `json:"tags"`
+}
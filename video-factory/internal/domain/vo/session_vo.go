@@ -0,0 +1,27 @@
+package vo
+
+// SessionVO 一次开播记录
+type SessionVO struct {
+	ID        int64          `json:"id,string"`
+	RoomID    int64          `json:"roomId,string"`
+	OpenTime  int64          `json:"openTime"`  // 平台返回的开播时间，秒
+	StartTime int64          `json:"startTime"` // 毫秒
+	EndTime   int64          `json:"endTime"`   // 毫秒，0 表示进行中
+	Sequence  int            `json:"sequence"`  // 下一个录制文件的序号
+	Gaps      []SessionGapVO `json:"gaps"`      // 断流后续接的区间
+}
+
+// SessionGapVO 开播期间的一次断流
+type SessionGapVO struct {
+	StartTime int64 `json:"startTime"` // 毫秒
+	EndTime   int64 `json:"endTime"`   // 毫秒
+}
+
+// TransitionVO Manager 的一次状态变更
+type TransitionVO struct {
+	ID     int64  `json:"id,string"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+	Time   int64  `json:"time"` // 毫秒时间戳
+}
@@ -0,0 +1,70 @@
+package vo
+
+import "time"
+
+// StorageVO 上传目标，配置中的密钥已脱敏
+type StorageVO struct {
+	ID         int64          `json:"id,string"`
+	Name       string         `json:"name"`
+	Type       string         `json:"type"` // local | s3 | webdav
+	Config     map[string]any `json:"config"`
+	CreateTime time.Time      `json:"createTime"`
+}
+
+// UploadRuleVO 上传规则，roomId 为 0 表示默认规则
+type UploadRuleVO struct {
+	ID          int64  `json:"id,string"`
+	RoomID      int64  `json:"roomId,string"`
+	StorageID   int64  `json:"storageId,string"`
+	StorageName string `json:"storageName"`
+	PathPrefix  string `json:"pathPrefix"`
+	DeleteLocal int    `json:"deleteLocal"` // 0: 保留 1: 上传成功后删除本地文件
+	Enabled     int    `json:"enabled"`     // 0: 关闭 1: 开启
+}
+
+// UploadVO 上传任务
+type UploadVO struct {
+	ID          int64     `json:"id,string"`
+	RecordingID int64     `json:"recordingId,string"`
+	RoomID      int64     `json:"roomId,string"`
+	StorageID   int64     `json:"storageId,string"`
+	LocalPath   string    `json:"localPath"`
+	RemoteKey   string    `json:"remoteKey"`
+	Size        int64     `json:"size"`
+	SizeStr     string    `json:"sizeStr"`
+	Checksum    string    `json:"checksum"`
+	Status      string    `json:"status"` // pending | uploading | done | failed
+	Attempts    int       `json:"attempts"`
+	Error       string    `json:"error"`
+	DeleteLocal int       `json:"deleteLocal"`
+	CreateTime  time.Time `json:"createTime"`
+	UpdateTime  time.Time `json:"updateTime"`
+}
+
+// StorageAddVO 添加存储
+type StorageAddVO struct {
+	Name   string         `json:"name"`
+	Type   string         `json:"type"`
+	Config map[string]any `json:"config"`
+}
+
+// StorageUpdateVO 修改存储
+type StorageUpdateVO struct {
+	Name   string         `json:"name"`
+	Config map[string]any `json:"config"`
+}
+
+// UploadRuleSaveVO 保存房间的上传规则
+type UploadRuleSaveVO struct {
+	RoomId      string `json:"roomId"`
+	StorageId   string `json:"storageId"`
+	PathPrefix  string `json:"pathPrefix"`
+	DeleteLocal int    `json:"deleteLocal"`
+	Enabled     int    `json:"enabled"`
+}
+
+// UploadRecordingVO 手动上传录制文件
+type UploadRecordingVO struct {
+	RecordingId string `json:"recordingId"`
+	StorageId   string `json:"storageId"`
+}
@@ -0,0 +1,404 @@
+package dvr
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// defaultPollInterval 上游未提供 TARGETDURATION 时的轮询间隔
+	defaultPollInterval = 2 * time.Second
+	// maxSegmentSize 单个分片的最大字节数，防止异常响应写满磁盘
+	maxSegmentSize = 64 * 1024 * 1024
+	// programDateTimeLayout EXT-X-PROGRAM-DATE-TIME 的格式，精确到毫秒
+	programDateTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+)
+
+// FetchFunc 请求上游，由 Manager 提供，负责 Header 与鉴权刷新
+type FetchFunc func(ctx context.Context, url string) (*http.Response, error)
+
+// Segment 已保存到本地的分片
+type Segment struct {
+	Sequence        int64
+	Name            string // 本地文件名
+	Duration        float64
+	ProgramDateTime time.Time
+	Discontinuity   bool
+	Map             string // fMP4 初始化分片的本地文件名
+	Size            int64
+}
+
+// DVR 将直播分片保存到本地，并提供可回看的滑动窗口播放列表
+// 超出回看窗口的分片会从列表头部移除并删除文件，MEDIA-SEQUENCE 随之增长
+type DVR struct {
+	dir         string
+	window      time.Duration
+	playlistURL func() string
+	fetch       FetchFunc
+
+	segments       []*Segment
+	maps           map[string]string    // 上游初始化分片地址 -> 本地文件名
+	seen           map[string]time.Time // 已处理的上游分片地址（不含参数）-> 处理时间
+	nextSeq        int64
+	discSeq        int64 // 已从头部移除的 DISCONTINUITY 数
+	lastURL        string
+	pendingDisc    bool
+	targetDuration int
+	ended          bool
+
+	now func() time.Time
+	mu  sync.RWMutex
+}
+
+// New 创建 DVR，分片保存在 dir 下，保留最近 window 时长
+func New(dir string, window time.Duration, playlistURL func() string, fetch FetchFunc) *DVR {
+	return &DVR{
+		dir:         dir,
+		window:      window,
+		playlistURL: playlistURL,
+		fetch:       fetch,
+		maps:        make(map[string]string),
+		seen:        make(map[string]time.Time),
+		now:         time.Now,
+	}
+}
+
+// Run 轮询上游播放列表并下载新分片，阻塞直到 ctx 取消，退出时删除本地文件
+func (d *DVR) Run(ctx context.Context) {
+	if err := os.MkdirAll(d.dir, 0777); err != nil {
+		log.Err(err).Str("dir", d.dir).Msg("[DVR] 创建目录失败，不启用回看")
+		return
+	}
+	defer d.Close()
+
+	log.Info().Str("dir", d.dir).Dur("window", d.window).Msg("[DVR] 开始缓存直播分片")
+	for {
+		if err := d.Poll(ctx); err != nil && ctx.Err() == nil {
+			log.Warn().Err(err).Str("dir", d.dir).Msg("[DVR] 拉取播放列表失败")
+		}
+
+		timer := time.NewTimer(d.pollInterval())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Info().Str("dir", d.dir).Msg("[DVR] 停止缓存直播分片")
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Poll 拉取一次上游播放列表，下载新出现的分片
+func (d *DVR) Poll(ctx context.Context) error {
+	playlistURL := d.playlistURL()
+	if playlistURL == "" {
+		// Manager 尚未完成首次刷新，或当前只有 FLV 等非 HLS 线路
+		return nil
+	}
+
+	playlist, err := d.fetchPlaylist(ctx, playlistURL)
+	if err != nil {
+		return err
+	}
+	// 主播放列表，取第一个子播放列表
+	if len(playlist.Segments) == 0 && len(playlist.Variants) > 0 {
+		if playlistURL, err = resolveURI(playlistURL, playlist.Variants[0]); err != nil {
+			return err
+		}
+		if playlist, err = d.fetchPlaylist(ctx, playlistURL); err != nil {
+			return err
+		}
+	}
+
+	// 切换线路或刷新 token 后地址变化，分片编号与时间戳不再连续
+	if d.lastURL != "" && stripQuery(d.lastURL) != stripQuery(playlistURL) {
+		d.pendingDisc = true
+	}
+	d.lastURL = playlistURL
+	if playlist.TargetDuration > 0 {
+		d.mu.Lock()
+		d.targetDuration = playlist.TargetDuration
+		d.mu.Unlock()
+	}
+
+	for _, seg := range playlist.Segments {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		segmentURL, err := resolveURI(playlistURL, seg.URI)
+		if err != nil {
+			continue
+		}
+		key := stripQuery(segmentURL)
+		if _, ok := d.seen[key]; ok {
+			continue
+		}
+		d.seen[key] = d.now()
+
+		if err := d.save(ctx, playlistURL, segmentURL, seg); err != nil {
+			// 缺少的分片会造成时间戳跳变，下一个分片前插入 DISCONTINUITY
+			log.Warn().Err(err).Str("url", key).Msg("[DVR] 下载分片失败")
+			d.pendingDisc = true
+		}
+	}
+	d.prune()
+	return nil
+}
+
+// save 下载分片并追加到列表
+func (d *DVR) save(ctx context.Context, playlistURL string, segmentURL string, seg playlistSegment) error {
+	mapName := ""
+	if seg.Map != "" {
+		mapURL, err := resolveURI(playlistURL, seg.Map)
+		if err != nil {
+			return err
+		}
+		if mapName = d.maps[stripQuery(mapURL)]; mapName == "" {
+			mapName = fmt.Sprintf("init_%d%s", len(d.maps), extOf(mapURL, ".mp4"))
+			if _, err := d.download(ctx, mapURL, mapName); err != nil {
+				return fmt.Errorf("下载初始化分片失败: %w", err)
+			}
+			d.maps[stripQuery(mapURL)] = mapName
+		}
+	}
+
+	name := fmt.Sprintf("%d%s", d.nextSeq, extOf(segmentURL, ".ts"))
+	size, err := d.download(ctx, segmentURL, name)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	segment := &Segment{
+		Sequence:        d.nextSeq,
+		Name:            name,
+		Duration:        seg.Duration,
+		ProgramDateTime: seg.ProgramDateTime,
+		Discontinuity:   seg.Discontinuity || d.pendingDisc,
+		Map:             mapName,
+		Size:            size,
+	}
+	if segment.ProgramDateTime.IsZero() {
+		// 上游没有提供时间，连续的分片按上一个分片推算，否则使用当前时间
+		if last := d.last(); last != nil && !segment.Discontinuity {
+			segment.ProgramDateTime = last.ProgramDateTime.Add(time.Duration(last.Duration * float64(time.Second)))
+		} else {
+			segment.ProgramDateTime = d.now()
+		}
+	}
+	d.segments = append(d.segments, segment)
+	d.nextSeq++
+	d.pendingDisc = false
+	return nil
+}
+
+// download 下载到本地文件，返回文件大小
+func (d *DVR) download(ctx context.Context, url string, name string) (int64, error) {
+	resp, err := d.fetch(ctx, url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	target := filepath.Join(d.dir, name)
+	file, err := os.Create(target)
+	if err != nil {
+		return 0, err
+	}
+	size, err := io.Copy(file, io.LimitReader(resp.Body, maxSegmentSize))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(target)
+		return 0, err
+	}
+	return size, nil
+}
+
+func (d *DVR) fetchPlaylist(ctx context.Context, url string) (*mediaPlaylist, error) {
+	resp, err := d.fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4*1024*1024))
+	if err != nil {
+		return nil, err
+	}
+	return parsePlaylist(string(body))
+}
+
+// prune 移除超出回看窗口的分片，至少保留一个
+func (d *DVR) prune() {
+	d.mu.Lock()
+	last := d.last()
+	if last == nil {
+		d.mu.Unlock()
+		return
+	}
+	cutoff := last.ProgramDateTime.Add(-d.window)
+	drop := 0
+	for drop < len(d.segments)-1 && d.segments[drop].ProgramDateTime.Before(cutoff) {
+		drop++
+	}
+	removed := d.segments[:drop]
+	for _, s := range removed {
+		if s.Discontinuity {
+			d.discSeq++
+		}
+	}
+	d.segments = append([]*Segment(nil), d.segments[drop:]...)
+	d.mu.Unlock()
+
+	for _, s := range removed {
+		if err := os.Remove(filepath.Join(d.dir, s.Name)); err != nil && !os.IsNotExist(err) {
+			log.Warn().Err(err).Str("file", s.Name).Msg("[DVR] 删除过期分片失败")
+		}
+	}
+
+	// 上游列表中的分片通常只保留几十秒，记录保留到窗口结束即可
+	seenCutoff := d.now().Add(-d.window - 10*time.Minute)
+	for key, at := range d.seen {
+		if at.Before(seenCutoff) {
+			delete(d.seen, key)
+		}
+	}
+}
+
+// Playlist 生成本地播放列表，分片地址为相对路径
+// 列表头部的分片会被移除，不满足 EVENT 类型只追加的要求，因此不声明 PLAYLIST-TYPE
+func (d *DVR) Playlist() string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	target := d.targetDuration
+	for _, s := range d.segments {
+		target = max(target, int(math.Ceil(s.Duration)))
+	}
+	if target == 0 {
+		target = int(defaultPollInterval.Seconds())
+	}
+
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	b.WriteString("#EXT-X-VERSION:7\n")
+	fmt.Fprintf(&b, "#EXT-X-TARGETDURATION:%d\n", target)
+	mediaSeq := d.nextSeq
+	if len(d.segments) > 0 {
+		mediaSeq = d.segments[0].Sequence
+	}
+	fmt.Fprintf(&b, "#EXT-X-MEDIA-SEQUENCE:%d\n", mediaSeq)
+	if d.discSeq > 0 {
+		fmt.Fprintf(&b, "#EXT-X-DISCONTINUITY-SEQUENCE:%d\n", d.discSeq)
+	}
+
+	currentMap := ""
+	for _, s := range d.segments {
+		if s.Discontinuity {
+			b.WriteString("#EXT-X-DISCONTINUITY\n")
+		}
+		if s.Map != "" && s.Map != currentMap {
+			fmt.Fprintf(&b, "#EXT-X-MAP:URI=\"%s\"\n", s.Map)
+			currentMap = s.Map
+		}
+		fmt.Fprintf(&b, "#EXT-X-PROGRAM-DATE-TIME:%s\n", s.ProgramDateTime.Format(programDateTimeLayout))
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\n", s.Duration)
+		b.WriteString(s.Name + "\n")
+	}
+	if d.ended {
+		b.WriteString("#EXT-X-ENDLIST\n")
+	}
+	return b.String()
+}
+
+// File 返回本地分片的路径，只允许访问列表中的文件
+func (d *DVR) File(name string) (string, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, s := range d.segments {
+		if s.Name == name || s.Map == name {
+			return filepath.Join(d.dir, name), true
+		}
+	}
+	return "", false
+}
+
+// Segments 当前回看窗口内的分片
+func (d *DVR) Segments() []Segment {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	list := make([]Segment, 0, len(d.segments))
+	for _, s := range d.segments {
+		list = append(list, *s)
+	}
+	return list
+}
+
+// Close 结束播放列表并删除本地文件
+func (d *DVR) Close() {
+	d.mu.Lock()
+	d.ended = true
+	d.segments = nil
+	d.mu.Unlock()
+	if err := os.RemoveAll(d.dir); err != nil {
+		log.Err(err).Str("dir", d.dir).Msg("[DVR] 删除回看目录失败")
+	}
+}
+
+func (d *DVR) pollInterval() time.Duration {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.targetDuration <= 0 {
+		return defaultPollInterval
+	}
+	// 按目标时长的一半轮询，保证不漏分片
+	return max(time.Duration(d.targetDuration)*time.Second/2, time.Second)
+}
+
+func (d *DVR) last() *Segment {
+	if len(d.segments) == 0 {
+		return nil
+	}
+	return d.segments[len(d.segments)-1]
+}
+
+func stripQuery(rawURL string) string {
+	if i := strings.IndexByte(rawURL, '?'); i >= 0 {
+		return rawURL[:i]
+	}
+	return rawURL
+}
+
+// extOf 取地址路径的扩展名，只接受常见分片格式
+func extOf(rawURL string, fallback string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fallback
+	}
+	switch ext := strings.ToLower(path.Ext(u.Path)); ext {
+	case ".ts", ".m4s", ".mp4", ".aac", ".m4a":
+		return ext
+	}
+	return fallback
+}
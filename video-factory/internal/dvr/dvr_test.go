@@ -0,0 +1,204 @@
+package dvr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLive 模拟直播 CDN，每次 advance 产生一个新分片，列表只保留最近 3 个
+type fakeLive struct {
+	mu    sync.Mutex
+	first int
+	count int
+	fmp4  bool
+}
+
+func (f *fakeLive) advance() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.count++
+	if f.count-f.first > 3 {
+		f.first = f.count - 3
+	}
+}
+
+func (f *fakeLive) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.URL.Path == "/live/index.m3u8":
+		if r.URL.Query().Get("token") != "abc" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var b strings.Builder
+		b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:2\n")
+		fmt.Fprintf(&b, "#EXT-X-MEDIA-SEQUENCE:%d\n", f.first)
+		if f.fmp4 {
+			b.WriteString("#EXT-X-MAP:URI=\"init.mp4\"\n")
+		}
+		ext := ".ts"
+		if f.fmp4 {
+			ext = ".m4s"
+		}
+		for i := f.first; i < f.count; i++ {
+			fmt.Fprintf(&b, "#EXTINF:2.000,\nseg%d%s\n", i, ext)
+		}
+		_, _ = w.Write([]byte(b.String()))
+	case strings.HasPrefix(r.URL.Path, "/live/seg") || r.URL.Path == "/live/init.mp4":
+		if r.URL.Query().Get("token") != "abc" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte("data:" + r.URL.Path))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestDVR(t *testing.T, live *fakeLive, window time.Duration) *DVR {
+	server := httptest.NewServer(live)
+	t.Cleanup(server.Close)
+
+	clock := time.Unix(1700000000, 0)
+	d := New(t.TempDir(), window,
+		func() string { return server.URL + "/live/index.m3u8?token=abc" },
+		func(ctx context.Context, url string) (*http.Response, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return nil, err
+			}
+			return http.DefaultClient.Do(req)
+		})
+	d.now = func() time.Time { return clock }
+	return d
+}
+
+func TestDVRPollAndPlaylist(t *testing.T) {
+	live := &fakeLive{}
+	d := newTestDVR(t, live, time.Hour)
+
+	for i := 0; i < 6; i++ {
+		live.advance()
+		if err := d.Poll(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	segments := d.Segments()
+	if len(segments) != 6 {
+		t.Fatalf("len(segments) = %d, want 6", len(segments))
+	}
+	for i, s := range segments {
+		if s.Sequence != int64(i) {
+			t.Errorf("segments[%d].Sequence = %d", i, s.Sequence)
+		}
+		data, err := os.ReadFile(filepath.Join(d.dir, s.Name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("data:/live/seg%d.ts", i); string(data) != want {
+			t.Errorf("segment %d = %q, want %q", i, data, want)
+		}
+	}
+	// 上游未提供时间，按时长连续推算
+	if got := segments[5].ProgramDateTime.Sub(segments[0].ProgramDateTime); got != 10*time.Second {
+		t.Errorf("program date time span = %v, want 10s", got)
+	}
+
+	playlist := d.Playlist()
+	for _, want := range []string{
+		"#EXT-X-MEDIA-SEQUENCE:0",
+		"#EXT-X-PROGRAM-DATE-TIME:" + segments[0].ProgramDateTime.Format(programDateTimeLayout),
+		"#EXTINF:2.000,\n5.ts",
+	} {
+		if !strings.Contains(playlist, want) {
+			t.Errorf("playlist missing %q:\n%s", want, playlist)
+		}
+	}
+	if strings.Contains(playlist, "#EXT-X-ENDLIST") {
+		t.Error("live playlist should not end")
+	}
+	// 头部分片会被移除，不能声明为 EVENT
+	if strings.Contains(playlist, "#EXT-X-PLAYLIST-TYPE") {
+		t.Error("windowed playlist should not declare a playlist type")
+	}
+
+	if _, ok := d.File("3.ts"); !ok {
+		t.Error("File(3.ts) not found")
+	}
+	if _, ok := d.File("../3.ts"); ok {
+		t.Error("File should reject unknown names")
+	}
+}
+
+func TestDVRPrune(t *testing.T) {
+	live := &fakeLive{}
+	d := newTestDVR(t, live, 5*time.Second)
+
+	for i := 0; i < 8; i++ {
+		live.advance()
+		if err := d.Poll(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	segments := d.Segments()
+	if len(segments) > 4 {
+		t.Errorf("len(segments) = %d, want <= 4", len(segments))
+	}
+	if _, err := os.Stat(filepath.Join(d.dir, "0.ts")); !os.IsNotExist(err) {
+		t.Errorf("pruned segment file still exists")
+	}
+	if !strings.Contains(d.Playlist(), fmt.Sprintf("#EXT-X-MEDIA-SEQUENCE:%d", segments[0].Sequence)) {
+		t.Errorf("media sequence not advanced:\n%s", d.Playlist())
+	}
+
+	d.Close()
+	if !strings.Contains(d.Playlist(), "#EXT-X-ENDLIST") {
+		t.Error("closed playlist should end")
+	}
+	if _, err := os.Stat(d.dir); !os.IsNotExist(err) {
+		t.Error("dir not removed after Close")
+	}
+}
+
+func TestDVRFmp4Map(t *testing.T) {
+	live := &fakeLive{fmp4: true}
+	d := newTestDVR(t, live, time.Hour)
+	live.advance()
+	live.advance()
+	if err := d.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	playlist := d.Playlist()
+	if strings.Count(playlist, "#EXT-X-MAP:URI=\"init_0.mp4\"") != 1 {
+		t.Errorf("playlist should contain one map:\n%s", playlist)
+	}
+	if _, ok := d.File("init_0.mp4"); !ok {
+		t.Error("init segment not served")
+	}
+	if !strings.Contains(playlist, "1.m4s") {
+		t.Errorf("playlist missing m4s segment:\n%s", playlist)
+	}
+}
+
+func TestParsePlaylistVariants(t *testing.T) {
+	p, err := parsePlaylist("#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=1000\nhigh/index.m3u8\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Variants) != 1 || p.Variants[0] != "high/index.m3u8" {
+		t.Errorf("Variants = %v", p.Variants)
+	}
+	if _, err := parsePlaylist("<html>"); err == nil {
+		t.Error("parsePlaylist should reject non m3u8")
+	}
+}
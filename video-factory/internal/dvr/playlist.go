@@ -0,0 +1,125 @@
+package dvr
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mediaPlaylist 上游 HLS 媒体播放列表中 DVR 关心的部分
+type mediaPlaylist struct {
+	TargetDuration int
+	MediaSequence  int64
+	Segments       []playlistSegment
+	Variants       []string // 主播放列表中的子播放列表，媒体播放列表为空
+	Ended          bool
+}
+
+type playlistSegment struct {
+	Sequence        int64
+	Duration        float64
+	URI             string
+	Map             string    // EXT-X-MAP 的 URI，fMP4 才有
+	ProgramDateTime time.Time // 上游未提供时为零值
+	Discontinuity   bool
+}
+
+// parsePlaylist 解析 m3u8，只支持 DVR 用到的标签
+func parsePlaylist(content string) (*mediaPlaylist, error) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	if !scanner.Scan() || !strings.HasPrefix(strings.TrimSpace(scanner.Text()), "#EXTM3U") {
+		return nil, fmt.Errorf("不是 m3u8 播放列表")
+	}
+
+	p := &mediaPlaylist{}
+	var (
+		next        playlistSegment
+		currentMap  string
+		hasDuration bool
+		isVariant   bool
+	)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#EXT-X-TARGETDURATION:"):
+			p.TargetDuration, _ = strconv.Atoi(strings.TrimPrefix(line, "#EXT-X-TARGETDURATION:"))
+		case strings.HasPrefix(line, "#EXT-X-MEDIA-SEQUENCE:"):
+			p.MediaSequence, _ = strconv.ParseInt(strings.TrimPrefix(line, "#EXT-X-MEDIA-SEQUENCE:"), 10, 64)
+		case strings.HasPrefix(line, "#EXT-X-MAP:"):
+			currentMap = attribute(strings.TrimPrefix(line, "#EXT-X-MAP:"), "URI")
+		case strings.HasPrefix(line, "#EXT-X-PROGRAM-DATE-TIME:"):
+			if t, err := time.Parse(time.RFC3339Nano, strings.TrimPrefix(line, "#EXT-X-PROGRAM-DATE-TIME:")); err == nil {
+				next.ProgramDateTime = t
+			}
+		case line == "#EXT-X-DISCONTINUITY":
+			next.Discontinuity = true
+		case strings.HasPrefix(line, "#EXTINF:"):
+			value := strings.TrimPrefix(line, "#EXTINF:")
+			if i := strings.IndexByte(value, ','); i >= 0 {
+				value = value[:i]
+			}
+			next.Duration, _ = strconv.ParseFloat(value, 64)
+			hasDuration = true
+		case strings.HasPrefix(line, "#EXT-X-STREAM-INF"):
+			isVariant = true
+		case line == "#EXT-X-ENDLIST":
+			p.Ended = true
+		case strings.HasPrefix(line, "#"):
+			// 其他标签忽略
+		default:
+			if isVariant {
+				p.Variants = append(p.Variants, line)
+				isVariant = false
+				continue
+			}
+			if !hasDuration {
+				continue
+			}
+			next.URI = line
+			next.Map = currentMap
+			next.Sequence = p.MediaSequence + int64(len(p.Segments))
+			p.Segments = append(p.Segments, next)
+			next = playlistSegment{}
+			hasDuration = false
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// attribute 读取属性列表中的值，如 URI="init.mp4"
+func attribute(attrs string, key string) string {
+	for _, part := range strings.Split(attrs, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok && k == key {
+			return strings.Trim(v, `"`)
+		}
+	}
+	return ""
+}
+
+// resolveURI 将播放列表中的相对地址转换为绝对地址
+// 分片没有自己的参数时沿用播放列表的参数，很多平台的鉴权 token 在播放列表的参数上
+func resolveURI(playlistURL string, ref string) (string, error) {
+	base, err := url.Parse(playlistURL)
+	if err != nil {
+		return "", err
+	}
+	rel, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	target := base.ResolveReference(rel)
+	if rel.RawQuery == "" && !rel.IsAbs() {
+		target.RawQuery = base.RawQuery
+	}
+	return target.String(), nil
+}
@@ -0,0 +1,206 @@
+package fakeplatform
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// biliResponse B 站接口的通用返回
+type biliResponse struct {
+	Code    int    `json:"code"`
+	Msg     string `json:"msg"`
+	Message string `json:"message"`
+	Data    any    `json:"data"`
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeBili 写入 B 站格式的返回，设置了风控错误码时直接返回错误
+func (s *Server) writeBili(w http.ResponseWriter, r *http.Request, data any) {
+	s.hit(r.URL.Path)
+	s.mu.Lock()
+	riskCode := s.riskCode
+	s.mu.Unlock()
+	if riskCode != 0 {
+		writeJSON(w, biliResponse{Code: riskCode, Msg: "请求被拦截", Message: "请求被拦截"})
+		return
+	}
+	writeJSON(w, biliResponse{Code: 0, Msg: "ok", Message: "ok", Data: data})
+}
+
+func (s *Server) writeBiliError(w http.ResponseWriter, r *http.Request, code int, msg string) {
+	s.hit(r.URL.Path)
+	writeJSON(w, biliResponse{Code: code, Msg: msg, Message: msg})
+}
+
+func liveStatus(room Room) int {
+	if room.Live {
+		return 1
+	}
+	return 0
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func (s *Server) biliRoomInfo(w http.ResponseWriter, r *http.Request) {
+	room, ok := s.room(r.URL.Query().Get("room_id"))
+	if !ok {
+		s.writeBiliError(w, r, 1, "未找到该房间")
+		return
+	}
+	liveTime := "0000-00-00 00:00:00"
+	if room.Live {
+		liveTime = room.LiveTime.Format(time.DateTime)
+	}
+	s.writeBili(w, r, map[string]any{
+		"uid":         atoi(room.UID),
+		"room_id":     atoi(room.ID),
+		"short_id":    atoi(room.ShortID),
+		"live_status": liveStatus(room),
+		"title":       room.Title,
+		"user_cover":  s.URL + "/cover/" + room.ID + ".jpg",
+		"live_time":   liveTime,
+	})
+}
+
+func (s *Server) biliRoomInit(w http.ResponseWriter, r *http.Request) {
+	room, ok := s.room(r.URL.Query().Get("id"))
+	if !ok {
+		s.writeBiliError(w, r, 60004, "直播间不存在")
+		return
+	}
+	s.writeBili(w, r, map[string]any{
+		"room_id":     atoi(room.ID),
+		"short_id":    atoi(room.ShortID),
+		"uid":         atoi(room.UID),
+		"live_status": liveStatus(room),
+		"live_time":   room.LiveTime.Unix(),
+	})
+}
+
+func (s *Server) biliAnchorInfo(w http.ResponseWriter, r *http.Request) {
+	uid := r.URL.Query().Get("uid")
+	s.mu.Lock()
+	var anchor *Room
+	for _, room := range s.rooms {
+		if room.UID == uid {
+			copied := *room
+			anchor = &copied
+			break
+		}
+	}
+	s.mu.Unlock()
+	if anchor == nil {
+		s.writeBiliError(w, r, 1, "用户不存在")
+		return
+	}
+	s.writeBili(w, r, map[string]any{
+		"info": map[string]any{
+			"uid":    atoi(anchor.UID),
+			"uname":  anchor.Anchor,
+			"face":   s.URL + "/face/" + anchor.UID + ".jpg",
+			"gender": 0,
+		},
+	})
+}
+
+func (s *Server) biliPlayInfo(w http.ResponseWriter, r *http.Request) {
+	room, ok := s.room(r.URL.Query().Get("room_id"))
+	if !ok {
+		s.writeBiliError(w, r, 1, "未找到该房间")
+		return
+	}
+	data := map[string]any{
+		"room_id":     atoi(room.ID),
+		"live_status": liveStatus(room),
+	}
+	if room.Live {
+		s.mu.Lock()
+		lines := s.lines
+		s.mu.Unlock()
+
+		// 与真实接口一致：完整地址为 host + base_url + extra
+		urlInfo := make([]map[string]any, 0, lines)
+		for line := 1; line <= lines; line++ {
+			urlInfo = append(urlInfo, map[string]any{"host": s.URL, "extra": s.signedQuery(room.ID, line), "stream_ttl": 0})
+		}
+		qn := atoi(r.URL.Query().Get("qn"))
+		if qn != 10000 && qn != 400 {
+			qn = 10000
+		}
+		data["playurl_info"] = map[string]any{
+			"playurl": map[string]any{
+				"stream": []any{map[string]any{
+					"protocol_name": "http_stream",
+					"format": []any{map[string]any{
+						"format_name": "flv",
+						"codec": []any{map[string]any{
+							"codec_name": "avc",
+							"current_qn": qn,
+							"accept_qn":  []int{10000, 400},
+							"base_url":   "/live/" + room.ID + ".flv?",
+							"url_info":   urlInfo,
+						}},
+					}},
+				}},
+			},
+		}
+	}
+	s.writeBili(w, r, data)
+}
+
+func (s *Server) biliStatusByUids(w http.ResponseWriter, r *http.Request) {
+	uids := r.URL.Query()["uids[]"]
+	result := make(map[string]any, len(uids))
+	s.mu.Lock()
+	for _, uid := range uids {
+		for _, room := range s.rooms {
+			if room.UID != uid {
+				continue
+			}
+			var liveTime int64
+			if room.Live {
+				liveTime = room.LiveTime.Unix()
+			}
+			result[uid] = map[string]any{
+				"uid":             atoi(room.UID),
+				"room_id":         atoi(room.ID),
+				"title":           room.Title,
+				"live_status":     liveStatus(*room),
+				"live_time":       liveTime,
+				"uname":           room.Anchor,
+				"cover_from_user": s.URL + "/cover/" + room.ID + ".jpg",
+			}
+		}
+	}
+	s.mu.Unlock()
+	if len(result) == 0 {
+		// 真实接口没有结果时 data 为 []
+		s.writeBili(w, r, []any{})
+		return
+	}
+	s.writeBili(w, r, result)
+}
+
+// biliNav 未登录状态的 nav 接口，只返回 wbi 签名所需的 key
+func (s *Server) biliNav(w http.ResponseWriter, r *http.Request) {
+	s.hit(r.URL.Path)
+	writeJSON(w, map[string]any{
+		"code":    -101,
+		"message": "账号未登录",
+		"data": map[string]any{
+			"wbi_img": map[string]any{
+				"img_url": s.URL + "/bfs/wbi/7cd084941338484aae1ad9425b84077c.png",
+				"sub_url": s.URL + "/bfs/wbi/4932caff0ff746eab6f01bf08b70ac45.png",
+			},
+		},
+	})
+}
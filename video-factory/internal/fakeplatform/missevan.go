@@ -0,0 +1,40 @@
+package fakeplatform
+
+import (
+	"net/http"
+)
+
+// missevanRoom 猫耳直播间信息，开播时返回 flv 与 hls 拉流地址
+func (s *Server) missevanRoom(w http.ResponseWriter, r *http.Request) {
+	s.hit("/api/v2/live/")
+	room, ok := s.room(r.PathValue("id"))
+	if !ok {
+		writeJSON(w, map[string]any{"code": 500030004, "info": "直播间不存在"})
+		return
+	}
+
+	channel := map[string]any{}
+	status := map[string]any{"open": liveStatus(room), "open_time": 0}
+	if room.Live {
+		channel["flv_pull_url"] = s.streamURL(room.ID, "flv", 1)
+		channel["hls_pull_url"] = s.streamURL(room.ID, "m3u8", 1)
+		status["open_time"] = room.LiveTime.UnixMilli()
+	}
+	writeJSON(w, map[string]any{
+		"code": 0,
+		"info": map[string]any{
+			"room": map[string]any{
+				"room_id":   atoi(room.ID),
+				"name":      room.Title,
+				"cover_url": s.URL + "/cover/" + room.ID + ".jpg",
+				"channel":   channel,
+				"status":    status,
+			},
+			"creator": map[string]any{
+				"user_id":  atoi(room.UID),
+				"username": room.Anchor,
+				"iconurl":  s.URL + "/face/" + room.UID + ".jpg",
+			},
+		},
+	})
+}
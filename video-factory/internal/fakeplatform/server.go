@@ -0,0 +1,211 @@
+// Package fakeplatform 基于 httptest 的模拟直播平台，用于在没有网络的环境下测试
+// 监控 → Manager → 代理 → 录制 的完整流程
+//
+// 同时提供 B 站与猫耳兼容的接口，配合 bili.SetBaseURL / missevan.SetBaseURL 使用：
+//   - 直播间状态可随时切换（开播/下播）
+//   - 直播流地址带签名与过期时间，过期或签名失效后返回 403
+//   - 可模拟风控错误码（-412、-352）
+//   - 下播后直播流断开，再次请求返回 404
+package fakeplatform
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultTTL 直播流地址的默认有效期
+	defaultTTL = 10 * time.Minute
+	// chunkSize 直播流每次写出的数据量
+	chunkSize = 16 * 1024
+	// chunkInterval 直播流写出数据的间隔，约 800KB/s
+	chunkInterval = 20 * time.Millisecond
+	// segmentDuration HLS 分片时长
+	segmentDuration = 2 * time.Second
+)
+
+// Room 模拟的直播间
+type Room struct {
+	ID       string // 真实房间号
+	ShortID  string // 短号，可为空
+	UID      string // 主播 uid
+	Title    string
+	Anchor   string
+	Live     bool
+	LiveTime time.Time // 开播时间，为零时开播时自动设置
+}
+
+// Server 模拟的直播平台
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	rooms    map[string]*Room
+	ttl      time.Duration
+	lines    int
+	riskCode int    // 不为 0 时 B 站接口返回该错误码
+	secret   string // 签名密钥，ExpireLinks 时更换
+	secretNo int
+	hits     map[string]int
+	offline  map[string]chan struct{} // 下播时关闭，断开正在进行的直播流
+}
+
+// New 启动模拟平台，测试结束后需调用 Close
+func New() *Server {
+	s := &Server{
+		rooms:   make(map[string]*Room),
+		ttl:     defaultTTL,
+		lines:   2,
+		hits:    make(map[string]int),
+		offline: make(map[string]chan struct{}),
+	}
+	s.rotateSecret()
+
+	mux := http.NewServeMux()
+	// B 站
+	mux.HandleFunc("GET /room/v1/Room/get_info", s.biliRoomInfo)
+	mux.HandleFunc("GET /room/v1/Room/room_init", s.biliRoomInit)
+	mux.HandleFunc("GET /live_user/v1/Master/info", s.biliAnchorInfo)
+	mux.HandleFunc("GET /xlive/web-room/v2/index/getRoomPlayInfo", s.biliPlayInfo)
+	mux.HandleFunc("GET /room/v1/Room/get_status_info_by_uids", s.biliStatusByUids)
+	mux.HandleFunc("GET /x/web-interface/nav", s.biliNav)
+	// 猫耳
+	mux.HandleFunc("GET /api/v2/live/{id}", s.missevanRoom)
+	// 直播流
+	mux.HandleFunc("GET /live/{file}", s.stream)
+
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// AddRoom 添加直播间，已存在时覆盖
+func (s *Server) AddRoom(room Room) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if room.Live && room.LiveTime.IsZero() {
+		room.LiveTime = time.Now()
+	}
+	s.rooms[room.ID] = &room
+	if _, ok := s.offline[room.ID]; !ok {
+		s.offline[room.ID] = make(chan struct{})
+	}
+}
+
+// SetLive 切换开播状态，下播时断开正在进行的直播流
+func (s *Server) SetLive(roomId string, live bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	room, ok := s.rooms[roomId]
+	if !ok || room.Live == live {
+		return
+	}
+	room.Live = live
+	if live {
+		room.LiveTime = time.Now()
+		s.offline[roomId] = make(chan struct{})
+		return
+	}
+	close(s.offline[roomId])
+}
+
+// SetTTL 设置之后下发的直播流地址的有效期
+func (s *Server) SetTTL(ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ttl = ttl
+}
+
+// SetLines 设置下发的线路数量
+func (s *Server) SetLines(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lines = max(n, 1)
+}
+
+// ExpireLinks 让已下发的直播流地址全部失效，模拟签名过期
+func (s *Server) ExpireLinks() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rotateSecret()
+}
+
+// SetRiskControl 设置 B 站接口返回的风控错误码，0 表示恢复正常
+func (s *Server) SetRiskControl(code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.riskCode = code
+}
+
+// Hits 指定路径被请求的次数，直播流按 /live/ 统计，403 另计为 /live/403
+func (s *Server) Hits(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[path]
+}
+
+func (s *Server) hit(path string) {
+	s.mu.Lock()
+	s.hits[path]++
+	s.mu.Unlock()
+}
+
+// room 按真实房间号或短号查找直播间，返回副本
+func (s *Server) room(id string) (Room, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if room, ok := s.rooms[id]; ok {
+		return *room, true
+	}
+	for _, room := range s.rooms {
+		if room.ShortID != "" && room.ShortID == id {
+			return *room, true
+		}
+	}
+	return Room{}, false
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+func (s *Server) rotateSecret() {
+	s.secretNo++
+	s.secret = strconv.FormatInt(time.Now().UnixNano(), 36) + strconv.Itoa(s.secretNo)
+}
+
+// streamURL 生成带签名的直播流地址
+func (s *Server) streamURL(roomId string, ext string, line int) string {
+	return fmt.Sprintf("%s/live/%s.%s?%s", s.URL, roomId, ext, s.signedQuery(roomId, line))
+}
+
+// signedQuery 直播流地址的签名参数，B 站接口中即 url_info 的 extra
+func (s *Server) signedQuery(roomId string, line int) string {
+	s.mu.Lock()
+	expires := time.Now().Add(s.ttl).Unix()
+	secret := s.secret
+	s.mu.Unlock()
+	return fmt.Sprintf("expires=%d&line=%d&sign=%s", expires, line, sign(secret, roomId, expires, line))
+}
+
+func sign(secret string, roomId string, expires int64, line int) string {
+	hash := md5.Sum([]byte(fmt.Sprintf("%s|%s|%d|%d", secret, roomId, expires, line)))
+	return hex.EncodeToString(hash[:8])
+}
+
+// verify 校验直播流地址的签名与有效期
+func (s *Server) verify(r *http.Request, roomId string) bool {
+	query := r.URL.Query()
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() >= expires {
+		return false
+	}
+	line, _ := strconv.Atoi(query.Get("line"))
+	s.mu.Lock()
+	secret := s.secret
+	s.mu.Unlock()
+	return query.Get("sign") == sign(secret, roomId, expires, line)
+}
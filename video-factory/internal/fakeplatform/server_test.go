@@ -0,0 +1,193 @@
+package fakeplatform
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"video-factory/internal/common/consts"
+	"video-factory/internal/iface"
+	"video-factory/internal/site/bili"
+	"video-factory/internal/site/missevan"
+	"video-factory/pkg/config"
+	"video-factory/pkg/fetcher"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	fetcher.Init(&config.AppConfig{})
+	s := New()
+	if err := bili.SetBaseURL(s.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := missevan.SetBaseURL(s.URL); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		s.Close()
+		_ = bili.SetBaseURL("")
+		_ = missevan.SetBaseURL("")
+		fetcher.GetBreaker(consts.PlatformBili).Reset()
+		fetcher.GetBreaker(consts.PlatformMissevan).Reset()
+	})
+	return s
+}
+
+func get(t *testing.T, u string) *http.Response {
+	t.Helper()
+	resp, err := http.Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestBili(t *testing.T) {
+	s := newTestServer(t)
+	s.AddRoom(Room{ID: "1001", ShortID: "11", UID: "501", Title: "测试直播", Anchor: "主播A", Live: true})
+
+	info, err := bili.GetRoomAddInfo("11")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.RealID != "1001" || info.AnchorID != "501" || info.AnchorName != "主播A" || info.Name != "测试直播" {
+		t.Fatalf("room info = %+v", info)
+	}
+
+	statuses, err := bili.GetRoomsLiveStatusByUids([]string{"501", "502"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statuses["501"] != 1 || statuses["502"] != 0 {
+		t.Fatalf("statuses = %v", statuses)
+	}
+
+	streamer := bili.NewStreamer("1001", &config.AppConfig{})
+	if streamer.GetOpenTime() == 0 {
+		t.Fatal("open time should be set")
+	}
+	streamInfo, err := streamer.FetchStreamInfo(0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(streamInfo.StreamUrls) != 2 {
+		t.Fatalf("lines = %v", streamInfo.StreamUrls)
+	}
+	streamURL := streamInfo.StreamUrls["线路1"]
+	expire, err := streamer.ParseExpiration(streamURL)
+	if err != nil || time.Until(expire) < 9*time.Minute {
+		t.Fatalf("expire = %v, err = %v", expire, err)
+	}
+
+	// 直播流
+	resp := get(t, streamURL)
+	header := make([]byte, 3)
+	if _, err := io.ReadFull(resp.Body, header); err != nil || string(header) != "FLV" {
+		t.Fatalf("status = %d, header = %q, err = %v", resp.StatusCode, header, err)
+	}
+
+	// 签名失效后 403，新地址可用
+	s.ExpireLinks()
+	if resp := get(t, streamURL); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expired link status = %d", resp.StatusCode)
+	}
+	if s.Hits("/live/403") != 1 {
+		t.Fatalf("403 hits = %d", s.Hits("/live/403"))
+	}
+	streamInfo, err = streamer.FetchStreamInfo(0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp := get(t, streamInfo.StreamUrls["线路2"]); resp.StatusCode != http.StatusOK {
+		t.Fatalf("refreshed link status = %d", resp.StatusCode)
+	}
+
+	// 下播：正在进行的直播流断开，接口返回未开播
+	s.SetLive("1001", false)
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		t.Fatalf("stream should end cleanly: %v", err)
+	}
+	if _, err := streamer.FetchStreamInfo(0, false); !errors.Is(err, iface.ErrRoomOffline) {
+		t.Fatalf("err = %v, want ErrRoomOffline", err)
+	}
+}
+
+func TestBiliRiskControl(t *testing.T) {
+	s := newTestServer(t)
+	s.AddRoom(Room{ID: "1002", UID: "502", Live: true})
+	// 两个平台共用模拟服务的地址，熔断器按域名生效，重新登记为 B 站
+	_ = bili.SetBaseURL(s.URL)
+
+	s.SetRiskControl(-412)
+	_, err := bili.FetchRoomInfo("1002")
+	if !fetcher.IsRateLimited(err) {
+		t.Fatalf("err = %v, want rate limited", err)
+	}
+	// 熔断中，恢复后依然被拒绝，直到熔断器重置
+	s.SetRiskControl(0)
+	if _, err := bili.FetchRoomInfo("1002"); !fetcher.IsRateLimited(err) {
+		t.Fatalf("err = %v, want circuit open", err)
+	}
+	fetcher.GetBreaker(consts.PlatformBili).Reset()
+	if _, err := bili.FetchRoomInfo("1002"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMissevan(t *testing.T) {
+	s := newTestServer(t)
+	s.AddRoom(Room{ID: "2001", UID: "601", Title: "猫耳直播", Anchor: "主播B", Live: true})
+	s.SetTTL(time.Minute)
+
+	info, err := missevan.GetRoomAddInfo("2001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.RealID != "2001" || info.AnchorName != "主播B" {
+		t.Fatalf("room info = %+v", info)
+	}
+
+	streamer := missevan.NewStreamer("2001", &config.AppConfig{})
+	streamInfo, err := streamer.FetchStreamInfo(0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expire, err := streamer.ParseExpiration(streamInfo.StreamUrls["hls"])
+	if err != nil || time.Until(expire) > time.Minute {
+		t.Fatalf("expire = %v, err = %v", expire, err)
+	}
+
+	// 播放列表中的分片为相对路径，沿用播放列表的签名参数
+	playlistURL := streamInfo.StreamUrls["hls"]
+	resp := get(t, playlistURL)
+	var segment string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if line := scanner.Text(); !strings.HasPrefix(line, "#") {
+			segment = line
+		}
+	}
+	if !strings.HasPrefix(segment, "2001-") {
+		t.Fatalf("segment = %q", segment)
+	}
+	base := playlistURL[:strings.LastIndex(playlistURL, "/")+1]
+	query := playlistURL[strings.Index(playlistURL, "?"):]
+	if resp := get(t, base+segment+query); resp.StatusCode != http.StatusOK {
+		t.Fatalf("segment status = %d", resp.StatusCode)
+	}
+	if resp := get(t, base+segment); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("unsigned segment status = %d", resp.StatusCode)
+	}
+
+	s.SetLive("2001", false)
+	if _, err := streamer.FetchStreamInfo(0, false); !errors.Is(err, iface.ErrRoomOffline) {
+		t.Fatalf("err = %v, want ErrRoomOffline", err)
+	}
+	if resp := get(t, playlistURL); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("offline playlist status = %d", resp.StatusCode)
+	}
+}
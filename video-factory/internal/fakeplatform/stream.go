@@ -0,0 +1,104 @@
+package fakeplatform
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// flvHeader FLV 文件头，带音频和视频
+var flvHeader = []byte{'F', 'L', 'V', 0x01, 0x05, 0x00, 0x00, 0x00, 0x09, 0x00, 0x00, 0x00, 0x00}
+
+// stream 直播流：{id}.flv 持续输出数据直到下播，{id}.m3u8 为滚动的播放列表，{id}-{n}.ts 为分片
+func (s *Server) stream(w http.ResponseWriter, r *http.Request) {
+	s.hit("/live/")
+	file := r.PathValue("file")
+	ext := path.Ext(file)
+	name := strings.TrimSuffix(file, ext)
+	roomId, seq, _ := strings.Cut(name, "-")
+
+	room, ok := s.room(roomId)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if !s.verify(r, room.ID) {
+		s.hit("/live/403")
+		http.Error(w, "403 Forbidden", http.StatusForbidden)
+		return
+	}
+	if !room.Live {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch ext {
+	case ".flv":
+		s.serveFLV(w, r, room.ID)
+	case ".m3u8":
+		servePlaylist(w, r, room)
+	case ".ts":
+		if _, err := strconv.Atoi(seq); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "video/mp2t")
+		// 只需要数据量，内容是重复的 TS 同步字节
+		_, _ = w.Write(bytes.Repeat([]byte{0x47}, 4*chunkSize))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveFLV(w http.ResponseWriter, r *http.Request, roomId string) {
+	s.mu.Lock()
+	offline := s.offline[roomId]
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "video/x-flv")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	if _, err := w.Write(flvHeader); err != nil {
+		return
+	}
+
+	chunk := bytes.Repeat([]byte{0x09}, chunkSize)
+	ticker := time.NewTicker(chunkInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := w.Write(chunk); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-offline:
+			// 下播，断开连接
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// servePlaylist 按开播时长计算分片序号，保留最近 3 个分片，分片地址为相对路径
+func servePlaylist(w http.ResponseWriter, r *http.Request, room Room) {
+	last := int(time.Since(room.LiveTime) / segmentDuration)
+	first := max(last-2, 0)
+
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+	fmt.Fprintf(&b, "#EXT-X-TARGETDURATION:%d\n", int(segmentDuration.Seconds()))
+	fmt.Fprintf(&b, "#EXT-X-MEDIA-SEQUENCE:%d\n", first)
+	for seq := first; seq <= last; seq++ {
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\n%s-%d.ts\n", segmentDuration.Seconds(), room.ID, seq)
+	}
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	_, _ = w.Write([]byte(b.String()))
+}
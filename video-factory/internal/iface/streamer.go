@@ -7,10 +7,12 @@ import (
 
 // StreamInfo 包含通用的流媒体信息
 type StreamInfo struct {
-	AcceptQns  []int // 可以使用的清晰度
-	SelectedQn int
-	ActualQn   int               // 实际获得的清晰度编号
-	StreamUrls map[string]string // 线路名 -> 完整的 HLS/Flv URL
+	AcceptQns   []int // 可以使用的清晰度
+	SelectedQn  int
+	ActualQn    int               // 实际获得的清晰度编号
+	QualityName string            // 实际清晰度的名称，用于展示
+	Channel     string            // 直播通道，如 CDN 域名
+	StreamUrls  map[string]string // 线路名 -> 完整的 HLS/Flv URL
 }
 
 // Streamer 定义了所有直播平台需要实现的方法
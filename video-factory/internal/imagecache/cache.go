@@ -0,0 +1,142 @@
+package imagecache
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxImageSize 单张图片最大字节数
+const maxImageSize = 8 * 1024 * 1024
+
+// namePattern 缓存文件名：url 的 sha1 加扩展名
+var namePattern = regexp.MustCompile(`^[0-9a-f]{40}\.(jpg|png|gif|webp)$`)
+
+// ErrNotImage 响应不是图片
+var ErrNotImage = errors.New("响应不是图片")
+
+// Cache 将封面、头像等平台图片下载到本地目录，前端通过本地地址访问，不直接引用平台 CDN
+type Cache struct {
+	dir    string
+	client *http.Client
+}
+
+// New 创建图片缓存，client 为 nil 时使用默认客户端
+func New(dir string, client *http.Client) *Cache {
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &Cache{dir: dir, client: client}
+}
+
+// Fetch 下载图片并返回缓存文件名，已缓存时直接返回
+func (c *Cache) Fetch(ctx context.Context, rawURL string) (string, error) {
+	if rawURL == "" {
+		return "", errors.New("图片地址为空")
+	}
+	// 平台接口可能返回 // 开头的地址
+	if strings.HasPrefix(rawURL, "//") {
+		rawURL = "https:" + rawURL
+	}
+	sum := sha1.Sum([]byte(rawURL))
+	key := hex.EncodeToString(sum[:])
+	if name, ok := c.lookup(key); ok {
+		return name, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("下载图片失败: status %d", resp.StatusCode)
+	}
+	ext := imageExt(resp.Header.Get("Content-Type"))
+	if ext == "" {
+		return "", ErrNotImage
+	}
+
+	if err := os.MkdirAll(c.dir, 0777); err != nil {
+		return "", err
+	}
+	name := key + "." + ext
+	tmp, err := os.CreateTemp(c.dir, key+"-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	n, err := io.Copy(tmp, io.LimitReader(resp.Body, maxImageSize+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n > maxImageSize {
+		err = fmt.Errorf("图片超过 %d 字节", maxImageSize)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(c.dir, name)); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+	return name, nil
+}
+
+// Path 缓存文件的路径，文件名不合法或不存在时返回 false
+func (c *Cache) Path(name string) (string, bool) {
+	if !namePattern.MatchString(name) {
+		return "", false
+	}
+	path := filepath.Join(c.dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// Remove 删除缓存文件，文件以图片地址命名，调用方需确认没有其他地方引用
+func (c *Cache) Remove(name string) {
+	if namePattern.MatchString(name) {
+		_ = os.Remove(filepath.Join(c.dir, name))
+	}
+}
+
+func (c *Cache) lookup(key string) (string, bool) {
+	for _, ext := range []string{"jpg", "png", "gif", "webp"} {
+		if _, ok := c.Path(key + "." + ext); ok {
+			return key + "." + ext, true
+		}
+	}
+	return "", false
+}
+
+func imageExt(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "image/jpeg", "image/jpg":
+		return "jpg"
+	case "image/png":
+		return "png"
+	case "image/gif":
+		return "gif"
+	case "image/webp":
+		return "webp"
+	}
+	return ""
+}
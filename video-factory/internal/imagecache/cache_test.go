@@ -0,0 +1,64 @@
+package imagecache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestFetch(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/cover.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("\x89PNG fake"))
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte("<html></html>"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cache := New(t.TempDir(), server.Client())
+	name, err := cache.Fetch(context.Background(), server.URL+"/cover.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, ok := cache.Path(name)
+	if !ok {
+		t.Fatalf("Path(%q) not found", name)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "\x89PNG fake" {
+		t.Errorf("cached content = %q", data)
+	}
+
+	// 已缓存时不再请求
+	if again, err := cache.Fetch(context.Background(), server.URL+"/cover.png"); err != nil || again != name {
+		t.Errorf("second Fetch() = %q, %v", again, err)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+
+	if _, err := cache.Fetch(context.Background(), server.URL+"/page"); !errors.Is(err, ErrNotImage) {
+		t.Errorf("Fetch(html) error = %v", err)
+	}
+	if _, err := cache.Fetch(context.Background(), server.URL+"/missing.jpg"); err == nil {
+		t.Error("Fetch(404) should fail")
+	}
+
+	cache.Remove(name)
+	if _, ok := cache.Path(name); ok {
+		t.Error("file should be removed")
+	}
+	if _, ok := cache.Path("../secret.jpg"); ok {
+		t.Error("invalid name should be rejected")
+	}
+}
@@ -0,0 +1,198 @@
+package lineprobe
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"video-factory/pkg/fetcher"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// probeTimeout 单条线路的探测超时
+	probeTimeout = 5 * time.Second
+	// probeMaxBytes 测速最多读取的字节数
+	probeMaxBytes = 512 * 1024
+)
+
+// Result 一次探测的结果
+type Result struct {
+	TTFB       time.Duration
+	Throughput float64 // kbps
+	Bytes      int64
+}
+
+// Prober 测量线路的首字节时间与吞吐
+type Prober struct {
+	Client   *http.Client
+	Timeout  time.Duration
+	MaxBytes int64
+}
+
+func NewProber() *Prober {
+	return &Prober{
+		Timeout:  probeTimeout,
+		MaxBytes: probeMaxBytes,
+	}
+}
+
+func (p *Prober) client() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	if fetcher.GlobalClient != nil {
+		return fetcher.GlobalClient
+	}
+	return http.DefaultClient
+}
+
+// Probe 探测一条线路
+// flv 等直接流：首字节时间为响应时间，吞吐为读取 MaxBytes 的速度
+// m3u8：首字节时间为播放列表的响应时间，吞吐为下载最新一个分片的速度
+func (p *Prober) Probe(ctx context.Context, rawURL string, header http.Header) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := p.get(ctx, rawURL, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	ttfb := time.Since(start)
+
+	if !isPlaylist(rawURL, resp) {
+		return p.measure(resp.Body, ttfb)
+	}
+
+	segmentURL, err := lastSegment(rawURL, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	segResp, err := p.get(ctx, segmentURL, header)
+	if err != nil {
+		return nil, fmt.Errorf("下载分片失败: %w", err)
+	}
+	defer segResp.Body.Close()
+	return p.measure(segResp.Body, ttfb)
+}
+
+func (p *Prober) get(ctx context.Context, rawURL string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	fetcher.ApplyHeader(req, header)
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &fetcher.StatusError{StatusCode: resp.StatusCode}
+	}
+	return resp, nil
+}
+
+// measure 读取数据计算吞吐，超时视为读取结束
+func (p *Prober) measure(body io.Reader, ttfb time.Duration) (*Result, error) {
+	start := time.Now()
+	n, err := io.Copy(io.Discard, io.LimitReader(body, p.MaxBytes))
+	elapsed := time.Since(start)
+	if n == 0 {
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("未读取到数据: %w", err)
+	}
+	if elapsed <= 0 {
+		elapsed = time.Millisecond
+	}
+	return &Result{
+		TTFB:       ttfb,
+		Throughput: float64(n) * 8 / 1000 / elapsed.Seconds(),
+		Bytes:      n,
+	}, nil
+}
+
+func isPlaylist(rawURL string, resp *http.Response) bool {
+	if u, err := url.Parse(rawURL); err == nil && strings.HasSuffix(u.Path, ".m3u8") {
+		return true
+	}
+	contentType := strings.ToLower(resp.Header.Get("Content-Type"))
+	return strings.Contains(contentType, "mpegurl")
+}
+
+// lastSegment 解析播放列表中最后一个分片的地址，分片没有参数时沿用播放列表的参数
+func lastSegment(playlistURL string, body io.Reader) (string, error) {
+	base, err := url.Parse(playlistURL)
+	if err != nil {
+		return "", err
+	}
+
+	var last string
+	scanner := bufio.NewScanner(io.LimitReader(body, 1024*1024))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		last = line
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if last == "" {
+		return "", fmt.Errorf("播放列表中没有分片")
+	}
+
+	ref, err := url.Parse(last)
+	if err != nil {
+		return "", err
+	}
+	segment := base.ResolveReference(ref)
+	if segment.RawQuery == "" {
+		segment.RawQuery = base.RawQuery
+	}
+	return segment.String(), nil
+}
+
+// ProbeAndRank 并发探测需要重新探测的线路，记录结果后返回排序后的线路名
+func ProbeAndRank(ctx context.Context, platform string, urls map[string]string, header http.Header) []string {
+	scorer := Default()
+	prober := NewProber()
+
+	var wg sync.WaitGroup
+	for name, u := range urls {
+		if !scorer.NeedsProbe(platform, u) {
+			continue
+		}
+		wg.Add(1)
+		go func(name string, u string) {
+			defer wg.Done()
+			result, err := prober.Probe(ctx, u, header)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Warn().Err(err).Str("line", name).Str("host", HostOf(u)).Msg("[lineprobe] 线路探测失败")
+				scorer.ReportFailure(platform, u)
+				return
+			}
+			log.Debug().Str("line", name).Str("host", HostOf(u)).
+				Dur("ttfb", result.TTFB).Float64("kbps", result.Throughput).
+				Msg("[lineprobe] 线路探测完成")
+			scorer.ReportProbe(platform, u, result.TTFB, result.Throughput)
+		}(name, u)
+	}
+	wg.Wait()
+
+	return scorer.Rank(platform, urls)
+}
@@ -0,0 +1,78 @@
+package lineprobe
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProbeFlv(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(make([]byte, 64*1024))
+	}))
+	defer server.Close()
+
+	result, err := NewProber().Probe(context.Background(), server.URL+"/live.flv", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Bytes != 64*1024 {
+		t.Errorf("Bytes = %d, want %d", result.Bytes, 64*1024)
+	}
+	if result.Throughput <= 0 {
+		t.Errorf("Throughput = %v", result.Throughput)
+	}
+}
+
+func TestProbeHls(t *testing.T) {
+	var segmentQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, ".m3u8"):
+			_, _ = w.Write([]byte("#EXTM3U\n#EXT-X-TARGETDURATION:1\n#EXTINF:1.0,\nseg-1.ts\n#EXTINF:1.0,\nseg-2.ts\n"))
+		case r.URL.Path == "/live/seg-2.ts":
+			segmentQuery = r.URL.RawQuery
+			_, _ = w.Write(make([]byte, 32*1024))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	result, err := NewProber().Probe(context.Background(), server.URL+"/live/index.m3u8?token=abc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Bytes != 32*1024 {
+		t.Errorf("Bytes = %d, want %d", result.Bytes, 32*1024)
+	}
+	if segmentQuery != "token=abc" {
+		t.Errorf("分片未沿用播放列表参数: %q", segmentQuery)
+	}
+}
+
+func TestProbeAndRank(t *testing.T) {
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(make([]byte, 16*1024))
+	}))
+	defer good.Close()
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer bad.Close()
+
+	defaultScorer = NewScorer(nil)
+	// 两个 httptest 服务的 host 都是 127.0.0.1，用 localhost 区分
+	badURL := strings.Replace(bad.URL, "127.0.0.1", "localhost", 1) + "/live.flv"
+	urls := map[string]string{
+		"线路1": badURL,
+		"线路2": good.URL + "/live.flv",
+	}
+
+	got := ProbeAndRank(context.Background(), "test", urls, nil)
+	if len(got) != 2 || got[0] != "线路2" {
+		t.Errorf("ProbeAndRank() = %v, want 线路2 first", got)
+	}
+}
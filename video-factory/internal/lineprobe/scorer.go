@@ -0,0 +1,241 @@
+package lineprobe
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// probeTTL 探测结果的有效期，过期后刷新时重新探测
+	probeTTL = 10 * time.Minute
+	// ewmaAlpha 新探测结果的权重
+	ewmaAlpha = 0.3
+)
+
+// Score 单个平台下某个 CDN 域名的评分
+type Score struct {
+	Platform    string  `json:"platform"`
+	Host        string  `json:"host"`
+	TTFB        float64 `json:"ttfb"`       // 首字节时间 ms，滑动平均
+	Throughput  float64 `json:"throughput"` // 吞吐 kbps，滑动平均
+	Successes   int     `json:"successes"`
+	Failures    int     `json:"failures"`    // 连续失败次数，成功后清零
+	LastProbe   int64   `json:"lastProbe"`   // 毫秒
+	LastFailure int64   `json:"lastFailure"` // 毫秒
+}
+
+// Value 评分值，越大越好：吞吐越高、首字节越快越好
+func (s *Score) Value() float64 {
+	if s.Successes == 0 {
+		return 0
+	}
+	return s.Throughput / (1 + s.TTFB/1000)
+}
+
+// Store 评分的持久化
+type Store interface {
+	LoadScores() ([]Score, error)
+	SaveScore(score Score) error
+}
+
+// Scorer 维护各平台 CDN 线路的评分
+type Scorer struct {
+	mu     sync.Mutex
+	scores map[string]*Score // platform|host -> score
+	store  Store
+	now    func() time.Time
+}
+
+func NewScorer(store Store) *Scorer {
+	return &Scorer{
+		scores: make(map[string]*Score),
+		store:  store,
+		now:    time.Now,
+	}
+}
+
+var defaultScorer = NewScorer(nil)
+
+// Init 设置全局评分的持久化并加载历史评分
+func Init(store Store) {
+	defaultScorer.mu.Lock()
+	defaultScorer.store = store
+	defaultScorer.mu.Unlock()
+
+	if store == nil {
+		return
+	}
+	scores, err := store.LoadScores()
+	if err != nil {
+		log.Err(err).Msg("[lineprobe] 加载线路评分失败")
+		return
+	}
+
+	defaultScorer.mu.Lock()
+	defer defaultScorer.mu.Unlock()
+	for i := range scores {
+		score := scores[i]
+		defaultScorer.scores[scoreKey(score.Platform, score.Host)] = &score
+	}
+	log.Info().Int("count", len(scores)).Msg("[lineprobe] 已加载线路评分")
+}
+
+// Default 全局评分
+func Default() *Scorer {
+	return defaultScorer
+}
+
+func scoreKey(platform string, host string) string {
+	return platform + "|" + host
+}
+
+// HostOf 线路地址的域名
+func HostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// score 获取评分，不存在则创建，调用方持有锁
+func (s *Scorer) score(platform string, host string) *Score {
+	key := scoreKey(platform, host)
+	score, ok := s.scores[key]
+	if !ok {
+		score = &Score{Platform: platform, Host: host}
+		s.scores[key] = score
+	}
+	return score
+}
+
+func (s *Scorer) save(score Score) {
+	s.mu.Lock()
+	store := s.store
+	s.mu.Unlock()
+	if store == nil {
+		return
+	}
+	if err := store.SaveScore(score); err != nil {
+		log.Err(err).Str("host", score.Host).Msg("[lineprobe] 保存线路评分失败")
+	}
+}
+
+// ReportProbe 记录一次成功的探测
+func (s *Scorer) ReportProbe(platform string, rawURL string, ttfb time.Duration, throughput float64) {
+	host := HostOf(rawURL)
+	if host == "" {
+		return
+	}
+
+	s.mu.Lock()
+	score := s.score(platform, host)
+	ttfbMs := float64(ttfb.Milliseconds())
+	if score.Successes == 0 {
+		score.TTFB = ttfbMs
+		score.Throughput = throughput
+	} else {
+		score.TTFB = ewmaAlpha*ttfbMs + (1-ewmaAlpha)*score.TTFB
+		score.Throughput = ewmaAlpha*throughput + (1-ewmaAlpha)*score.Throughput
+	}
+	score.Successes++
+	score.Failures = 0
+	score.LastProbe = s.now().UnixMilli()
+	snapshot := *score
+	s.mu.Unlock()
+
+	s.save(snapshot)
+}
+
+// ReportFailure 记录一次失败（探测失败或录制中断），降低线路排名
+func (s *Scorer) ReportFailure(platform string, rawURL string) {
+	host := HostOf(rawURL)
+	if host == "" {
+		return
+	}
+
+	s.mu.Lock()
+	score := s.score(platform, host)
+	score.Failures++
+	now := s.now().UnixMilli()
+	score.LastFailure = now
+	score.LastProbe = now
+	snapshot := *score
+	s.mu.Unlock()
+
+	log.Info().Str("platform", platform).Str("host", host).Int("failures", snapshot.Failures).
+		Msg("[lineprobe] 线路失败，降低排名")
+	s.save(snapshot)
+}
+
+// NeedsProbe 线路是否需要重新探测
+func (s *Scorer) NeedsProbe(platform string, rawURL string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	score, ok := s.scores[scoreKey(platform, HostOf(rawURL))]
+	if !ok || score.LastProbe == 0 {
+		return true
+	}
+	return s.now().Sub(time.UnixMilli(score.LastProbe)) > probeTTL
+}
+
+// Rank 按评分对线路排序，返回线路名
+// 排序规则：连续失败次数少的优先，其次评分高的优先，最后按线路名保证顺序稳定
+func (s *Scorer) Rank(platform string, urls map[string]string) []string {
+	type ranked struct {
+		name     string
+		failures int
+		value    float64
+	}
+
+	s.mu.Lock()
+	list := make([]ranked, 0, len(urls))
+	for name, u := range urls {
+		r := ranked{name: name}
+		if score, ok := s.scores[scoreKey(platform, HostOf(u))]; ok {
+			r.failures = score.Failures
+			r.value = score.Value()
+		}
+		list = append(list, r)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].failures != list[j].failures {
+			return list[i].failures < list[j].failures
+		}
+		if list[i].value != list[j].value {
+			return list[i].value > list[j].value
+		}
+		return list[i].name < list[j].name
+	})
+
+	names := make([]string, 0, len(list))
+	for _, r := range list {
+		names = append(names, r.name)
+	}
+	return names
+}
+
+// List 所有评分，按平台、评分排序
+func (s *Scorer) List() []Score {
+	s.mu.Lock()
+	list := make([]Score, 0, len(s.scores))
+	for _, score := range s.scores {
+		list = append(list, *score)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Platform != list[j].Platform {
+			return list[i].Platform < list[j].Platform
+		}
+		return list[i].Value() > list[j].Value()
+	})
+	return list
+}
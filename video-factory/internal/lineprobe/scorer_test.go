@@ -0,0 +1,110 @@
+package lineprobe
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type memStore struct {
+	scores map[string]Score
+}
+
+func (m *memStore) LoadScores() ([]Score, error) {
+	list := make([]Score, 0, len(m.scores))
+	for _, s := range m.scores {
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+func (m *memStore) SaveScore(score Score) error {
+	m.scores[scoreKey(score.Platform, score.Host)] = score
+	return nil
+}
+
+func TestScorerRank(t *testing.T) {
+	s := NewScorer(nil)
+	urls := map[string]string{
+		"线路1": "http://slow.example.com/live.flv",
+		"线路2": "http://fast.example.com/live.flv",
+		"线路3": "http://unknown.example.com/live.flv",
+		"线路4": "http://broken.example.com/live.flv",
+	}
+
+	s.ReportProbe("bili", urls["线路1"], 800*time.Millisecond, 2000)
+	s.ReportProbe("bili", urls["线路2"], 100*time.Millisecond, 8000)
+	s.ReportProbe("bili", urls["线路4"], 50*time.Millisecond, 20000)
+	s.ReportFailure("bili", urls["线路4"])
+
+	got := s.Rank("bili", urls)
+	want := []string{"线路2", "线路1", "线路3", "线路4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Rank() = %v, want %v", got, want)
+	}
+
+	// 其他平台的评分互不影响
+	got = s.Rank("missevan", urls)
+	want = []string{"线路1", "线路2", "线路3", "线路4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Rank() = %v, want %v", got, want)
+	}
+}
+
+func TestScorerRecoverAfterProbe(t *testing.T) {
+	s := NewScorer(nil)
+	u := "http://cdn.example.com/live.flv"
+
+	s.ReportFailure("bili", u)
+	s.ReportFailure("bili", u)
+	s.ReportProbe("bili", u, 100*time.Millisecond, 1000)
+
+	score := s.List()[0]
+	if score.Failures != 0 {
+		t.Errorf("Failures = %d, want 0", score.Failures)
+	}
+	if score.Successes != 1 {
+		t.Errorf("Successes = %d, want 1", score.Successes)
+	}
+}
+
+func TestScorerNeedsProbe(t *testing.T) {
+	now := time.Now()
+	s := NewScorer(nil)
+	s.now = func() time.Time { return now }
+	u := "http://cdn.example.com/live.flv"
+
+	if !s.NeedsProbe("bili", u) {
+		t.Error("未探测过的线路需要探测")
+	}
+	s.ReportProbe("bili", u, 100*time.Millisecond, 1000)
+	if s.NeedsProbe("bili", u) {
+		t.Error("刚探测过的线路不需要探测")
+	}
+	now = now.Add(probeTTL + time.Second)
+	if !s.NeedsProbe("bili", u) {
+		t.Error("探测结果过期后需要重新探测")
+	}
+}
+
+func TestScorerPersist(t *testing.T) {
+	store := &memStore{scores: make(map[string]Score)}
+	s := NewScorer(store)
+	s.ReportProbe("bili", "http://cdn.example.com/live.flv", 100*time.Millisecond, 1000)
+
+	saved, ok := store.scores[scoreKey("bili", "cdn.example.com")]
+	if !ok {
+		t.Fatal("评分未保存")
+	}
+	if saved.Throughput != 1000 || saved.TTFB != 100 {
+		t.Errorf("saved = %+v", saved)
+	}
+
+	// 重新加载后排名保持
+	defaultScorer = NewScorer(nil)
+	Init(store)
+	list := Default().List()
+	if len(list) != 1 || list[0].Host != "cdn.example.com" {
+		t.Errorf("List() = %+v", list)
+	}
+}
@@ -0,0 +1,66 @@
+package manager
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"video-factory/internal/dvr"
+)
+
+// GetCurrentURL 当前使用的直播流地址
+func (m *Manager) GetCurrentURL() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.CurrentURL
+}
+
+// GetPlaylistURL 代理播放使用的地址，优先使用 HLS 地址，没有 HLS 地址时返回当前线路
+func (m *Manager) GetPlaylistURL() string {
+	if hls := m.GetHLSURL(); hls != "" {
+		return hls
+	}
+	return m.GetCurrentURL()
+}
+
+// GetHLSURL 回看使用的 HLS 地址，当前线路不是 HLS 时使用平台同时下发的 HLS 地址（如猫耳）
+// 没有 HLS 地址时返回空，FLV 等线路无法按分片缓存
+func (m *Manager) GetHLSURL() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if isPlaylist(m.CurrentURL) {
+		return m.CurrentURL
+	}
+	names := make([]string, 0, len(m.StreamURLMap))
+	for name, streamURL := range m.StreamURLMap {
+		if isPlaylist(streamURL) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	return m.StreamURLMap[names[0]]
+}
+
+func isPlaylist(streamURL string) bool {
+	u, err := url.Parse(streamURL)
+	return err == nil && strings.HasSuffix(u.Path, ".m3u8")
+}
+
+// newDVR 按配置创建回看缓存，未开启时返回 nil
+func (m *Manager) newDVR() *dvr.DVR {
+	if m.Config.DVR == nil || !m.Config.DVR.Enabled || m.Config.DVR.Window <= 0 {
+		return nil
+	}
+	dir := filepath.Join(m.Config.DVR.Dir, strconv.FormatInt(m.Id, 10))
+	window := time.Duration(m.Config.DVR.Window) * time.Minute
+	return dvr.New(dir, window, m.GetHLSURL, func(ctx context.Context, url string) (*http.Response, error) {
+		return m.Fetch(ctx, url, nil)
+	})
+}
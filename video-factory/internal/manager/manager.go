@@ -8,15 +8,20 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"video-factory/internal/clip"
 	"video-factory/internal/common/consts"
 	"video-factory/internal/domain/model"
+	"video-factory/internal/dvr"
 	"video-factory/internal/iface"
+	"video-factory/internal/lineprobe"
 	"video-factory/internal/recorder"
 	"video-factory/internal/site/bili"
 	"video-factory/internal/site/missevan"
 	"video-factory/pkg/config"
 	"video-factory/pkg/fetcher"
+	"video-factory/pkg/logger"
 
 	"github.com/avast/retry-go/v5"
 	"github.com/rs/zerolog"
@@ -27,6 +32,8 @@ const (
 	MaxAttemptTimes       = 10
 	RetryWaitDuration     = 2 * time.Second
 	refreshSafetyInterval = 1 * time.Minute
+	// defaultOfflineRecheck 下播宽限期内重新检查开播状态的默认间隔
+	defaultOfflineRecheck = 10 * time.Second
 )
 
 type Manager struct {
@@ -35,11 +42,15 @@ type Manager struct {
 	Room     *model.Room
 
 	Id               int64
+	SessionID        int64 // 本次开播记录 ID
 	Platform         string
 	OpenTime         int64
 	CurrentURL       string
+	CurrentLine      string // 当前使用的线路名
 	ProxyURL         string
 	StreamURLMap     map[string]string
+	QualityName      string // 当前清晰度名称
+	Channel          string // 当前直播通道，如 CDN 域名
 	ActualExpireTime time.Time
 	SafetyExpireTime time.Time
 	LastRefreshTime  time.Time
@@ -49,9 +60,36 @@ type Manager struct {
 	ctx       context.Context    // manager 的生命周期
 	onStop    func(int64)        // 停止回调
 
-	Recorder     *recorder.Recorder // 持有录制器实例
-	RecordStatus int                // 是否开启录制（来自 Room 配置）
-	recordCancel context.CancelFunc // 用于单独停止录制任务
+	Recorder     recorder.Engine // 持有录制引擎实例
+	recording    sync.WaitGroup  // 运行中的录制引擎，停止时等待其完成当前文件
+	RecordStatus int             // 是否开启录制（来自 Room 配置）
+	// OnFileClosed 录制文件完成时回调，用于保存录制记录
+	OnFileClosed func(record *recorder.FileRecord)
+	// OnRecordFailed 录制任务异常退出时回调，用于发送通知
+	OnRecordFailed func(err error)
+	// ClipBuffer 最近一段时间的直播数据，用于保存片段，未开启时为 nil
+	ClipBuffer *clip.Buffer
+	// DVR 直播分片本地缓存，用于回看，未开启时为 nil
+	DVR *dvr.DVR
+
+	// OfflineGrace 平台返回未开播后继续检查的时长，期间恢复直播则不结束本场
+	OfflineGrace time.Duration
+	// OfflineRecheck 宽限期内重新检查开播状态的间隔
+	OfflineRecheck time.Duration
+	// OnTransition 状态变更时回调，用于保存状态历史
+	OnTransition func(transition Transition)
+
+	state      State
+	stateSince time.Time
+	graceUntil time.Time
+	stateMu    sync.Mutex
+
+	sequence atomic.Int64 // 下一个录制文件的序号
+	// info 房间标题与主播名，开播后刷新房间信息时更新，新建的录制文件使用最新的值
+	info atomic.Pointer[recorder.RoomInfo]
+
+	// Log 房间日志，同时写入该房间的日志文件
+	Log zerolog.Logger
 
 	mu sync.RWMutex
 }
@@ -88,9 +126,23 @@ func NewManager(room *model.Room, config *config.AppConfig, onStop func(int64))
 		SafetyExpireTime: time.Now(),
 		RecordStatus:     room.RecordStatus,
 		onStop:           onStop,
+		OfflineRecheck:   defaultOfflineRecheck,
+		Log:              logger.Room(room.ID),
 	}
+	m.info.Store(&recorder.RoomInfo{Title: room.Name, Username: room.AnchorName})
+	if config.Monitor != nil {
+		m.OfflineGrace = time.Duration(config.Monitor.OfflineGrace) * time.Second
+		if config.Monitor.OfflineRecheck > 0 {
+			m.OfflineRecheck = time.Duration(config.Monitor.OfflineRecheck) * time.Second
+		}
+	}
+	if config.Clip != nil && config.Clip.BufferSeconds > 0 {
+		m.ClipBuffer = clip.NewBuffer(time.Duration(config.Clip.BufferSeconds)*time.Second, config.Clip.BufferMaxMB*1024*1024)
+	}
+
+	m.DVR = m.newDVR()
 
-	log.Info().Object("manager", m).Msg("[Manager] Init Manager")
+	m.Log.Info().Object("manager", m).Msg("[Manager] Init Manager")
 	return m, nil
 }
 
@@ -101,7 +153,7 @@ func (m *Manager) StartAutoRefresh(parentCtx context.Context) {
 
 	// 确保只启动一次
 	if m.cancel != nil {
-		log.Warn().Int64("id", m.Id).Msg("[Manager] 自动刷新服务已在运行。")
+		m.Log.Warn().Int64("id", m.Id).Msg("[Manager] 自动刷新服务已在运行。")
 		return
 	}
 
@@ -111,10 +163,14 @@ func (m *Manager) StartAutoRefresh(parentCtx context.Context) {
 	m.refreshCh = make(chan struct{}, 1) // 有缓冲，防止发送阻塞
 	m.ctx = childCtx
 
-	log.Info().Int64("id", m.Id).Msg("[Manager AutoRefresh] 启动自动刷新服务")
+	m.Log.Info().Int64("id", m.Id).Msg("[Manager AutoRefresh] 启动自动刷新服务")
+	m.setState(StateStarting, "启动")
 
 	// 启动 Goroutine
 	go m.autoRefreshLoop()
+	if m.DVR != nil {
+		go m.DVR.Run(childCtx)
+	}
 }
 
 // StopAutoRefresh 发送停止信号给自动刷新 Goroutine
@@ -131,35 +187,42 @@ func (m *Manager) TriggerRefresh() {
 	// 防崩溃保护：如果通道已关闭，recover 会捕获 panic 并打印日志
 	defer func() {
 		if r := recover(); r != nil {
-			log.Warn().Int64("id", m.Id).Msgf("[Manager] panic 刷新信号发送失败(Manager已停止/通道已关闭): %v", r)
+			m.Log.Warn().Int64("id", m.Id).Msgf("[Manager] panic 刷新信号发送失败(Manager已停止/通道已关闭): %v", r)
 		}
 	}()
 	select {
 	case m.refreshCh <- struct{}{}:
-		log.Info().Int64("id", m.Id).Msg("[Manager] 手动触发即时刷新信号。")
+		m.Log.Info().Int64("id", m.Id).Msg("[Manager] 手动触发即时刷新信号。")
 	case <-m.ctx.Done():
-		log.Warn().Msg("[Manager] Manager 已停止，忽略刷新请求")
+		m.Log.Warn().Msg("[Manager] Manager 已停止，忽略刷新请求")
 	default:
 		// 如果通道已满，说明循环正在忙或等待，忽略本次触发
-		log.Debug().Int64("id", m.Id).Msg("[Manager] 即时刷新信号发送失败，循环正忙。")
+		m.Log.Debug().Int64("id", m.Id).Msg("[Manager] 即时刷新信号发送失败，循环正忙。")
 	}
 }
 
 // autoRefreshLoop 是 AutoRefresh 的核心循环
 func (m *Manager) autoRefreshLoop() {
+	stopReason := "Manager 已停止"
 	defer func() {
+		m.setState(StateStopped, stopReason)
 		// 循环退出时关闭 Channel
 		close(m.refreshCh)
+		// 停止录制，等待录制引擎完成当前文件，之后不再写入房间日志
+		m.mu.Lock()
+		if m.Recorder != nil {
+			m.Recorder.Stop()
+			m.Recorder = nil
+		}
+		m.mu.Unlock()
+		m.recording.Wait()
+		m.Log.Info().Int64("id", m.Id).Msg("[Manager] Manager 停止，触发 onStop 回调")
+		// 在移出 Pool 之前关闭，房间重新开播时使用新打开的日志文件
+		logger.CloseRoom(m.Id)
 		// 循环退出时（下播或异常），触发回调通知 Pool 移除自己
 		if m.onStop != nil {
-			log.Info().Int64("id", m.Id).Msg("[Manager] Manager 停止，触发 onStop 回调")
 			m.onStop(m.Id)
 		}
-		// 停止录制
-		if m.recordCancel != nil {
-			m.recordCancel()
-			m.Recorder = nil
-		}
 	}()
 
 	// 立即触发一次初始刷新，确保启动时就有有效的URL
@@ -172,14 +235,17 @@ func (m *Manager) autoRefreshLoop() {
 		isFirstRun := m.LastRefreshTime.IsZero()
 		m.mu.RUnlock()
 
-		if waitTime < 0 {
+		if state, _ := m.State(); state == StateOfflineGrace {
+			// 宽限期内按固定间隔检查，不等待链接过期
+			waitTime = m.graceWait()
+		} else if waitTime < 0 {
 			if isFirstRun {
 				waitTime = 5 * time.Second
-				log.Info().Msg("[Manager AutoRefresh] 首次启动，准备立即刷新")
+				m.Log.Info().Msg("[Manager AutoRefresh] 首次启动，准备立即刷新")
 			} else {
 				// 如果计算出负值（已过期或配置的安全间隔太长），则等待一个短的重试时间
 				waitTime = 3 * time.Second
-				log.Warn().Int64("id", m.Id).Msgf("[Manager AutoRefresh] 链接已过期或即将过期，立即等待 %s 后重试。", waitTime)
+				m.Log.Warn().Int64("id", m.Id).Msgf("[Manager AutoRefresh] 链接已过期或即将过期，立即等待 %s 后重试。", waitTime)
 			}
 		}
 
@@ -189,17 +255,17 @@ func (m *Manager) autoRefreshLoop() {
 		case <-m.ctx.Done():
 			// 收到停止信号，退出循环
 			timer.Stop()
-			log.Info().Int64("id", m.Id).Msg("[Manager AutoRefresh] 自动刷新服务已优雅停止。")
+			m.Log.Info().Int64("id", m.Id).Msg("[Manager AutoRefresh] 自动刷新服务已优雅停止。")
 			return // 退出 Goroutine
 
 		case <-m.refreshCh:
 			// 收到立即刷新信号（手动触发或首次启动）
 			timer.Stop()
-			log.Info().Int64("id", m.Id).Msg("[Manager AutoRefresh] 收到即时刷新信号，立即刷新。")
+			m.Log.Info().Int64("id", m.Id).Msg("[Manager AutoRefresh] 收到即时刷新信号，立即刷新。")
 			// 继续执行刷新逻辑
 
 		case <-timer.C:
-			log.Info().Int64("id", m.Id).Msg("[Manager AutoRefresh] 刷新间隔到期，开始刷新。")
+			m.Log.Info().Int64("id", m.Id).Msg("[Manager AutoRefresh] 刷新间隔到期，开始刷新。")
 			// 定时器到期，执行刷新逻辑
 			// 继续执行刷新逻辑
 		}
@@ -207,15 +273,20 @@ func (m *Manager) autoRefreshLoop() {
 		// --- 核心刷新执行 ---
 		err := m.CommonRefresh(nil, MaxAttemptTimes)
 
-		// 检测是否下播
+		// 检测是否下播，宽限期结束仍未开播才停止
 		if errors.Is(err, iface.ErrRoomOffline) {
-			log.Info().Int64("id", m.Id).Msg("[Manager AutoRefresh] 检测到直播结束，自动停止 Manager")
+			if !m.graceExpired() {
+				m.Log.Info().Int64("id", m.Id).Dur("grace", m.OfflineGrace).Msg("[Manager AutoRefresh] 平台返回未开播，宽限期内继续检查")
+				continue
+			}
+			m.Log.Info().Int64("id", m.Id).Msg("[Manager AutoRefresh] 检测到直播结束，自动停止 Manager")
+			stopReason = "下播"
 			// 这里不需要调用 StopAutoRefresh，直接 return 即可退出循环
 			return
 		}
 
 		if err != nil {
-			log.Err(err).Int64("id", m.Id).Msg("[Manager AutoRefresh] 自动刷新失败，将在下一轮循环中重试。")
+			m.Log.Err(err).Int64("id", m.Id).Msg("[Manager AutoRefresh] 自动刷新失败，将在下一轮循环中重试。")
 			// 如果是其他错误，可以选择继续重试，或者设置一个连续失败阈值来退出
 		}
 	}
@@ -223,7 +294,7 @@ func (m *Manager) autoRefreshLoop() {
 
 // CommonRefresh 通用 Refresh 函数，负责控制流、重试和状态更新
 func (m *Manager) CommonRefresh(tempCtx context.Context, attempts int) error {
-	log.Info().Msg("[Manager CommonRefresh] 正在刷新直播流 token...")
+	m.Log.Info().Msg("[Manager CommonRefresh] 正在刷新直播流 token...")
 
 	currentCtx := m.ctx
 	if tempCtx != nil {
@@ -239,14 +310,17 @@ func (m *Manager) CommonRefresh(tempCtx context.Context, attempts int) error {
 	}
 
 	var newStreamUrl string
+	var newLine string
 	var newExpireTime time.Time
 
+	m.beginRefresh()
+
 	r := retry.New(
 		retry.Attempts(uint(attempts)),
 		retry.Delay(RetryWaitDuration),
 		retry.OnRetry(
 			func(n uint, err error) {
-				log.Err(err).Msgf("[Manager CommonRefresh] 第%d次重试 start", n+1)
+				m.Log.Err(err).Msgf("[Manager CommonRefresh] 第%d次重试 start", n+1)
 			},
 		),
 		retry.RetryIf(func(err error) bool {
@@ -254,50 +328,87 @@ func (m *Manager) CommonRefresh(tempCtx context.Context, attempts int) error {
 				// 不重试
 				return false
 			}
+			if fetcher.IsRateLimited(err) {
+				// 风控/熔断中，重试只会加重风控
+				return false
+			}
 			return true
 		}),
 		retry.Context(currentCtx),
 	)
+	var streamInfo *iface.StreamInfo
 	err := r.Do(func() error {
 		// --- 1. 业务逻辑调用（通过策略接口） ---
-		streamInfo, fetchErr := m.Streamer.FetchStreamInfo(m.Streamer.GetStreamInfo().SelectedQn, true)
+		info, fetchErr := m.Streamer.FetchStreamInfo(m.Streamer.GetStreamInfo().SelectedQn, true)
 		if fetchErr != nil {
-			log.Err(fetchErr).Msg("[Manager CommonRefresh] 刷新直播流信息失败:")
+			m.Log.Err(fetchErr).Msg("[Manager CommonRefresh] 刷新直播流信息失败:")
 			return fetchErr
 		}
+		// 至少有一条线路能解析出过期时间，否则重新获取
+		for _, streamUrl := range info.StreamUrls {
+			if _, parseErr := m.Streamer.ParseExpiration(streamUrl); parseErr == nil {
+				streamInfo = info
+				return nil
+			}
+		}
+		return errors.New("[Manager CommonRefresh] 解析 expireTime 失败")
+	})
 
-		// --- 2. 业务逻辑调用（通过策略接口） ---
-		// 尝试解析 Stream URL
-		for _, streamUrl := range streamInfo.StreamUrls {
+	// --- 2. 业务逻辑调用（通过策略接口） ---
+	// 按线路评分排序，优先使用最好的线路；探测在重试之外，只在获取成功后执行一次
+	if err == nil {
+		ranked := lineprobe.ProbeAndRank(currentCtx, m.Platform, streamInfo.StreamUrls, m.Streamer.GetHeaders())
+		for _, line := range ranked {
+			streamUrl := streamInfo.StreamUrls[line]
 			expireTime, parseErr := m.Streamer.ParseExpiration(streamUrl)
 			if parseErr != nil {
-				log.Err(parseErr).Msg("[Manager CommonRefresh] 解析 expireTime 失败")
+				m.Log.Err(parseErr).Msg("[Manager CommonRefresh] 解析 expireTime 失败")
 				continue
 			}
 			newStreamUrl = streamUrl
+			newLine = line
 			newExpireTime = expireTime
-			return nil
+			break
 		}
-		return errors.New("[Manager CommonRefresh] 解析 expireTime 失败")
-	})
+		if newStreamUrl == "" {
+			err = errors.New("[Manager CommonRefresh] 没有可用的线路")
+		}
+	}
 
 	// 检查是否所有重试都失败
 	if newStreamUrl == "" || err != nil {
-		log.Err(err).Msg("[Manager CommonRefresh] 所有重试均失败，上次错误")
+		m.Log.Err(err).Msg("[Manager CommonRefresh] 所有重试均失败，上次错误")
+		switch {
+		case errors.Is(err, iface.ErrRoomOffline):
+			// 代理等外部调用发现下播时，通知循环按宽限期的间隔检查
+			if m.setState(StateOfflineGrace, "平台返回未开播") && tempCtx != nil {
+				m.TriggerRefresh()
+			}
+		case currentCtx.Err() == nil:
+			m.setState(StateDegraded, fmt.Sprintf("刷新失败: %v", err))
+		}
 		return err
 	}
 
 	// --- 3. 通用状态更新和加锁 ---
 	m.mu.Lock()
 	m.CurrentURL = newStreamUrl
-	m.StreamURLMap = m.Streamer.GetStreamInfo().StreamUrls
+	m.CurrentLine = newLine
+	m.StreamURLMap = streamInfo.StreamUrls
+	m.QualityName = streamInfo.QualityName
+	m.Channel = streamInfo.Channel
 	m.ActualExpireTime = newExpireTime
 	m.SafetyExpireTime = newExpireTime.Add(-1 * time.Minute)
 	m.LastRefreshTime = time.Now()
 	m.mu.Unlock()
 
-	log.Info().Msg("[Manager CommonRefresh] 更新成功")
-	log.Info().Object("manager", m).Msg("[Manager CommonRefresh] Manager")
+	m.Log.Info().Msg("[Manager CommonRefresh] 更新成功")
+	if state, _ := m.State(); state == StateOfflineGrace {
+		m.setState(StateLive, "宽限期内恢复直播")
+	} else {
+		m.setState(StateLive, "刷新成功")
+	}
+	m.Log.Info().Object("manager", m).Msg("[Manager CommonRefresh] Manager")
 
 	// 核心联动逻辑：URL 变了，或者录制没启动，就去处理一下
 	if m.RecordStatus == 1 {
@@ -311,7 +422,7 @@ func (m *Manager) CommonRefresh(tempCtx context.Context, attempts int) error {
 // ResolveTargetURL 根据请求的文件名（相对路径），计算出上游直播流的完整 URL
 func (m *Manager) ResolveTargetURL(filename string) (string, error) {
 	// 1. 获取当前的基础流地址
-	currentHls := m.CurrentURL
+	currentHls := m.GetPlaylistURL()
 	if currentHls == "" {
 		return "", fmt.Errorf("current stream url is empty")
 	}
@@ -362,13 +473,16 @@ func (m *Manager) ResolveTargetURL(filename string) (string, error) {
 // 调用 log.Object("manager", m) 时，哪些字段会被打印
 func (m *Manager) MarshalZerologObject(e *zerolog.Event) {
 	// 只记录关键的业务字段，跳过锁、Context、通道等无关字段
+	state, _ := m.State()
 	e.Int64("id", m.Id).
 		Str("current_url", m.CurrentURL).
+		Str("current_line", m.CurrentLine).
 		Str("proxy_url", m.ProxyURL).
 		Time("actual_expire_time", m.ActualExpireTime).
 		Time("safety_expire_time", m.SafetyExpireTime).
 		Time("last_refresh_time", m.LastRefreshTime).
 		Int("record_status", m.RecordStatus).
+		Str("state", string(state)).
 		Int64("open_time", m.OpenTime)
 }
 
@@ -391,6 +505,18 @@ func (m *Manager) Fetch(ctx context.Context, urlStr string, params url.Values) (
 	return fetcher.FetchWithRefresh(ctx, m, executor, "GET", urlStr, params)
 }
 
+// FetchFile 按文件名请求上游，与 Fetch 不同的是每次重试都重新计算地址，刷新后的新链接能立即生效
+func (m *Manager) FetchFile(ctx context.Context, filename string) (*http.Response, error) {
+	executor := func(method, _ string, p url.Values) (*http.Response, error) {
+		targetURL, err := m.ResolveTargetURL(filename)
+		if err != nil {
+			return nil, err
+		}
+		return fetcher.Fetch(method, targetURL, p, m.Streamer.GetHeaders())
+	}
+	return fetcher.FetchWithRefresh(ctx, m, executor, "GET", filename, nil)
+}
+
 // Refresh 实现 fetcher.Refresher 接口，用于 FetchWithRefresh 调用
 func (m *Manager) Refresh(ctx context.Context, attempts int) error {
 	// 调用自身的 CommonRefresh，传入保存的配置
@@ -0,0 +1,38 @@
+package manager
+
+import "testing"
+
+// 同时下发 FLV 与 HLS 的平台（如猫耳），当前线路为 FLV 时代理使用 HLS 地址
+func TestResolveTargetURL(t *testing.T) {
+	m := &Manager{
+		CurrentURL: "https://cdn.example.com/live/1.flv?expires=1&sign=a",
+		StreamURLMap: map[string]string{
+			"flv": "https://cdn.example.com/live/1.flv?expires=1&sign=a",
+			"hls": "https://cdn.example.com/live/1/index.m3u8?expires=1&sign=b",
+		},
+	}
+	cases := map[string]string{
+		"index.m3u8":   "https://cdn.example.com/live/1/index.m3u8?expires=1&sign=b",
+		"seg-1.ts":     "https://cdn.example.com/live/1/seg-1.ts?expires=1&sign=b",
+		"sub/seg-2.ts": "https://cdn.example.com/live/1/sub/seg-2.ts?expires=1&sign=b",
+	}
+	for filename, want := range cases {
+		got, err := m.ResolveTargetURL(filename)
+		if err != nil || got != want {
+			t.Errorf("%s: got %s, err = %v, want %s", filename, got, err, want)
+		}
+	}
+	if _, err := m.ResolveTargetURL("cover.jpg"); err == nil {
+		t.Error("unsupported file type should fail")
+	}
+
+	// 没有 HLS 地址时使用当前线路
+	delete(m.StreamURLMap, "hls")
+	if got, _ := m.ResolveTargetURL("index.m3u8"); got != m.CurrentURL {
+		t.Errorf("got %s, want current url", got)
+	}
+	// 回看只缓存 HLS，FLV 线路不拉取
+	if got := m.GetHLSURL(); got != "" {
+		t.Errorf("hls url = %s, want empty", got)
+	}
+}
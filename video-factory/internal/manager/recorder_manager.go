@@ -1,71 +1,124 @@
 package manager
 
 import (
-	"context"
+	"fmt"
 	"video-factory/internal/recorder"
-
-	"github.com/rs/zerolog/log"
 )
 
 func (m *Manager) StartRecorder() {
-	log.Info().Int64("id", m.Id).Str("name", m.Room.AnchorName).Msg("[Recoder Manager] 启动新录制任务")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.startRecorder()
+}
+
+// SetRecordMode 修改录制模式，正在录制时以新模式重新开始录制
+// 与录制引擎的创建在同一把锁内完成，刷新协程不会读到修改一半的状态
+func (m *Manager) SetRecordMode(mode string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Room.RecordMode = mode
+	if m.RecordStatus == 1 {
+		m.startRecorder()
+	}
+}
+
+// SetRecordEngine 修改录制引擎，正在录制时以新引擎重新开始录制
+func (m *Manager) SetRecordEngine(engine string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Room.RecordEngine = engine
+	if m.RecordStatus == 1 {
+		m.startRecorder()
+	}
+}
+
+// startRecorder 创建并启动录制引擎，调用方需持有 m.mu
+// 已有录制引擎时先停止，避免两个引擎同时写入文件
+func (m *Manager) startRecorder() {
+	if m.Recorder != nil {
+		m.Recorder.Stop()
+		m.Recorder = nil
+	}
+	m.Log.Info().Int64("id", m.Id).Str("name", m.Room.AnchorName).Msg("[Recoder Manager] 启动新录制任务")
 
-	// 创建新 Recorder
-	rec, err := recorder.NewRecorder(m.Config, m.StreamURLMap, m.Room, m.Streamer.GetOpenTime())
+	// 文件切分与命名由 Sink 负责，与录制引擎无关
+	sink := recorder.NewSink(m.Config, m.Room, m.Streamer.GetOpenTime())
+	sink.OnFileClosed = func(record *recorder.FileRecord) {
+		m.sequence.Store(int64(record.Sequence + 1))
+		if m.OnFileClosed != nil {
+			m.OnFileClosed(record)
+		}
+	}
+	sink.RoomInfo = m.RoomInfo
+	sink.SessionID = m.SessionID
+	sink.Sequence = m.NextSequence()
+	sink.Quality = m.Streamer.GetStreamInfo().ActualQn
+
+	// 创建房间配置的录制引擎
+	rec, err := recorder.NewEngine(m.Room.RecordEngine, m.Config, m.Room.Platform, m.StreamURLMap, m.Streamer.GetHeaders(), sink)
 	if err != nil {
-		log.Err(err).Int64("id", m.Id).Str("anchor", m.Room.AnchorName).Msg("[Recoder Manager] 初始化录制器失败")
+		m.Log.Err(err).Int64("id", m.Id).Str("anchor", m.Room.AnchorName).Msg("[Recoder Manager] 初始化录制器失败")
 		return
 	}
-	m.mu.Lock()
-	recordCtx, cancel := context.WithCancel(m.ctx)
-	m.recordCancel = cancel
+	// 文件格式由引擎决定，重新开始录制时清空缓冲，片段不跨越两次录制
+	if m.ClipBuffer != nil {
+		m.ClipBuffer.Reset()
+		m.ClipBuffer.SetFormat(sink.Ext)
+		sink.Tee = m.ClipBuffer
+	}
 	m.Recorder = rec
 
+	m.recording.Add(1)
 	go func() {
-		if err := rec.Start(recordCtx); err != nil {
-			log.Err(err).Int64("id", m.Id).Str("anchor", m.Room.AnchorName).
+		defer m.recording.Done()
+		if err := rec.Start(m.ctx); err != nil {
+			m.Log.Err(err).Int64("id", m.Id).Str("anchor", m.Room.AnchorName).
 				Msg("[Recoder Manager] 录制任务异常退出")
+			if m.OnRecordFailed != nil {
+				m.OnRecordFailed(err)
+			}
+			m.setState(StateDegraded, fmt.Sprintf("录制异常: %v", err))
 			// 进阶：如果录制频繁失败，是否要触发 Manager 重新刷新 URL？
-			log.Warn().Int64("id", m.Id).Str("anchor", m.Room.AnchorName).
+			m.Log.Warn().Int64("id", m.Id).Str("anchor", m.Room.AnchorName).
 				Msg("[Recoder Manager] 录制任务异常，触发刷新")
 			m.TriggerRefresh()
 		}
 	}()
-	m.mu.Unlock()
 }
 
 func (m *Manager) updateRecorder() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	// 如果 Recorder 正在运行
-	if m.Recorder != nil && m.Recorder.IsRunning() {
+	if m.Recorder != nil && m.Recorder.Stats().Running {
 		// 判断录制url是否有变化
 		changeFlag := true
+		currentURL := m.Recorder.Stats().URL
 		for _, u := range m.StreamURLMap {
-			if m.Recorder.GetCurrentURL() == u {
+			if currentURL == u {
 				changeFlag = false
 				break
 			}
 		}
 		// URL 没变，直接返回
 		if !changeFlag {
-			log.Info().Int64("id", m.Id).Str("anchor", m.Room.AnchorName).
+			m.Log.Info().Int64("id", m.Id).Str("anchor", m.Room.AnchorName).
 				Msg("[Recoder Manager] 录制URL未变化，不更新")
 			return
 		}
 
 		// 如果 URL 变了，更新 URL
-		log.Info().Int64("id", m.Id).Str("anchor", m.Room.AnchorName).Msg("[Recoder Manager] 更新录制URL")
+		m.Log.Info().Int64("id", m.Id).Str("anchor", m.Room.AnchorName).Msg("[Recoder Manager] 更新录制URL")
 		m.Recorder.UpdateStreamURLs(m.StreamURLMap)
 		return
 	}
 
 	// 清除旧引用
 	if m.Recorder != nil {
-		log.Warn().Int64("id", m.Id).Str("anchor", m.Room.AnchorName).
+		m.Log.Warn().Int64("id", m.Id).Str("anchor", m.Room.AnchorName).
 			Msg("[Recoder Manager] Recorder 非运行中，清理旧引用")
+		m.Recorder.Stop()
 		m.Recorder = nil
-		m.recordCancel = nil
 	}
 	// 启动新的 recorder
 	go m.StartRecorder()
@@ -73,19 +126,41 @@ func (m *Manager) updateRecorder() {
 
 func (m *Manager) StopRecorder() {
 	m.mu.Lock()
-	log.Info().Int64("id", m.Id).Str("anchor", m.Room.AnchorName).Msg("[Recoder Manager] 停止录制任务")
-	if m.recordCancel != nil {
-		log.Info().Int64("id", m.Id).Str("anchor", m.Room.AnchorName).Msg("[Recoder Manager] 触发 cancel")
-		m.recordCancel() // 这会触发 Recorder.Start 中的 ctx.Done()
-		m.recordCancel = nil
-		m.Recorder = nil
-	}
-	// Recorder 没在运行，清理旧引用
+	m.Log.Info().Int64("id", m.Id).Str("anchor", m.Room.AnchorName).Msg("[Recoder Manager] 停止录制任务")
 	if m.Recorder != nil {
-		log.Warn().Int64("id", m.Id).Str("anchor", m.Room.AnchorName).
-			Msg("[Recoder Manager] Recorder 非运行中，清理旧引用")
+		m.Log.Info().Int64("id", m.Id).Str("anchor", m.Room.AnchorName).Msg("[Recoder Manager] 触发 Stop")
+		m.Recorder.Stop() // Start 返回前会完成当前文件
 		m.Recorder = nil
-		m.recordCancel = nil
 	}
 	m.mu.Unlock()
 }
+
+// RoomInfo 当前的房间标题与主播名
+func (m *Manager) RoomInfo() recorder.RoomInfo {
+	return *m.info.Load()
+}
+
+// SetRoomInfo 更新房间标题与主播名，正在录制时从下一个文件开始生效
+func (m *Manager) SetRoomInfo(title, anchorName string) {
+	m.info.Store(&recorder.RoomInfo{Title: title, Username: anchorName})
+}
+
+// NextSequence 下一个录制文件的序号
+func (m *Manager) NextSequence() int {
+	return int(m.sequence.Load())
+}
+
+// SetNextSequence 设置下一个录制文件的序号，续接开播时沿用上一段的序号
+func (m *Manager) SetNextSequence(sequence int) {
+	m.sequence.Store(int64(sequence))
+}
+
+// RecorderStats 当前录制引擎的状态，未在录制时 ok 为 false
+func (m *Manager) RecorderStats() (stats recorder.Stats, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.Recorder == nil {
+		return stats, false
+	}
+	return m.Recorder.Stats(), true
+}
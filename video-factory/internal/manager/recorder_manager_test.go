@@ -0,0 +1,87 @@
+package manager
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+	"video-factory/internal/clip"
+	"video-factory/internal/common/consts"
+	"video-factory/internal/domain/model"
+	"video-factory/internal/iface"
+	"video-factory/internal/recorder"
+	"video-factory/pkg/config"
+)
+
+type fakeStreamer struct{}
+
+func (fakeStreamer) GetHeaders() http.Header { return http.Header{} }
+func (fakeStreamer) IsLive() (bool, error)   { return true, nil }
+func (fakeStreamer) FetchStreamInfo(int, bool) (*iface.StreamInfo, error) {
+	return &iface.StreamInfo{}, nil
+}
+func (fakeStreamer) GetStreamInfo() iface.StreamInfo           { return iface.StreamInfo{} }
+func (fakeStreamer) ParseExpiration(string) (time.Time, error) { return time.Time{}, nil }
+func (fakeStreamer) GetOpenTime() int64                        { return 0 }
+
+// fakeEngine 记录启动时的录制模式，Stop 后 Start 返回
+type fakeEngine struct {
+	mode string
+	stop chan struct{}
+	once sync.Once
+}
+
+func (e *fakeEngine) Start(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+	case <-e.stop:
+	}
+	return nil
+}
+func (e *fakeEngine) Stop()                              { e.once.Do(func() { close(e.stop) }) }
+func (e *fakeEngine) UpdateStreamURLs(map[string]string) {}
+func (e *fakeEngine) Stats() recorder.Stats              { return recorder.Stats{Running: true} }
+
+func TestSetRecordMode(t *testing.T) {
+	var mu sync.Mutex
+	var engines []*fakeEngine
+	recorder.RegisterEngine("fake-manager", func(cfg *config.AppConfig, platform string, streamURLMap map[string]string, header http.Header, sink *recorder.Sink) (recorder.Engine, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		e := &fakeEngine{mode: sink.Mode, stop: make(chan struct{})}
+		engines = append(engines, e)
+		return e, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := &Manager{
+		Id:           1,
+		Room:         &model.Room{ID: 1, RecordEngine: "fake-manager"},
+		Config:       &config.AppConfig{Recorder: &config.Recorder{}},
+		Streamer:     fakeStreamer{},
+		RecordStatus: 1,
+		ClipBuffer:   clip.NewBuffer(time.Minute, 0),
+		ctx:          ctx,
+	}
+	m.StartRecorder()
+	_, _ = m.ClipBuffer.Write([]byte("ts"))
+	m.SetRecordMode(consts.RecordModeAudio)
+	// 重新开始录制时清空片段缓冲，片段不包含上一次录制的数据
+	if size := m.ClipBuffer.Size(); size != 0 {
+		t.Errorf("clip buffer size = %d, want 0", size)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(engines) != 2 || engines[1].mode != consts.RecordModeAudio {
+		t.Fatalf("engines = %+v", engines)
+	}
+	// 重新开始录制前停止原来的引擎，避免两个引擎同时写入
+	select {
+	case <-engines[0].stop:
+	default:
+		t.Error("previous engine was not stopped")
+	}
+}
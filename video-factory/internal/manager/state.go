@@ -0,0 +1,97 @@
+package manager
+
+import (
+	"slices"
+	"time"
+)
+
+// State Manager 的生命周期状态
+type State string
+
+const (
+	StateStarting     State = "starting"      // 已创建，尚未拿到可用的直播流地址
+	StateLive         State = "live"          // 直播中，地址可用
+	StateRefreshing   State = "refreshing"    // 正在刷新地址
+	StateDegraded     State = "degraded"      // 刷新或录制失败，等待重试
+	StateOfflineGrace State = "offline_grace" // 平台返回未开播，宽限期内继续检查，避免网络抖动结束本场直播
+	StateStopped      State = "stopped"       // 已停止
+)
+
+// transitions 允许的状态转换
+var transitions = map[State][]State{
+	StateStarting:     {StateLive, StateDegraded, StateOfflineGrace, StateStopped},
+	StateLive:         {StateRefreshing, StateDegraded, StateOfflineGrace, StateStopped},
+	StateRefreshing:   {StateLive, StateDegraded, StateOfflineGrace, StateStopped},
+	StateDegraded:     {StateRefreshing, StateLive, StateOfflineGrace, StateStopped},
+	StateOfflineGrace: {StateLive, StateDegraded, StateStopped},
+}
+
+// Transition 一次状态转换
+type Transition struct {
+	From   State
+	To     State
+	Reason string
+	Time   time.Time
+}
+
+// CanTransit 是否允许从 from 转换到 to
+func CanTransit(from State, to State) bool {
+	return slices.Contains(transitions[from], to)
+}
+
+// State 当前状态及进入该状态的时间
+func (m *Manager) State() (State, time.Time) {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+	if m.state == "" {
+		return StateStarting, m.stateSince
+	}
+	return m.state, m.stateSince
+}
+
+// setState 转换状态，不允许的转换会被忽略，返回是否发生了转换
+func (m *Manager) setState(to State, reason string) bool {
+	m.stateMu.Lock()
+	from := m.state
+	if from == to || (from != "" && !CanTransit(from, to)) {
+		m.stateMu.Unlock()
+		if from != to {
+			m.Log.Warn().Int64("id", m.Id).Str("from", string(from)).Str("to", string(to)).Msg("[Manager] 忽略不允许的状态转换")
+		}
+		return false
+	}
+	transition := Transition{From: from, To: to, Reason: reason, Time: time.Now()}
+	m.state = to
+	m.stateSince = transition.Time
+	if to == StateOfflineGrace {
+		m.graceUntil = transition.Time.Add(m.OfflineGrace)
+	}
+	m.stateMu.Unlock()
+
+	m.Log.Info().Int64("id", m.Id).Str("from", string(from)).Str("to", string(to)).Str("reason", reason).Msg("[Manager] 状态变更")
+	if m.OnTransition != nil {
+		m.OnTransition(transition)
+	}
+	return true
+}
+
+// beginRefresh 直播中或降级时进入刷新状态，启动阶段与宽限期内的刷新保持原状态
+func (m *Manager) beginRefresh() {
+	if state, _ := m.State(); state == StateLive || state == StateDegraded {
+		m.setState(StateRefreshing, "刷新直播流地址")
+	}
+}
+
+// graceExpired 宽限期是否已结束
+func (m *Manager) graceExpired() bool {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+	return m.state == StateOfflineGrace && !time.Now().Before(m.graceUntil)
+}
+
+// graceWait 宽限期内距离下一次检查的时间
+func (m *Manager) graceWait() time.Duration {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+	return max(min(m.OfflineRecheck, time.Until(m.graceUntil)), 0)
+}
@@ -0,0 +1,60 @@
+package manager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetState(t *testing.T) {
+	var got []Transition
+	m := &Manager{Id: 1, OfflineGrace: time.Minute}
+	m.OnTransition = func(transition Transition) { got = append(got, transition) }
+
+	if state, _ := m.State(); state != StateStarting {
+		t.Fatalf("initial state = %s", state)
+	}
+	steps := []struct {
+		to State
+		ok bool
+	}{
+		{StateStarting, true},
+		{StateLive, true},
+		{StateLive, false}, // 状态未变化
+		{StateOfflineGrace, true},
+		{StateRefreshing, false}, // 宽限期内不进入刷新状态
+		{StateLive, true},
+		{StateStopped, true},
+		{StateLive, false}, // 停止后不再变化
+	}
+	for i, step := range steps {
+		if ok := m.setState(step.to, "test"); ok != step.ok {
+			t.Fatalf("step %d: setState(%s) = %v, want %v", i, step.to, ok, step.ok)
+		}
+	}
+
+	want := []State{StateStarting, StateLive, StateOfflineGrace, StateLive, StateStopped}
+	if len(got) != len(want) {
+		t.Fatalf("transitions = %+v", got)
+	}
+	for i, transition := range got {
+		if transition.To != want[i] || (i > 0 && transition.From != want[i-1]) {
+			t.Fatalf("transition %d = %+v", i, transition)
+		}
+	}
+}
+
+func TestOfflineGrace(t *testing.T) {
+	m := &Manager{Id: 1, OfflineGrace: 50 * time.Millisecond}
+	m.setState(StateLive, "test")
+	if m.graceExpired() {
+		t.Fatal("未进入宽限期")
+	}
+	m.setState(StateOfflineGrace, "test")
+	if m.graceExpired() || m.graceWait() > 50*time.Millisecond {
+		t.Fatalf("graceExpired = %v, graceWait = %v", m.graceExpired(), m.graceWait())
+	}
+	time.Sleep(60 * time.Millisecond)
+	if !m.graceExpired() || m.graceWait() != 0 {
+		t.Fatalf("graceExpired = %v, graceWait = %v", m.graceExpired(), m.graceWait())
+	}
+}
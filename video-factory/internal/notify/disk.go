@@ -0,0 +1,21 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// FreeSpace 目录所在磁盘的可用字节数，目录不存在时向上查找已存在的父目录
+func FreeSpace(dir string) (uint64, error) {
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return freeSpace(dir)
+}
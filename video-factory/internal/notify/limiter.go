@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter 通知限流：同一 key（渠道、房间、事件）在 minInterval 内只发送一次，
+// 每个渠道每小时最多发送 maxPerHour 条
+type Limiter struct {
+	mu       sync.Mutex
+	lastSent map[string]time.Time
+	sent     map[int64][]time.Time // channelId -> 最近一小时的发送时间
+}
+
+func NewLimiter() *Limiter {
+	return &Limiter{
+		lastSent: make(map[string]time.Time),
+		sent:     make(map[int64][]time.Time),
+	}
+}
+
+// Allow 判断是否允许发送，允许时记录本次发送
+func (l *Limiter) Allow(channelId int64, key string, now time.Time, minInterval time.Duration, maxPerHour int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// 超过间隔的记录不再影响判断，顺便清理，避免房间与事件越来越多时无限增长
+	for k, last := range l.lastSent {
+		if now.Sub(last) >= minInterval {
+			delete(l.lastSent, k)
+		}
+	}
+	if _, ok := l.lastSent[key]; ok {
+		return false
+	}
+
+	recent := l.sent[channelId][:0]
+	for _, t := range l.sent[channelId] {
+		if now.Sub(t) < time.Hour {
+			recent = append(recent, t)
+		}
+	}
+	if maxPerHour > 0 && len(recent) >= maxPerHour {
+		l.sent[channelId] = recent
+		return false
+	}
+
+	l.lastSent[key] = now
+	l.sent[channelId] = append(recent, now)
+	return true
+}
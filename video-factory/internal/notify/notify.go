@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// 通知渠道类型
+const (
+	TypeTelegram = "telegram"
+	TypeEmail    = "email"
+	TypeBark     = "bark"
+	TypeNtfy     = "ntfy"
+)
+
+// 通知事件
+const (
+	EventLive         = "live"          // 开播
+	EventRecordFailed = "record_failed" // 录制异常
+	EventDiskLow      = "disk_low"      // 磁盘空间不足
+)
+
+// Events 所有事件
+var Events = []string{EventLive, EventRecordFailed, EventDiskLow}
+
+// DefaultTemplates 各事件的默认模板，第一行为标题，其余为正文
+var DefaultTemplates = map[string]string{
+	EventLive:         "{{.Anchor}} 开播了\n{{.Title}}\n{{.URL}}",
+	EventRecordFailed: "{{.Anchor}} 录制异常\n{{.Error}}",
+	EventDiskLow:      "磁盘空间不足\n{{.Dir}} 剩余 {{.Free}}",
+}
+
+// Message 发送的消息
+type Message struct {
+	Title string
+	Body  string
+}
+
+// Text 标题与正文合并的纯文本，用于只支持单段文本的渠道
+func (m Message) Text() string {
+	if m.Body == "" {
+		return m.Title
+	}
+	return m.Title + "\n" + m.Body
+}
+
+// Vars 模板变量
+type Vars struct {
+	Event    string
+	RoomID   int64
+	Platform string
+	Anchor   string // 主播名
+	Title    string // 直播间标题
+	URL      string // 直播间地址
+	Error    string
+	Dir      string
+	Free     string
+	Time     time.Time
+}
+
+// Channel 通知渠道
+type Channel interface {
+	Type() string
+	Send(ctx context.Context, msg Message) error
+}
+
+// New 根据类型与 JSON 配置创建渠道
+func New(channelType string, rawConfig string) (Channel, error) {
+	if strings.TrimSpace(rawConfig) == "" {
+		return nil, errors.New("渠道配置为空")
+	}
+	var (
+		channel Channel
+		err     error
+	)
+	switch channelType {
+	case TypeTelegram:
+		var cfg TelegramConfig
+		if err = json.Unmarshal([]byte(rawConfig), &cfg); err == nil {
+			channel, err = NewTelegram(cfg)
+		}
+	case TypeEmail:
+		var cfg EmailConfig
+		if err = json.Unmarshal([]byte(rawConfig), &cfg); err == nil {
+			channel, err = NewEmail(cfg)
+		}
+	case TypeBark:
+		var cfg BarkConfig
+		if err = json.Unmarshal([]byte(rawConfig), &cfg); err == nil {
+			channel, err = NewBark(cfg)
+		}
+	case TypeNtfy:
+		var cfg NtfyConfig
+		if err = json.Unmarshal([]byte(rawConfig), &cfg); err == nil {
+			channel, err = NewNtfy(cfg)
+		}
+	default:
+		return nil, fmt.Errorf("不支持的渠道类型: %s", channelType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("渠道配置有误: %w", err)
+	}
+	return channel, nil
+}
+
+// Render 渲染模板，tmpl 为空时使用事件的默认模板
+func Render(tmpl string, vars Vars) (Message, error) {
+	if tmpl == "" {
+		tmpl = DefaultTemplates[vars.Event]
+	}
+	t, err := template.New(vars.Event).Option("missingkey=zero").Parse(tmpl)
+	if err != nil {
+		return Message{}, fmt.Errorf("模板格式有误: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, vars); err != nil {
+		return Message{}, fmt.Errorf("渲染模板失败: %w", err)
+	}
+	title, body, _ := strings.Cut(strings.TrimSpace(buf.String()), "\n")
+	return Message{Title: strings.TrimSpace(title), Body: strings.TrimSpace(body)}, nil
+}
+
+// CheckTemplate 校验模板能否解析
+func CheckTemplate(tmpl string) error {
+	_, err := Render(tmpl, Vars{Event: EventLive, Time: time.Now()})
+	return err
+}
@@ -0,0 +1,276 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	msg, err := Render("", Vars{Event: EventLive, Anchor: "主播", Title: "今晚唱歌", URL: "https://live.example/1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Title != "主播 开播了" || msg.Body != "今晚唱歌\nhttps://live.example/1" {
+		t.Errorf("Render() = %+v", msg)
+	}
+
+	msg, err = Render("{{.Anchor}}\n", Vars{Event: EventLive, Anchor: "a"})
+	if err != nil || msg.Title != "a" || msg.Body != "" {
+		t.Errorf("Render(title only) = %+v, %v", msg, err)
+	}
+	if err := CheckTemplate("{{.Anchor"); err == nil {
+		t.Error("invalid template should fail")
+	}
+	if err := CheckTemplate("{{.Missing}}"); err == nil {
+		t.Error("unknown field should fail")
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter()
+	now := time.Now()
+	if !l.Allow(1, "1:10:live", now, time.Minute, 0) {
+		t.Fatal("first message should be allowed")
+	}
+	if l.Allow(1, "1:10:live", now.Add(30*time.Second), time.Minute, 0) {
+		t.Error("same key within interval should be blocked")
+	}
+	if !l.Allow(1, "1:10:live", now.Add(61*time.Second), time.Minute, 0) {
+		t.Error("same key after interval should be allowed")
+	}
+
+	// 每小时上限
+	for i := 0; i < 3; i++ {
+		l.Allow(2, fmt.Sprintf("k%d", i), now, 0, 3)
+	}
+	if l.Allow(2, "k3", now, 0, 3) {
+		t.Error("channel over hourly cap should be blocked")
+	}
+	if !l.Allow(2, "k3", now.Add(time.Hour+time.Second), 0, 3) {
+		t.Error("cap should reset after an hour")
+	}
+
+	// 超过间隔的记录会被清理
+	l = NewLimiter()
+	for i := 0; i < 10; i++ {
+		l.Allow(3, fmt.Sprintf("room%d", i), now, time.Minute, 0)
+	}
+	l.Allow(3, "room10", now.Add(2*time.Minute), time.Minute, 0)
+	if n := len(l.lastSent); n != 1 {
+		t.Errorf("len(lastSent) = %d, want 1", n)
+	}
+}
+
+func TestTelegram(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/botTOKEN/sendMessage" {
+			_, _ = w.Write([]byte(`{"ok":false,"description":"Unauthorized"}`))
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	ch, err := New(TypeTelegram, fmt.Sprintf(`{"api_base":%q,"token":"TOKEN","chat_id":"42"}`, server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ch.Send(context.Background(), Message{Title: "标题", Body: "正文"}); err != nil {
+		t.Fatal(err)
+	}
+	if got["chat_id"] != "42" || got["text"] != "标题\n正文" {
+		t.Errorf("request = %v", got)
+	}
+
+	bad, _ := New(TypeTelegram, fmt.Sprintf(`{"api_base":%q,"token":"WRONG","chat_id":"42"}`, server.URL))
+	if err := bad.Send(context.Background(), Message{Title: "x"}); err == nil || !strings.Contains(err.Error(), "Unauthorized") {
+		t.Errorf("Send() error = %v", err)
+	}
+}
+
+func TestBarkAndNtfy(t *testing.T) {
+	var barkBody map[string]string
+	var ntfyTitle, ntfyAuth, ntfyBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bark/KEY":
+			_ = json.NewDecoder(r.Body).Decode(&barkBody)
+		case "/ntfy/topic":
+			ntfyTitle, _ = new(mime.WordDecoder).DecodeHeader(r.Header.Get("Title"))
+			ntfyAuth = r.Header.Get("Authorization")
+			data, _ := io.ReadAll(r.Body)
+			ntfyBody = string(data)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	msg := Message{Title: "主播 开播了", Body: "今晚唱歌"}
+	bark, err := New(TypeBark, fmt.Sprintf(`{"url":%q,"group":"live"}`, server.URL+"/bark/KEY"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bark.Send(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if barkBody["title"] != msg.Title || barkBody["body"] != msg.Body || barkBody["group"] != "live" {
+		t.Errorf("bark body = %v", barkBody)
+	}
+
+	ntfy, err := New(TypeNtfy, fmt.Sprintf(`{"url":%q,"token":"tk"}`, server.URL+"/ntfy/topic"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ntfy.Send(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if ntfyTitle != msg.Title || ntfyBody != msg.Body || ntfyAuth != "Bearer tk" {
+		t.Errorf("ntfy title=%q body=%q auth=%q", ntfyTitle, ntfyBody, ntfyAuth)
+	}
+
+	missing, _ := New(TypeNtfy, fmt.Sprintf(`{"url":%q}`, server.URL+"/missing"))
+	if err := missing.Send(context.Background(), msg); err == nil {
+		t.Error("404 should fail")
+	}
+}
+
+// fakeSMTP 最小的 SMTP 服务，支持 AUTH PLAIN，记录收到的邮件
+type fakeSMTP struct {
+	listener net.Listener
+	mu       sync.Mutex
+	auth     string
+	from     string
+	to       []string
+	data     string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTP{listener: l}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		s.mu.Lock()
+		switch cmd {
+		case "EHLO", "HELO":
+			reply("250-fake")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			parts := strings.Fields(line)
+			decoded, _ := base64.StdEncoding.DecodeString(parts[len(parts)-1])
+			s.auth = string(decoded)
+			reply("235 ok")
+		case "MAIL":
+			s.from = line
+			reply("250 ok")
+		case "RCPT":
+			s.to = append(s.to, line)
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil || l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.data = data.String()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			s.mu.Unlock()
+			return
+		default:
+			reply("250 ok")
+		}
+		s.mu.Unlock()
+	}
+}
+
+func TestEmail(t *testing.T) {
+	server := newFakeSMTP(t)
+	port := server.listener.Addr().(*net.TCPAddr).Port
+	ch, err := New(TypeEmail, fmt.Sprintf(
+		`{"host":"127.0.0.1","port":%d,"username":"u","password":"p","from":"bot@example.com","to":["a@example.com","b@example.com"]}`, port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ch.Send(context.Background(), Message{Title: "主播 开播了", Body: "今晚唱歌"}); err != nil {
+		t.Fatal(err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.auth != "\x00u\x00p" {
+		t.Errorf("auth = %q", server.auth)
+	}
+	if len(server.to) != 2 || !strings.Contains(server.from, "bot@example.com") {
+		t.Errorf("from = %q to = %v", server.from, server.to)
+	}
+	header, body, _ := strings.Cut(server.data, "\r\n\r\n")
+	var subject string
+	for _, line := range strings.Split(header, "\r\n") {
+		if v, ok := strings.CutPrefix(line, "Subject: "); ok {
+			subject, _ = new(mime.WordDecoder).DecodeHeader(v)
+		}
+	}
+	if subject != "主播 开播了" {
+		t.Errorf("subject = %q", subject)
+	}
+	decoded, _ := base64.StdEncoding.DecodeString(strings.ReplaceAll(body, "\r\n", ""))
+	if string(decoded) != "今晚唱歌" {
+		t.Errorf("body = %q", decoded)
+	}
+}
+
+func TestFreeSpace(t *testing.T) {
+	free, err := FreeSpace(t.TempDir() + "/not/exist/yet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if free == 0 {
+		t.Error("free space should be > 0")
+	}
+}
@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// BarkConfig Bark 推送，URL 为包含设备 key 的地址，如 https://api.day.app/<key>
+type BarkConfig struct {
+	URL   string `json:"url"`
+	Group string `json:"group"` // 通知分组，可选
+	Sound string `json:"sound"` // 提示音，可选
+}
+
+type Bark struct {
+	cfg    BarkConfig
+	client *http.Client
+}
+
+func NewBark(cfg BarkConfig) (*Bark, error) {
+	if !strings.HasPrefix(cfg.URL, "http://") && !strings.HasPrefix(cfg.URL, "https://") {
+		return nil, errors.New("url 格式有误")
+	}
+	cfg.URL = strings.TrimSuffix(cfg.URL, "/")
+	return &Bark{cfg: cfg, client: &http.Client{Timeout: 15 * time.Second}}, nil
+}
+
+func (b *Bark) Type() string {
+	return TypeBark
+}
+
+func (b *Bark) Send(ctx context.Context, msg Message) error {
+	payload := map[string]string{"title": msg.Title, "body": msg.Body}
+	if b.cfg.Group != "" {
+		payload["group"] = b.cfg.Group
+	}
+	if b.cfg.Sound != "" {
+		payload["sound"] = b.cfg.Sound
+	}
+	body, _ := json.Marshal(payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	return doPush(b.client, req, TypeBark)
+}
+
+// NtfyConfig ntfy 推送，URL 为主题地址，如 https://ntfy.sh/<topic>
+type NtfyConfig struct {
+	URL      string `json:"url"`
+	Token    string `json:"token"`    // 访问令牌，可选
+	Priority string `json:"priority"` // 1-5，可选
+}
+
+type Ntfy struct {
+	cfg    NtfyConfig
+	client *http.Client
+}
+
+func NewNtfy(cfg NtfyConfig) (*Ntfy, error) {
+	if !strings.HasPrefix(cfg.URL, "http://") && !strings.HasPrefix(cfg.URL, "https://") {
+		return nil, errors.New("url 格式有误")
+	}
+	return &Ntfy{cfg: cfg, client: &http.Client{Timeout: 15 * time.Second}}, nil
+}
+
+func (n *Ntfy) Type() string {
+	return TypeNtfy
+}
+
+func (n *Ntfy) Send(ctx context.Context, msg Message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.URL, strings.NewReader(msg.Body))
+	if err != nil {
+		return err
+	}
+	// 请求头不能直接包含非 ASCII 字符，ntfy 支持 RFC 2047 编码
+	req.Header.Set("Title", mime.BEncoding.Encode("utf-8", msg.Title))
+	if n.cfg.Priority != "" {
+		req.Header.Set("Priority", n.cfg.Priority)
+	}
+	if n.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.cfg.Token)
+	}
+	return doPush(n.client, req, TypeNtfy)
+}
+
+func doPush(client *http.Client, req *http.Request, name string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: status %d %s", name, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TelegramConfig Telegram Bot API 及兼容的接口
+type TelegramConfig struct {
+	APIBase string `json:"api_base"` // 默认 https://api.telegram.org，可填自建的兼容服务或反代
+	Token   string `json:"token"`
+	ChatID  string `json:"chat_id"`
+}
+
+// Telegram 调用 sendMessage 发送纯文本
+type Telegram struct {
+	cfg    TelegramConfig
+	client *http.Client
+}
+
+func NewTelegram(cfg TelegramConfig) (*Telegram, error) {
+	if cfg.Token == "" || cfg.ChatID == "" {
+		return nil, errors.New("token 与 chat_id 不能为空")
+	}
+	if cfg.APIBase == "" {
+		cfg.APIBase = "https://api.telegram.org"
+	}
+	cfg.APIBase = strings.TrimSuffix(cfg.APIBase, "/")
+	return &Telegram{cfg: cfg, client: &http.Client{Timeout: 15 * time.Second}}, nil
+}
+
+func (t *Telegram) Type() string {
+	return TypeTelegram
+}
+
+func (t *Telegram) Send(ctx context.Context, msg Message) error {
+	body, _ := json.Marshal(map[string]any{
+		"chat_id":                  t.cfg.ChatID,
+		"text":                     msg.Text(),
+		"disable_web_page_preview": true,
+	})
+	endpoint := fmt.Sprintf("%s/bot%s/sendMessage", t.cfg.APIBase, t.cfg.Token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		// 错误信息中的地址含有 token
+		return errors.New(strings.ReplaceAll(err.Error(), t.cfg.Token, "***"))
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("telegram: unexpected status %d", resp.StatusCode)
+	}
+	if !result.OK {
+		return fmt.Errorf("telegram: %s", result.Description)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"video-factory/pkg/config"
+)
+
+// EngineFFmpeg 默认的录制引擎，调用 ffmpeg 拉流
+const EngineFFmpeg = "ffmpeg"
+
+// Engine 录制引擎，负责拉流并把数据写入 Sink，文件切分、命名与完成回调由 Sink 处理
+type Engine interface {
+	// Start 开始录制，阻塞直到 ctx 取消、调用 Stop 或发生无法恢复的错误
+	Start(ctx context.Context) error
+	// Stop 停止录制，可重复调用
+	Stop()
+	// UpdateStreamURLs 热更新线路，key 为线路名
+	UpdateStreamURLs(streamURLMap map[string]string)
+	// Stats 当前的录制状态
+	Stats() Stats
+}
+
+// Stats 录制状态
+type Stats struct {
+	Running  bool
+	File     string // 当前写入的文件，未录制时为空
+	Filesize int
+	Duration float64 // 当前文件的时长，秒
+	URL      string  // 当前拉流地址
+	Line     string  // 当前线路名
+	Health   HealthStats
+}
+
+// EngineFactory 创建录制引擎，streamURLMap 为线路名到地址的映射，header 为拉流时的请求头
+type EngineFactory func(cfg *config.AppConfig, platform string, streamURLMap map[string]string, header http.Header, sink *Sink) (Engine, error)
+
+var (
+	enginesMu sync.RWMutex
+	engines   = map[string]EngineFactory{
+		EngineFFmpeg: func(cfg *config.AppConfig, platform string, streamURLMap map[string]string, header http.Header, sink *Sink) (Engine, error) {
+			return NewRecorder(cfg, platform, streamURLMap, header, sink)
+		},
+	}
+)
+
+// RegisterEngine 注册录制引擎，同名时覆盖
+func RegisterEngine(name string, factory EngineFactory) {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	engines[name] = factory
+}
+
+// NewEngine 按名称创建录制引擎，名称为空时使用 ffmpeg
+func NewEngine(name string, cfg *config.AppConfig, platform string, streamURLMap map[string]string, header http.Header, sink *Sink) (Engine, error) {
+	if name == "" {
+		name = EngineFFmpeg
+	}
+	enginesMu.RLock()
+	factory, ok := engines[name]
+	enginesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("未知的录制引擎: %s", name)
+	}
+	return factory(cfg, platform, streamURLMap, header, sink)
+}
+
+// EngineNames 已注册的录制引擎
+func EngineNames() []string {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
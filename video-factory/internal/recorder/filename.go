@@ -0,0 +1,309 @@
+package recorder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+	"video-factory/pkg/config"
+)
+
+// maxSegmentBytes 单级目录/文件名的最大字节数，多数文件系统限制为 255
+const maxSegmentBytes = 255
+
+// Pattern 文件名模板可用的变量
+// Year ~ Second 为开播时间，File 前缀的为当前文件开始录制的时间
+type Pattern struct {
+	Username   string
+	Platform   string
+	RoomTitle  string
+	RoomRealId string
+	Quality    int
+	SessionID  int64
+
+	Year   string
+	Month  string
+	Day    string
+	Hour   string
+	Minute string
+	Second string
+
+	FileYear   string
+	FileMonth  string
+	FileDay    string
+	FileHour   string
+	FileMinute string
+	FileSecond string
+
+	StreamTime time.Time // 开播时间，可使用 {{.StreamTime.Format "20060102"}}
+	FileTime   time.Time // 当前文件开始录制的时间
+
+	Sequence int
+	Ext      string
+}
+
+var (
+	sequencePatternRegex = regexp.MustCompile(`\{\{[^}]*\.Sequence\b[^}]*}}`)
+	extPatternRegex      = regexp.MustCompile(`\{\{[^}]*\.Ext\b[^}]*}}`)
+	slugSeparatorRegex   = regexp.MustCompile(`-+`)
+)
+
+// templateFuncs 文件名模板函数，参数顺序适配管道写法，如 {{.RoomTitle | truncate 20}}
+var templateFuncs = template.FuncMap{
+	// truncate 按字符截断
+	"truncate": func(n int, s string) string {
+		runes := []rune(s)
+		if n < 0 || len(runes) <= n {
+			return s
+		}
+		return string(runes[:n])
+	},
+	// slug 转小写，非字母数字的字符替换为 -
+	"slug": func(s string) string {
+		s = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
+			}
+			return '-'
+		}, s)
+		return strings.Trim(slugSeparatorRegex.ReplaceAllString(s, "-"), "-")
+	},
+	// pad 左侧补 0 到指定宽度
+	"pad": func(width int, v any) string {
+		s := fmt.Sprint(v)
+		if n := utf8.RuneCountInString(s); n < width {
+			s = strings.Repeat("0", width-n) + s
+		}
+		return s
+	},
+}
+
+// SanitizeName 将变量值转换为可安全用作文件名的字符串
+// 路径分隔符与 Windows 保留字符替换为 _，去掉控制字符与 emoji，去掉首尾的空格和点
+// ★ ♪ © 等普通符号与扩展区汉字保留，只去掉默认以 emoji 显示或带 emoji 变体选择符的字符
+func SanitizeName(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		switch {
+		case strings.ContainsRune(`/\:*?"<>|`, r):
+			b.WriteRune('_')
+		case unicode.IsControl(r), isEmoji(r):
+			// 丢弃
+		case i+1 < len(runes) && runes[i+1] == 0xFE0F:
+			// 后跟 emoji 变体选择符，如 ❤️
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return strings.Trim(b.String(), " .")
+}
+
+// emojiTable 默认以 emoji 显示的字符（Unicode Emoji_Presentation）与组合 emoji 用的字符
+var emojiTable = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x200D, Hi: 0x200D, Stride: 1}, // 零宽连接符
+		{Lo: 0x20E3, Hi: 0x20E3, Stride: 1}, // 键帽组合符
+		{Lo: 0x231A, Hi: 0x231B, Stride: 1},
+		{Lo: 0x23E9, Hi: 0x23EC, Stride: 1},
+		{Lo: 0x23F0, Hi: 0x23F0, Stride: 1},
+		{Lo: 0x23F3, Hi: 0x23F3, Stride: 1},
+		{Lo: 0x25FD, Hi: 0x25FE, Stride: 1},
+		{Lo: 0x2614, Hi: 0x2615, Stride: 1},
+		{Lo: 0x2648, Hi: 0x2653, Stride: 1},
+		{Lo: 0x267F, Hi: 0x267F, Stride: 1},
+		{Lo: 0x2693, Hi: 0x2693, Stride: 1},
+		{Lo: 0x26A1, Hi: 0x26A1, Stride: 1},
+		{Lo: 0x26AA, Hi: 0x26AB, Stride: 1},
+		{Lo: 0x26BD, Hi: 0x26BE, Stride: 1},
+		{Lo: 0x26C4, Hi: 0x26C5, Stride: 1},
+		{Lo: 0x26CE, Hi: 0x26CE, Stride: 1},
+		{Lo: 0x26D4, Hi: 0x26D4, Stride: 1},
+		{Lo: 0x26EA, Hi: 0x26EA, Stride: 1},
+		{Lo: 0x26F2, Hi: 0x26F3, Stride: 1},
+		{Lo: 0x26F5, Hi: 0x26F5, Stride: 1},
+		{Lo: 0x26FA, Hi: 0x26FA, Stride: 1},
+		{Lo: 0x26FD, Hi: 0x26FD, Stride: 1},
+		{Lo: 0x2705, Hi: 0x2705, Stride: 1},
+		{Lo: 0x270A, Hi: 0x270B, Stride: 1},
+		{Lo: 0x2728, Hi: 0x2728, Stride: 1},
+		{Lo: 0x274C, Hi: 0x274C, Stride: 1},
+		{Lo: 0x274E, Hi: 0x274E, Stride: 1},
+		{Lo: 0x2753, Hi: 0x2755, Stride: 1},
+		{Lo: 0x2757, Hi: 0x2757, Stride: 1},
+		{Lo: 0x2795, Hi: 0x2797, Stride: 1},
+		{Lo: 0x27B0, Hi: 0x27B0, Stride: 1},
+		{Lo: 0x27BF, Hi: 0x27BF, Stride: 1},
+		{Lo: 0x2B1B, Hi: 0x2B1C, Stride: 1},
+		{Lo: 0x2B50, Hi: 0x2B50, Stride: 1},
+		{Lo: 0x2B55, Hi: 0x2B55, Stride: 1},
+		{Lo: 0xFE00, Hi: 0xFE0F, Stride: 1}, // 变体选择符
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1F000, Hi: 0x1FAFF, Stride: 1}, // 麻将、扑克、区域旗帜、表情与各类图形符号
+		{Lo: 0xE0020, Hi: 0xE007F, Stride: 1}, // 旗帜用的标签字符
+	},
+}
+
+// isEmoji emoji 及其组合用的字符
+func isEmoji(r rune) bool {
+	return unicode.Is(emojiTable, r)
+}
+
+// sanitizeSegment 清理单级路径，并限制长度
+func sanitizeSegment(segment string) string {
+	segment = SanitizeName(segment)
+	for len(segment) > maxSegmentBytes {
+		_, size := utf8.DecodeLastRuneInString(segment)
+		segment = segment[:len(segment)-size]
+	}
+	return segment
+}
+
+// sanitizeVars 清理所有字符串变量，避免变量中的 / 等字符改变目录结构
+func sanitizeVars(p *Pattern) *Pattern {
+	safe := *p
+	safe.Username = SanitizeName(p.Username)
+	safe.Platform = SanitizeName(p.Platform)
+	safe.RoomTitle = SanitizeName(p.RoomTitle)
+	safe.RoomRealId = SanitizeName(p.RoomRealId)
+	safe.Ext = SanitizeName(p.Ext)
+	return &safe
+}
+
+// NewPattern 根据开播时间和文件开始时间构造模板变量
+func NewPattern(streamAt time.Time, fileAt time.Time) *Pattern {
+	return &Pattern{
+		Year:       streamAt.Format("2006"),
+		Month:      streamAt.Format("01"),
+		Day:        streamAt.Format("02"),
+		Hour:       streamAt.Format("15"),
+		Minute:     streamAt.Format("04"),
+		Second:     streamAt.Format("05"),
+		FileYear:   fileAt.Format("2006"),
+		FileMonth:  fileAt.Format("01"),
+		FileDay:    fileAt.Format("02"),
+		FileHour:   fileAt.Format("15"),
+		FileMinute: fileAt.Format("04"),
+		FileSecond: fileAt.Format("05"),
+		StreamTime: streamAt,
+		FileTime:   fileAt,
+	}
+}
+
+// completePattern 补全模板：必须包含 Sequence，否则文件会覆盖；必须包含扩展名
+func completePattern(filenamePattern string) string {
+	if !sequencePatternRegex.MatchString(filenamePattern) {
+		filenamePattern += "_{{.Sequence}}"
+	}
+	if !extPatternRegex.MatchString(filenamePattern) {
+		filenamePattern += ".{{.Ext}}"
+	}
+	return filenamePattern
+}
+
+// RenderFilename 渲染文件名模板，返回 root 下的文件路径
+// 模板中的 / 视为目录分隔符，每一级都会清理，且不能跳出 root
+func RenderFilename(root string, filenamePattern string, vars *Pattern) (string, error) {
+	tpl, err := template.New("filename").Funcs(templateFuncs).Option("missingkey=error").Parse(completePattern(filenamePattern))
+	if err != nil {
+		return "", fmt.Errorf("filename pattern error: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err = tpl.Execute(&buf, sanitizeVars(vars)); err != nil {
+		return "", fmt.Errorf("template execution error: %w", err)
+	}
+
+	rendered := filepath.ToSlash(buf.String())
+	if strings.HasPrefix(rendered, "/") || filepath.IsAbs(buf.String()) {
+		return "", errors.New("文件名格式不能是绝对路径，请使用录制根目录配置")
+	}
+
+	segments := make([]string, 0, strings.Count(rendered, "/")+1)
+	for _, segment := range strings.Split(rendered, "/") {
+		if segment == ".." {
+			return "", errors.New("文件名格式不能包含 ..")
+		}
+		if segment = sanitizeSegment(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	if len(segments) == 0 {
+		return "", errors.New("文件名为空")
+	}
+
+	name := segments[len(segments)-1]
+	if ext := "." + vars.Ext; !strings.HasSuffix(name, ext) || name == ext {
+		return "", fmt.Errorf("文件名必须以扩展名结尾: %s", name)
+	}
+	return filepath.Join(append([]string{root}, segments...)...), nil
+}
+
+// SamplePattern 预览与校验用的示例变量
+func SamplePattern() *Pattern {
+	now := time.Now()
+	p := NewPattern(now.Add(-30*time.Minute), now)
+	p.Username = "主播/名字:🎤"
+	p.Platform = "bili"
+	p.RoomTitle = "直播间标题 | 测试"
+	p.RoomRealId = "21452505"
+	p.Quality = 10000
+	p.SessionID = 1234567890
+	p.Sequence = 1
+	p.Ext = "ts"
+	return p
+}
+
+// PreviewFilename 使用示例变量预览文件名
+func PreviewFilename(root string, filenamePattern string, vars *Pattern) (string, error) {
+	if vars == nil {
+		vars = SamplePattern()
+	}
+	return RenderFilename(root, filenamePattern, vars)
+}
+
+// ValidateFilenamePattern 校验文件名模板
+func ValidateFilenamePattern(filenamePattern string) error {
+	if strings.TrimSpace(filenamePattern) == "" {
+		return errors.New("文件名格式不能为空")
+	}
+	_, err := RenderFilename("", filenamePattern, SamplePattern())
+	return err
+}
+
+// RegisterValidators 登记录制相关配置项的校验，需要在加载配置前调用
+func RegisterValidators() {
+	config.RegisterValidator("recorder.filename_pattern", ValidateFilenamePattern)
+}
+
+// GenerateFileName 生成当前文件的路径
+func (s *Sink) GenerateFileName() (string, error) {
+	fileAt := s.fileStart
+	if fileAt.IsZero() {
+		fileAt = time.Now()
+	}
+
+	vars := NewPattern(time.Unix(s.StreamAt, 0), fileAt)
+	vars.Username = s.Username
+	vars.RoomTitle = s.RoomTitle
+	if s.RoomInfo != nil {
+		info := s.RoomInfo()
+		vars.Username = info.Username
+		vars.RoomTitle = info.Title
+	}
+	vars.Platform = s.Platform
+	vars.RoomRealId = s.RoomRealId
+	vars.Quality = s.Quality
+	vars.SessionID = s.SessionID
+	vars.Sequence = s.Sequence
+	vars.Ext = s.Ext
+
+	return RenderFilename(s.Config.Recorder.OutputDir, s.Config.Recorder.FilenamePattern, vars)
+}
@@ -0,0 +1,134 @@
+package recorder
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+	"video-factory/pkg/config"
+)
+
+func testPattern() *Pattern {
+	streamAt := time.Date(2024, 1, 2, 20, 30, 0, 0, time.Local)
+	p := NewPattern(streamAt, streamAt.Add(90*time.Minute))
+	p.Username = "a/b:c🎤"
+	p.Platform = "bili"
+	p.RoomTitle = "Hello World! 晚安"
+	p.RoomRealId = "123"
+	p.Quality = 10000
+	p.SessionID = 42
+	p.Sequence = 3
+	p.Ext = "ts"
+	return p
+}
+
+func TestSanitizeName(t *testing.T) {
+	cases := map[string]string{
+		"a/b\\c":         "a_b_c",
+		`x:y*z?"<>|`:     "x_y_z_____",
+		"主播🎤名字❤️":        "主播名字",
+		"  .hidden. ":    "hidden",
+		"tab\tand\nline": "tabandline",
+		// 扩展区汉字与常见符号不是 emoji
+		"𠀀★♪©°㊣":       "𠀀★♪©°㊣",
+		"⭐🇨🇳👨‍👩‍👧1️⃣名": "名",
+	}
+	for in, want := range cases {
+		if got := SanitizeName(in); got != want {
+			t.Errorf("SanitizeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRenderFilename(t *testing.T) {
+	cases := []struct {
+		pattern string
+		want    string
+	}{
+		{
+			"{{.Username}}_{{.Year}}{{.Month}}{{.Day}}-{{.Hour}}{{.Minute}}_{{.Sequence}}",
+			"a_b_c_20240102-2030_3.ts",
+		},
+		{
+			"{{.Platform}}/{{.RoomRealId}}/{{.FileHour}}{{.FileMinute}}_{{.Sequence | pad 3}}.{{.Ext}}",
+			"bili/123/2200_003.ts",
+		},
+		{
+			"{{.RoomTitle | slug}}_{{.Quality}}_{{.SessionID}}",
+			"hello-world-晚安_10000_42_3.ts",
+		},
+		{
+			"{{.RoomTitle | truncate 5}}",
+			"Hello_3.ts",
+		},
+		{
+			`{{.StreamTime.Format "2006-01"}}/{{.Username}}`,
+			"2024-01/a_b_c_3.ts",
+		},
+	}
+	for _, c := range cases {
+		got, err := RenderFilename("", c.pattern, testPattern())
+		if err != nil {
+			t.Errorf("RenderFilename(%q) err = %v", c.pattern, err)
+			continue
+		}
+		if got != filepath.FromSlash(c.want) {
+			t.Errorf("RenderFilename(%q) = %q, want %q", c.pattern, got, c.want)
+		}
+	}
+}
+
+func TestRenderFilenameRoot(t *testing.T) {
+	got, err := RenderFilename("/data/records", "{{.Platform}}/{{.Username}}", testPattern())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join("/data/records", "bili", "a_b_c_3.ts"); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestValidateFilenamePattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		wantErr bool
+	}{
+		{"{{.Username}}_{{.Year}}-{{.Month}}-{{.Day}}_{{.Hour}}-{{.Minute}}-{{.Second}}_{{.Sequence}}", false},
+		{"{{.Platform}}/{{.Username}}/{{.FileTime.Format \"150405\"}}", false},
+		{"", true},
+		{"{{.Username", true},
+		{"{{.Unknown}}", true},
+		{"{{.Username | nofunc}}", true},
+		{"/abs/{{.Username}}", true},
+		{"../{{.Username}}", true},
+		{"{{.Ext}}/{{.Username}}", true},
+	}
+	for _, c := range cases {
+		err := ValidateFilenamePattern(c.pattern)
+		if (err != nil) != c.wantErr {
+			t.Errorf("ValidateFilenamePattern(%q) err = %v, wantErr %v", c.pattern, err, c.wantErr)
+		}
+	}
+
+	// 登记后通过配置校验生效
+	RegisterValidators()
+	if err := config.Validate("recorder.filename_pattern", "../{{.Username}}"); err == nil {
+		t.Error("config.Validate should use the filename validator")
+	}
+}
+
+func TestGenerateFileNameRoomInfo(t *testing.T) {
+	s := &Sink{
+		Config:    &config.AppConfig{Recorder: &config.Recorder{FilenamePattern: "{{.Username}}_{{.RoomTitle}}"}},
+		Username:  "旧主播",
+		RoomTitle: "旧标题",
+		Ext:       "flv",
+	}
+	if got, _ := s.GenerateFileName(); got != "旧主播_旧标题_0.flv" {
+		t.Errorf("got %q", got)
+	}
+	// 创建文件时使用最新的房间信息
+	s.RoomInfo = func() RoomInfo { return RoomInfo{Title: "新标题", Username: "新主播"} }
+	if got, _ := s.GenerateFileName(); got != "新主播_新标题_0.flv" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,325 @@
+package recorder
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// healthSampleInterval 健康指标采样间隔
+	healthSampleInterval = 10 * time.Second
+	// maxHealthSamples 单个文件最多保留的采样数，超过后两两合并降采样
+	maxHealthSamples = 360
+	// stallThreshold 两次读到数据的间隔超过该值记为一次卡顿
+	stallThreshold = 5 * time.Second
+	// gapThreshold ffmpeg 时间戳前进超过墙上时间该值记为一次时间戳跳变
+	gapThreshold = 5 * time.Second
+)
+
+var (
+	bitratePattern = regexp.MustCompile(`bitrate=\s*([\d.]+)kbits/s`)
+	speedPattern   = regexp.MustCompile(`speed=\s*([\d.]+)x`)
+	fpsPattern     = regexp.MustCompile(`fps=\s*([\d.]+)`)
+	framePattern   = regexp.MustCompile(`frame=\s*(\d+)`)
+	// gapLogPatterns ffmpeg 检测到时间戳不连续时输出的日志
+	gapLogPatterns = []string{"Non-monotonous DTS", "Non-monotonic DTS", "DTS discontinuity", "timestamp discontinuity"}
+)
+
+// HealthSample 一次采样
+type HealthSample struct {
+	At      int64   `json:"at"`      // 采样时间，毫秒
+	Bitrate float64 `json:"bitrate"` // 采样周期内的实际码率 kbps
+	Fps     float64 `json:"fps"`
+	Speed   float64 `json:"speed"`
+	Line    string  `json:"line"` // 当前线路
+}
+
+// LineStats 单条线路在一个文件内的表现
+type LineStats struct {
+	Name     string  `json:"name"`
+	Bytes    int64   `json:"bytes"`
+	Seconds  float64 `json:"seconds"` // 在该线路上录制的时长
+	Bitrate  float64 `json:"bitrate"` // 平均码率 kbps
+	Stalls   int     `json:"stalls"`
+	Gaps     int     `json:"gaps"`
+	Failures int     `json:"failures"` // 因该线路异常而切换的次数
+}
+
+// HealthStats 健康指标快照
+type HealthStats struct {
+	StartTime      int64          `json:"startTime"`      // 毫秒
+	Bitrate        float64        `json:"bitrate"`        // 最近一个采样周期的码率 kbps
+	AvgBitrate     float64        `json:"avgBitrate"`     // 平均码率 kbps
+	FfmpegBitrate  float64        `json:"ffmpegBitrate"`  // ffmpeg 统计行中的码率 kbps
+	Fps            float64        `json:"fps"`            // 最近的帧率
+	AvgFps         float64        `json:"avgFps"`         // 平均帧率
+	Speed          float64        `json:"speed"`          // 最近的速度倍率，长期低于 1 说明拉流跟不上
+	GapCount       int            `json:"gapCount"`       // 时间戳跳变次数
+	StallCount     int            `json:"stallCount"`     // 卡顿次数
+	URLSwitchCount int            `json:"urlSwitchCount"` // 线路切换次数
+	Lines          []LineStats    `json:"lines"`
+	Samples        []HealthSample `json:"samples,omitempty"`
+}
+
+// Health 统计单个录制文件的直播流健康指标，readPipe/HandleStderr/SwitchNextStream 并发写入
+type Health struct {
+	mu sync.Mutex
+
+	startTime  time.Time
+	totalBytes int64
+
+	// 采样窗口
+	windowStart time.Time
+	windowBytes int64
+	lastSample  HealthSample
+	samples     []HealthSample
+	fpsSum      float64
+	fpsCnt      int
+
+	// ffmpeg 统计行
+	ffmpegBitrate float64
+	speed         float64
+	fps           float64
+	lastFrame     int64
+	lastFrameAt   time.Time
+
+	// 时间戳跳变检测
+	lastMediaTime float64
+	lastMediaAt   time.Time
+
+	lastDataAt time.Time
+	gapCount   int
+	stallCount int
+	switchCnt  int
+
+	line      string
+	lineStart time.Time
+	lines     map[string]*LineStats
+	lineOrder []string
+}
+
+func NewHealth(line string) *Health {
+	now := time.Now()
+	h := &Health{
+		startTime:   now,
+		windowStart: now,
+		lines:       make(map[string]*LineStats),
+	}
+	h.setLine(line, now)
+	return h
+}
+
+// lineStats 获取线路统计，调用方持有锁
+func (h *Health) lineStats(line string) *LineStats {
+	ls, ok := h.lines[line]
+	if !ok {
+		ls = &LineStats{Name: line}
+		h.lines[line] = ls
+		h.lineOrder = append(h.lineOrder, line)
+	}
+	return ls
+}
+
+func (h *Health) setLine(line string, now time.Time) {
+	if h.line != "" {
+		h.lineStats(h.line).Seconds += now.Sub(h.lineStart).Seconds()
+	}
+	h.line = line
+	h.lineStart = now
+	h.lineStats(line)
+	// 新线路重新开始计算时间戳与帧数
+	h.lastMediaAt = time.Time{}
+	h.lastFrameAt = time.Time{}
+}
+
+// OnData 记录读到的数据
+func (h *Health) OnData(n int, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.lastDataAt.IsZero() && now.Sub(h.lastDataAt) >= stallThreshold {
+		h.stallCount++
+		h.lineStats(h.line).Stalls++
+	}
+	h.lastDataAt = now
+	h.totalBytes += int64(n)
+	h.windowBytes += int64(n)
+	h.lineStats(h.line).Bytes += int64(n)
+
+	if now.Sub(h.windowStart) >= healthSampleInterval {
+		h.sample(now)
+	}
+}
+
+// OnStall 看门狗判定僵尸流时调用
+func (h *Health) OnStall() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stallCount++
+	h.lineStats(h.line).Stalls++
+}
+
+// OnSwitch 切换线路，failed 表示因当前线路异常而切换
+func (h *Health) OnSwitch(line string, failed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if failed {
+		h.lineStats(h.line).Failures++
+	}
+	if line == h.line {
+		return
+	}
+	h.switchCnt++
+	h.setLine(line, time.Now())
+}
+
+// OnProcessStart 重启 ffmpeg 进程后，进度从 0 开始，重置时间戳检测
+func (h *Health) OnProcessStart() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastMediaAt = time.Time{}
+	h.lastFrameAt = time.Time{}
+}
+
+// OnStatsLine 解析 ffmpeg 的统计行与日志
+func (h *Health) OnStatsLine(line string, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, p := range gapLogPatterns {
+		if strings.Contains(line, p) {
+			h.gapCount++
+			h.lineStats(h.line).Gaps++
+			return
+		}
+	}
+
+	if m := bitratePattern.FindStringSubmatch(line); len(m) == 2 {
+		h.ffmpegBitrate, _ = strconv.ParseFloat(m[1], 64)
+	}
+	if m := speedPattern.FindStringSubmatch(line); len(m) == 2 {
+		h.speed, _ = strconv.ParseFloat(m[1], 64)
+	}
+
+	// -c copy 时 fps= 可能缺失，用 frame= 的增量计算
+	if m := fpsPattern.FindStringSubmatch(line); len(m) == 2 {
+		h.fps, _ = strconv.ParseFloat(m[1], 64)
+	} else if m := framePattern.FindStringSubmatch(line); len(m) == 2 {
+		frame, _ := strconv.ParseInt(m[1], 10, 64)
+		if !h.lastFrameAt.IsZero() && frame >= h.lastFrame {
+			if elapsed := now.Sub(h.lastFrameAt).Seconds(); elapsed >= 1 {
+				h.fps = float64(frame-h.lastFrame) / elapsed
+				h.lastFrame, h.lastFrameAt = frame, now
+			}
+		} else {
+			h.lastFrame, h.lastFrameAt = frame, now
+		}
+	}
+
+	if m := timePattern.FindStringSubmatch(line); len(m) == 5 {
+		hh, _ := strconv.Atoi(m[1])
+		mm, _ := strconv.Atoi(m[2])
+		ss, _ := strconv.Atoi(m[3])
+		mediaTime := float64(hh*3600 + mm*60 + ss)
+		if !h.lastMediaAt.IsZero() {
+			advance := mediaTime - h.lastMediaTime
+			wall := now.Sub(h.lastMediaAt).Seconds()
+			if advance-wall > gapThreshold.Seconds() || advance < 0 {
+				h.gapCount++
+				h.lineStats(h.line).Gaps++
+			}
+		}
+		h.lastMediaTime, h.lastMediaAt = mediaTime, now
+	}
+}
+
+// sample 结束当前采样窗口，调用方持有锁
+func (h *Health) sample(now time.Time) {
+	elapsed := now.Sub(h.windowStart).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	s := HealthSample{
+		At:      now.UnixMilli(),
+		Bitrate: float64(h.windowBytes) * 8 / 1000 / elapsed,
+		Fps:     h.fps,
+		Speed:   h.speed,
+		Line:    h.line,
+	}
+	h.lastSample = s
+	h.windowStart = now
+	h.windowBytes = 0
+	if h.fps > 0 {
+		h.fpsSum += h.fps
+		h.fpsCnt++
+	}
+
+	h.samples = append(h.samples, s)
+	if len(h.samples) > maxHealthSamples {
+		h.samples = downsample(h.samples)
+	}
+}
+
+// downsample 相邻两个采样合并为一个，保持覆盖整个文件的时间范围
+func downsample(samples []HealthSample) []HealthSample {
+	merged := make([]HealthSample, 0, len(samples)/2+1)
+	for i := 0; i < len(samples); i += 2 {
+		if i+1 == len(samples) {
+			merged = append(merged, samples[i])
+			break
+		}
+		a, b := samples[i], samples[i+1]
+		merged = append(merged, HealthSample{
+			At:      b.At,
+			Bitrate: (a.Bitrate + b.Bitrate) / 2,
+			Fps:     (a.Fps + b.Fps) / 2,
+			Speed:   (a.Speed + b.Speed) / 2,
+			Line:    b.Line,
+		})
+	}
+	return merged
+}
+
+// Snapshot 获取当前指标，withSamples 为 false 时不返回采样序列
+func (h *Health) Snapshot(withSamples bool) HealthStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	stats := HealthStats{
+		StartTime:      h.startTime.UnixMilli(),
+		Bitrate:        h.lastSample.Bitrate,
+		FfmpegBitrate:  h.ffmpegBitrate,
+		Fps:            h.fps,
+		Speed:          h.speed,
+		GapCount:       h.gapCount,
+		StallCount:     h.stallCount,
+		URLSwitchCount: h.switchCnt,
+	}
+	if elapsed := now.Sub(h.startTime).Seconds(); elapsed > 0 {
+		stats.AvgBitrate = float64(h.totalBytes) * 8 / 1000 / elapsed
+	}
+	if h.fpsCnt > 0 {
+		stats.AvgFps = h.fpsSum / float64(h.fpsCnt)
+	}
+
+	stats.Lines = make([]LineStats, 0, len(h.lineOrder))
+	for _, name := range h.lineOrder {
+		ls := *h.lines[name]
+		if name == h.line {
+			ls.Seconds += now.Sub(h.lineStart).Seconds()
+		}
+		if ls.Seconds > 0 {
+			ls.Bitrate = float64(ls.Bytes) * 8 / 1000 / ls.Seconds
+		}
+		stats.Lines = append(stats.Lines, ls)
+	}
+
+	if withSamples {
+		stats.Samples = append([]HealthSample(nil), h.samples...)
+	}
+	return stats
+}
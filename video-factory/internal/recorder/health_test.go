@@ -0,0 +1,108 @@
+package recorder
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHealthStatsLine(t *testing.T) {
+	h := NewHealth("线路1")
+	now := time.Now()
+
+	h.OnStatsLine("size=    1024kB time=00:00:10.00 bitrate= 838.9kbits/s speed=1.02x", now)
+	h.OnStatsLine("frame=  250 fps= 25 q=-1.0 size=    2048kB time=00:00:20.00 bitrate= 840.0kbits/s speed=1.01x", now.Add(10*time.Second))
+
+	stats := h.Snapshot(false)
+	if stats.FfmpegBitrate != 840 {
+		t.Errorf("FfmpegBitrate = %v, want 840", stats.FfmpegBitrate)
+	}
+	if stats.Speed != 1.01 {
+		t.Errorf("Speed = %v, want 1.01", stats.Speed)
+	}
+	if stats.Fps != 25 {
+		t.Errorf("Fps = %v, want 25", stats.Fps)
+	}
+	if stats.GapCount != 0 {
+		t.Errorf("GapCount = %d, want 0", stats.GapCount)
+	}
+}
+
+func TestHealthFpsFromFrames(t *testing.T) {
+	h := NewHealth("线路1")
+	now := time.Now()
+
+	h.OnStatsLine("frame=  100 size=    1024kB time=00:00:04.00 bitrate= 838.9kbits/s speed=1x", now)
+	h.OnStatsLine("frame=  150 size=    2048kB time=00:00:06.00 bitrate= 838.9kbits/s speed=1x", now.Add(2*time.Second))
+
+	if fps := h.Snapshot(false).Fps; fps != 25 {
+		t.Errorf("Fps = %v, want 25", fps)
+	}
+}
+
+func TestHealthGaps(t *testing.T) {
+	h := NewHealth("线路1")
+	now := time.Now()
+
+	h.OnStatsLine("size=1kB time=00:00:10.00 bitrate=1.0kbits/s speed=1x", now)
+	// 1 秒墙上时间内时间戳前进了 30 秒
+	h.OnStatsLine("size=1kB time=00:00:40.00 bitrate=1.0kbits/s speed=1x", now.Add(time.Second))
+	h.OnStatsLine("[mpegts @ 0x0] Non-monotonous DTS in output stream 0:1", now.Add(2*time.Second))
+
+	stats := h.Snapshot(false)
+	if stats.GapCount != 2 {
+		t.Errorf("GapCount = %d, want 2", stats.GapCount)
+	}
+	if stats.Lines[0].Gaps != 2 {
+		t.Errorf("Lines[0].Gaps = %d, want 2", stats.Lines[0].Gaps)
+	}
+}
+
+func TestHealthStallsAndSwitches(t *testing.T) {
+	h := NewHealth("线路1")
+	now := time.Now()
+
+	h.OnData(1000, now)
+	h.OnData(1000, now.Add(time.Second))
+	h.OnData(1000, now.Add(10*time.Second)) // 9 秒没有数据
+	h.OnSwitch("线路2", true)
+	h.OnData(1000, now.Add(11*time.Second))
+	h.OnSwitch("线路2", false) // 线路未变化不计数
+
+	stats := h.Snapshot(false)
+	if stats.StallCount != 1 {
+		t.Errorf("StallCount = %d, want 1", stats.StallCount)
+	}
+	if stats.URLSwitchCount != 1 {
+		t.Errorf("URLSwitchCount = %d, want 1", stats.URLSwitchCount)
+	}
+	if len(stats.Lines) != 2 {
+		t.Fatalf("len(Lines) = %d, want 2", len(stats.Lines))
+	}
+	if stats.Lines[0].Bytes != 3000 || stats.Lines[0].Failures != 1 || stats.Lines[0].Stalls != 1 {
+		t.Errorf("Lines[0] = %+v", stats.Lines[0])
+	}
+	if stats.Lines[1].Bytes != 1000 {
+		t.Errorf("Lines[1].Bytes = %d, want 1000", stats.Lines[1].Bytes)
+	}
+}
+
+func TestHealthSamples(t *testing.T) {
+	h := NewHealth("线路1")
+	now := h.windowStart
+
+	for i := 1; i <= maxHealthSamples+1; i++ {
+		// 每个采样周期 125000 字节，即 100 kbps
+		h.OnData(125000, now.Add(time.Duration(i)*healthSampleInterval))
+	}
+
+	stats := h.Snapshot(true)
+	if len(stats.Samples) > maxHealthSamples {
+		t.Errorf("len(Samples) = %d, want <= %d", len(stats.Samples), maxHealthSamples)
+	}
+	if stats.Bitrate != 100 {
+		t.Errorf("Bitrate = %v, want 100", stats.Bitrate)
+	}
+	if stats.Samples[0].Bitrate != 100 {
+		t.Errorf("Samples[0].Bitrate = %v, want 100", stats.Samples[0].Bitrate)
+	}
+}
@@ -0,0 +1,291 @@
+package recorder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"video-factory/internal/common/consts"
+	"video-factory/internal/lineprobe"
+	"video-factory/pkg/config"
+	"video-factory/pkg/fetcher"
+)
+
+// EngineHTTP 直接下载 HTTP-FLV 流写入文件，不依赖 ffmpeg，只支持音视频录制
+const EngineHTTP = "http"
+
+func init() {
+	RegisterEngine(EngineHTTP, func(cfg *config.AppConfig, platform string, streamURLMap map[string]string, header http.Header, sink *Sink) (Engine, error) {
+		return NewHTTPEngine(cfg, platform, streamURLMap, header, sink)
+	})
+}
+
+// errLinkExpired 上游返回 403，链接签名已过期，需要 Manager 刷新
+var errLinkExpired = errors.New("stream link expired")
+
+// HTTPEngine 原生 HTTP 录制引擎，按原样保存 FLV 数据
+type HTTPEngine struct {
+	Config   *config.AppConfig
+	Platform string
+	Header   http.Header // 拉流请求头，创建时复制，平台更新 Cookie 时不会并发修改
+	Sink     *Sink
+
+	StreamURLs      []string
+	StreamNames     []string // 与 StreamURLs 一一对应的线路名
+	CurrentURLIndex int
+
+	client       *http.Client
+	rapidFailCnt int // 连续快速失败的次数
+	running      atomic.Bool
+	stop         context.CancelFunc
+	mu           sync.RWMutex
+}
+
+func NewHTTPEngine(cfg *config.AppConfig, platform string, streamURLMap map[string]string, header http.Header, sink *Sink) (*HTTPEngine, error) {
+	if len(streamURLMap) == 0 {
+		return nil, fmt.Errorf("stream urls is empty")
+	}
+	if sink == nil {
+		return nil, fmt.Errorf("sink is nil")
+	}
+	if sink.Mode == consts.RecordModeAudio {
+		return nil, fmt.Errorf("http 录制引擎不支持纯音频录制")
+	}
+
+	// 直播流是长连接，不能使用带超时的全局客户端，只复用其代理设置
+	transport := http.DefaultTransport
+	if fetcher.GlobalClient != nil && fetcher.GlobalClient.Transport != nil {
+		transport = fetcher.GlobalClient.Transport
+	}
+
+	streamURLMap = flvStreamURLs(streamURLMap)
+	if len(streamURLMap) == 0 {
+		return nil, fmt.Errorf("http 录制引擎需要 FLV 直播流地址")
+	}
+	names, urls := sortStreamURLs(platform, streamURLMap)
+	e := &HTTPEngine{
+		Config:      cfg,
+		Platform:    platform,
+		Header:      header.Clone(),
+		Sink:        sink,
+		StreamURLs:  urls,
+		StreamNames: names,
+		client:      &http.Client{Transport: transport},
+	}
+	// 保存的是原始 FLV 数据
+	sink.Ext = "flv"
+	sink.SetLine(e.currentLine(), false)
+	return e, nil
+}
+
+// Start 开始录制循环，阻塞直到 context 取消或发生致命错误
+func (e *HTTPEngine) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	e.mu.Lock()
+	e.stop = cancel
+	e.mu.Unlock()
+
+	if err := e.Sink.Open(); err != nil {
+		return fmt.Errorf("next file: %w", err)
+	}
+	e.rapidFailCnt = 0
+	e.running.Store(true)
+	defer func() {
+		e.running.Store(false)
+		if err := e.Sink.Close(); err != nil {
+			e.Sink.Log.Err(err).Msgf("cleanup on record stream exit")
+		}
+	}()
+	e.Sink.Log.Info().Str("filename", e.Sink.Stats().File).Msg("[HTTPEngine] 开始录制")
+
+	for {
+		if ctx.Err() != nil {
+			e.Sink.Log.Info().Str("name", e.Sink.Username).Msg("[HTTPEngine] 录制任务收到停止信号")
+			return nil
+		}
+
+		currentURL := e.GetCurrentURL()
+		e.Sink.Log.Info().Str("url", currentURL).Msg("[HTTPEngine] 开始拉流")
+
+		startTime := time.Now()
+		err := e.download(ctx, currentURL)
+		if ctx.Err() != nil {
+			e.Sink.Log.Info().Str("name", e.Sink.Username).Msg("[HTTPEngine] 录制任务已停止，原因：收到停止信号")
+			return nil
+		}
+		e.Sink.Log.Warn().Err(err).Str("name", e.Sink.Username).Msg("[HTTPEngine] 录制中断，进行故障排查")
+
+		// 签名过期换线路也没用，交给 Manager 刷新
+		if errors.Is(err, errLinkExpired) {
+			return err
+		}
+
+		if time.Since(startTime) < 10*time.Second {
+			e.rapidFailCnt++
+			if e.rapidFailCnt > len(e.StreamURLs) {
+				e.Sink.Log.Error().Msg("[HTTPEngine] 所有线路轮询失败，进入冷却模式 (60s)")
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-time.After(60 * time.Second):
+					return fmt.Errorf("[HTTPEngine] all streams failed after cooldown")
+				}
+			}
+			e.SwitchNextStream()
+			continue
+		}
+
+		// 录制了一段时间后断开，多为网络抖动，原线路重连
+		e.rapidFailCnt = 0
+	}
+}
+
+// download 拉取一次直播流写入 Sink，连接断开或超时无数据时返回
+func (e *HTTPEngine) download(ctx context.Context, streamURL string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, streamURL, nil)
+	if err != nil {
+		return err
+	}
+	fetcher.ApplyHeader(req, e.Header)
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusForbidden:
+		return errLinkExpired
+	case resp.StatusCode != http.StatusOK:
+		return &fetcher.StatusError{StatusCode: resp.StatusCode}
+	}
+
+	startTime := time.Now()
+	e.Sink.ProcessStarted()
+
+	// 看门狗：长时间没有数据时断开连接
+	var lastActivity atomic.Int64
+	lastActivity.Store(startTime.Unix())
+	go func() {
+		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if time.Now().Unix()-lastActivity.Load() > int64(stallTimeout.Seconds()) {
+					e.Sink.Log.Error().Str("url", streamURL).Msg("[HTTPEngine] 检测到直播流长时间未更新(僵尸流)，断开重连")
+					e.Sink.Health().OnStall()
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	buf := make([]byte, readBufferSize)
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			lastActivity.Store(time.Now().Unix())
+			if _, err := e.Sink.Write(buf[:n]); err != nil {
+				return err
+			}
+			// 不解析 FLV 时间戳，按拉流时长估算进度
+			e.Sink.Progress(time.Since(startTime).Seconds())
+		}
+		if readErr != nil {
+			if readErr == io.EOF {
+				return fmt.Errorf("http stream EOF")
+			}
+			return readErr
+		}
+	}
+}
+
+func (e *HTTPEngine) GetCurrentURL() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if len(e.StreamURLs) == 0 {
+		return ""
+	}
+	return e.StreamURLs[e.CurrentURLIndex%len(e.StreamURLs)]
+}
+
+func (e *HTTPEngine) currentLine() string {
+	if e.CurrentURLIndex < len(e.StreamNames) {
+		return e.StreamNames[e.CurrentURLIndex]
+	}
+	return ""
+}
+
+// Stop 停止录制，Start 返回前 Sink 会完成当前文件
+func (e *HTTPEngine) Stop() {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.stop != nil {
+		e.stop()
+	}
+}
+
+// Stats 当前的录制状态
+func (e *HTTPEngine) Stats() Stats {
+	stats := e.Sink.Stats()
+	stats.Running = e.running.Load()
+	stats.URL = e.GetCurrentURL()
+	return stats
+}
+
+// UpdateStreamURLs 热更新线路，下次重连时生效
+func (e *HTTPEngine) UpdateStreamURLs(newURLMap map[string]string) {
+	newURLMap = flvStreamURLs(newURLMap)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(newURLMap) == 0 {
+		return
+	}
+	e.StreamNames, e.StreamURLs = sortStreamURLs(e.Platform, newURLMap)
+	e.CurrentURLIndex = 0
+	e.Sink.SetLine(e.currentLine(), false)
+	e.Sink.Log.Info().Str("name", e.Sink.Username).Msg("[HTTPEngine] 内部流地址列表已热更新(等待下次重连生效)")
+}
+
+// flvStreamURLs 去掉 HLS 播放列表地址，同时下发 FLV 与 HLS 的平台（如猫耳）只使用 FLV 线路
+func flvStreamURLs(streamURLMap map[string]string) map[string]string {
+	result := make(map[string]string, len(streamURLMap))
+	for name, streamURL := range streamURLMap {
+		if u, err := url.Parse(streamURL); err == nil && strings.HasSuffix(u.Path, ".m3u8") {
+			continue
+		}
+		result[name] = streamURL
+	}
+	return result
+}
+
+// SwitchNextStream 当前线路失败，切换到下一条
+func (e *HTTPEngine) SwitchNextStream() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.CurrentURLIndex < len(e.StreamURLs) {
+		lineprobe.Default().ReportFailure(e.Platform, e.StreamURLs[e.CurrentURLIndex])
+	}
+	if len(e.StreamURLs) > 0 {
+		e.CurrentURLIndex = (e.CurrentURLIndex + 1) % len(e.StreamURLs)
+	}
+	e.Sink.SetLine(e.currentLine(), true)
+	e.Sink.Log.Info().Str("line", e.currentLine()).Msg("[HTTPEngine] 切换到下一条线路")
+}
@@ -1,86 +1,41 @@
 package recorder
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
-	"text/template"
 	"time"
 )
 
-type Pattern struct {
-	Username   string
-	Year       string
-	Month      string
-	Day        string
-	Hour       string
-	Minute     string
-	Second     string
-	Sequence   int
-	RoomRealId string
-	Ext        string
+// FileRecord 一个录制完成的文件
+type FileRecord struct {
+	Filename  string
+	Sequence  int   // 文件序号
+	StartTime int64 // 毫秒
+	EndTime   int64 // 毫秒
+	Filesize  int
+	Duration  float64
+	Health    HealthStats
 }
 
-var (
-	sequencePatternRegex = regexp.MustCompile(`\{\{\s*\.Sequence\s*}}`)
-	extPatternRegex      = regexp.MustCompile(`\{\{\s*\.Ext\s*}}`)
-)
-
-func (r *Recorder) GenerateFileName() (string, error) {
-	var buf bytes.Buffer
-
-	filenamePattern := r.Config.Recorder.FilenamePattern
-
-	// 必须包含 sequence，否则文件会覆盖
-	if !sequencePatternRegex.MatchString(filenamePattern) {
-		filenamePattern += "_{{.Sequence}}"
-	}
-	if !extPatternRegex.MatchString(filenamePattern) {
-		filenamePattern += ".{{.Ext}}"
-	}
-
-	tpl, err := template.New("filename").Parse(filenamePattern)
-	if err != nil {
-		return "", fmt.Errorf("filename pattern error: %w", err)
-	}
-
-	t := time.Unix(r.StreamAt, 0)
-	pattern := &Pattern{
-		Username:   r.Username,
-		Year:       t.Format("2006"),
-		Month:      t.Format("01"),
-		Day:        t.Format("02"),
-		Hour:       t.Format("15"),
-		Minute:     t.Format("04"),
-		Second:     t.Format("05"),
-		Sequence:   r.Sequence,
-		RoomRealId: r.RoomRealId,
-		Ext:        r.Ext,
-	}
-
-	if err = tpl.Execute(&buf, pattern); err != nil {
-		return "", fmt.Errorf("template execution error: %w", err)
-	}
-
-	return buf.String(), nil
-}
+// initialSequence 从当前序号开始查找文件不存在的序号，续接开播时沿用上一段的序号
+func (s *Sink) initialSequence() error {
+	for i := s.Sequence; i < s.Sequence+1000; i++ {
+		s.Sequence = i
 
-func (r *Recorder) InitialSequence() error {
-	for i := 0; i < 1000; i++ {
-		r.Sequence = i
-
-		filename, err := r.GenerateFileName()
+		filename, err := s.GenerateFileName()
 		if err != nil {
 			return err
 		}
 
 		_, err = os.Stat(filename)
 		if os.IsNotExist(err) {
-			// file not exist, so sequence is available
-			return nil
+			// 封装后的文件也不能存在，否则会被覆盖
+			if _, err = os.Stat(s.finalFilename(filename)); os.IsNotExist(err) {
+				// file not exist, so sequence is available
+				return nil
+			}
 		}
 	}
 
@@ -88,15 +43,15 @@ func (r *Recorder) InitialSequence() error {
 }
 
 // ShouldSwitchFile determine if the file should be switched
-func (r *Recorder) ShouldSwitchFile() bool {
-	maxFilesizeBytes := r.Config.Recorder.MaxFilesize * 1024 * 1024
-	maxDurationSeconds := r.Config.Recorder.MaxDuration * 60
+func (s *Sink) ShouldSwitchFile() bool {
+	maxFilesizeBytes := s.Config.Recorder.MaxFilesize * 1024 * 1024
+	maxDurationSeconds := s.Config.Recorder.MaxDuration * 60
 
-	return (r.Duration >= float64(maxDurationSeconds) && r.Config.Recorder.MaxDuration > 0) ||
-		(r.Filesize >= maxFilesizeBytes && r.Config.Recorder.MaxFilesize > 0)
+	return (s.Duration >= float64(maxDurationSeconds) && s.Config.Recorder.MaxDuration > 0) ||
+		(s.Filesize >= maxFilesizeBytes && s.Config.Recorder.MaxFilesize > 0)
 }
 
-func (r *Recorder) CreateNewFile(filename string) error {
+func (s *Sink) createFile(filename string) error {
 	// Ensure the directory exists before creating the File
 	if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
 		return fmt.Errorf("mkdir all: %w", err)
@@ -108,51 +63,58 @@ func (r *Recorder) CreateNewFile(filename string) error {
 		return fmt.Errorf("cannot open File: %s: %w", filename, err)
 	}
 
-	r.File = file
+	s.File = file
 	return nil
 }
 
-// NextFile prepares the next file to be created, by cleaning up the last file and generating a new one
-func (r *Recorder) NextFile() error {
-	if err := r.Cleanup(); err != nil {
+// nextFile prepares the next file to be created, by cleaning up the last file and generating a new one
+func (s *Sink) nextFile() error {
+	if err := s.cleanup(); err != nil {
 		return err
 	}
 
+	// 文件开始时间用于文件名模板
+	s.fileStart = time.Now()
+
 	// check the sequence if exist
-	if err := r.InitialSequence(); err != nil {
+	if err := s.initialSequence(); err != nil {
 		return fmt.Errorf("initial sequence: %w", err)
 	}
 
-	filename, err := r.GenerateFileName()
+	filename, err := s.GenerateFileName()
 	if err != nil {
 		return err
 	}
-	if err := r.CreateNewFile(filename); err != nil {
+	if err := s.createFile(filename); err != nil {
 		return err
 	}
+	s.mediaOffset = s.mediaTime
+	s.fileReported = false
+	s.fileSequence = s.Sequence
+	s.health.Store(NewHealth(s.line))
 
 	// Increment the sequence number for the next file
-	r.Sequence++
+	s.Sequence++
 	return nil
 }
 
-// Cleanup cleans the file and resets it, called when the stream errors out or before next file was created.
-func (r *Recorder) Cleanup() error {
-	if r.File == nil {
+// cleanup cleans the file and resets it, called when the stream errors out or before next file was created.
+func (s *Sink) cleanup() error {
+	if s.File == nil {
 		return nil
 	}
-	filename := r.File.Name()
+	filename := s.File.Name()
 
 	defer func() {
-		r.Filesize = 0
-		r.Duration = 0
+		s.Filesize = 0
+		s.Duration = 0
 	}()
 
 	// Sync the file to ensure data is written to disk
-	if err := r.File.Sync(); err != nil && !errors.Is(err, os.ErrClosed) {
+	if err := s.File.Sync(); err != nil && !errors.Is(err, os.ErrClosed) {
 		return fmt.Errorf("sync file: %w", err)
 	}
-	if err := r.File.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+	if err := s.File.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
 		return fmt.Errorf("close file: %w", err)
 	}
 
@@ -165,6 +127,21 @@ func (r *Recorder) Cleanup() error {
 		if err := os.Remove(filename); err != nil {
 			return fmt.Errorf("remove zero file: %w", err)
 		}
+		return nil
+	}
+
+	// 同一文件只处理一次
+	if fileInfo != nil && !s.fileReported {
+		s.fileReported = true
+		s.finishFile(&FileRecord{
+			Filename:  filename,
+			Sequence:  s.fileSequence,
+			StartTime: s.fileStart.UnixMilli(),
+			EndTime:   time.Now().UnixMilli(),
+			Filesize:  int(fileInfo.Size()),
+			Duration:  s.Duration,
+			Health:    s.Health().Snapshot(true),
+		})
 	}
 	return nil
 }
@@ -11,14 +11,14 @@ import (
 
 func TestGenerateFileName(t *testing.T) {
 
-	r := &Recorder{
+	s := &Sink{
 		Username: "test",
 		StreamAt: time.Now().Unix(),
 		Sequence: 1,
-		Config:   &config.AppConfig{},
+		Config:   &config.AppConfig{Recorder: &config.Recorder{}},
 	}
-	r.Config.Recorder.FilenamePattern = "{{.Username}}_{{.Year}}-{{.Month}}-{{.Day}}_{{.Hour}}-{{.Minute}}-{{.Second}}_{{.Sequence}}"
-	name, err := r.GenerateFileName()
+	s.Config.Recorder.FilenamePattern = "{{.Username}}_{{.Year}}-{{.Month}}-{{.Day}}_{{.Hour}}-{{.Minute}}-{{.Second}}_{{.Sequence}}"
+	name, err := s.GenerateFileName()
 	if err != nil {
 		log.Err(err)
 		return
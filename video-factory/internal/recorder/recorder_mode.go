@@ -0,0 +1,156 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+	"video-factory/internal/common/consts"
+)
+
+const (
+	userAgent = "Mozilla/5.0 (iPod; CPU iPhone OS 14_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/87.0.4280.163 Mobile/15E148 Safari/604.1"
+	// remuxTimeout 单个文件封装为 m4a 的超时时间
+	remuxTimeout = 10 * time.Minute
+)
+
+// outputExt 录制过程中写入的文件扩展名
+// 纯音频录制时先写 ADTS 流，可以在任意位置切分，m4a 在文件完成后再封装
+func outputExt(mode string) string {
+	if mode == consts.RecordModeAudio {
+		return consts.AudioFormatAAC
+	}
+	return "ts"
+}
+
+// userAgent 平台请求头中的 User-Agent，未设置时使用默认值
+func (r *Recorder) userAgent() string {
+	if ua := r.Header.Get("User-Agent"); ua != "" {
+		return ua
+	}
+	return userAgent
+}
+
+// extraHeaders User-Agent 以外的请求头，按 ffmpeg -headers 的格式拼接
+// 包含 Host 时 ffmpeg 使用该值作为请求的 Host
+// 命令行参数对本机其他用户可见，不传递 Cookie，拉流地址本身已带鉴权参数
+func (r *Recorder) extraHeaders() string {
+	keys := make([]string, 0, len(r.Header))
+	for key := range r.Header {
+		if key != "User-Agent" && key != "Cookie" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, key := range keys {
+		for _, value := range r.Header[key] {
+			sb.WriteString(key + ": " + value + "\r\n")
+		}
+	}
+	return sb.String()
+}
+
+// buildArgs 根据录制模式构造 ffmpeg 参数，输出到标准输出
+func (r *Recorder) buildArgs(inputURL string) []string {
+	args := []string{
+		"-y", "-hide_banner",
+		"-loglevel", "info", // 减少日志噪音
+		"-stats",
+
+		// --- 网络重连参数 (必须放在 -i 之前) ---
+		"-reconnect", "1", // 当底层 TCP 连接意外断开时，尝试重连
+		"-reconnect_at_eof", "1", // 在读取到流的结尾（EOF）时尝试重连，避免直播抖动
+		"-reconnect_streamed", "1", // 专门针对流媒体（Infinite Stream）启用重连
+		"-reconnect_delay_max", "5", // 重连尝试的最大等待时间5秒
+		// --- header 伪装 ---
+		"-user_agent", r.userAgent(),
+	}
+	if headers := r.extraHeaders(); headers != "" {
+		args = append(args, "-headers", headers)
+	}
+	// --- 输入 ---
+	args = append(args, "-i", inputURL)
+
+	if r.Mode == consts.RecordModeAudio {
+		// --- 输出：只保留音频，不转码，ADTS 封装 ---
+		return append(args,
+			"-vn", "-sn", "-dn",
+			"-c:a", "copy",
+			"-f", "adts",
+			"pipe:1",
+		)
+	}
+
+	// --- 输出 ---
+	return append(args,
+		"-c", "copy", // 直接复制流，不转码（CPU占用低）
+		"-f", "mpegts", // 强制封装格式为 TS
+		"pipe:1", // 输出到标准输出
+	)
+}
+
+// remuxToM4a 是否需要在文件完成后封装为 m4a
+func (s *Sink) remuxToM4a() bool {
+	return s.Mode == consts.RecordModeAudio &&
+		s.Config != nil && s.Config.Recorder != nil &&
+		s.Config.Recorder.AudioFormat == consts.AudioFormatM4A
+}
+
+// finalFilename 文件完成后的最终文件名
+func (s *Sink) finalFilename(filename string) string {
+	if s.remuxToM4a() {
+		return strings.TrimSuffix(filename, "."+s.Ext) + "." + consts.AudioFormatM4A
+	}
+	return filename
+}
+
+// finishFile 文件完成后的处理，需要封装时异步执行，完成后回调 OnFileClosed
+func (s *Sink) finishFile(record *FileRecord) {
+	if !s.remuxToM4a() {
+		if s.OnFileClosed != nil {
+			s.OnFileClosed(record)
+		}
+		return
+	}
+
+	go func() {
+		target := s.finalFilename(record.Filename)
+		if err := remux(record.Filename, target); err != nil {
+			s.Log.Err(err).Str("file", record.Filename).Msg("[Recorder] 封装 m4a 失败，保留原文件")
+		} else if info, err := os.Stat(target); err == nil {
+			if err := os.Remove(record.Filename); err != nil {
+				s.Log.Err(err).Str("file", record.Filename).Msg("[Recorder] 删除原文件失败")
+			}
+			record.Filename = target
+			record.Filesize = int(info.Size())
+			s.Log.Info().Str("file", target).Msg("[Recorder] 已封装为 m4a")
+		}
+		if s.OnFileClosed != nil {
+			s.OnFileClosed(record)
+		}
+	}()
+}
+
+// remux 无损封装 ADTS 为 m4a
+func remux(input string, output string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), remuxTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-y", "-hide_banner", "-loglevel", "error",
+		"-i", input,
+		"-c", "copy",
+		"-bsf:a", "aac_adtstoasc",
+		"-movflags", "+faststart",
+		output,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		_ = os.Remove(output)
+		return fmt.Errorf("ffmpeg remux: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
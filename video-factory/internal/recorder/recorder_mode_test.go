@@ -0,0 +1,164 @@
+package recorder
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"video-factory/internal/common/consts"
+	"video-factory/internal/domain/model"
+	"video-factory/pkg/config"
+)
+
+func TestNewRecorderMode(t *testing.T) {
+	cfg := &config.AppConfig{Recorder: &config.Recorder{}}
+	urls := map[string]string{"hls": "http://example.com/live.m3u8"}
+
+	sink := NewSink(cfg, &model.Room{RecordMode: consts.RecordModeAudio}, 0)
+	r, err := NewRecorder(cfg, "", urls, nil, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Mode != consts.RecordModeAudio || sink.Ext != "aac" {
+		t.Errorf("Mode = %s, Ext = %s", r.Mode, sink.Ext)
+	}
+
+	// 未设置录制模式的旧房间按音视频录制
+	sink = NewSink(cfg, &model.Room{}, 0)
+	r, err = NewRecorder(cfg, "", urls, nil, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Mode != consts.RecordModeVideo || sink.Ext != "ts" {
+		t.Errorf("Mode = %s, Ext = %s", r.Mode, sink.Ext)
+	}
+
+	// 请求头是平台共用的，更新 Cookie 不影响已创建的录制
+	header := http.Header{}
+	header.Set("Cookie", "a=1")
+	r, err = NewRecorder(cfg, "", urls, header, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header.Set("Cookie", "a=2")
+	if got := r.Header.Get("Cookie"); got != "a=1" {
+		t.Errorf("Cookie = %s", got)
+	}
+}
+
+func TestBuildArgs(t *testing.T) {
+	r := &Recorder{Mode: consts.RecordModeAudio}
+	args := r.buildArgs("http://example.com/live.flv")
+	if !slices.Contains(args, "-vn") || !slices.Contains(args, "adts") {
+		t.Errorf("audio args = %v", args)
+	}
+
+	r.Mode = consts.RecordModeVideo
+	args = r.buildArgs("http://example.com/live.flv")
+	if slices.Contains(args, "-vn") || !slices.Contains(args, "mpegts") || slices.Contains(args, "-headers") {
+		t.Errorf("video args = %v", args)
+	}
+
+	// 平台请求头，Host 用于改写 CDN 请求
+	r.Header = http.Header{}
+	r.Header.Set("User-Agent", "test-agent")
+	r.Header.Set("Referer", "https://fm.missevan.com/live/1")
+	r.Header.Set("Host", "cdn.example.com")
+	r.Header.Set("Cookie", "SESSDATA=secret")
+	args = r.buildArgs("http://127.0.0.1/live.flv")
+	if i := slices.Index(args, "-user_agent"); i < 0 || args[i+1] != "test-agent" {
+		t.Errorf("user agent args = %v", args)
+	}
+	i := slices.Index(args, "-headers")
+	if i < 0 || args[i+1] != "Host: cdn.example.com\r\nReferer: https://fm.missevan.com/live/1\r\n" || slices.Index(args, "-i") < i {
+		t.Errorf("header args = %v", args)
+	}
+}
+
+func TestInitialSequenceSkipRemuxed(t *testing.T) {
+	dir := t.TempDir()
+	s := &Sink{
+		Config: &config.AppConfig{Recorder: &config.Recorder{
+			OutputDir:       dir,
+			FilenamePattern: "{{.Username}}_{{.Sequence}}",
+			AudioFormat:     consts.AudioFormatM4A,
+		}},
+		Username: "test",
+		Mode:     consts.RecordModeAudio,
+		Ext:      outputExt(consts.RecordModeAudio),
+	}
+
+	// 上一次录制的 0 号文件已经封装为 m4a
+	if err := os.WriteFile(filepath.Join(dir, "test_0.m4a"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.initialSequence(); err != nil {
+		t.Fatal(err)
+	}
+	if s.Sequence != 1 {
+		t.Errorf("Sequence = %d, want 1", s.Sequence)
+	}
+}
+
+// 续接开播时从上一段的序号继续
+func TestInitialSequenceContinue(t *testing.T) {
+	s := &Sink{
+		Config: &config.AppConfig{Recorder: &config.Recorder{
+			OutputDir:       t.TempDir(),
+			FilenamePattern: "{{.Username}}_{{.Sequence}}",
+		}},
+		Username: "test",
+		Ext:      "ts",
+		Sequence: 3,
+	}
+	if err := s.initialSequence(); err != nil {
+		t.Fatal(err)
+	}
+	if s.Sequence != 3 {
+		t.Errorf("Sequence = %d, want 3", s.Sequence)
+	}
+}
+
+func TestDurationPerFile(t *testing.T) {
+	dir := t.TempDir()
+	sink := &Sink{
+		Config: &config.AppConfig{Recorder: &config.Recorder{
+			OutputDir:       dir,
+			FilenamePattern: "{{.Username}}_{{.Sequence}}",
+		}},
+		Username: "test",
+		Ext:      "ts",
+	}
+	r := &Recorder{Sink: sink}
+	if err := sink.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	feed := func(line string) {
+		r.HandleStderr(io.NopCloser(strings.NewReader(line + "\r")))
+	}
+
+	feed("size=1kB time=00:01:05.00 bitrate=1.0kbits/s speed=1x")
+	if sink.Duration != 65 {
+		t.Fatalf("Duration = %v, want 65", sink.Duration)
+	}
+
+	// 切换文件后，新文件的时长从 0 开始计算
+	if _, err := sink.Write([]byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	sink.mu.Lock()
+	err := sink.nextFile()
+	sink.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed("size=1kB time=00:01:15.00 bitrate=1.0kbits/s speed=1x")
+	if sink.Duration != 10 {
+		t.Errorf("Duration = %v, want 10", sink.Duration)
+	}
+}
@@ -3,6 +3,7 @@ package recorder
 import (
 	"context"
 	"net/http"
+	"os/exec"
 	"testing"
 	"time"
 	"video-factory/pkg/config"
@@ -25,18 +26,26 @@ import (
 // }
 
 func TestRecorder_Start(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("ffmpeg not found")
+	}
 	logger.InitLogger()
+	cfg := &config.AppConfig{
+		Recorder: &config.Recorder{
+			FilenamePattern: "{{.Username}}_{{.Year}}-{{.Month}}-{{.Day}}_{{.Hour}}-{{.Minute}}-{{.Second}}_{{.Sequence}}",
+			MaxDuration:     60,
+			MaxFilesize:     1024 * 1024 * 1024,
+		},
+	}
 	r := &Recorder{
-		Config: &config.AppConfig{
-			Recorder: &config.Recorder{
-				FilenamePattern: "{{.Username}}_{{.Year}}-{{.Month}}-{{.Day}}_{{.Hour}}-{{.Minute}}-{{.Second}}_{{.Sequence}}",
-				MaxDuration:     60,
-				MaxFilesize:     1024 * 1024 * 1024,
-			},
+		Config: cfg,
+		Sink: &Sink{
+			Config:   cfg,
+			Username: "testUsername",
+			StreamAt: time.Now().Unix(),
+			Ext:      "ts",
 		},
-		StreamURL: "http://d1-missevan104.bilivideo.com/live-bvc/586617/maoer_5362942_868802213.m3u8?cdn=missevan104&oi=2095728767&pt=web&expires=1766048193&qn=10000&len=0&trid=05fb5209b958cf6c96b00e7bb7be951d&sigparams=cdn,oi,pt,expires,qn,len,trid&sign=964f5f5ef291cf3ef9d0733a942ce6e7&sk=dd6689e451588085222b5317170891cad671f642910ae3a3ef2cc131fb53adaf",
-		Username:  "testUsername",
-		StreamAt:  time.Now().Unix(),
+		StreamURLs: []string{"http://d1-missevan104.bilivideo.com/live-bvc/586617/maoer_5362942_868802213.m3u8?cdn=missevan104&oi=2095728767&pt=web&expires=1766048193&qn=10000&len=0&trid=05fb5209b958cf6c96b00e7bb7be951d&sigparams=cdn,oi,pt,expires,qn,len,trid&sign=964f5f5ef291cf3ef9d0733a942ce6e7&sk=dd6689e451588085222b5317170891cad671f642910ae3a3ef2cc131fb53adaf"},
 	}
 
 	fetcher.GlobalClient = &http.Client{}
@@ -0,0 +1,177 @@
+package recorder
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+	"video-factory/internal/common/consts"
+	"video-factory/internal/domain/model"
+	"video-factory/pkg/config"
+	"video-factory/pkg/logger"
+
+	"github.com/rs/zerolog"
+)
+
+var errSinkClosed = errors.New("sink closed")
+
+// RoomInfo 生成文件名使用的房间标题与主播名
+type RoomInfo struct {
+	Title    string
+	Username string
+}
+
+// Sink 录制数据的写入端，按配置切分文件、生成文件名，文件完成后回调 OnFileClosed
+// 与拉流方式无关，各录制引擎把数据写入 Sink 即可
+type Sink struct {
+	Config     *config.AppConfig
+	Platform   string
+	Username   string
+	RoomTitle  string
+	RoomRealId string
+	Quality    int   // 实际清晰度
+	SessionID  int64 // 开播记录 ID
+	StreamAt   int64
+	Mode       string // 录制模式 video | audio
+	Ext        string
+	Sequence   int // 下一个文件的序号
+
+	// OnFileClosed 文件录制完成（非空）时回调
+	OnFileClosed func(record *FileRecord)
+	// Tee 录制数据同时写入，如片段缓冲，写入失败不影响录制
+	Tee io.Writer
+	// Log 房间日志，录制引擎与 ffmpeg 的输出都写入这里
+	Log zerolog.Logger
+	// RoomInfo 创建文件时获取最新的房间标题与主播名，为 nil 时使用 RoomTitle 与 Username
+	RoomInfo func() RoomInfo
+
+	File     *os.File
+	Filesize int
+	Duration float64 // 当前文件的时长
+
+	health       atomic.Pointer[Health] // 当前文件的直播流健康指标
+	line         string                 // 当前线路名，用于健康指标
+	fileStart    time.Time
+	fileSequence int     // 当前文件的序号
+	mediaTime    float64 // 引擎上报的进度，秒
+	mediaOffset  float64 // 当前文件开始时的进度，用于计算单个文件的时长
+	fileReported bool
+	mu           sync.Mutex
+}
+
+func NewSink(cfg *config.AppConfig, room *model.Room, openTime int64) *Sink {
+	mode := room.RecordMode
+	if mode != consts.RecordModeAudio {
+		mode = consts.RecordModeVideo
+	}
+	return &Sink{
+		Config:     cfg,
+		Platform:   room.Platform,
+		Username:   room.AnchorName,
+		RoomTitle:  room.Name,
+		RoomRealId: room.RealID,
+		StreamAt:   openTime,
+		Mode:       mode,
+		Ext:        outputExt(mode),
+		Log:        logger.Room(room.ID),
+	}
+}
+
+// Open 创建第一个文件
+func (s *Sink) Open() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nextFile()
+}
+
+// Write 写入当前文件，超过最大大小或时长时切换到新文件
+func (s *Sink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.File == nil {
+		return 0, errSinkClosed
+	}
+	s.Health().OnData(len(p), time.Now())
+
+	if _, err := s.File.Write(p); err != nil {
+		return 0, fmt.Errorf("[Recorder] write file error: %w", err)
+	}
+	if s.Tee != nil {
+		_, _ = s.Tee.Write(p)
+	}
+	s.Filesize += len(p)
+
+	if s.ShouldSwitchFile() {
+		if err := s.nextFile(); err != nil {
+			s.Log.Err(err).Str("file", s.File.Name()).Msg("[Recorder] 切换文件失败")
+		} else {
+			s.Log.Info().Msgf("max filesize or duration exceeded, new file created: %s", s.File.Name())
+		}
+	}
+	return len(p), nil
+}
+
+// Close 完成当前文件，之后的写入会失败
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.cleanup()
+	s.File = nil
+	return err
+}
+
+// ProcessStarted 引擎重新连接后调用，新连接的进度从 0 开始，接着当前文件已有的时长累计
+func (s *Sink) ProcessStarted() {
+	s.mu.Lock()
+	s.mediaOffset = -s.Duration
+	s.mediaTime = 0
+	s.mu.Unlock()
+	s.Health().OnProcessStart()
+}
+
+// Progress 上报当前连接的媒体进度（秒），用于计算文件时长
+func (s *Sink) Progress(mediaTime float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mediaTime = mediaTime
+	s.Duration = mediaTime - s.mediaOffset
+}
+
+// SetLine 记录当前线路，failover 表示因故障切换
+func (s *Sink) SetLine(line string, failover bool) {
+	s.mu.Lock()
+	s.line = line
+	s.mu.Unlock()
+	s.Health().OnSwitch(line, failover)
+}
+
+// Health 当前文件的健康指标，未开始录制时返回一个空的统计
+func (s *Sink) Health() *Health {
+	if h := s.health.Load(); h != nil {
+		return h
+	}
+	h := NewHealth("")
+	if s.health.CompareAndSwap(nil, h) {
+		return h
+	}
+	return s.health.Load()
+}
+
+// Stats 当前文件的状态，Running、URL 由引擎填写
+func (s *Sink) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := Stats{
+		Filesize: s.Filesize,
+		Duration: s.Duration,
+		Line:     s.line,
+		Health:   s.Health().Snapshot(false),
+	}
+	if s.File != nil {
+		stats.File = s.File.Name()
+	}
+	return stats
+}
@@ -0,0 +1,122 @@
+package recorder
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"video-factory/internal/domain/model"
+	"video-factory/pkg/config"
+)
+
+// fakeEngine 把固定的数据块写入 Sink，用于测试不依赖 ffmpeg 的录制流程
+type fakeEngine struct {
+	sink   *Sink
+	chunks [][]byte
+	urls   map[string]string
+	mu     sync.Mutex
+}
+
+func (f *fakeEngine) Start(ctx context.Context) error {
+	if err := f.sink.Open(); err != nil {
+		return err
+	}
+	defer f.sink.Close()
+	for _, chunk := range f.chunks {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if _, err := f.sink.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeEngine) Stop() {}
+
+func (f *fakeEngine) UpdateStreamURLs(streamURLMap map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.urls = streamURLMap
+}
+
+func (f *fakeEngine) Stats() Stats {
+	return f.sink.Stats()
+}
+
+func TestSinkRotate(t *testing.T) {
+	cfg := &config.AppConfig{Recorder: &config.Recorder{
+		OutputDir:       t.TempDir(),
+		FilenamePattern: "{{.Username}}_{{.Sequence}}",
+		MaxFilesize:     1,
+	}}
+	sink := NewSink(cfg, &model.Room{AnchorName: "test"}, 0)
+	var tee bytes.Buffer
+	sink.Tee = &tee
+	var records []*FileRecord
+	sink.OnFileClosed = func(record *FileRecord) {
+		records = append(records, record)
+	}
+
+	chunk := bytes.Repeat([]byte("x"), 256*1024)
+	engine := &fakeEngine{sink: sink, chunks: [][]byte{chunk, chunk, chunk, chunk, chunk, chunk}}
+	if err := engine.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 1MB 切分：第一个文件 4 块，第二个文件 2 块
+	if len(records) != 2 {
+		t.Fatalf("records = %d, want 2", len(records))
+	}
+	if records[0].Filesize != 4*len(chunk) || records[1].Filesize != 2*len(chunk) {
+		t.Errorf("filesize = %d, %d", records[0].Filesize, records[1].Filesize)
+	}
+	if records[0].Filename == records[1].Filename {
+		t.Error("rotated file should have a new name")
+	}
+	if records[0].Sequence != 0 || records[1].Sequence != 1 || sink.Sequence != 2 {
+		t.Errorf("sequence = %d, %d, next = %d", records[0].Sequence, records[1].Sequence, sink.Sequence)
+	}
+	if tee.Len() != 6*len(chunk) {
+		t.Errorf("tee = %d bytes", tee.Len())
+	}
+	if stats := engine.Stats(); stats.File != "" || stats.Filesize != 0 {
+		t.Errorf("stats after close = %+v", stats)
+	}
+	if _, err := sink.Write(chunk); err == nil {
+		t.Error("write after close should fail")
+	}
+}
+
+func TestNewEngine(t *testing.T) {
+	cfg := &config.AppConfig{Recorder: &config.Recorder{}}
+	urls := map[string]string{"hls": "http://example.com/live.m3u8"}
+	sink := NewSink(cfg, &model.Room{}, 0)
+
+	engine, err := NewEngine("", cfg, "", urls, nil, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := engine.(*Recorder); !ok {
+		t.Errorf("default engine = %T", engine)
+	}
+	if stats := engine.Stats(); stats.URL != urls["hls"] || stats.Line != "hls" || stats.Running {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	RegisterEngine("fake", func(cfg *config.AppConfig, platform string, streamURLMap map[string]string, header http.Header, sink *Sink) (Engine, error) {
+		return &fakeEngine{sink: sink, urls: streamURLMap}, nil
+	})
+	engine, err = NewEngine("fake", cfg, "", urls, nil, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := engine.(*fakeEngine); !ok {
+		t.Errorf("engine = %T", engine)
+	}
+	if _, err := NewEngine("missing", cfg, "", urls, nil, sink); err == nil {
+		t.Error("unknown engine should fail")
+	}
+}
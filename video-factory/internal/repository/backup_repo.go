@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type BackupRepository struct {
+	db *gorm.DB
+}
+
+func NewBackupRepository(db *gorm.DB) *BackupRepository {
+	return &BackupRepository{db: db}
+}
+
+// VacuumInto 在线备份到 target，SQLite 保证备份是一致的快照，备份期间不阻塞读
+func (b *BackupRepository) VacuumInto(target string) error {
+	if target == "" {
+		return errors.New("备份路径为空")
+	}
+	return b.db.Exec("VACUUM INTO ?", target).Error
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"errors"
+	"video-factory/internal/domain/model"
+
+	"gorm.io/gorm"
+)
+
+type ClipRepository struct {
+	db *gorm.DB
+}
+
+func NewClipRepository(db *gorm.DB) *ClipRepository {
+	return &ClipRepository{db: db}
+}
+
+func (r *ClipRepository) AddClip(clip *model.Clip) error {
+	if clip == nil {
+		return errors.New("clip 为空")
+	}
+	return r.db.Create(clip).Error
+}
+
+func (r *ClipRepository) GetClipById(id int64) (*model.Clip, error) {
+	var clip model.Clip
+	err := r.db.First(&clip, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &clip, nil
+}
+
+func (r *ClipRepository) UpdateClipById(id int64, updateMap map[string]any) error {
+	if id == 0 {
+		return errors.New("clip ID 不能为空")
+	}
+	return r.db.Model(&model.Clip{}).Where("id = ?", id).Updates(updateMap).Error
+}
+
+// ListClips 按创建时间倒序获取片段，roomId 为 0 时不过滤房间
+func (r *ClipRepository) ListClips(roomId int64, limit int) ([]model.Clip, error) {
+	var clips []model.Clip
+	query := r.db.Order("create_time desc")
+	if roomId != 0 {
+		query = query.Where("room_id = ?", roomId)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&clips).Error
+	return clips, err
+}
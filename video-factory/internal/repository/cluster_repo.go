@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"video-factory/internal/domain/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxWorkerID snowflake 节点号的上限
+const maxWorkerID = 1023
+
+type ClusterRepository struct {
+	db *gorm.DB
+}
+
+func NewClusterRepository(db *gorm.DB) *ClusterRepository {
+	return &ClusterRepository{db: db}
+}
+
+// RegisterNode 登记节点，同名节点沿用原来的 WorkerID，新节点分配最小的空闲 WorkerID
+func (c *ClusterRepository) RegisterNode(node *model.ClusterNode) error {
+	if node == nil || node.ID == "" {
+		return errors.New("节点名为空")
+	}
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		var nodes []model.ClusterNode
+		if err := tx.Find(&nodes).Error; err != nil {
+			return err
+		}
+		used := make(map[int64]bool, len(nodes))
+		node.WorkerID = 0
+		for _, n := range nodes {
+			used[n.WorkerID] = true
+			if n.ID == node.ID {
+				node.WorkerID = n.WorkerID
+			}
+		}
+		for id := int64(1); node.WorkerID == 0 && id <= maxWorkerID; id++ {
+			if !used[id] {
+				node.WorkerID = id
+			}
+		}
+		if node.WorkerID == 0 {
+			return fmt.Errorf("集群节点数超过上限 %d", maxWorkerID)
+		}
+		return tx.Save(node).Error
+	})
+}
+
+// Heartbeat 更新节点的心跳时间与地址
+func (c *ClusterRepository) Heartbeat(nodeId string, addr string, now int64) error {
+	result := c.db.Model(&model.ClusterNode{}).Where("id = ?", nodeId).
+		Updates(map[string]any{"addr": addr, "heartbeat_time": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("节点[%s]未登记", nodeId)
+	}
+	return nil
+}
+
+func (c *ClusterRepository) ListNodes() ([]model.ClusterNode, error) {
+	var nodes []model.ClusterNode
+	err := c.db.Order("id").Find(&nodes).Error
+	return nodes, err
+}
+
+func (c *ClusterRepository) ListLeases() ([]model.RoomLease, error) {
+	var leases []model.RoomLease
+	err := c.db.Find(&leases).Error
+	return leases, err
+}
+
+// AcquireLease 获取房间的租约，租约不存在、已过期或已由本节点持有时成功
+func (c *ClusterRepository) AcquireLease(roomId int64, nodeId string, now int64, expire int64) (bool, error) {
+	lease := &model.RoomLease{RoomID: roomId, NodeID: nodeId, ExpireTime: expire}
+	result := c.db.Clauses(clause.OnConflict{DoNothing: true}).Create(lease)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+	// 条件更新保证同一时刻只有一个节点能接管
+	result = c.db.Model(&model.RoomLease{}).
+		Where("room_id = ? AND (node_id = ? OR expire_time < ?)", roomId, nodeId, now).
+		Updates(map[string]any{"node_id": nodeId, "expire_time": expire})
+	return result.RowsAffected > 0, result.Error
+}
+
+// RenewLeases 续期节点持有的所有租约
+func (c *ClusterRepository) RenewLeases(nodeId string, expire int64) error {
+	return c.db.Model(&model.RoomLease{}).Where("node_id = ?", nodeId).
+		Update("expire_time", expire).Error
+}
+
+// ReleaseLeases 释放节点持有的指定房间的租约，roomIds 为空时释放全部
+func (c *ClusterRepository) ReleaseLeases(nodeId string, roomIds []int64) error {
+	query := c.db.Where("node_id = ?", nodeId)
+	if len(roomIds) > 0 {
+		query = query.Where("room_id IN ?", roomIds)
+	}
+	return query.Delete(&model.RoomLease{}).Error
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"errors"
+	"video-factory/internal/domain/model"
+
+	"gorm.io/gorm"
+)
+
+type ConfigHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewConfigHistoryRepository(db *gorm.DB) *ConfigHistoryRepository {
+	return &ConfigHistoryRepository{db: db}
+}
+
+func (c *ConfigHistoryRepository) AddHistory(history *model.ConfigHistory) error {
+	if history == nil {
+		return errors.New("history 为空")
+	}
+	return c.db.Create(history).Error
+}
+
+// ListHistories 按时间倒序获取变更历史，key 为空时返回全部
+func (c *ConfigHistoryRepository) ListHistories(key string, limit int) ([]model.ConfigHistory, error) {
+	var histories []model.ConfigHistory
+	query := c.db.Order("create_time desc, id desc")
+	if key != "" {
+		query = query.Where("key = ?", key)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&histories).Error
+	return histories, err
+}
+
+func (c *ConfigHistoryRepository) GetHistoryById(id int64) (*model.ConfigHistory, error) {
+	var history model.ConfigHistory
+	err := c.db.First(&history, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &history, nil
+}
@@ -65,6 +65,18 @@ func (c *ConfigRepository) UpdateConfig(config *model.Config) error {
 	return nil
 }
 
+// UpdateConfigValue 仅更新 value，允许更新为空字符串
+func (c *ConfigRepository) UpdateConfigValue(id int64, value string) error {
+	result := c.db.Model(&model.Config{}).Where("id = ?", id).Update("value", value)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("更新失败，未找到记录")
+	}
+	return nil
+}
+
 // GetConfigByKey 根据key获取配置，没获取到返回空切片非nil
 func (c *ConfigRepository) GetConfigByKey(key string) (*model.Config, error) {
 	// 声明一个结构体值，而不是指针
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"errors"
+	"video-factory/internal/domain/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LineScoreRepository struct {
+	db *gorm.DB
+}
+
+func NewLineScoreRepository(db *gorm.DB) *LineScoreRepository {
+	return &LineScoreRepository{db: db}
+}
+
+func (l *LineScoreRepository) ListScores() ([]model.LineScore, error) {
+	var scores []model.LineScore
+	err := l.db.Find(&scores).Error
+	return scores, err
+}
+
+// UpsertScore 按平台 + 域名插入或更新评分
+func (l *LineScoreRepository) UpsertScore(score *model.LineScore) error {
+	if score == nil {
+		return errors.New("score 为空")
+	}
+	return l.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "platform"}, {Name: "host"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"ttfb", "throughput", "successes", "failures", "last_probe", "last_failure", "update_time",
+		}),
+	}).Create(score).Error
+}
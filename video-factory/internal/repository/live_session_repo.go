@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"errors"
+	"video-factory/internal/domain/model"
+
+	"gorm.io/gorm"
+)
+
+type LiveSessionRepository struct {
+	db *gorm.DB
+}
+
+func NewLiveSessionRepository(db *gorm.DB) *LiveSessionRepository {
+	return &LiveSessionRepository{db: db}
+}
+
+func (l *LiveSessionRepository) AddSession(session *model.LiveSession) error {
+	if session == nil {
+		return errors.New("session 为空")
+	}
+	return l.db.Create(session).Error
+}
+
+func (l *LiveSessionRepository) UpdateSessionById(id int64, updateMap map[string]any) error {
+	if id == 0 {
+		return errors.New("session ID 不能为空")
+	}
+	return l.db.Model(&model.LiveSession{}).Where("id = ?", id).Updates(updateMap).Error
+}
+
+func (l *LiveSessionRepository) GetSessionById(id int64) (*model.LiveSession, error) {
+	var session model.LiveSession
+	err := l.db.First(&session, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListRecentSessions 按开始时间倒序获取房间最近的开播记录
+func (l *LiveSessionRepository) ListRecentSessions(roomId int64, limit int) ([]model.LiveSession, error) {
+	var sessions []model.LiveSession
+	query := l.db.Where("room_id = ?", roomId).Order("start_time desc")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&sessions).Error
+	return sessions, err
+}
+
+// ResumeSession 续接开播记录：清空结束时间并记录断流区间
+func (l *LiveSessionRepository) ResumeSession(gap *model.LiveSessionGap) error {
+	if gap == nil {
+		return errors.New("gap 为空")
+	}
+	return l.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(gap).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.LiveSession{}).Where("id = ?", gap.SessionID).Updates(map[string]any{
+			"end_time": 0,
+			"gaps":     gorm.Expr("gaps + 1"),
+		}).Error
+	})
+}
+
+// ListGaps 按时间顺序获取一次开播的断流区间
+func (l *LiveSessionRepository) ListGaps(sessionId int64) ([]model.LiveSessionGap, error) {
+	var gaps []model.LiveSessionGap
+	err := l.db.Where("session_id = ?", sessionId).Order("start_time asc").Find(&gaps).Error
+	return gaps, err
+}
+
+func (l *LiveSessionRepository) AddTransition(transition *model.SessionTransition) error {
+	if transition == nil {
+		return errors.New("transition 为空")
+	}
+	return l.db.Create(transition).Error
+}
+
+// ListTransitions 按时间顺序获取一次开播的状态变更记录
+func (l *LiveSessionRepository) ListTransitions(sessionId int64) ([]model.SessionTransition, error) {
+	var transitions []model.SessionTransition
+	err := l.db.Where("session_id = ?", sessionId).Order("time asc, id asc").Find(&transitions).Error
+	return transitions, err
+}
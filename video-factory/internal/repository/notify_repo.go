@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"errors"
+	"video-factory/internal/domain/model"
+
+	"gorm.io/gorm"
+)
+
+type NotifyChannelRepository struct {
+	db *gorm.DB
+}
+
+func NewNotifyChannelRepository(db *gorm.DB) *NotifyChannelRepository {
+	return &NotifyChannelRepository{db: db}
+}
+
+func (r *NotifyChannelRepository) AddChannel(channel *model.NotifyChannel) error {
+	if channel == nil {
+		return errors.New("channel 为空")
+	}
+	return r.db.Create(channel).Error
+}
+
+func (r *NotifyChannelRepository) GetChannelById(id int64) (*model.NotifyChannel, error) {
+	var channel model.NotifyChannel
+	err := r.db.First(&channel, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func (r *NotifyChannelRepository) UpdateChannelById(id int64, updateMap map[string]any) error {
+	if id == 0 {
+		return errors.New("channel ID 不能为空")
+	}
+	return r.db.Model(&model.NotifyChannel{}).Where("id = ?", id).Updates(updateMap).Error
+}
+
+// RemoveChannel 删除渠道及其订阅
+func (r *NotifyChannelRepository) RemoveChannel(id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("channel_id = ?", id).Delete(&model.NotifySubscription{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.NotifyChannel{}, id).Error
+	})
+}
+
+func (r *NotifyChannelRepository) ListChannels() ([]model.NotifyChannel, error) {
+	var channels []model.NotifyChannel
+	err := r.db.Order("create_time").Find(&channels).Error
+	return channels, err
+}
+
+type NotifySubscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewNotifySubscriptionRepository(db *gorm.DB) *NotifySubscriptionRepository {
+	return &NotifySubscriptionRepository{db: db}
+}
+
+// SaveSubscription 有主键就更新，无主键就插入
+func (r *NotifySubscriptionRepository) SaveSubscription(sub *model.NotifySubscription) error {
+	if sub == nil {
+		return errors.New("subscription 为空")
+	}
+	return r.db.Save(sub).Error
+}
+
+func (r *NotifySubscriptionRepository) GetSubscription(channelId int64, roomId int64) (*model.NotifySubscription, error) {
+	var sub model.NotifySubscription
+	err := r.db.Where("channel_id = ? AND room_id = ?", channelId, roomId).First(&sub).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (r *NotifySubscriptionRepository) RemoveSubscription(id int64) error {
+	return r.db.Delete(&model.NotifySubscription{}, id).Error
+}
+
+// ListSubscriptions 获取订阅列表，channelId 为 0 时不过滤
+func (r *NotifySubscriptionRepository) ListSubscriptions(channelId int64) ([]model.NotifySubscription, error) {
+	var subs []model.NotifySubscription
+	query := r.db.Order("channel_id, room_id")
+	if channelId != 0 {
+		query = query.Where("channel_id = ?", channelId)
+	}
+	err := query.Find(&subs).Error
+	return subs, err
+}
+
+// ListMatched 订阅了某个房间某个事件的记录，包含订阅所有房间的记录，roomId 为 0 时只匹配全局订阅
+func (r *NotifySubscriptionRepository) ListMatched(roomId int64, event string) ([]model.NotifySubscription, error) {
+	var subs []model.NotifySubscription
+	err := r.db.Where("room_id IN ? AND events LIKE ?", []int64{0, roomId}, "%,"+event+",%").Find(&subs).Error
+	return subs, err
+}
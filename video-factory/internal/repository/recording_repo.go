@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+	"video-factory/internal/domain/model"
+
+	"gorm.io/gorm"
+)
+
+type RecordingRepository struct {
+	db *gorm.DB
+}
+
+func NewRecordingRepository(db *gorm.DB) *RecordingRepository {
+	return &RecordingRepository{db: db}
+}
+
+func (r *RecordingRepository) AddRecording(recording *model.Recording) error {
+	if recording == nil {
+		return errors.New("recording 为空")
+	}
+	return r.db.Create(recording).Error
+}
+
+func (r *RecordingRepository) GetRecordingById(id int64) (*model.Recording, error) {
+	var recording model.Recording
+	err := r.db.First(&recording, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &recording, nil
+}
+
+// GetRecordingByFilename 按文件路径获取录制记录，路径可能以相对或绝对形式保存，传入所有可能的写法，不存在时返回 nil
+func (r *RecordingRepository) GetRecordingByFilename(filenames []string) (*model.Recording, error) {
+	var recordings []model.Recording
+	err := r.db.Omit("samples").Where("filename IN ?", filenames).Order("start_time desc").Limit(1).Find(&recordings).Error
+	if err != nil || len(recordings) == 0 {
+		return nil, err
+	}
+	return &recordings[0], nil
+}
+
+func (r *RecordingRepository) UpdateRecordingById(id int64, updateMap map[string]any) error {
+	if id == 0 {
+		return errors.New("recording ID 不能为空")
+	}
+	return r.db.Model(&model.Recording{}).Where("id = ?", id).Updates(updateMap).Error
+}
+
+// ListRecordings 按开始时间倒序获取录制记录，roomId 为 0 时不过滤房间，不返回采样序列
+func (r *RecordingRepository) ListRecordings(roomId int64, limit int) ([]model.Recording, error) {
+	var recordings []model.Recording
+	query := r.db.Omit("samples").Order("start_time desc")
+	if roomId != 0 {
+		query = query.Where("room_id = ?", roomId)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&recordings).Error
+	return recordings, err
+}
+
+// ListRecordingsByTime 获取开始时间在 [from, to) 内的录制记录，时间为毫秒，roomId 为 0 时不过滤房间
+func (r *RecordingRepository) ListRecordingsByTime(roomId int64, from int64, to int64, limit int) ([]model.Recording, error) {
+	var recordings []model.Recording
+	query := r.db.Omit("samples").Where("start_time >= ? AND start_time < ?", from, to).Order("start_time desc")
+	if roomId != 0 {
+		query = query.Where("room_id = ?", roomId)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&recordings).Error
+	return recordings, err
+}
@@ -3,13 +3,39 @@ package repository
 import "gorm.io/gorm"
 
 type Repository struct {
-	Room   *RoomRepository
-	Config *ConfigRepository
+	Room          *RoomRepository
+	Config        *ConfigRepository
+	ConfigHistory *ConfigHistoryRepository
+	LiveSession   *LiveSessionRepository
+	Recording     *RecordingRepository
+	LineScore     *LineScoreRepository
+	Clip          *ClipRepository
+	Storage       *StorageRepository
+	UploadRule    *UploadRuleRepository
+	Upload        *UploadRepository
+	RoomTitle     *RoomTitleRepository
+	NotifyChannel *NotifyChannelRepository
+	NotifySub     *NotifySubscriptionRepository
+	Backup        *BackupRepository
+	Cluster       *ClusterRepository
 }
 
 func NewRepository(db *gorm.DB) *Repository {
 	return &Repository{
-		Room:   NewRoomRepository(db),
-		Config: NewConfigRepository(db),
+		Room:          NewRoomRepository(db),
+		Config:        NewConfigRepository(db),
+		ConfigHistory: NewConfigHistoryRepository(db),
+		LiveSession:   NewLiveSessionRepository(db),
+		Recording:     NewRecordingRepository(db),
+		LineScore:     NewLineScoreRepository(db),
+		Clip:          NewClipRepository(db),
+		Storage:       NewStorageRepository(db),
+		UploadRule:    NewUploadRuleRepository(db),
+		Upload:        NewUploadRepository(db),
+		RoomTitle:     NewRoomTitleRepository(db),
+		NotifyChannel: NewNotifyChannelRepository(db),
+		NotifySub:     NewNotifySubscriptionRepository(db),
+		Backup:        NewBackupRepository(db),
+		Cluster:       NewClusterRepository(db),
 	}
 }
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"errors"
+	"strings"
 	"video-factory/internal/domain/model"
 
 	"gorm.io/gorm"
@@ -72,12 +73,80 @@ func (r *RoomRepository) UpdateRoomById(id int64, updateMap map[string]any) erro
 	return nil
 }
 
+// CountImageRefs 引用某个缓存图片的房间数，封面与头像都计入
+func (r *RoomRepository) CountImageRefs(name string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Room{}).Where("cover_cache = ? OR avatar_cache = ?", name, name).Count(&count).Error
+	return count, err
+}
+
 func (r *RoomRepository) ListRooms() ([]model.Room, error) {
 	var rooms []model.Room
 	err := r.db.Find(&rooms).Error
 	return rooms, err
 }
 
+// RoomFilter 房间查询条件，字段为空时不过滤
+type RoomFilter struct {
+	IDs          []int64
+	Keyword      string // 匹配名称、主播名、房间号
+	Platform     string
+	Status       *int
+	RecordStatus *int
+	Group        string
+	Tag          string
+}
+
+func (f *RoomFilter) apply(query *gorm.DB) *gorm.DB {
+	if len(f.IDs) > 0 {
+		query = query.Where("id in ?", f.IDs)
+	}
+	if f.Keyword != "" {
+		like := "%" + escapeLike(f.Keyword) + "%"
+		query = query.Where(`name like ? escape '\' or anchor_name like ? escape '\' or short_id = ? or real_id = ?`,
+			like, like, f.Keyword, f.Keyword)
+	}
+	if f.Platform != "" {
+		query = query.Where("platform = ?", f.Platform)
+	}
+	if f.Status != nil {
+		query = query.Where("status = ?", *f.Status)
+	}
+	if f.RecordStatus != nil {
+		query = query.Where("record_status = ?", *f.RecordStatus)
+	}
+	if f.Group != "" {
+		query = query.Where("group_name = ?", f.Group)
+	}
+	if f.Tag != "" {
+		// 标签以 ",a,b," 形式保存，按完整的标签精确匹配
+		query = query.Where("instr(tags, ?) > 0", ","+f.Tag+",")
+	}
+	return query
+}
+
+// likeEscaper 转义 like 模式中的通配符，配合 escape '\' 使用
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// QueryRooms 按条件分页查询房间，按创建时间排序，pageSize 为 0 时返回全部
+func (r *RoomRepository) QueryRooms(filter RoomFilter, page int, pageSize int) ([]model.Room, int64, error) {
+	var rooms []model.Room
+	var total int64
+	if err := filter.apply(r.db.Model(&model.Room{})).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	query := filter.apply(r.db.Order("create_time"))
+	if pageSize > 0 {
+		query = query.Offset((max(page, 1) - 1) * pageSize).Limit(pageSize)
+	}
+	err := query.Find(&rooms).Error
+	return rooms, total, err
+}
+
 func (r *RoomRepository) GetRoomById(id int64) (*model.Room, error) {
 	var room model.Room
 	err := r.db.First(&room, id).Error
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"errors"
+	"video-factory/internal/domain/model"
+
+	"gorm.io/gorm"
+)
+
+type RoomTitleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoomTitleRepository(db *gorm.DB) *RoomTitleRepository {
+	return &RoomTitleRepository{db: db}
+}
+
+func (r *RoomTitleRepository) AddTitle(title *model.RoomTitle) error {
+	if title == nil {
+		return errors.New("title 为空")
+	}
+	return r.db.Create(title).Error
+}
+
+// ListTitles 按时间倒序获取房间的标题变更记录，sessionId 为 0 时不过滤
+func (r *RoomTitleRepository) ListTitles(roomId int64, sessionId int64, limit int) ([]model.RoomTitle, error) {
+	var titles []model.RoomTitle
+	query := r.db.Where("room_id = ?", roomId).Order("create_time desc")
+	if sessionId != 0 {
+		query = query.Where("session_id = ?", sessionId)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&titles).Error
+	return titles, err
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"errors"
+	"video-factory/internal/domain/model"
+
+	"gorm.io/gorm"
+)
+
+type StorageRepository struct {
+	db *gorm.DB
+}
+
+func NewStorageRepository(db *gorm.DB) *StorageRepository {
+	return &StorageRepository{db: db}
+}
+
+func (r *StorageRepository) AddStorage(storage *model.Storage) error {
+	if storage == nil {
+		return errors.New("storage 为空")
+	}
+	return r.db.Create(storage).Error
+}
+
+func (r *StorageRepository) GetStorageById(id int64) (*model.Storage, error) {
+	var storage model.Storage
+	err := r.db.First(&storage, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &storage, nil
+}
+
+func (r *StorageRepository) UpdateStorageById(id int64, updateMap map[string]any) error {
+	if id == 0 {
+		return errors.New("storage ID 不能为空")
+	}
+	return r.db.Model(&model.Storage{}).Where("id = ?", id).Updates(updateMap).Error
+}
+
+func (r *StorageRepository) RemoveStorage(id int64) error {
+	return r.db.Delete(&model.Storage{}, id).Error
+}
+
+func (r *StorageRepository) ListStorages() ([]model.Storage, error) {
+	var storages []model.Storage
+	err := r.db.Order("create_time").Find(&storages).Error
+	return storages, err
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"errors"
+	"video-factory/internal/domain/model"
+
+	"gorm.io/gorm"
+)
+
+type UploadRepository struct {
+	db *gorm.DB
+}
+
+func NewUploadRepository(db *gorm.DB) *UploadRepository {
+	return &UploadRepository{db: db}
+}
+
+func (r *UploadRepository) AddUpload(upload *model.Upload) error {
+	if upload == nil {
+		return errors.New("upload 为空")
+	}
+	return r.db.Create(upload).Error
+}
+
+func (r *UploadRepository) GetUploadById(id int64) (*model.Upload, error) {
+	var upload model.Upload
+	err := r.db.First(&upload, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &upload, nil
+}
+
+func (r *UploadRepository) UpdateUploadById(id int64, updateMap map[string]any) error {
+	if id == 0 {
+		return errors.New("upload ID 不能为空")
+	}
+	return r.db.Model(&model.Upload{}).Where("id = ?", id).Updates(updateMap).Error
+}
+
+// ListUploads 按创建时间倒序获取上传任务，roomId 为 0、status 为空时不过滤
+func (r *UploadRepository) ListUploads(roomId int64, status string, limit int) ([]model.Upload, error) {
+	var uploads []model.Upload
+	query := r.db.Order("create_time desc")
+	if roomId != 0 {
+		query = query.Where("room_id = ?", roomId)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&uploads).Error
+	return uploads, err
+}
+
+// ListUnfinished 未完成的上传任务，按创建时间正序，用于启动后恢复
+func (r *UploadRepository) ListUnfinished() ([]model.Upload, error) {
+	var uploads []model.Upload
+	err := r.db.Where("status in ?", []string{model.UploadStatusPending, model.UploadStatusUploading}).
+		Order("create_time").Find(&uploads).Error
+	return uploads, err
+}
+
+// ExistsActive 录制文件是否已有未失败的上传任务
+func (r *UploadRepository) ExistsActive(recordingId int64, storageId int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Upload{}).
+		Where("recording_id = ? and storage_id = ? and status <> ?", recordingId, storageId, model.UploadStatusFailed).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ExistsUnfinishedByPath 除 excludeId 外是否还有同一本地文件的未完成上传任务
+func (r *UploadRepository) ExistsUnfinishedByPath(localPath string, excludeId int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Upload{}).
+		Where("local_path = ? and id <> ? and status in ?", localPath, excludeId,
+			[]string{model.UploadStatusPending, model.UploadStatusUploading}).
+		Count(&count).Error
+	return count > 0, err
+}
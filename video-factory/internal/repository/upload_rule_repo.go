@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"errors"
+	"video-factory/internal/domain/model"
+
+	"gorm.io/gorm"
+)
+
+type UploadRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewUploadRuleRepository(db *gorm.DB) *UploadRuleRepository {
+	return &UploadRuleRepository{db: db}
+}
+
+// SaveUploadRule 有主键就更新，无主键就插入
+func (r *UploadRuleRepository) SaveUploadRule(rule *model.UploadRule) error {
+	if rule == nil {
+		return errors.New("rule 为空")
+	}
+	return r.db.Save(rule).Error
+}
+
+// GetUploadRuleByRoomId 获取房间的上传规则，roomId 为 0 时为默认规则
+func (r *UploadRuleRepository) GetUploadRuleByRoomId(roomId int64) (*model.UploadRule, error) {
+	var rule model.UploadRule
+	err := r.db.Where("room_id = ?", roomId).First(&rule).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *UploadRuleRepository) RemoveUploadRule(id int64) error {
+	return r.db.Delete(&model.UploadRule{}, id).Error
+}
+
+// CountByStorageId 引用某个存储的规则数
+func (r *UploadRuleRepository) CountByStorageId(storageId int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.UploadRule{}).Where("storage_id = ?", storageId).Count(&count).Error
+	return count, err
+}
+
+func (r *UploadRuleRepository) ListUploadRules() ([]model.UploadRule, error) {
+	var rules []model.UploadRule
+	err := r.db.Order("room_id").Find(&rules).Error
+	return rules, err
+}
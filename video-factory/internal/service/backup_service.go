@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"video-factory/internal/db"
+	"video-factory/internal/domain/vo"
+	"video-factory/internal/repository"
+	"video-factory/pkg/config"
+	"video-factory/pkg/util"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// backupPrefix 备份文件名前缀，文件名为 前缀+时间+.db
+	backupPrefix = "video-factory-"
+	// backupTimeFormat 备份文件名中的时间格式，按名称排序即按时间排序
+	backupTimeFormat = "20060102-150405"
+)
+
+// BackupService 定期与手动备份数据库，并清理超出数量的旧备份
+type BackupService struct {
+	config     *config.AppConfig
+	backupRepo *repository.BackupRepository
+	// leader 是否由本节点执行定期备份，集群中的节点共用数据库，只需一个节点备份
+	leader func() bool
+	mu     sync.Mutex
+}
+
+func NewBackupService(config *config.AppConfig, backupRepo *repository.BackupRepository) *BackupService {
+	return &BackupService{
+		config:     config,
+		backupRepo: backupRepo,
+	}
+}
+
+// Run 按配置的间隔定期备份，每分钟检查一次以便配置修改后生效，距最近一次备份超过间隔时备份
+func (s *BackupService) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			cfg := s.config.Backup
+			if cfg == nil || !cfg.Enabled || cfg.Interval <= 0 {
+				continue
+			}
+			if s.leader != nil && !s.leader() {
+				continue
+			}
+			if last, ok := s.lastBackupTime(); ok && now.Sub(last) < time.Duration(cfg.Interval)*time.Hour {
+				continue
+			}
+			if _, err := s.Backup(); err != nil {
+				log.Err(err).Msg("[Backup] 定期备份数据库失败")
+			}
+		}
+	}
+}
+
+// SetLeaderCheck 设置是否由本节点执行定期备份，需在 Run 之前设置，手动备份不受影响
+func (s *BackupService) SetLeaderCheck(leader func() bool) {
+	s.leader = leader
+}
+
+// Backup 立即备份数据库，完成后清理超出数量的旧备份
+func (s *BackupService) Backup() (*vo.BackupVO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir := s.dir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建备份目录失败: %w", err)
+	}
+	name := backupPrefix + time.Now().Format(backupTimeFormat) + ".db"
+	target := filepath.Join(dir, name)
+	if _, err := os.Stat(target); err == nil {
+		return nil, errors.New("一秒内只能备份一次")
+	}
+	start := time.Now()
+	if err := s.backupRepo.VacuumInto(target); err != nil {
+		os.Remove(target)
+		return nil, fmt.Errorf("备份数据库失败: %w", err)
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Str("file", target).Int64("size", info.Size()).Dur("cost", time.Since(start)).Msg("[Backup] 数据库备份完成")
+
+	s.prune()
+	backup := toBackupVO(info)
+	return &backup, nil
+}
+
+// ListBackups 按时间倒序列出备份文件
+func (s *BackupService) ListBackups() ([]vo.BackupVO, error) {
+	entries, err := os.ReadDir(s.dir())
+	if errors.Is(err, os.ErrNotExist) {
+		return []vo.BackupVO{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	backups := make([]vo.BackupVO, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !isBackupName(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, toBackupVO(info))
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Name > backups[j].Name })
+	return backups, nil
+}
+
+// BackupPath 备份文件的完整路径，只接受备份目录下的文件名
+func (s *BackupService) BackupPath(name string) (string, bool) {
+	if !isBackupName(name) || filepath.Base(name) != name {
+		return "", false
+	}
+	path := filepath.Join(s.dir(), name)
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// dir 备份目录，未配置时放在数据库文件旁边，不依赖程序的运行目录
+func (s *BackupService) dir() string {
+	if s.config.Backup != nil && s.config.Backup.Dir != "" {
+		return s.config.Backup.Dir
+	}
+	return filepath.Join(filepath.Dir(db.FilePath), "backup")
+}
+
+// lastBackupTime 最近一次备份的时间，重启后不会立即重复备份
+func (s *BackupService) lastBackupTime() (time.Time, bool) {
+	backups, err := s.ListBackups()
+	if err != nil || len(backups) == 0 {
+		return time.Time{}, false
+	}
+	return backups[0].CreateTime, true
+}
+
+// prune 删除超出保留数量的旧备份
+func (s *BackupService) prune() {
+	if s.config.Backup == nil || s.config.Backup.Keep <= 0 {
+		return
+	}
+	backups, err := s.ListBackups()
+	if err != nil {
+		log.Err(err).Msg("[Backup] 读取备份目录失败")
+		return
+	}
+	for _, backup := range backups[min(s.config.Backup.Keep, len(backups)):] {
+		if err := os.Remove(filepath.Join(s.dir(), backup.Name)); err != nil {
+			log.Err(err).Str("name", backup.Name).Msg("[Backup] 删除旧备份失败")
+			continue
+		}
+		log.Info().Str("name", backup.Name).Msg("[Backup] 已删除旧备份")
+	}
+}
+
+func isBackupName(name string) bool {
+	stamp, ok := strings.CutPrefix(strings.TrimSuffix(name, ".db"), backupPrefix)
+	if !ok || !strings.HasSuffix(name, ".db") {
+		return false
+	}
+	_, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+	return err == nil
+}
+
+func toBackupVO(info os.FileInfo) vo.BackupVO {
+	stamp := strings.TrimPrefix(strings.TrimSuffix(info.Name(), ".db"), backupPrefix)
+	createTime, _ := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+	return vo.BackupVO{
+		Name:        info.Name(),
+		Filesize:    info.Size(),
+		FilesizeStr: util.FormatFilesize(int(info.Size())),
+		CreateTime:  createTime,
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"video-factory/internal/db"
+	"video-factory/internal/domain/model"
+	"video-factory/internal/repository"
+	"video-factory/pkg/config"
+)
+
+func TestBackup(t *testing.T) {
+	dir := t.TempDir()
+	db := newTestDB(t, &model.Config{})
+	backupDir := filepath.Join(dir, "backup")
+	cfg := &config.AppConfig{Backup: &config.Backup{Enabled: true, Interval: 24, Keep: 2, Dir: backupDir}}
+	s := NewBackupService(cfg, repository.NewBackupRepository(db))
+
+	// 两个旧备份和一个无关文件
+	os.MkdirAll(backupDir, 0755)
+	for _, at := range []time.Time{time.Now().Add(-48 * time.Hour), time.Now().Add(-24 * time.Hour)} {
+		os.WriteFile(filepath.Join(backupDir, backupPrefix+at.Format(backupTimeFormat)+".db"), []byte("old"), 0644)
+	}
+	os.WriteFile(filepath.Join(backupDir, "notes.db"), nil, 0644)
+
+	backup, err := s.Backup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backup.Filesize == 0 || time.Since(backup.CreateTime) > time.Minute {
+		t.Fatalf("backup = %+v", backup)
+	}
+
+	// 超出保留数量的最旧备份被删除，无关文件保留
+	list, err := s.ListBackups()
+	if err != nil || len(list) != 2 || list[0].Name != backup.Name {
+		t.Fatalf("list = %+v, err = %v", list, err)
+	}
+	if _, err := os.Stat(filepath.Join(backupDir, "notes.db")); err != nil {
+		t.Fatal(err)
+	}
+	if last, ok := s.lastBackupTime(); !ok || !last.Equal(backup.CreateTime) {
+		t.Fatalf("last = %v", last)
+	}
+
+	if _, ok := s.BackupPath(backup.Name); !ok {
+		t.Fatal("备份文件应当存在")
+	}
+	if _, ok := s.BackupPath("../" + backup.Name); ok {
+		t.Fatal("不允许访问备份目录之外的文件")
+	}
+}
+
+func TestBackupDefaultDir(t *testing.T) {
+	oldPath := db.FilePath
+	db.FilePath = filepath.Join(t.TempDir(), "data", "video-factory.db")
+	t.Cleanup(func() { db.FilePath = oldPath })
+
+	// 未配置备份目录时使用数据库文件旁边的目录，与运行目录无关
+	s := NewBackupService(&config.AppConfig{Backup: &config.Backup{}}, nil)
+	if got, want := s.dir(), filepath.Join(filepath.Dir(db.FilePath), "backup"); got != want {
+		t.Errorf("dir() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"video-factory/internal/clip"
+	"video-factory/internal/domain/model"
+	"video-factory/internal/domain/vo"
+	"video-factory/internal/recorder"
+	"video-factory/internal/repository"
+	"video-factory/pkg/config"
+	"video-factory/pkg/pool"
+	"video-factory/pkg/util"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxClipExports 同时从录制文件导出片段的任务数
+const maxClipExports = 2
+
+type ClipService struct {
+	pool       *pool.ManagerPool
+	config     *config.AppConfig
+	clipRepo   *repository.ClipRepository
+	recordRepo *repository.RecordingRepository
+	exportSem  chan struct{}
+}
+
+func NewClipService(pool *pool.ManagerPool, config *config.AppConfig, clipRepo *repository.ClipRepository,
+	recordRepo *repository.RecordingRepository) *ClipService {
+	return &ClipService{
+		pool:       pool,
+		config:     config,
+		clipRepo:   clipRepo,
+		recordRepo: recordRepo,
+		exportSem:  make(chan struct{}, maxClipExports),
+	}
+}
+
+// ClipFromLive 保存直播中房间最近 seconds 秒的内容
+func (c *ClipService) ClipFromLive(roomIdStr string, seconds int) (*vo.ClipVO, error) {
+	roomId, err := strconv.ParseInt(roomIdStr, 10, 64)
+	if err != nil {
+		return nil, errors.New("房间 id 格式有误")
+	}
+	if c.config.Clip == nil || c.config.Clip.BufferSeconds <= 0 {
+		return nil, errors.New("未开启直播片段缓冲")
+	}
+	if seconds <= 0 || seconds > c.config.Clip.BufferSeconds {
+		return nil, fmt.Errorf("时长需在 1~%d 秒之间", c.config.Clip.BufferSeconds)
+	}
+	mgr, ok := c.pool.Get(roomId)
+	if !ok || mgr.ClipBuffer == nil {
+		return nil, errors.New("房间未在直播或未在录制")
+	}
+
+	data, span, err := mgr.ClipBuffer.Last(time.Duration(seconds) * time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	record := &model.Clip{
+		ID:        util.MustNextID(),
+		RoomID:    roomId,
+		SessionID: mgr.SessionID,
+		Source:    model.ClipSourceLive,
+		Duration:  span.Seconds(),
+		Status:    model.ClipStatusPending,
+	}
+	record.Filename = c.clipFilename(mgr.RoomInfo().Username, now, record.ID, mgr.ClipBuffer.Format())
+	if err := c.clipRepo.AddClip(record); err != nil {
+		return nil, err
+	}
+
+	// 数据已在内存中，直接写入
+	if err := clip.WriteFile(record.Filename, data); err != nil {
+		log.Err(err).Int64("roomId", roomId).Msg("[Clip] 写入片段失败")
+		c.finish(record, err)
+		return toClipVO(record), err
+	}
+	c.finish(record, nil)
+	log.Info().Int64("roomId", roomId).Str("file", record.Filename).Int("seconds", seconds).Msg("[Clip] 已保存直播片段")
+	return toClipVO(record), nil
+}
+
+// ClipFromRecording 从录制文件截取 [start, end] 的内容，异步执行
+func (c *ClipService) ClipFromRecording(recordingIdStr string, startStr string, endStr string) (*vo.ClipVO, error) {
+	recordingId, err := strconv.ParseInt(recordingIdStr, 10, 64)
+	if err != nil {
+		return nil, errors.New("录制记录 id 格式有误")
+	}
+	start, err := clip.ParseTimecode(startStr)
+	if err != nil {
+		return nil, err
+	}
+	end, err := clip.ParseTimecode(endStr)
+	if err != nil {
+		return nil, err
+	}
+	if end <= start {
+		return nil, errors.New("结束时间需晚于开始时间")
+	}
+
+	recording, err := c.recordRepo.GetRecordingById(recordingId)
+	if err != nil {
+		return nil, err
+	}
+	if recording == nil {
+		return nil, errors.New("录制记录不存在")
+	}
+	if recording.Duration > 0 && start >= recording.Duration {
+		return nil, fmt.Errorf("开始时间超出文件时长 %s", util.FormatDuration(recording.Duration))
+	}
+	if recording.Duration > 0 && end > recording.Duration {
+		end = recording.Duration
+	}
+
+	ext := strings.TrimPrefix(filepath.Ext(recording.Filename), ".")
+	record := &model.Clip{
+		ID:          util.MustNextID(),
+		RoomID:      recording.RoomID,
+		SessionID:   recording.SessionID,
+		RecordingID: recording.ID,
+		Source:      model.ClipSourceRecording,
+		Start:       start,
+		Duration:    end - start,
+		Status:      model.ClipStatusPending,
+	}
+	name := strings.TrimSuffix(filepath.Base(recording.Filename), filepath.Ext(recording.Filename))
+	record.Filename = c.clipFilename(name, time.Now(), record.ID, ext)
+	if err := c.clipRepo.AddClip(record); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		c.exportSem <- struct{}{}
+		defer func() { <-c.exportSem }()
+
+		err := clip.ExtractFromFile(context.Background(), recording.Filename, record.Start, record.Duration, record.Filename)
+		if err != nil {
+			log.Err(err).Int64("recordingId", recording.ID).Msg("[Clip] 从录制文件截取片段失败")
+		} else {
+			log.Info().Str("file", record.Filename).Msg("[Clip] 已从录制文件截取片段")
+		}
+		c.finish(record, err)
+	}()
+	return toClipVO(record), nil
+}
+
+// ListClips 获取片段列表
+func (c *ClipService) ListClips(roomId int64, limit int) ([]vo.ClipVO, error) {
+	clips, err := c.clipRepo.ListClips(roomId, limit)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]vo.ClipVO, 0, len(clips))
+	for i := range clips {
+		list = append(list, *toClipVO(&clips[i]))
+	}
+	return list, nil
+}
+
+// GetClip 获取片段详情
+func (c *ClipService) GetClip(id int64) (*vo.ClipVO, error) {
+	record, err := c.clipRepo.GetClipById(id)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, errors.New("片段不存在")
+	}
+	return toClipVO(record), nil
+}
+
+// GetClipFile 获取已导出片段的文件路径
+func (c *ClipService) GetClipFile(id int64) (string, error) {
+	record, err := c.clipRepo.GetClipById(id)
+	if err != nil {
+		return "", err
+	}
+	if record == nil || record.Status != model.ClipStatusDone {
+		return "", errors.New("片段不存在或未导出完成")
+	}
+	return record.Filename, nil
+}
+
+// finish 更新导出结果
+func (c *ClipService) finish(record *model.Clip, exportErr error) {
+	updateMap := map[string]any{}
+	if exportErr != nil {
+		record.Status = model.ClipStatusFailed
+		record.Error = exportErr.Error()
+		updateMap["error"] = record.Error
+	} else {
+		record.Status = model.ClipStatusDone
+		if info, err := os.Stat(record.Filename); err == nil {
+			record.Filesize = info.Size()
+			updateMap["filesize"] = record.Filesize
+		}
+	}
+	updateMap["status"] = record.Status
+	if err := c.clipRepo.UpdateClipById(record.ID, updateMap); err != nil {
+		log.Err(err).Int64("id", record.ID).Msg("[Clip] 更新片段状态失败")
+	}
+}
+
+// clipFilename 片段文件名：输出目录/名称_时间_ID.扩展名
+func (c *ClipService) clipFilename(name string, at time.Time, id int64, ext string) string {
+	dir := "clips"
+	if c.config.Clip != nil && c.config.Clip.OutputDir != "" {
+		dir = c.config.Clip.OutputDir
+	}
+	base := fmt.Sprintf("%s_%s_%d.%s", recorder.SanitizeName(name), at.Format("20060102_150405"), id, ext)
+	return filepath.Join(dir, base)
+}
+
+func toClipVO(record *model.Clip) *vo.ClipVO {
+	clipVo := &vo.ClipVO{
+		ID:          record.ID,
+		RoomID:      record.RoomID,
+		SessionID:   record.SessionID,
+		RecordingID: record.RecordingID,
+		Source:      record.Source,
+		Start:       record.Start,
+		StartStr:    util.FormatDuration(record.Start),
+		Duration:    record.Duration,
+		DurationStr: util.FormatDuration(record.Duration),
+		Filename:    record.Filename,
+		Filesize:    record.Filesize,
+		FilesizeStr: util.FormatFilesize(int(record.Filesize)),
+		Status:      record.Status,
+		Error:       record.Error,
+		CreateTime:  util.MillisToTime(record.CreateTime),
+	}
+	if record.Status == model.ClipStatusDone {
+		clipVo.FileURL = fmt.Sprintf("/api/v1/clip/%d/file", record.ID)
+	}
+	return clipVo
+}
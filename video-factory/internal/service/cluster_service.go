@@ -0,0 +1,473 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"video-factory/internal/domain/model"
+	"video-factory/internal/domain/vo"
+	"video-factory/internal/repository"
+	"video-factory/pkg/client"
+	"video-factory/pkg/config"
+	"video-factory/pkg/pool"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultHeartbeat = 5 * time.Second
+	defaultLeaseTTL  = 30 * time.Second
+	// remoteTimeout 请求其他节点的超时时间
+	remoteTimeout = 3 * time.Second
+)
+
+// ClusterService 集群模式下多个节点共享数据库，通过房间租约分配监控与录制任务
+//
+// 节点定期心跳并续期持有的租约。没有有效租约的房间由存活节点中 rendezvous 哈希得分最高的节点获取，
+// 节点下线后其租约过期，房间按同样的规则由其余节点接管。
+// 有运行中 Manager 的房间不会因为新节点加入而迁移，避免中断录制
+type ClusterService struct {
+	pool        *pool.ManagerPool
+	config      *config.AppConfig
+	clusterRepo *repository.ClusterRepository
+	roomRepo    *repository.RoomRepository
+
+	// 本节点，nil 表示未开启集群模式
+	node *model.ClusterNode
+	now  func() time.Time
+
+	mu        sync.RWMutex
+	owned     map[int64]bool            // 本节点持有租约的房间
+	leases    map[int64]model.RoomLease // 最近一次同步的所有租约
+	alive     []model.ClusterNode       // 最近一次同步的存活节点
+	renewedAt time.Time                 // 最近一次成功续约的时间
+}
+
+func NewClusterService(pool *pool.ManagerPool, config *config.AppConfig,
+	clusterRepo *repository.ClusterRepository, roomRepo *repository.RoomRepository,
+) *ClusterService {
+	return &ClusterService{
+		pool:        pool,
+		config:      config,
+		clusterRepo: clusterRepo,
+		roomRepo:    roomRepo,
+		now:         time.Now,
+		owned:       make(map[int64]bool),
+		leases:      make(map[int64]model.RoomLease),
+	}
+}
+
+// Join 以 name 登记本节点并开启集群模式，返回的节点包含分配到的 WorkerID
+func (s *ClusterService) Join(name string, addr string) (*model.ClusterNode, error) {
+	now := s.now()
+	nodes, err := s.clusterRepo.ListNodes()
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		if node.ID == name && node.Addr != addr && s.isAlive(node, now) {
+			return nil, fmt.Errorf("节点名[%s]正在被 %s 使用", name, node.Addr)
+		}
+	}
+	node := &model.ClusterNode{
+		ID:            name,
+		Addr:          addr,
+		StartTime:     now.UnixMilli(),
+		HeartbeatTime: now.UnixMilli(),
+	}
+	if err := s.clusterRepo.RegisterNode(node); err != nil {
+		return nil, err
+	}
+	s.node = node
+	log.Info().Str("node", node.ID).Str("addr", node.Addr).Int64("workerId", node.WorkerID).Msg("[Cluster] 节点已加入集群")
+	return node, nil
+}
+
+// Enabled 是否以集群模式运行
+func (s *ClusterService) Enabled() bool {
+	return s.node != nil
+}
+
+// Run 定期心跳、续约并分配房间，退出时释放本节点的租约以便其他节点立即接管
+func (s *ClusterService) Run(ctx context.Context) {
+	if !s.Enabled() {
+		return
+	}
+	s.sync()
+	timer := time.NewTimer(s.heartbeat())
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.clusterRepo.ReleaseLeases(s.node.ID, nil); err != nil {
+				log.Err(err).Msg("[Cluster] 释放租约失败")
+			}
+			return
+		case <-timer.C:
+			s.sync()
+			// 每次读取配置，修改心跳间隔后立即生效
+			timer.Reset(s.heartbeat())
+		}
+	}
+}
+
+// sync 心跳并续约，获取应由本节点负责且没有有效租约的房间，释放不再需要的租约
+func (s *ClusterService) sync() {
+	now := s.now()
+	ttl := s.leaseTTL()
+	expire := now.Add(ttl).UnixMilli()
+	if err := s.clusterRepo.Heartbeat(s.node.ID, s.node.Addr, now.UnixMilli()); err != nil {
+		log.Err(err).Msg("[Cluster] 更新心跳失败")
+		s.expireIfStale(now, ttl)
+		return
+	}
+	if err := s.clusterRepo.RenewLeases(s.node.ID, expire); err != nil {
+		log.Err(err).Msg("[Cluster] 续约失败")
+		s.expireIfStale(now, ttl)
+		return
+	}
+	s.mu.Lock()
+	s.renewedAt = now
+	s.mu.Unlock()
+
+	nodes, err := s.clusterRepo.ListNodes()
+	if err != nil {
+		log.Err(err).Msg("[Cluster] 获取节点列表失败")
+		return
+	}
+	leaseList, err := s.clusterRepo.ListLeases()
+	if err != nil {
+		log.Err(err).Msg("[Cluster] 获取租约失败")
+		return
+	}
+	rooms, err := s.roomRepo.GetEnabledRooms()
+	if err != nil {
+		log.Err(err).Msg("[Cluster] 获取启用房间失败")
+		return
+	}
+
+	alive := make([]model.ClusterNode, 0, len(nodes))
+	for _, node := range nodes {
+		if s.isAlive(node, now) {
+			alive = append(alive, node)
+		}
+	}
+	leases := make(map[int64]model.RoomLease, len(leaseList))
+	for _, lease := range leaseList {
+		leases[lease.RoomID] = lease
+	}
+
+	owned := make(map[int64]bool)
+	enabled := make(map[int64]bool, len(rooms))
+	var released []int64
+	for _, room := range rooms {
+		enabled[room.ID] = true
+		lease, leased := leases[room.ID]
+		preferred := pickNode(room.ID, alive)
+		if leased && lease.NodeID == s.node.ID {
+			// 空闲的房间交还给哈希选出的节点，新节点加入后逐步均衡
+			if _, running := s.pool.Get(room.ID); !running && preferred != s.node.ID {
+				released = append(released, room.ID)
+				continue
+			}
+			owned[room.ID] = true
+			continue
+		}
+		if (leased && lease.ExpireTime >= now.UnixMilli()) || preferred != s.node.ID {
+			continue
+		}
+		acquired, err := s.clusterRepo.AcquireLease(room.ID, s.node.ID, now.UnixMilli(), expire)
+		if err != nil {
+			log.Err(err).Int64("roomId", room.ID).Msg("[Cluster] 获取租约失败")
+			continue
+		}
+		if !acquired {
+			continue
+		}
+		owned[room.ID] = true
+		if leased {
+			log.Info().Int64("roomId", room.ID).Str("from", lease.NodeID).Msg("[Cluster] 租约过期，接管房间")
+		}
+		leases[room.ID] = model.RoomLease{RoomID: room.ID, NodeID: s.node.ID, ExpireTime: expire}
+	}
+	// 已停用或删除的房间
+	for roomId, lease := range leases {
+		if lease.NodeID == s.node.ID && !enabled[roomId] {
+			released = append(released, roomId)
+		}
+	}
+	if len(released) > 0 {
+		if err := s.clusterRepo.ReleaseLeases(s.node.ID, released); err != nil {
+			log.Err(err).Msg("[Cluster] 释放租约失败")
+		}
+		for _, roomId := range released {
+			delete(leases, roomId)
+		}
+	}
+
+	s.update(owned, leases, alive)
+}
+
+// expireIfStale 超过租约有效期未能续约时，其他节点可能已经接管，停止本节点的所有房间
+func (s *ClusterService) expireIfStale(now time.Time, ttl time.Duration) {
+	s.mu.RLock()
+	stale := len(s.owned) > 0 && now.Sub(s.renewedAt) > ttl
+	s.mu.RUnlock()
+	if !stale {
+		return
+	}
+	log.Error().Msg("[Cluster] 超过租约有效期未能续约，停止本节点负责的所有房间")
+	s.update(map[int64]bool{}, map[int64]model.RoomLease{}, nil)
+}
+
+// update 保存同步结果，停止已失去租约的房间的 Manager
+func (s *ClusterService) update(owned map[int64]bool, leases map[int64]model.RoomLease, alive []model.ClusterNode) {
+	s.mu.Lock()
+	var lost []int64
+	for roomId := range s.owned {
+		if !owned[roomId] {
+			lost = append(lost, roomId)
+		}
+	}
+	s.owned = owned
+	s.leases = leases
+	s.alive = alive
+	s.mu.Unlock()
+
+	for _, roomId := range lost {
+		if mgr, ok := s.pool.Get(roomId); ok {
+			log.Warn().Int64("roomId", roomId).Msg("[Cluster] 已失去房间租约，停止 Manager")
+			mgr.StopAutoRefresh()
+		}
+	}
+}
+
+// CheckOwner 房间是否由本节点负责，未开启集群模式时总是由本节点负责
+func (s *ClusterService) CheckOwner(roomId int64) error {
+	if !s.Enabled() {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.owned[roomId] {
+		return nil
+	}
+	if lease, ok := s.leases[roomId]; ok {
+		return fmt.Errorf("房间由节点[%s]负责", lease.NodeID)
+	}
+	return errors.New("房间暂未分配到节点，请稍后重试")
+}
+
+// OwnerAddr 房间由其他存活节点负责时返回该节点的地址
+func (s *ClusterService) OwnerAddr(roomId int64) (string, bool) {
+	if !s.Enabled() {
+		return "", false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	lease, ok := s.leases[roomId]
+	if !ok || lease.NodeID == s.node.ID {
+		return "", false
+	}
+	for _, node := range s.alive {
+		if node.ID == lease.NodeID && node.Addr != "" {
+			return node.Addr, true
+		}
+	}
+	return "", false
+}
+
+// IsLeader 本节点是否为存活节点中 ID 最小的节点，只需一个节点执行的定时任务（如备份）由其执行
+// 未开启集群模式时总是 true，尚未同步或续约失败时为 false
+func (s *ClusterService) IsLeader() bool {
+	if !s.Enabled() {
+		return true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.alive) > 0 && s.alive[0].ID == s.node.ID
+}
+
+// IsPeer 请求是否来自其他存活节点，按节点地址中的主机解析出的 IP 判断
+func (s *ClusterService) IsPeer(remoteIP string) bool {
+	if !s.Enabled() || remoteIP == "" {
+		return false
+	}
+	s.mu.RLock()
+	alive := s.alive
+	s.mu.RUnlock()
+	for _, node := range alive {
+		if node.ID == s.node.ID {
+			continue
+		}
+		u, err := url.Parse(node.Addr)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		addrs, err := net.LookupHost(u.Hostname())
+		if err == nil && slices.Contains(addrs, remoteIP) {
+			return true
+		}
+	}
+	return false
+}
+
+// NodeID 本节点名，未开启集群模式时为空
+func (s *ClusterService) NodeID() string {
+	if !s.Enabled() {
+		return ""
+	}
+	return s.node.ID
+}
+
+// ListNodes 所有登记过的节点及其持有的有效租约数
+func (s *ClusterService) ListNodes() ([]vo.ClusterNodeVO, error) {
+	if !s.Enabled() {
+		return []vo.ClusterNodeVO{}, nil
+	}
+	nodes, err := s.clusterRepo.ListNodes()
+	if err != nil {
+		return nil, err
+	}
+	leases, err := s.clusterRepo.ListLeases()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	rooms := make(map[string]int)
+	for _, lease := range leases {
+		if lease.ExpireTime >= now.UnixMilli() {
+			rooms[lease.NodeID]++
+		}
+	}
+	list := make([]vo.ClusterNodeVO, len(nodes))
+	for i, node := range nodes {
+		list[i] = vo.ClusterNodeVO{
+			ID:            node.ID,
+			WorkerID:      node.WorkerID,
+			Addr:          node.Addr,
+			StartTime:     time.UnixMilli(node.StartTime),
+			HeartbeatTime: time.UnixMilli(node.HeartbeatTime),
+			Alive:         s.isAlive(node, now),
+			Self:          node.ID == s.node.ID,
+			Rooms:         rooms[node.ID],
+		}
+	}
+	return list, nil
+}
+
+// MergeManagers 用负责节点返回的状态替换本地列表中其他节点的房间，并标注负责的节点
+// 获取失败的节点保留本地的基本信息
+func (s *ClusterService) MergeManagers(ctx context.Context, local []vo.ManagerVO) []vo.ManagerVO {
+	if !s.Enabled() {
+		return local
+	}
+	s.mu.RLock()
+	leases := s.leases
+	alive := s.alive
+	s.mu.RUnlock()
+
+	var (
+		mu     sync.Mutex
+		remote = make(map[int64]vo.ManagerVO)
+		wg     sync.WaitGroup
+	)
+	for _, node := range alive {
+		if node.ID == s.node.ID || node.Addr == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(node model.ClusterNode) {
+			defer wg.Done()
+			list, err := fetchManagers(ctx, node.Addr)
+			if err != nil {
+				log.Warn().Err(err).Str("node", node.ID).Msg("[Cluster] 获取节点的 Manager 列表失败")
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, item := range list {
+				if lease, ok := leases[item.RoomID]; ok && lease.NodeID == node.ID {
+					remote[item.RoomID] = item
+				}
+			}
+		}(node)
+	}
+	wg.Wait()
+
+	merged := make([]vo.ManagerVO, len(local))
+	for i, item := range local {
+		if r, ok := remote[item.RoomID]; ok {
+			item = r
+		}
+		item.NodeID = leases[item.RoomID].NodeID
+		merged[i] = item
+	}
+	return merged
+}
+
+// fetchManagers 获取节点本地的 Manager 列表
+func fetchManagers(ctx context.Context, addr string) ([]vo.ManagerVO, error) {
+	ctx, cancel := context.WithTimeout(ctx, remoteTimeout)
+	defer cancel()
+	page, err := client.New(addr).ListManagers(ctx, &client.ListManagersParams{Local: true})
+	if err != nil {
+		return nil, err
+	}
+	// 客户端类型由接口文档生成，与 vo.ManagerVO 的 JSON 结构一致
+	data, err := json.Marshal(page.List)
+	if err != nil {
+		return nil, err
+	}
+	var list []vo.ManagerVO
+	err = json.Unmarshal(data, &list)
+	return list, err
+}
+
+func (s *ClusterService) isAlive(node model.ClusterNode, now time.Time) bool {
+	return now.Sub(time.UnixMilli(node.HeartbeatTime)) <= s.leaseTTL()
+}
+
+func (s *ClusterService) heartbeat() time.Duration {
+	if s.config.Cluster == nil || s.config.Cluster.Heartbeat <= 0 {
+		return defaultHeartbeat
+	}
+	return time.Duration(s.config.Cluster.Heartbeat) * time.Second
+}
+
+// leaseTTL 租约有效期，至少为两个心跳间隔，避免一次心跳延迟就被接管
+func (s *ClusterService) leaseTTL() time.Duration {
+	ttl := defaultLeaseTTL
+	if s.config.Cluster != nil && s.config.Cluster.LeaseTTL > 0 {
+		ttl = time.Duration(s.config.Cluster.LeaseTTL) * time.Second
+	}
+	return max(ttl, 2*s.heartbeat())
+}
+
+// pickNode rendezvous 哈希，房间分配给得分最高的节点，节点增减时只有少量房间需要迁移
+func pickNode(roomId int64, nodes []model.ClusterNode) string {
+	var (
+		best      string
+		bestScore uint64
+	)
+	key := strconv.FormatInt(roomId, 10)
+	for _, node := range nodes {
+		h := fnv.New64a()
+		h.Write([]byte(node.ID))
+		h.Write([]byte{0})
+		h.Write([]byte(key))
+		score := h.Sum64()
+		if best == "" || score > bestScore || (score == bestScore && node.ID < best) {
+			best, bestScore = node.ID, score
+		}
+	}
+	return best
+}
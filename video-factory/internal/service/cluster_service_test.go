@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"video-factory/internal/domain/model"
+	"video-factory/internal/domain/vo"
+	"video-factory/internal/repository"
+	"video-factory/pkg/config"
+	"video-factory/pkg/pool"
+)
+
+func TestClusterTakeover(t *testing.T) {
+	db := newTestDB(t, &model.Room{}, &model.ClusterNode{}, &model.RoomLease{})
+	for i := int64(1); i <= 20; i++ {
+		mustCreate(t, db, &model.Room{ID: i, Name: fmt.Sprint(i), Status: 1})
+	}
+	mustCreate(t, db, &model.Room{ID: 100, Name: "disabled", Status: 0})
+
+	// 节点 b 的地址指向模拟的接口，返回其负责房间的状态
+	var localQuery string
+	var bRoom int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		localQuery = r.URL.Query().Get("local")
+		fmt.Fprintf(w, `{"code":0,"message":"ok","data":{"list":[{"roomId":%d,"state":"live","sessionId":"7"}],"total":1}}`, bRoom)
+	}))
+	defer srv.Close()
+
+	clock := time.Now()
+	cfg := &config.AppConfig{Cluster: &config.Cluster{Heartbeat: 5, LeaseTTL: 30}}
+	clusterRepo := repository.NewClusterRepository(db)
+	roomRepo := repository.NewRoomRepository(db)
+	newNode := func(name string, addr string) *ClusterService {
+		s := NewClusterService(pool.NewManagerPool(cfg), cfg, clusterRepo, roomRepo)
+		s.now = func() time.Time { return clock }
+		node, err := s.Join(name, addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if node.WorkerID == 0 {
+			t.Fatalf("node = %+v", node)
+		}
+		return s
+	}
+	a := newNode("a", "http://a")
+	b := newNode("b", srv.URL)
+	if a.node.WorkerID == b.node.WorkerID {
+		t.Fatal("各节点的 WorkerID 需要不同")
+	}
+	if _, err := NewClusterService(pool.NewManagerPool(cfg), cfg, clusterRepo, roomRepo).Join("a", "http://other"); err == nil {
+		t.Fatal("节点名正在使用时不能加入")
+	}
+
+	a.sync()
+	b.sync()
+	a.sync()
+	owned := map[string]int{}
+	for i := int64(1); i <= 20; i++ {
+		errA, errB := a.CheckOwner(i), b.CheckOwner(i)
+		if (errA == nil) == (errB == nil) {
+			t.Fatalf("房间 %d 需要且只能由一个节点负责: a=%v b=%v", i, errA, errB)
+		}
+		if errA == nil {
+			owned["a"]++
+		} else {
+			owned["b"]++
+			bRoom = i
+		}
+	}
+	if owned["a"] == 0 || owned["b"] == 0 {
+		t.Fatalf("房间没有分配到两个节点: %v", owned)
+	}
+	if a.CheckOwner(100) == nil || b.CheckOwner(100) == nil {
+		t.Fatal("停用的房间不分配")
+	}
+	if addr, ok := a.OwnerAddr(bRoom); !ok || addr != srv.URL {
+		t.Fatalf("addr = %s", addr)
+	}
+	// 只有一个节点执行定期备份等任务
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatal("a 的 ID 最小，应由 a 执行")
+	}
+	// 节点 b 的地址为 127.0.0.1，只认可来自该地址的转发请求
+	if !a.IsPeer("127.0.0.1") || a.IsPeer("10.0.0.1") || b.IsPeer("127.0.0.1") {
+		t.Fatal("IsPeer 判断有误")
+	}
+
+	// 汇总时使用负责节点返回的状态
+	merged := a.MergeManagers(context.Background(), []vo.ManagerVO{{RoomID: bRoom}, {RoomID: 100}})
+	if localQuery != "true" || merged[0].State != "live" || merged[0].SessionID != 7 || merged[0].NodeID != "b" {
+		t.Fatalf("merged = %+v, local = %s", merged, localQuery)
+	}
+	if merged[1].NodeID != "" {
+		t.Fatalf("merged = %+v", merged[1])
+	}
+
+	// 节点 b 停止心跳，租约过期后由 a 接管
+	clock = clock.Add(31 * time.Second)
+	a.sync()
+	for i := int64(1); i <= 20; i++ {
+		if err := a.CheckOwner(i); err != nil {
+			t.Fatalf("房间 %d 未被接管: %v", i, err)
+		}
+	}
+	nodes, err := a.ListNodes()
+	if err != nil || len(nodes) != 2 {
+		t.Fatalf("nodes = %+v, err = %v", nodes, err)
+	}
+	for _, node := range nodes {
+		if node.ID == "a" && (!node.Alive || !node.Self || node.Rooms != 20) {
+			t.Fatalf("a = %+v", node)
+		}
+		if node.ID == "b" && (node.Alive || node.Rooms != 0) {
+			t.Fatalf("b = %+v", node)
+		}
+	}
+}
+
+func TestPickNode(t *testing.T) {
+	nodes := []model.ClusterNode{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	before := make(map[int64]string)
+	for i := int64(1); i <= 300; i++ {
+		before[i] = pickNode(i, nodes)
+	}
+	// 移除节点 c 后只有原来分配给 c 的房间改变
+	for i := int64(1); i <= 300; i++ {
+		after := pickNode(i, nodes[:2])
+		if before[i] != "c" && after != before[i] {
+			t.Fatalf("房间 %d 从 %s 迁移到 %s", i, before[i], after)
+		}
+	}
+	if pickNode(1, nil) != "" {
+		t.Fatal("没有节点时不分配")
+	}
+}
@@ -5,36 +5,45 @@ import (
 	"fmt"
 	"video-factory/internal/domain/model"
 	"video-factory/internal/domain/vo"
+	"video-factory/internal/recorder"
 	"video-factory/internal/repository"
 	"video-factory/pkg/config"
 	"video-factory/pkg/pool"
 	"video-factory/pkg/util"
 
+	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
 )
 
 type ConfigService struct {
-	pool       *pool.ManagerPool
-	config     *config.AppConfig
-	configRepo *repository.ConfigRepository
+	pool        *pool.ManagerPool
+	config      *config.AppConfig
+	configRepo  *repository.ConfigRepository
+	historyRepo *repository.ConfigHistoryRepository
 }
 
-func NewConfigService(pool *pool.ManagerPool, config *config.AppConfig, configRepo *repository.ConfigRepository) *ConfigService {
+func NewConfigService(pool *pool.ManagerPool, config *config.AppConfig, configRepo *repository.ConfigRepository,
+	historyRepo *repository.ConfigHistoryRepository,
+) *ConfigService {
 	return &ConfigService{
-		pool:       pool,
-		config:     config,
-		configRepo: configRepo,
+		pool:        pool,
+		config:      config,
+		configRepo:  configRepo,
+		historyRepo: historyRepo,
 	}
 }
 
-func (c *ConfigService) AddConfig(config *model.Config) error {
-	if config == nil {
+func (c *ConfigService) AddConfig(newConfig *model.Config) error {
+	if newConfig == nil {
 		return errors.New("config 为空")
 	}
-	if config.Key == "" {
+	if newConfig.Key == "" {
 		return errors.New("key 为空")
 	}
-	_, err := c.configRepo.GetConfigByKey(config.Key)
+	if err := config.Validate(newConfig.Key, newConfig.Value); err != nil {
+		return err
+	}
+	_, err := c.configRepo.GetConfigByKey(newConfig.Key)
 	if err == nil {
 		// 如果 err 为 nil，说明记录被成功找到了
 		return errors.New("key 已存在，请勿重复添加")
@@ -46,13 +55,14 @@ func (c *ConfigService) AddConfig(config *model.Config) error {
 		return fmt.Errorf("查询配置失败: %w", err)
 	}
 
-	err = c.configRepo.AddConfig(config)
+	err = c.configRepo.AddConfig(newConfig)
 	if err != nil {
 		return err
 	}
+	c.recordHistory(newConfig.ID, newConfig.Key, "", newConfig.Value, model.ConfigActionAdd)
 
 	// 更新全局配置并通知订阅者
-	err = c.config.OnUpdate(config.Key, config.Value)
+	err = c.config.OnUpdate(newConfig.Key, newConfig.Value)
 	if err != nil {
 		return err
 	}
@@ -67,14 +77,20 @@ func (c *ConfigService) ListConfigs() ([]vo.ConfigVO, error) {
 
 	var configVOs []vo.ConfigVO
 	for _, cfg := range configs {
-		configVOs = append(configVOs, vo.ConfigVO{
+		configVO := vo.ConfigVO{
 			ID:          cfg.ID,
 			Key:         cfg.Key,
-			Value:       cfg.Value,
+			Value:       config.MaskValue(cfg.Key, cfg.Value),
 			Description: cfg.Description,
 			CreateTime:  util.MillisToTime(cfg.CreateTime),
 			UpdateTime:  util.MillisToTime(cfg.UpdateTime),
-		})
+		}
+		if schema, ok := config.LookupSchema(cfg.Key); ok {
+			configVO.Type = schema.Type
+			configVO.Secret = schema.Secret
+			configVO.RequiresRestart = schema.RequiresRestart
+		}
+		configVOs = append(configVOs, configVO)
 	}
 
 	return configVOs, err
@@ -88,24 +104,44 @@ func (c *ConfigService) UpdateConfig(updateVo *vo.ConfigUpdateVO) error {
 		return errors.New("id 为空")
 	}
 
-	cfg, err := c.configRepo.GetConfigById(updateVo.ID)
+	existing, err := c.configRepo.GetConfigById(updateVo.ID)
 	if err != nil {
 		return err
 	}
-	if cfg == nil {
+	if existing == nil || existing.ID == 0 {
 		return errors.New("配置不存在")
 	}
+	if updateVo.Key != existing.Key {
+		return errors.New("key 不允许修改")
+	}
+
+	// 前端原样回传脱敏后的值，视为未修改
+	value := updateVo.Value
+	if config.IsMasked(existing.Key, existing.Value, value) {
+		value = existing.Value
+	}
+	if err := config.Validate(existing.Key, value); err != nil {
+		return err
+	}
 
 	updateConfig := &model.Config{
 		ID:          updateVo.ID,
-		Key:         updateVo.Key,
-		Value:       updateVo.Value,
+		Key:         existing.Key,
+		Value:       value,
 		Description: updateVo.Description,
 	}
-	err = c.configRepo.UpdateConfig(updateConfig)
-	if err != nil {
+	if updateConfig.Description != "" && updateConfig.Description != existing.Description {
+		if err = c.configRepo.UpdateConfig(&model.Config{ID: updateConfig.ID, Description: updateConfig.Description}); err != nil {
+			return err
+		}
+	}
+	if value == existing.Value {
+		return nil
+	}
+	if err = c.configRepo.UpdateConfigValue(updateConfig.ID, value); err != nil {
 		return err
 	}
+	c.recordHistory(existing.ID, existing.Key, existing.Value, value, model.ConfigActionUpdate)
 
 	// 更新全局配置并通知订阅者
 	err = c.config.OnUpdate(updateConfig.Key, updateConfig.Value)
@@ -129,3 +165,92 @@ func (c *ConfigService) ListConfigMap() (map[string]string, error) {
 
 	return configMap, nil
 }
+
+// ListHistories 获取配置变更历史，敏感项脱敏
+func (c *ConfigService) ListHistories(key string, limit int) ([]vo.ConfigHistoryVO, error) {
+	histories, err := c.historyRepo.ListHistories(key, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	historyVOs := make([]vo.ConfigHistoryVO, 0, len(histories))
+	for _, h := range histories {
+		historyVOs = append(historyVOs, vo.ConfigHistoryVO{
+			ID:         h.ID,
+			ConfigID:   h.ConfigID,
+			Key:        h.Key,
+			OldValue:   config.MaskValue(h.Key, h.OldValue),
+			NewValue:   config.MaskValue(h.Key, h.NewValue),
+			Action:     h.Action,
+			CreateTime: util.MillisToTime(h.CreateTime),
+		})
+	}
+	return historyVOs, nil
+}
+
+// Rollback 将配置恢复为某条变更记录之前的值
+func (c *ConfigService) Rollback(historyId int64) (*model.Config, error) {
+	if historyId == 0 {
+		return nil, errors.New("historyId 为空")
+	}
+	history, err := c.historyRepo.GetHistoryById(historyId)
+	if err != nil {
+		return nil, err
+	}
+	if history == nil {
+		return nil, errors.New("变更记录不存在")
+	}
+
+	existing, err := c.configRepo.GetConfigByKey(history.Key)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("配置不存在")
+		}
+		return nil, err
+	}
+
+	target := history.OldValue
+	if err := config.Validate(existing.Key, target); err != nil {
+		return nil, fmt.Errorf("该记录无法回滚: %w", err)
+	}
+	if target == existing.Value {
+		return existing, nil
+	}
+
+	if err = c.configRepo.UpdateConfigValue(existing.ID, target); err != nil {
+		return nil, err
+	}
+	c.recordHistory(existing.ID, existing.Key, existing.Value, target, model.ConfigActionRollback)
+
+	if err = c.config.OnUpdate(existing.Key, target); err != nil {
+		return nil, err
+	}
+	existing.Value = target
+	return existing, nil
+}
+
+// recordHistory 记录配置变更，失败只打印日志，不影响配置更新
+func (c *ConfigService) recordHistory(configId int64, key string, oldValue string, newValue string, action string) {
+	err := c.historyRepo.AddHistory(&model.ConfigHistory{
+		ID:       util.MustNextID(),
+		ConfigID: configId,
+		Key:      key,
+		OldValue: oldValue,
+		NewValue: newValue,
+		Action:   action,
+	})
+	if err != nil {
+		log.Err(err).Str("key", key).Msg("[Config] 记录配置变更历史失败")
+	}
+}
+
+// PreviewFilename 使用示例变量预览文件名格式，pattern 为空时预览当前配置
+func (c *ConfigService) PreviewFilename(pattern string) (string, error) {
+	if pattern == "" {
+		pattern = c.config.Recorder.FilenamePattern
+	}
+	if err := recorder.ValidateFilenamePattern(pattern); err != nil {
+		return "", err
+	}
+	return recorder.PreviewFilename(c.config.Recorder.OutputDir, pattern, nil)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"video-factory/internal/domain/vo"
+	"video-factory/internal/repository"
+	"video-factory/internal/vod"
+	"video-factory/pkg/config"
+	"video-factory/pkg/util"
+)
+
+const (
+	fileStreamPrefix   = "/api/v1/files/stream/"
+	filePlaylistPrefix = "/api/v1/files/hls/"
+)
+
+// FileService 浏览与在线播放录制目录下的文件
+type FileService struct {
+	config     *config.AppConfig
+	recordRepo *repository.RecordingRepository
+}
+
+func NewFileService(config *config.AppConfig, recordRepo *repository.RecordingRepository) *FileService {
+	return &FileService{
+		config:     config,
+		recordRepo: recordRepo,
+	}
+}
+
+// root 录制根目录，未配置时为运行目录
+func (f *FileService) root() string {
+	if f.config.Recorder != nil && f.config.Recorder.OutputDir != "" {
+		return f.config.Recorder.OutputDir
+	}
+	return "."
+}
+
+// Browse 列出目录下的子目录与录制文件
+func (f *FileService) Browse(rel string) ([]vod.Entry, error) {
+	return vod.ListDir(f.root(), strings.TrimPrefix(rel, "/"))
+}
+
+// ListRecordingFiles 按房间与日期列出录制文件，date 为空时不限日期
+func (f *FileService) ListRecordingFiles(roomId int64, date string, limit int) ([]vo.RecordingFileVO, error) {
+	from, to := int64(0), time.Now().Add(24*time.Hour).UnixMilli()
+	if date != "" {
+		day, err := time.ParseInLocation(time.DateOnly, date, time.Local)
+		if err != nil {
+			return nil, errors.New("日期格式应为 2006-01-02")
+		}
+		from, to = day.UnixMilli(), day.AddDate(0, 0, 1).UnixMilli()
+	}
+
+	recordings, err := f.recordRepo.ListRecordingsByTime(roomId, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]vo.RecordingFileVO, 0, len(recordings))
+	for _, recording := range recordings {
+		startTime := util.MillisToTime(recording.StartTime)
+		item := vo.RecordingFileVO{
+			ID:          recording.ID,
+			RoomID:      recording.RoomID,
+			Date:        startTime.Format(time.DateOnly),
+			Name:        filepath.Base(recording.Filename),
+			Filesize:    recording.Filesize,
+			FilesizeStr: util.FormatFilesize(int(recording.Filesize)),
+			Duration:    recording.Duration,
+			DurationStr: util.FormatDuration(recording.Duration),
+			StartTime:   startTime,
+		}
+		if rel, ok := vod.RelPath(f.root(), recording.Filename); ok {
+			item.Path = rel
+			if _, err := os.Stat(recording.Filename); err == nil {
+				item.Exists = true
+				item.StreamURL, item.PlaylistURL = fileURLs(rel)
+			}
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// ResolveFile 将相对路径解析为录制目录下的文件，只允许访问录制文件
+func (f *FileService) ResolveFile(rel string) (string, error) {
+	file, err := vod.SafeJoin(f.root(), strings.TrimPrefix(rel, "/"))
+	if err != nil {
+		return "", err
+	}
+	if !vod.IsMedia(file) {
+		return "", errors.New("不支持的文件类型")
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", errors.New("不是文件")
+	}
+	return file, nil
+}
+
+// Playlist 为 TS 文件生成按关键帧切分的 HLS VOD 播放列表
+func (f *FileService) Playlist(rel string) (string, error) {
+	rel = strings.TrimSuffix(strings.TrimPrefix(rel, "/"), ".m3u8")
+	if !strings.EqualFold(path.Ext(rel), ".ts") {
+		return "", errors.New("只有 TS 文件支持 HLS 播放")
+	}
+	file, err := f.ResolveFile(rel)
+	if err != nil {
+		return "", err
+	}
+	segments, err := vod.IndexFile(file, f.recordingDuration(rel, file))
+	if err != nil {
+		return "", err
+	}
+	streamURL, _ := fileURLs(rel)
+	return vod.Playlist(streamURL, segments), nil
+}
+
+// recordingDuration 文件对应录制记录的时长，没有关键帧索引时按时长估算分片，找不到记录时为 0
+func (f *FileService) recordingDuration(rel string, file string) float64 {
+	candidates := []string{filepath.Join(f.root(), filepath.FromSlash(rel)), file}
+	recording, err := f.recordRepo.GetRecordingByFilename(candidates)
+	if err != nil || recording == nil {
+		return 0
+	}
+	return recording.Duration
+}
+
+// fileURLs 文件的下载地址与播放列表地址
+func fileURLs(rel string) (string, string) {
+	escaped := vod.EscapePath(rel)
+	streamURL := fileStreamPrefix + escaped
+	if strings.EqualFold(path.Ext(rel), ".ts") {
+		return streamURL, filePlaylistPrefix + escaped + ".m3u8"
+	}
+	return streamURL, ""
+}
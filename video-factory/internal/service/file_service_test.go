@@ -0,0 +1,37 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"video-factory/internal/domain/model"
+	"video-factory/internal/repository"
+	"video-factory/pkg/config"
+)
+
+func TestPlaylistUsesRecordingDuration(t *testing.T) {
+	db := newTestDB(t, &model.Recording{})
+	root := t.TempDir()
+	file := filepath.Join(root, "room", "a.ts")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// 没有关键帧的数据，分片时长按录制时长估算
+	if err := os.WriteFile(file, make([]byte, 188*10), 0644); err != nil {
+		t.Fatal(err)
+	}
+	recordRepo := repository.NewRecordingRepository(db)
+	if err := recordRepo.AddRecording(&model.Recording{ID: 1, Filename: file, Duration: 42.5}); err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewFileService(&config.AppConfig{Recorder: &config.Recorder{OutputDir: root}}, recordRepo)
+	playlist, err := f.Playlist("room/a.ts.m3u8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(playlist, "#EXTINF:42.500,") {
+		t.Errorf("playlist = %s", playlist)
+	}
+}
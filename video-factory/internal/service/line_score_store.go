@@ -0,0 +1,52 @@
+package service
+
+import (
+	"video-factory/internal/domain/model"
+	"video-factory/internal/lineprobe"
+	"video-factory/internal/repository"
+	"video-factory/pkg/util"
+)
+
+// LineScoreStore 将线路评分持久化到数据库，实现 lineprobe.Store
+type LineScoreStore struct {
+	repo *repository.LineScoreRepository
+}
+
+func NewLineScoreStore(repo *repository.LineScoreRepository) *LineScoreStore {
+	return &LineScoreStore{repo: repo}
+}
+
+func (l *LineScoreStore) LoadScores() ([]lineprobe.Score, error) {
+	rows, err := l.repo.ListScores()
+	if err != nil {
+		return nil, err
+	}
+	scores := make([]lineprobe.Score, 0, len(rows))
+	for _, row := range rows {
+		scores = append(scores, lineprobe.Score{
+			Platform:    row.Platform,
+			Host:        row.Host,
+			TTFB:        row.TTFB,
+			Throughput:  row.Throughput,
+			Successes:   row.Successes,
+			Failures:    row.Failures,
+			LastProbe:   row.LastProbe,
+			LastFailure: row.LastFailure,
+		})
+	}
+	return scores, nil
+}
+
+func (l *LineScoreStore) SaveScore(score lineprobe.Score) error {
+	return l.repo.UpsertScore(&model.LineScore{
+		ID:          util.MustNextID(),
+		Platform:    score.Platform,
+		Host:        score.Host,
+		TTFB:        score.TTFB,
+		Throughput:  score.Throughput,
+		Successes:   score.Successes,
+		Failures:    score.Failures,
+		LastProbe:   score.LastProbe,
+		LastFailure: score.LastFailure,
+	})
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+	"video-factory/internal/common/consts"
+	"video-factory/internal/domain/model"
+	"video-factory/internal/domain/vo"
+	"video-factory/internal/imagecache"
+	"video-factory/internal/repository"
+	"video-factory/internal/site/bili"
+	"video-factory/internal/site/missevan"
+	"video-factory/pkg/config"
+	"video-factory/pkg/fetcher"
+	"video-factory/pkg/pool"
+	"video-factory/pkg/util"
+
+	"github.com/rs/zerolog/log"
+)
+
+// imageFetchTimeout 下载单张封面或头像的超时时间
+const imageFetchTimeout = 30 * time.Second
+
+// MetadataService 刷新房间标题、封面、主播名与头像，记录标题变更，并将图片缓存到本地
+type MetadataService struct {
+	pool      *pool.ManagerPool
+	config    *config.AppConfig
+	roomRepo  *repository.RoomRepository
+	titleRepo *repository.RoomTitleRepository
+	images    *imagecache.Cache
+	// imageMu 下载图片并保存到房间时持有读锁，删除不再引用的图片时持有写锁，
+	// 避免其他房间刚下载到同一文件、尚未保存时被删除
+	imageMu sync.RWMutex
+}
+
+func NewMetadataService(pool *pool.ManagerPool, config *config.AppConfig, roomRepo *repository.RoomRepository,
+	titleRepo *repository.RoomTitleRepository) *MetadataService {
+	dir := "cache/images"
+	if config.Metadata != nil && config.Metadata.CacheDir != "" {
+		dir = config.Metadata.CacheDir
+	}
+	return &MetadataService{
+		pool:      pool,
+		config:    config,
+		roomRepo:  roomRepo,
+		titleRepo: titleRepo,
+		images:    imagecache.New(dir, fetcher.GlobalClient),
+	}
+}
+
+// Run 按配置的间隔定期刷新所有房间，每分钟检查一次以便配置修改后生效
+func (s *MetadataService) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	var lastRun time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if s.config.Metadata == nil || s.config.Metadata.Interval <= 0 {
+				continue
+			}
+			if now.Sub(lastRun) < time.Duration(s.config.Metadata.Interval)*time.Minute {
+				continue
+			}
+			lastRun = now
+			s.refreshAll(ctx)
+		}
+	}
+}
+
+// OnManagerStart 开播时刷新房间信息并记录本场的标题，room 的标题与主播名会被更新
+// 续接上一场时本场已有标题记录，不再刷新
+func (s *MetadataService) OnManagerStart(room *model.Room, sessionId int64, resumed bool) {
+	if resumed {
+		return
+	}
+	if err := s.refresh(room, sessionId, true); err != nil {
+		log.Err(err).Int64("roomId", room.ID).Msg("[Metadata] 开播时刷新房间信息失败")
+	}
+}
+
+// Refresh 手动刷新某个房间的信息
+func (s *MetadataService) Refresh(roomIdStr string) error {
+	roomId, err := strconv.ParseInt(roomIdStr, 10, 64)
+	if err != nil {
+		return errors.New("房间 id 格式有误")
+	}
+	room, err := s.roomRepo.GetRoomById(roomId)
+	if err != nil {
+		return err
+	}
+	if room == nil {
+		return errors.New("房间不存在")
+	}
+	return s.refresh(room, s.currentSession(roomId), false)
+}
+
+// ListTitles 获取房间的标题变更记录
+func (s *MetadataService) ListTitles(roomId int64, sessionId int64, limit int) ([]vo.RoomTitleVO, error) {
+	titles, err := s.titleRepo.ListTitles(roomId, sessionId, limit)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]vo.RoomTitleVO, 0, len(titles))
+	for _, title := range titles {
+		list = append(list, vo.RoomTitleVO{
+			SessionID:  title.SessionID,
+			Title:      title.Title,
+			CreateTime: util.MillisToTime(title.CreateTime),
+		})
+	}
+	return list, nil
+}
+
+// ImagePath 缓存图片的本地路径
+func (s *MetadataService) ImagePath(name string) (string, bool) {
+	return s.images.Path(name)
+}
+
+func (s *MetadataService) refreshAll(ctx context.Context) {
+	rooms, err := s.roomRepo.ListRooms()
+	if err != nil {
+		log.Err(err).Msg("[Metadata] 获取房间列表失败")
+		return
+	}
+	failed := 0
+	for i := range rooms {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.refresh(&rooms[i], s.currentSession(rooms[i].ID), false); err != nil {
+			failed++
+			log.Warn().Err(err).Int64("roomId", rooms[i].ID).Msg("[Metadata] 刷新房间信息失败")
+			if fetcher.IsRateLimited(err) {
+				// 平台限流时停止本轮，避免加重风控
+				break
+			}
+		}
+	}
+	log.Info().Int("total", len(rooms)).Int("failed", failed).Msg("[Metadata] 房间信息刷新完成")
+}
+
+// currentSession 直播中房间的开播记录 ID
+func (s *MetadataService) currentSession(roomId int64) int64 {
+	if mgr, ok := s.pool.Get(roomId); ok {
+		return mgr.SessionID
+	}
+	return 0
+}
+
+// refresh 获取最新信息并更新房间，标题变化或新开播时写入标题记录
+func (s *MetadataService) refresh(room *model.Room, sessionId int64, sessionStart bool) error {
+	var info *vo.RoomAddVO
+	var err error
+	switch room.Platform {
+	case consts.PlatformBili:
+		info, err = bili.GetRoomAddInfo(room.RealID)
+	case consts.PlatformMissevan:
+		info, err = missevan.GetRoomAddInfo(room.RealID)
+	default:
+		return errors.New("不支持的平台")
+	}
+	if err != nil {
+		return err
+	}
+
+	updateMap := map[string]any{"meta_time": time.Now().UnixMilli()}
+	titleChanged := info.Name != "" && info.Name != room.Name
+	if titleChanged {
+		log.Info().Int64("roomId", room.ID).Str("old", room.Name).Str("new", info.Name).Msg("[Metadata] 房间标题变更")
+		room.Name = info.Name
+		updateMap["name"] = info.Name
+	}
+	if titleChanged || sessionStart {
+		if err := s.titleRepo.AddTitle(&model.RoomTitle{
+			ID:        util.MustNextID(),
+			RoomID:    room.ID,
+			SessionID: sessionId,
+			Title:     room.Name,
+		}); err != nil {
+			log.Err(err).Int64("roomId", room.ID).Msg("[Metadata] 保存标题记录失败")
+		}
+	}
+	if info.AnchorName != "" && info.AnchorName != room.AnchorName {
+		log.Info().Int64("roomId", room.ID).Str("old", room.AnchorName).Str("new", info.AnchorName).Msg("[Metadata] 主播名变更")
+		room.AnchorName = info.AnchorName
+		updateMap["anchor_name"] = info.AnchorName
+	}
+
+	cover := info.CoverURL != "" && (info.CoverURL != room.CoverURL || room.CoverCache == "")
+	if cover {
+		room.CoverURL = info.CoverURL
+		updateMap["cover_url"] = info.CoverURL
+	}
+	avatar := info.AnchorAvatar != "" && (info.AnchorAvatar != room.AnchorAvatar || room.AvatarCache == "")
+	if avatar {
+		room.AnchorAvatar = info.AnchorAvatar
+		updateMap["anchor_avatar"] = info.AnchorAvatar
+	}
+	if err := s.roomRepo.UpdateRoomById(room.ID, updateMap); err != nil {
+		return err
+	}
+	if !cover && !avatar {
+		return nil
+	}
+	if sessionStart {
+		// 开播时在录制开始前执行，下载图片较慢，放到后台
+		go s.cacheImages(*room, cover, avatar)
+		return nil
+	}
+	s.cacheImages(*room, cover, avatar)
+	return nil
+}
+
+// cacheImages 下载封面与头像并保存缓存文件名
+// 图片文件以地址命名，多个房间可能共用，旧文件在没有房间引用后才删除
+func (s *MetadataService) cacheImages(room model.Room, cover bool, avatar bool) {
+	updateMap := make(map[string]any, 2)
+	var stale []string
+	s.imageMu.RLock()
+	if cover {
+		if name, ok := s.cacheImage(room.ID, room.CoverURL); ok {
+			if room.CoverCache != "" && room.CoverCache != name {
+				stale = append(stale, room.CoverCache)
+			}
+			updateMap["cover_cache"] = name
+		}
+	}
+	if avatar {
+		if name, ok := s.cacheImage(room.ID, room.AnchorAvatar); ok {
+			if room.AvatarCache != "" && room.AvatarCache != name {
+				stale = append(stale, room.AvatarCache)
+			}
+			updateMap["avatar_cache"] = name
+		}
+	}
+	var err error
+	if len(updateMap) > 0 {
+		err = s.roomRepo.UpdateRoomById(room.ID, updateMap)
+	}
+	s.imageMu.RUnlock()
+	if err != nil {
+		log.Err(err).Int64("roomId", room.ID).Msg("[Metadata] 保存图片缓存失败")
+		return
+	}
+	s.removeUnusedImages(stale)
+}
+
+// cacheImage 下载图片，返回缓存文件名
+func (s *MetadataService) cacheImage(roomId int64, url string) (string, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), imageFetchTimeout)
+	defer cancel()
+	name, err := s.images.Fetch(ctx, url)
+	if err != nil {
+		log.Warn().Err(err).Int64("roomId", roomId).Str("url", url).Msg("[Metadata] 缓存图片失败")
+		return "", false
+	}
+	return name, true
+}
+
+// removeUnusedImages 删除已没有房间引用的缓存图片
+func (s *MetadataService) removeUnusedImages(names []string) {
+	if len(names) == 0 {
+		return
+	}
+	s.imageMu.Lock()
+	defer s.imageMu.Unlock()
+	for _, name := range names {
+		count, err := s.roomRepo.CountImageRefs(name)
+		if err != nil {
+			log.Warn().Err(err).Str("name", name).Msg("[Metadata] 查询图片引用失败，保留缓存文件")
+			continue
+		}
+		if count == 0 {
+			s.images.Remove(name)
+		}
+	}
+}
+
+// cachedImageURL 已缓存时返回本地地址，否则返回平台地址
+func cachedImageURL(cache string, original string) string {
+	if cache == "" {
+		return original
+	}
+	return "/api/v1/image/" + cache
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"video-factory/internal/domain/model"
+	"video-factory/internal/imagecache"
+	"video-factory/internal/repository"
+)
+
+func TestRemoveUnusedImages(t *testing.T) {
+	db := newTestDB(t, &model.Room{})
+	dir := t.TempDir()
+	shared := strings.Repeat("a", 40) + ".jpg"
+	unused := strings.Repeat("b", 40) + ".jpg"
+	for _, name := range []string{shared, unused} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 同一主播的两个房间共用头像
+	mustCreate(t, db, &model.Room{ID: 1, AvatarCache: shared})
+	mustCreate(t, db, &model.Room{ID: 2, CoverCache: unused, AvatarCache: shared})
+
+	s := &MetadataService{roomRepo: repository.NewRoomRepository(db), images: imagecache.New(dir, nil)}
+	// 房间 2 的图片地址变化，旧文件只有房间 1 仍在引用
+	db.Model(&model.Room{}).Where("id = ?", 2).Updates(map[string]any{"cover_cache": "", "avatar_cache": ""})
+	s.removeUnusedImages([]string{shared, unused})
+	if _, ok := s.ImagePath(shared); !ok {
+		t.Error("其他房间仍在引用的图片不能删除")
+	}
+	if _, ok := s.ImagePath(unused); ok {
+		t.Error("没有引用的图片需要删除")
+	}
+}
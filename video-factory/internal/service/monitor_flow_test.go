@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"video-factory/internal/common/consts"
+	"video-factory/internal/domain/model"
+	"video-factory/internal/fakeplatform"
+	"video-factory/internal/manager"
+	"video-factory/internal/recorder"
+	"video-factory/internal/repository"
+	"video-factory/internal/site/bili"
+	"video-factory/pkg/config"
+	"video-factory/pkg/fetcher"
+	"video-factory/pkg/pool"
+	"video-factory/pkg/util"
+)
+
+// waitFor 轮询直到 cond 满足或超时
+func waitFor(t *testing.T, timeout time.Duration, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待超时: %s", what)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// TestMonitorFlow 使用模拟平台跑通 监控 → Manager → 代理 → 录制 的完整流程，不依赖网络和 ffmpeg
+func TestMonitorFlow(t *testing.T) {
+	if testing.Short() {
+		t.Skip("完整流程耗时数秒")
+	}
+	util.InitIDGenerator(1)
+
+	cfg := &config.AppConfig{
+		Recorder: &config.Recorder{
+			FilenamePattern: "{{.Username}}_{{.Sequence}}",
+			OutputDir:       t.TempDir(),
+		},
+		Monitor: &config.Monitor{Interval: 30, MinInterval: 10, MaxInterval: 60, Concurrency: 2, QPS: 5, OfflineGrace: 1, OfflineRecheck: 1, StitchWindow: 5},
+	}
+	fetcher.Init(cfg)
+
+	fake := fakeplatform.New()
+	fake.AddRoom(fakeplatform.Room{ID: "1001", UID: "501", Title: "测试直播", Anchor: "主播A", Live: true})
+	if err := bili.SetBaseURL(fake.URL); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		fake.Close()
+		_ = bili.SetBaseURL("")
+		fetcher.GetBreaker(consts.PlatformBili).Reset()
+	})
+
+	db := newTestDB(t, &model.Room{}, &model.LiveSession{}, &model.LiveSessionGap{}, &model.SessionTransition{}, &model.Recording{})
+	roomRepo := repository.NewRoomRepository(db)
+	sessionRepo := repository.NewLiveSessionRepository(db)
+	recordRepo := repository.NewRecordingRepository(db)
+	room := &model.Room{
+		ID: util.MustNextID(), Platform: consts.PlatformBili, RealID: "1001",
+		AnchorID: "501", AnchorName: "主播A", Status: 1, RecordStatus: 1, RecordEngine: recorder.EngineHTTP,
+	}
+	if err := roomRepo.AddRoom(room); err != nil {
+		t.Fatal(err)
+	}
+
+	p := pool.NewManagerPool(cfg)
+	monitor := NewMonitorService(p, cfg, roomRepo, sessionRepo, recordRepo)
+	saved := make(chan *model.Recording, 4)
+	monitor.OnRecordingSaved(func(recording *model.Recording) { saved <- recording })
+	starts := make(chan bool, 4)
+	monitor.OnManagerStart(func(room *model.Room, sessionId int64, resumed bool) { starts <- resumed })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := monitor.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer monitor.StopMonitor()
+
+	// 1. 首次扫描发现开播，启动 Manager 并开始录制
+	var mgr *manager.Manager
+	waitFor(t, 10*time.Second, "Manager 启动", func() bool {
+		mgr, _ = p.Get(room.ID)
+		return mgr != nil
+	})
+	waitFor(t, 10*time.Second, "录制数据写入", func() bool {
+		stats, ok := mgr.RecorderStats()
+		return ok && stats.Filesize > 0
+	})
+
+	// 2. 链接提前失效，代理请求遇到 403 后刷新并使用新链接
+	fake.ExpireLinks()
+	resp, err := mgr.FetchFile(ctx, "index.m3u8")
+	if err != nil {
+		t.Fatalf("刷新后代理请求失败: %v", err)
+	}
+	header := make([]byte, 3)
+	_, err = io.ReadFull(resp.Body, header)
+	resp.Body.Close()
+	if err != nil || string(header) != "FLV" {
+		t.Fatalf("header = %q, err = %v", header, err)
+	}
+	if fake.Hits("/live/403") == 0 {
+		t.Fatal("过期链接应当返回 403")
+	}
+
+	if state, _ := mgr.State(); state != manager.StateLive {
+		t.Fatalf("state = %s, want live", state)
+	}
+
+	// 3. 下播：刷新时发现未开播，宽限期结束后 Manager 停止，录制文件完成并保存记录
+	fake.SetLive("1001", false)
+	mgr.TriggerRefresh()
+
+	var recording *model.Recording
+	select {
+	case recording = <-saved:
+	case <-time.After(10 * time.Second):
+		t.Fatal("等待超时: 录制记录保存")
+	}
+	if recording.RoomID != room.ID || recording.Filesize == 0 || !strings.HasSuffix(recording.Filename, "_0.flv") {
+		t.Fatalf("recording = %+v", recording)
+	}
+	data, err := os.ReadFile(recording.Filename)
+	if err != nil || !strings.HasPrefix(string(data), "FLV") {
+		t.Fatalf("录制文件内容错误, err = %v", err)
+	}
+	waitFor(t, 5*time.Second, "Manager 移出 pool", func() bool {
+		_, ok := p.Get(room.ID)
+		return !ok
+	})
+
+	session, err := sessionRepo.GetSessionById(recording.SessionID)
+	if err != nil || session == nil || session.EndTime == 0 {
+		t.Fatalf("开播记录未结束, session = %+v, err = %v", session, err)
+	}
+	transitions, err := monitor.ListTransitions(session.ID)
+	if err != nil || len(transitions) < 4 {
+		t.Fatalf("transitions = %+v, err = %v", transitions, err)
+	}
+	last := len(transitions) - 1
+	if transitions[0].To != string(manager.StateStarting) || transitions[1].To != string(manager.StateLive) ||
+		transitions[last-1].To != string(manager.StateOfflineGrace) || transitions[last].To != string(manager.StateStopped) {
+		t.Fatalf("transitions = %+v", transitions)
+	}
+
+	// 4. 续接窗口内重新开播：沿用开播记录与文件序号，并记录断流区间
+	fake.SetLive("1001", true)
+	monitor.TriggerRefresh()
+	waitFor(t, 10*time.Second, "Manager 重新启动", func() bool {
+		mgr, _ = p.Get(room.ID)
+		return mgr != nil
+	})
+	if mgr.SessionID != session.ID {
+		t.Fatalf("sessionId = %d, want %d", mgr.SessionID, session.ID)
+	}
+	// 首次开播与续接各回调一次，续接时 resumed 为 true，不会重复发送开播通知
+	if first, second := <-starts, <-starts; first || !second {
+		t.Fatalf("resumed = %v, %v, want false, true", first, second)
+	}
+	waitFor(t, 10*time.Second, "录制数据写入", func() bool {
+		stats, ok := mgr.RecorderStats()
+		return ok && stats.Filesize > 0
+	})
+	if stats, _ := mgr.RecorderStats(); !strings.HasSuffix(stats.File, "_1.flv") {
+		t.Fatalf("file = %s, want sequence 1", stats.File)
+	}
+	resumed, err := monitor.GetSession(session.ID)
+	if err != nil || resumed.EndTime != 0 || len(resumed.Gaps) != 1 || resumed.Gaps[0].StartTime != session.EndTime {
+		t.Fatalf("session = %+v, err = %v", resumed, err)
+	}
+}
@@ -2,27 +2,56 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 	"video-factory/internal/common/consts"
 	"video-factory/internal/domain/model"
 	"video-factory/internal/domain/vo"
+	"video-factory/internal/lineprobe"
 	"video-factory/internal/manager"
+	"video-factory/internal/recorder"
 	"video-factory/internal/repository"
 	"video-factory/internal/site/bili"
 	"video-factory/internal/site/missevan"
 	"video-factory/pkg/config"
+	"video-factory/pkg/fetcher"
+	"video-factory/pkg/limiter"
 	"video-factory/pkg/pool"
 	"video-factory/pkg/util"
 
 	"github.com/rs/zerolog/log"
 )
 
+const (
+	// pollTick 调度器检查到期房间的周期，实际的轮询间隔由 PollScheduler 按房间计算
+	pollTick = 5 * time.Second
+)
+
 type MonitorService struct {
-	pool     *pool.ManagerPool
-	config   *config.AppConfig
-	roomRepo *repository.RoomRepository
+	pool        *pool.ManagerPool
+	config      *config.AppConfig
+	roomRepo    *repository.RoomRepository
+	sessionRepo *repository.LiveSessionRepository
+	recordRepo  *repository.RecordingRepository
+
+	// 录制记录保存后的回调，如生成缩略图
+	recordingListeners []func(*model.Recording)
+	// Manager 加入 pool 后在后台执行的回调，如刷新房间信息，可以修改 room
+	startListeners []func(room *model.Room, sessionId int64, resumed bool)
+	// 录制任务异常退出时的回调，如发送通知
+	failedListeners []func(room *model.Room, err error)
+	// 房间是否由本节点负责，集群模式下只监控持有租约的房间
+	ownerCheck func(roomId int64) error
+
+	// 轮询调度与限流
+	scheduler *PollScheduler
+	limiter   *limiter.TokenBucket
+
+	// 最近一次轮询得到的开播状态，房间列表直接读取，不再逐个请求平台
+	liveStatuses sync.Map // roomId -> int
 
 	// 控制相关
 	refreshCh chan struct{}
@@ -37,12 +66,18 @@ type MonitorService struct {
 	mu        sync.Mutex
 }
 
-func NewMonitorService(pool *pool.ManagerPool, cfg *config.AppConfig, roomRepo *repository.RoomRepository) *MonitorService {
+func NewMonitorService(pool *pool.ManagerPool, cfg *config.AppConfig, roomRepo *repository.RoomRepository,
+	sessionRepo *repository.LiveSessionRepository, recordRepo *repository.RecordingRepository,
+) *MonitorService {
 	return &MonitorService{
-		pool:      pool,
-		config:    cfg,
-		roomRepo:  roomRepo,
-		refreshCh: make(chan struct{}, 1),
+		pool:        pool,
+		config:      cfg,
+		roomRepo:    roomRepo,
+		sessionRepo: sessionRepo,
+		recordRepo:  recordRepo,
+		scheduler:   NewPollScheduler(),
+		limiter:     limiter.NewTokenBucket(float64(cfg.Monitor.QPS), cfg.Monitor.QPS),
+		refreshCh:   make(chan struct{}, 1),
 	}
 }
 
@@ -130,11 +165,11 @@ func (m *MonitorService) TriggerRefresh() {
 }
 
 func (m *MonitorService) monitorLoop() {
-	ticker := time.NewTicker(60 * time.Second)
+	ticker := time.NewTicker(pollTick)
 	defer ticker.Stop()
 
 	// 首次启动时，立即扫描并开启直播流
-	m.scanAndStartRooms()
+	m.scanAndStartRooms(true)
 
 	for {
 		select {
@@ -143,37 +178,198 @@ func (m *MonitorService) monitorLoop() {
 			return
 		case <-m.refreshCh:
 			log.Info().Msg("[Monitor] 收到即时刷新信号，立即刷新")
-			m.scanAndStartRooms()
+			m.scanAndStartRooms(true)
 		case <-ticker.C:
-			// 扫描并开启直播流
-			log.Info().Msgf("[Monitor] -------- 定时扫描开始 --------")
-			m.scanAndStartRooms()
-			log.Info().Msgf("[Monitor] ---------- 扫描完成 ----------")
+			// 只检查到期的房间
+			m.scanAndStartRooms(false)
 		}
 	}
 }
 
-func (m *MonitorService) scanAndStartRooms() {
+// scanAndStartRooms 检查到期房间的开播状态，force 为 true 时忽略调度时间检查所有房间
+func (m *MonitorService) scanAndStartRooms(force bool) {
+	now := time.Now()
+	if until := m.scheduler.BackoffUntil(); now.Before(until) {
+		log.Debug().Time("until", until).Msg("[Monitor] 平台限流退避中，跳过本轮扫描")
+		return
+	}
+
 	rooms, err := m.roomRepo.GetEnabledRooms()
 	if err != nil {
 		log.Err(err).Msg("获取启用房间失败")
 		return
 	}
 
+	dueRooms := make([]model.Room, 0, len(rooms))
 	for _, room := range rooms {
 		// 已经在 pool 中，直接跳过
 		if _, exist := m.pool.Get(room.ID); exist {
 			continue
 		}
+		if m.ownerCheck != nil && m.ownerCheck(room.ID) != nil {
+			continue
+		}
+		if !force && !m.scheduler.IsDue(room.ID, now) {
+			continue
+		}
+		dueRooms = append(dueRooms, room)
+	}
+	if len(dueRooms) == 0 {
+		return
+	}
+
+	log.Info().Int("due", len(dueRooms)).Int("total", len(rooms)).Msgf("[Monitor] -------- 扫描开始 --------")
+	statusMap := m.checkLiveStatuses(dueRooms)
+	for _, room := range dueRooms {
+		status, checked := statusMap[room.ID]
+		if !checked {
+			// 被限流未检查，保持到期状态，退避结束后再查
+			continue
+		}
+		m.scheduler.Schedule(room.ID, time.Now(), m.pollIntervals(room.ID))
+		m.liveStatuses.Store(room.ID, status)
 
 		// 检查房间是否正在直播
-		if m.checkRoomLiveStatus(&room) {
+		if status == 1 {
 			log.Info().Str("anchor", room.AnchorName).Msg("监测到房间开播，正在启动 Manager")
 			if err := m.StartManager(room.ID); err != nil {
 				log.Err(err).Int64("roomId", room.ID).Msg("启动 Manager 失败")
 			}
 		}
 	}
+	log.Info().Msgf("[Monitor] ---------- 扫描完成 ----------")
+}
+
+// checkLiveStatuses 查询房间开播状态，支持批量接口的平台优先批量查询
+// 返回 roomId -> 直播状态，因限流未查询的房间不在结果中，查询失败的房间视为未开播
+func (m *MonitorService) checkLiveStatuses(rooms []model.Room) map[int64]int {
+	m.syncLimiter()
+
+	var (
+		mu      sync.Mutex
+		limited atomic.Bool
+		result  = make(map[int64]int, len(rooms))
+	)
+	setResult := func(roomId int64, status int) {
+		mu.Lock()
+		result[roomId] = status
+		mu.Unlock()
+	}
+	onErr := func(room *model.Room, err error) {
+		if fetcher.IsRateLimited(err) {
+			if limited.CompareAndSwap(false, true) {
+				wait := m.scheduler.Backoff(time.Now())
+				log.Warn().Err(err).Dur("backoff", wait).Msg("[Monitor] 触发平台限流，暂停状态查询")
+			}
+			return
+		}
+		log.Warn().Err(err).Int64("roomId", room.ID).Msg("[Monitor] 获取直播状态失败")
+		setResult(room.ID, 0)
+	}
+
+	// 1. B站按主播 uid 批量查询
+	singles := make([]model.Room, 0, len(rooms))
+	biliRooms := make(map[string]model.Room)
+	uids := make([]string, 0, len(rooms))
+	for _, room := range rooms {
+		if room.Platform == consts.PlatformBili && room.AnchorID != "" && room.AnchorID != "0" {
+			if _, dup := biliRooms[room.AnchorID]; !dup {
+				biliRooms[room.AnchorID] = room
+				uids = append(uids, room.AnchorID)
+				continue
+			}
+		}
+		singles = append(singles, room)
+	}
+	// 分批请求由 bili 客户端负责
+	if len(uids) > 0 {
+		if err := m.limiter.Wait(m.ctx); err != nil {
+			return result
+		}
+		statusMap, err := bili.GetRoomsLiveStatusByUids(uids)
+		switch {
+		case fetcher.IsRateLimited(err):
+			onErr(nil, err)
+		case err != nil:
+			// 批量接口异常时退化为逐个查询
+			log.Warn().Err(err).Msg("[Monitor] 批量获取直播状态失败，改为逐个查询")
+			for _, uid := range uids {
+				singles = append(singles, biliRooms[uid])
+			}
+		default:
+			for uid, status := range statusMap {
+				setResult(biliRooms[uid].ID, status)
+			}
+		}
+	}
+
+	// 2. 其他房间并发逐个查询，受全局并发数和 QPS 限制
+	concurrency := max(m.config.Monitor.Concurrency, 1)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range singles {
+		if limited.Load() {
+			break
+		}
+		if err := m.limiter.Wait(m.ctx); err != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(room *model.Room) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			status, err := m.fetchRoomLiveStatus(room)
+			if err != nil {
+				onErr(room, err)
+				return
+			}
+			setResult(room.ID, status)
+		}(&singles[i])
+	}
+	wg.Wait()
+
+	if !limited.Load() {
+		m.scheduler.ResetBackoff()
+	}
+	return result
+}
+
+// syncLimiter 配置变更后同步限流速率
+func (m *MonitorService) syncLimiter() {
+	qps := float64(m.config.Monitor.QPS)
+	if m.limiter.Rate() != qps {
+		m.limiter.SetRate(qps, m.config.Monitor.QPS)
+	}
+}
+
+// pollIntervals 读取配置中的轮询间隔，并按需加载房间的开播历史
+func (m *MonitorService) pollIntervals(roomId int64) pollIntervals {
+	now := time.Now()
+	if m.scheduler.NeedHistory(roomId, now) {
+		sessions, err := m.sessionRepo.ListRecentSessions(roomId, 30)
+		if err != nil {
+			log.Err(err).Int64("roomId", roomId).Msg("[Monitor] 获取开播历史失败")
+		}
+		openTimes := make([]int64, 0, len(sessions))
+		for _, session := range sessions {
+			if session.OpenTime > 0 {
+				openTimes = append(openTimes, session.OpenTime)
+			} else if session.StartTime > 0 {
+				openTimes = append(openTimes, session.StartTime/1000)
+			}
+		}
+		m.scheduler.SetHistory(roomId, openTimes, now)
+	}
+
+	cfg := m.config.Monitor
+	return pollIntervals{
+		Base: time.Duration(cfg.Interval) * time.Second,
+		Min:  time.Duration(cfg.MinInterval) * time.Second,
+		Max:  time.Duration(cfg.MaxInterval) * time.Second,
+	}
 }
 
 func (m *MonitorService) StartManager(roomId int64) error {
@@ -183,6 +379,11 @@ func (m *MonitorService) StartManager(roomId int64) error {
 	if _, exist := m.pool.Get(roomId); exist {
 		return errors.New("已处于运行中状态")
 	}
+	if m.ownerCheck != nil {
+		if err := m.ownerCheck(roomId); err != nil {
+			return err
+		}
+	}
 
 	room, err := m.roomRepo.GetRoomById(roomId)
 	if err != nil {
@@ -195,16 +396,78 @@ func (m *MonitorService) StartManager(roomId int64) error {
 		return errors.New("房间未启用，请先启用房间")
 	}
 
+	// 记录本次开播，续接窗口内的重新开播沿用上一场的记录
+	session := m.resumableSession(room.ID)
+	resumed := session != nil
+	if !resumed {
+		session = &model.LiveSession{
+			ID:        util.MustNextID(),
+			RoomID:    room.ID,
+			StartTime: time.Now().UnixMilli(),
+		}
+	}
+
 	// 定义回调：Manager 停止时从池中移除
 	onStop := func(id int64) {
 		log.Info().Int64("id", id).Msg("Manager 已停止，从 Pool 中移除")
 		m.pool.Remove(id)
+		if err := m.sessionRepo.UpdateSessionById(session.ID, map[string]any{
+			"end_time": time.Now().UnixMilli(),
+		}); err != nil {
+			log.Err(err).Int64("id", id).Msg("更新开播记录失败")
+		}
+		// 下播后重新加载开播历史，并尽快检查一次状态
+		m.scheduler.Forget(id)
 	}
 	mgr, err := manager.NewManager(room, m.config, onStop)
 	if err != nil {
 		return err
 	}
 
+	mgr.SessionID = session.ID
+	mgr.SetNextSequence(session.Sequence)
+	mgr.OnFileClosed = func(record *recorder.FileRecord) {
+		m.saveRecording(room.ID, session.ID, record)
+	}
+	mgr.OnRecordFailed = func(err error) {
+		for _, listener := range m.failedListeners {
+			listener(room, err)
+		}
+	}
+	mgr.OnTransition = func(transition manager.Transition) {
+		if err := m.sessionRepo.AddTransition(&model.SessionTransition{
+			ID:        util.MustNextID(),
+			SessionID: session.ID,
+			RoomID:    room.ID,
+			FromState: string(transition.From),
+			ToState:   string(transition.To),
+			Reason:    transition.Reason,
+			Time:      transition.Time.UnixMilli(),
+		}); err != nil {
+			log.Err(err).Int64("roomId", room.ID).Msg("记录状态变更失败")
+		}
+	}
+
+	if resumed {
+		now := time.Now().UnixMilli()
+		if err := m.sessionRepo.ResumeSession(&model.LiveSessionGap{
+			ID:        util.MustNextID(),
+			SessionID: session.ID,
+			RoomID:    room.ID,
+			StartTime: session.EndTime,
+			EndTime:   now,
+		}); err != nil {
+			log.Err(err).Int64("roomId", roomId).Msg("续接开播记录失败")
+		}
+		log.Info().Int64("roomId", roomId).Int64("sessionId", session.ID).
+			Dur("gap", time.Duration(now-session.EndTime)*time.Millisecond).Msg("断流后重新开播，续接上一场")
+	} else {
+		session.OpenTime = mgr.OpenTime
+		if err := m.sessionRepo.AddSession(session); err != nil {
+			log.Err(err).Int64("roomId", roomId).Msg("记录开播信息失败")
+		}
+	}
+
 	// 添加到 pool 中
 	m.pool.Add(roomId, mgr)
 	log.Info().Int64("roomId", roomId).Msg("Manager 新建成功并加入 pool")
@@ -212,22 +475,131 @@ func (m *MonitorService) StartManager(roomId int64) error {
 	// 启动自动刷新，录制功能在 manager 中启动
 	go mgr.StartAutoRefresh(m.ctx)
 
+	// 回调需要请求平台，在后台按注册顺序执行，不阻塞启动
+	// 回调使用房间的副本，刷新后的标题与主播名交给 Manager，之后创建的录制文件使用新的主播名
+	info := *room
+	go func() {
+		for _, listener := range m.startListeners {
+			listener(&info, session.ID, resumed)
+		}
+		mgr.SetRoomInfo(info.Name, info.AnchorName)
+	}()
+
 	return nil
 }
 
-func (m *MonitorService) checkRoomLiveStatus(room *model.Room) bool {
+// resumableSession 房间最近一场在续接窗口内结束时返回该记录，否则返回 nil
+func (m *MonitorService) resumableSession(roomId int64) *model.LiveSession {
+	window := time.Duration(m.config.Monitor.StitchWindow) * time.Minute
+	if window <= 0 {
+		return nil
+	}
+	sessions, err := m.sessionRepo.ListRecentSessions(roomId, 1)
+	if err != nil {
+		log.Err(err).Int64("roomId", roomId).Msg("获取最近开播记录失败")
+		return nil
+	}
+	if len(sessions) == 0 || sessions[0].EndTime == 0 {
+		return nil
+	}
+	if time.Since(time.UnixMilli(sessions[0].EndTime)) > window {
+		return nil
+	}
+	return &sessions[0]
+}
+
+// saveRecording 保存录制文件记录及其健康指标
+func (m *MonitorService) saveRecording(roomId int64, sessionId int64, record *recorder.FileRecord) {
+	lines, err := json.Marshal(record.Health.Lines)
+	if err != nil {
+		log.Err(err).Int64("roomId", roomId).Msg("序列化线路统计失败")
+	}
+	samples, err := json.Marshal(record.Health.Samples)
+	if err != nil {
+		log.Err(err).Int64("roomId", roomId).Msg("序列化健康采样失败")
+	}
+
+	recording := &model.Recording{
+		ID:             util.MustNextID(),
+		RoomID:         roomId,
+		SessionID:      sessionId,
+		Filename:       record.Filename,
+		Filesize:       int64(record.Filesize),
+		Duration:       record.Duration,
+		StartTime:      record.StartTime,
+		EndTime:        record.EndTime,
+		AvgBitrate:     record.Health.AvgBitrate,
+		AvgFps:         record.Health.AvgFps,
+		GapCount:       record.Health.GapCount,
+		StallCount:     record.Health.StallCount,
+		URLSwitchCount: record.Health.URLSwitchCount,
+		Lines:          string(lines),
+		Samples:        string(samples),
+	}
+	if err := m.recordRepo.AddRecording(recording); err != nil {
+		log.Err(err).Int64("roomId", roomId).Str("file", record.Filename).Msg("保存录制记录失败")
+		return
+	}
+	// 保存下一个文件的序号，续接开播时沿用
+	if err := m.sessionRepo.UpdateSessionById(sessionId, map[string]any{"sequence": record.Sequence + 1}); err != nil {
+		log.Err(err).Int64("roomId", roomId).Msg("更新文件序号失败")
+	}
+	log.Info().Int64("roomId", roomId).Str("file", record.Filename).
+		Float64("avgBitrate", record.Health.AvgBitrate).
+		Int("stalls", record.Health.StallCount).
+		Int("gaps", record.Health.GapCount).
+		Msg("录制记录已保存")
+
+	for _, listener := range m.recordingListeners {
+		listener(recording)
+	}
+}
+
+// OnManagerStart 注册 Manager 加入 pool 后的回调，需在启动监控前注册
+// resumed 为 true 时本次是断流后续接上一场，不是新的开播
+func (m *MonitorService) OnManagerStart(listener func(room *model.Room, sessionId int64, resumed bool)) {
+	m.startListeners = append(m.startListeners, listener)
+}
+
+// OnRecordFailed 注册录制任务异常退出时的回调，需在启动监控前注册
+func (m *MonitorService) OnRecordFailed(listener func(room *model.Room, err error)) {
+	m.failedListeners = append(m.failedListeners, listener)
+}
+
+// LiveStatus 房间的开播状态，有运行中的 Manager 时为直播中，否则取最近一次轮询的结果（如轮播中）
+func (m *MonitorService) LiveStatus(roomId int64) int {
+	if _, ok := m.pool.Get(roomId); ok {
+		return 1
+	}
+	if status, ok := m.liveStatuses.Load(roomId); ok {
+		if s := status.(int); s != 1 {
+			return s
+		}
+	}
+	return 0
+}
+
+// SetOwnerCheck 设置房间归属检查，返回错误的房间不会被监控和启动，需在启动监控前设置
+func (m *MonitorService) SetOwnerCheck(check func(roomId int64) error) {
+	m.ownerCheck = check
+}
+
+// OnRecordingSaved 注册录制记录保存后的回调，需在启动监控前注册
+func (m *MonitorService) OnRecordingSaved(listener func(*model.Recording)) {
+	m.recordingListeners = append(m.recordingListeners, listener)
+}
+
+func (m *MonitorService) fetchRoomLiveStatus(room *model.Room) (int, error) {
 	if room == nil {
-		return false
+		return 0, nil
 	}
 	switch room.Platform {
 	case consts.PlatformBili:
-		status, err := bili.GetRoomLiveStatus(room.RealID)
-		return err == nil && status == 1
+		return bili.GetRoomLiveStatus(room.RealID)
 	case consts.PlatformMissevan:
-		status, err := missevan.GetRoomLiveStatus(room.RealID)
-		return err == nil && status == 1
+		return missevan.GetRoomLiveStatus(room.RealID)
 	default:
-		return false
+		return 0, nil
 	}
 }
 
@@ -247,26 +619,37 @@ func (m *MonitorService) GetManagerList() ([]vo.ManagerVO, error) {
 			RealID:       room.RealID,
 			Platform:     room.Platform,
 			Name:         room.Name,
-			CoverURL:     room.CoverURL,
+			CoverURL:     cachedImageURL(room.CoverCache, room.CoverURL),
 			AnchorName:   room.AnchorName,
 			AnchorID:     room.AnchorID,
-			AnchorAvatar: room.AnchorAvatar,
+			AnchorAvatar: cachedImageURL(room.AvatarCache, room.AnchorAvatar),
 			URL:          room.URL,
 			ProxyURL:     room.ProxyURL,
 		}
 
 		if managerPtr, ok := poolSnapshot[room.ID]; ok {
 			managerVo.LiveStatus = 1
+			managerVo.SessionID = managerPtr.SessionID
+			state, since := managerPtr.State()
+			managerVo.State = string(state)
+			managerVo.StateSince = &since
 			managerVo.CurrentURL = managerPtr.CurrentURL
+			managerVo.CurrentLine = managerPtr.CurrentLine
+			managerVo.Quality = managerPtr.QualityName
+			managerVo.Channel = managerPtr.Channel
 			managerVo.LastRefresh = &managerPtr.LastRefreshTime
 			managerVo.ExpireTime = &managerPtr.ActualExpireTime
 			managerVo.RecordStatus = managerPtr.RecordStatus
-			if managerPtr.RecordStatus == 1 {
-				managerVo.RecordFile = managerPtr.Recorder.File.Name()
-				managerVo.RecordSize = managerPtr.Recorder.Filesize
-				managerVo.RecordSizeStr = util.FormatFilesize(managerPtr.Recorder.Filesize)
-				managerVo.RecordDuration = managerPtr.Recorder.Duration
-				managerVo.RecordDurationStr = util.FormatDuration(managerPtr.Recorder.Duration)
+			if rec := managerPtr.Recorder; managerPtr.RecordStatus == 1 && rec != nil {
+				if stats := rec.Stats(); stats.File != "" {
+					managerVo.RecordFile = stats.File
+					managerVo.RecordSize = stats.Filesize
+					managerVo.RecordSizeStr = util.FormatFilesize(stats.Filesize)
+					managerVo.RecordDuration = stats.Duration
+					managerVo.RecordDurationStr = util.FormatDuration(stats.Duration)
+					managerVo.RecordLine = stats.Line
+					managerVo.RecordHealth = toHealthVO(stats.Health)
+				}
 			}
 		}
 		respList[i] = *managerVo
@@ -274,3 +657,89 @@ func (m *MonitorService) GetManagerList() ([]vo.ManagerVO, error) {
 
 	return respList, nil
 }
+
+// toHealthVO 复制录制引擎的健康指标，接口返回的结构不依赖录制包
+func toHealthVO(health recorder.HealthStats) *vo.HealthVO {
+	healthVo := &vo.HealthVO{
+		StartTime:      health.StartTime,
+		Bitrate:        health.Bitrate,
+		AvgBitrate:     health.AvgBitrate,
+		FfmpegBitrate:  health.FfmpegBitrate,
+		Fps:            health.Fps,
+		AvgFps:         health.AvgFps,
+		Speed:          health.Speed,
+		GapCount:       health.GapCount,
+		StallCount:     health.StallCount,
+		URLSwitchCount: health.URLSwitchCount,
+	}
+	for _, line := range health.Lines {
+		healthVo.Lines = append(healthVo.Lines, vo.LineStatsVO(line))
+	}
+	for _, sample := range health.Samples {
+		healthVo.Samples = append(healthVo.Samples, vo.HealthSampleVO(sample))
+	}
+	return healthVo
+}
+
+// GetSession 获取开播记录及断流区间
+func (m *MonitorService) GetSession(sessionId int64) (*vo.SessionVO, error) {
+	session, err := m.sessionRepo.GetSessionById(sessionId)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, errors.New("开播记录不存在")
+	}
+	gaps, err := m.sessionRepo.ListGaps(sessionId)
+	if err != nil {
+		return nil, err
+	}
+	sessionVo := &vo.SessionVO{
+		ID:        session.ID,
+		RoomID:    session.RoomID,
+		OpenTime:  session.OpenTime,
+		StartTime: session.StartTime,
+		EndTime:   session.EndTime,
+		Sequence:  session.Sequence,
+		Gaps:      make([]vo.SessionGapVO, len(gaps)),
+	}
+	for i, gap := range gaps {
+		sessionVo.Gaps[i] = vo.SessionGapVO{StartTime: gap.StartTime, EndTime: gap.EndTime}
+	}
+	return sessionVo, nil
+}
+
+// ListTransitions 获取一次开播期间 Manager 的状态变更记录
+func (m *MonitorService) ListTransitions(sessionId int64) ([]vo.TransitionVO, error) {
+	transitions, err := m.sessionRepo.ListTransitions(sessionId)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]vo.TransitionVO, len(transitions))
+	for i, transition := range transitions {
+		list[i] = vo.TransitionVO{
+			ID:     transition.ID,
+			From:   transition.FromState,
+			To:     transition.ToState,
+			Reason: transition.Reason,
+			Time:   transition.Time,
+		}
+	}
+	return list, nil
+}
+
+// GetPlatformStatus 获取各平台熔断状态和轮询退避状态
+func (m *MonitorService) GetPlatformStatus() *vo.PlatformStatusVO {
+	status := &vo.PlatformStatusVO{
+		Breakers: fetcher.Breakers(),
+	}
+	if until := m.scheduler.BackoffUntil(); time.Now().Before(until) {
+		status.MonitorBackoffUntil = &until
+	}
+	return status
+}
+
+// GetLineScores 获取各平台 CDN 线路评分
+func (m *MonitorService) GetLineScores() []lineprobe.Score {
+	return lineprobe.Default().List()
+}
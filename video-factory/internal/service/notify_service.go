@@ -0,0 +1,427 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"video-factory/internal/domain/model"
+	"video-factory/internal/domain/vo"
+	"video-factory/internal/notify"
+	"video-factory/internal/repository"
+	"video-factory/pkg/config"
+	"video-factory/pkg/util"
+
+	"github.com/rs/zerolog/log"
+)
+
+// notifySendTimeout 单条通知的发送超时时间
+const notifySendTimeout = 30 * time.Second
+
+// NotifyService 按订阅将开播、录制异常、磁盘空间不足等事件推送到通知渠道
+type NotifyService struct {
+	config      *config.AppConfig
+	channelRepo *repository.NotifyChannelRepository
+	subRepo     *repository.NotifySubscriptionRepository
+	roomRepo    *repository.RoomRepository
+	limiter     *notify.Limiter
+	diskLow     bool // 上次检查时磁盘空间是否不足，只在状态变化时通知
+}
+
+func NewNotifyService(config *config.AppConfig, channelRepo *repository.NotifyChannelRepository,
+	subRepo *repository.NotifySubscriptionRepository, roomRepo *repository.RoomRepository) *NotifyService {
+	return &NotifyService{
+		config:      config,
+		channelRepo: channelRepo,
+		subRepo:     subRepo,
+		roomRepo:    roomRepo,
+		limiter:     notify.NewLimiter(),
+	}
+}
+
+// Run 每分钟检查一次录制目录的剩余空间
+func (s *NotifyService) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkDisk()
+		}
+	}
+}
+
+// OnManagerStart 开播通知，在刷新房间信息之后注册以使用最新标题
+// 断流后续接上一场时已通知过开播，不再重复发送
+func (s *NotifyService) OnManagerStart(room *model.Room, sessionId int64, resumed bool) {
+	if resumed {
+		return
+	}
+	s.Notify(notify.EventLive, room.ID, roomVars(room))
+}
+
+// OnRecordFailed 录制异常通知
+func (s *NotifyService) OnRecordFailed(room *model.Room, err error) {
+	vars := roomVars(room)
+	vars.Error = err.Error()
+	s.Notify(notify.EventRecordFailed, room.ID, vars)
+}
+
+// Notify 异步发送给订阅了该事件的渠道，roomId 为 0 时为全局事件
+func (s *NotifyService) Notify(event string, roomId int64, vars notify.Vars) {
+	vars.Event = event
+	if vars.Time.IsZero() {
+		vars.Time = time.Now()
+	}
+	go s.dispatch(event, roomId, vars)
+}
+
+func (s *NotifyService) dispatch(event string, roomId int64, vars notify.Vars) {
+	subs, err := s.subRepo.ListMatched(roomId, event)
+	if err != nil {
+		log.Err(err).Str("event", event).Msg("[Notify] 获取订阅失败")
+		return
+	}
+	minInterval, maxPerHour := s.limits()
+	sent := make(map[int64]bool, len(subs))
+	for _, sub := range subs {
+		// 同时订阅了所有房间和单个房间时只发送一次
+		if sent[sub.ChannelID] {
+			continue
+		}
+		sent[sub.ChannelID] = true
+
+		ch, err := s.channelRepo.GetChannelById(sub.ChannelID)
+		if err != nil || ch == nil || ch.Enabled != 1 {
+			continue
+		}
+		key := fmt.Sprintf("%d:%d:%s", ch.ID, roomId, event)
+		if !s.limiter.Allow(ch.ID, key, time.Now(), minInterval, maxPerHour) {
+			log.Debug().Int64("channelId", ch.ID).Int64("roomId", roomId).Str("event", event).Msg("[Notify] 通知被限流")
+			continue
+		}
+		if err := s.send(ch, vars); err != nil {
+			log.Err(err).Int64("channelId", ch.ID).Str("event", event).Msg("[Notify] 发送通知失败")
+		}
+	}
+}
+
+// send 渲染渠道的模板并发送
+func (s *NotifyService) send(ch *model.NotifyChannel, vars notify.Vars) error {
+	channel, err := notify.New(ch.Type, ch.Config)
+	if err != nil {
+		return err
+	}
+	msg, err := notify.Render(decodeTemplates(ch.Templates)[vars.Event], vars)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifySendTimeout)
+	defer cancel()
+	return channel.Send(ctx, msg)
+}
+
+func (s *NotifyService) limits() (time.Duration, int) {
+	if s.config.Notify == nil {
+		return 0, 0
+	}
+	return time.Duration(s.config.Notify.MinInterval) * time.Second, s.config.Notify.MaxPerHour
+}
+
+// checkDisk 剩余空间低于阈值时通知一次，恢复后再次低于阈值时重新通知
+func (s *NotifyService) checkDisk() {
+	if s.config.Notify == nil || s.config.Notify.DiskLowGB <= 0 {
+		s.diskLow = false
+		return
+	}
+	dir := "."
+	if s.config.Recorder != nil && s.config.Recorder.OutputDir != "" {
+		dir = s.config.Recorder.OutputDir
+	}
+	free, err := notify.FreeSpace(dir)
+	if err != nil {
+		log.Warn().Err(err).Str("dir", dir).Msg("[Notify] 获取磁盘剩余空间失败")
+		return
+	}
+	low := free < uint64(s.config.Notify.DiskLowGB)<<30
+	if low && !s.diskLow {
+		log.Warn().Str("dir", dir).Uint64("free", free).Msg("[Notify] 磁盘剩余空间不足")
+		s.Notify(notify.EventDiskLow, 0, notify.Vars{Dir: dir, Free: util.FormatFilesize(int(free))})
+	}
+	s.diskLow = low
+}
+
+// ListChannels 获取通知渠道列表，密钥已脱敏
+func (s *NotifyService) ListChannels() ([]vo.NotifyChannelVO, error) {
+	channels, err := s.channelRepo.ListChannels()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]vo.NotifyChannelVO, 0, len(channels))
+	for i := range channels {
+		list = append(list, *toNotifyChannelVO(&channels[i]))
+	}
+	return list, nil
+}
+
+// AddChannel 添加通知渠道，保存前校验配置与模板
+func (s *NotifyService) AddChannel(name string, channelType string, cfg map[string]any,
+	templates map[string]string) (*vo.NotifyChannelVO, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("名称不能为空")
+	}
+	raw, err := checkChannelConfig(channelType, cfg)
+	if err != nil {
+		return nil, err
+	}
+	rawTemplates, err := checkTemplates(templates)
+	if err != nil {
+		return nil, err
+	}
+	ch := &model.NotifyChannel{
+		ID:        util.MustNextID(),
+		Name:      name,
+		Type:      channelType,
+		Config:    raw,
+		Templates: rawTemplates,
+		Enabled:   1,
+	}
+	if err := s.channelRepo.AddChannel(ch); err != nil {
+		return nil, err
+	}
+	return toNotifyChannelVO(ch), nil
+}
+
+// UpdateChannel 更新通知渠道，密钥为占位符时保留原值
+func (s *NotifyService) UpdateChannel(idStr string, name string, cfg map[string]any,
+	templates map[string]string, enabled *int) error {
+	ch, err := s.getChannel(idStr)
+	if err != nil {
+		return err
+	}
+	updateMap := map[string]any{}
+	if cfg != nil {
+		keepSecrets(cfg, ch.Config)
+		raw, err := checkChannelConfig(ch.Type, cfg)
+		if err != nil {
+			return err
+		}
+		updateMap["config"] = raw
+	}
+	if templates != nil {
+		raw, err := checkTemplates(templates)
+		if err != nil {
+			return err
+		}
+		updateMap["templates"] = raw
+	}
+	if strings.TrimSpace(name) != "" {
+		updateMap["name"] = name
+	}
+	if enabled != nil {
+		updateMap["enabled"] = *enabled
+	}
+	if len(updateMap) == 0 {
+		return nil
+	}
+	return s.channelRepo.UpdateChannelById(ch.ID, updateMap)
+}
+
+// RemoveChannel 删除通知渠道及其订阅
+func (s *NotifyService) RemoveChannel(idStr string) error {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return errors.New("渠道 id 格式有误")
+	}
+	return s.channelRepo.RemoveChannel(id)
+}
+
+// TestChannel 发送一条测试消息，不受限流影响
+func (s *NotifyService) TestChannel(idStr string, event string) error {
+	ch, err := s.getChannel(idStr)
+	if err != nil {
+		return err
+	}
+	if event == "" {
+		event = notify.EventLive
+	}
+	if !slices.Contains(notify.Events, event) {
+		return fmt.Errorf("不支持的事件: %s", event)
+	}
+	return s.send(ch, notify.Vars{
+		Event:    event,
+		Platform: "bili",
+		Anchor:   "测试主播",
+		Title:    "测试标题",
+		URL:      "https://live.bilibili.com/1",
+		Error:    "测试错误",
+		Dir:      "/record",
+		Free:     "1 GB",
+		Time:     time.Now(),
+	})
+}
+
+// ListSubscriptions 获取订阅列表，channelId 为 0 时返回全部
+func (s *NotifyService) ListSubscriptions(channelId int64) ([]vo.NotifySubscriptionVO, error) {
+	subs, err := s.subRepo.ListSubscriptions(channelId)
+	if err != nil {
+		return nil, err
+	}
+	channels, err := s.channelRepo.ListChannels()
+	if err != nil {
+		return nil, err
+	}
+	channelNames := make(map[int64]string, len(channels))
+	for _, ch := range channels {
+		channelNames[ch.ID] = ch.Name
+	}
+	rooms, err := s.roomRepo.ListRooms()
+	if err != nil {
+		return nil, err
+	}
+	anchorNames := make(map[int64]string, len(rooms))
+	for _, room := range rooms {
+		anchorNames[room.ID] = room.AnchorName
+	}
+	list := make([]vo.NotifySubscriptionVO, 0, len(subs))
+	for _, sub := range subs {
+		list = append(list, vo.NotifySubscriptionVO{
+			ID:          sub.ID,
+			ChannelID:   sub.ChannelID,
+			ChannelName: channelNames[sub.ChannelID],
+			RoomID:      sub.RoomID,
+			AnchorName:  anchorNames[sub.RoomID],
+			Events:      decodeTags(sub.Events),
+		})
+	}
+	return list, nil
+}
+
+// SaveSubscription 保存渠道对房间的订阅，每个渠道每个房间只有一条，roomId 为 0 时订阅所有房间
+func (s *NotifyService) SaveSubscription(channelIdStr string, roomIdStr string, events []string) error {
+	ch, err := s.getChannel(channelIdStr)
+	if err != nil {
+		return err
+	}
+	roomId, err := strconv.ParseInt(roomIdStr, 10, 64)
+	if err != nil {
+		return errors.New("房间 id 格式有误")
+	}
+	if roomId != 0 {
+		room, err := s.roomRepo.GetRoomById(roomId)
+		if err != nil {
+			return err
+		}
+		if room == nil {
+			return errors.New("房间不存在")
+		}
+	}
+	var valid []string
+	for _, event := range events {
+		if !slices.Contains(notify.Events, event) {
+			return fmt.Errorf("不支持的事件: %s", event)
+		}
+		if !slices.Contains(valid, event) {
+			valid = append(valid, event)
+		}
+	}
+	if len(valid) == 0 {
+		return errors.New("至少订阅一个事件")
+	}
+
+	sub, err := s.subRepo.GetSubscription(ch.ID, roomId)
+	if err != nil {
+		return err
+	}
+	if sub == nil {
+		sub = &model.NotifySubscription{ID: util.MustNextID(), ChannelID: ch.ID, RoomID: roomId}
+	}
+	sub.Events = encodeTags(valid)
+	return s.subRepo.SaveSubscription(sub)
+}
+
+// RemoveSubscription 删除订阅
+func (s *NotifyService) RemoveSubscription(idStr string) error {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return errors.New("订阅 id 格式有误")
+	}
+	return s.subRepo.RemoveSubscription(id)
+}
+
+func (s *NotifyService) getChannel(idStr string) (*model.NotifyChannel, error) {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return nil, errors.New("渠道 id 格式有误")
+	}
+	ch, err := s.channelRepo.GetChannelById(id)
+	if err != nil {
+		return nil, err
+	}
+	if ch == nil {
+		return nil, errors.New("渠道不存在")
+	}
+	return ch, nil
+}
+
+func roomVars(room *model.Room) notify.Vars {
+	return notify.Vars{
+		RoomID:   room.ID,
+		Platform: room.Platform,
+		Anchor:   room.AnchorName,
+		Title:    room.Name,
+		URL:      room.URL,
+	}
+}
+
+// checkChannelConfig 创建一次渠道以校验配置，返回保存用的 JSON
+func checkChannelConfig(channelType string, cfg map[string]any) (string, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	if _, err := notify.New(channelType, string(data)); err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// checkTemplates 校验模板，忽略空模板与未知事件
+func checkTemplates(templates map[string]string) (string, error) {
+	valid := make(map[string]string, len(templates))
+	for event, tmpl := range templates {
+		if strings.TrimSpace(tmpl) == "" || !slices.Contains(notify.Events, event) {
+			continue
+		}
+		if err := notify.CheckTemplate(tmpl); err != nil {
+			return "", fmt.Errorf("[%s] %w", event, err)
+		}
+		valid[event] = tmpl
+	}
+	data, err := json.Marshal(valid)
+	return string(data), err
+}
+
+func decodeTemplates(raw string) map[string]string {
+	templates := map[string]string{}
+	_ = json.Unmarshal([]byte(raw), &templates)
+	return templates
+}
+
+func toNotifyChannelVO(ch *model.NotifyChannel) *vo.NotifyChannelVO {
+	return &vo.NotifyChannelVO{
+		ID:         ch.ID,
+		Name:       ch.Name,
+		Type:       ch.Type,
+		Config:     maskSecrets(ch.Config),
+		Templates:  decodeTemplates(ch.Templates),
+		Enabled:    ch.Enabled,
+		CreateTime: util.MillisToTime(ch.CreateTime),
+	}
+}
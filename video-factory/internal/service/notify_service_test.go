@@ -0,0 +1,37 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"video-factory/pkg/config"
+)
+
+func TestCheckTemplates(t *testing.T) {
+	raw, err := checkTemplates(map[string]string{
+		"live":     "{{.Anchor}} 开播\n{{.Title}}",
+		"unknown":  "{{.Anchor}}",
+		"disk_low": " ",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := decodeTemplates(raw)
+	if !reflect.DeepEqual(got, map[string]string{"live": "{{.Anchor}} 开播\n{{.Title}}"}) {
+		t.Errorf("checkTemplates() = %v", got)
+	}
+	if _, err := checkTemplates(map[string]string{"live": "{{.Nope}}"}); err == nil {
+		t.Error("unknown field should fail")
+	}
+}
+
+func TestSecrets(t *testing.T) {
+	masked := maskSecrets(`{"token":"abcdefgh","chat_id":"1","password":""}`)
+	if masked["token"] != config.MaskSecret("abcdefgh") || masked["chat_id"] != "1" || masked["password"] != "" {
+		t.Errorf("maskSecrets() = %v", masked)
+	}
+	cfg := map[string]any{"token": masked["token"], "chat_id": "2"}
+	keepSecrets(cfg, `{"token":"abcdefgh","chat_id":"1"}`)
+	if cfg["token"] != "abcdefgh" || cfg["chat_id"] != "2" {
+		t.Errorf("keepSecrets() = %v", cfg)
+	}
+}
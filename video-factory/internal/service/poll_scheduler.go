@@ -0,0 +1,177 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// historyTTL 开播历史缓存时间，过期后重新从数据库加载
+	historyTTL = 1 * time.Hour
+	// rareLiveThreshold 超过该时长未开播的房间视为很少开播
+	rareLiveThreshold = 14 * 24 * time.Hour
+	// nearOpenBefore / nearOpenAfter 常规开播时间附近的加速窗口
+	nearOpenBefore = 30 * time.Minute
+	nearOpenAfter  = 60 * time.Minute
+	// 限流退避的初始值与上限
+	backoffBase = 30 * time.Second
+	backoffMax  = 10 * time.Minute
+)
+
+// pollIntervals 轮询间隔配置
+type pollIntervals struct {
+	Base time.Duration
+	Min  time.Duration
+	Max  time.Duration
+}
+
+type pollEntry struct {
+	nextCheck       time.Time
+	openTimes       []int64 // 历史开播时间，秒
+	historyLoadedAt time.Time
+}
+
+// PollScheduler 为每个房间计算下一次检查开播状态的时间，并维护全局限流退避状态
+type PollScheduler struct {
+	mu           sync.Mutex
+	entries      map[int64]*pollEntry
+	backoffUntil time.Time
+	backoffLevel int
+}
+
+func NewPollScheduler() *PollScheduler {
+	return &PollScheduler{
+		entries: make(map[int64]*pollEntry),
+	}
+}
+
+func (p *PollScheduler) entry(roomId int64) *pollEntry {
+	e, ok := p.entries[roomId]
+	if !ok {
+		e = &pollEntry{}
+		p.entries[roomId] = e
+	}
+	return e
+}
+
+// IsDue 房间是否到了检查时间，从未检查过的房间立即检查
+func (p *PollScheduler) IsDue(roomId int64, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !now.Before(p.entry(roomId).nextCheck)
+}
+
+// NeedHistory 开播历史是否需要重新加载
+func (p *PollScheduler) NeedHistory(roomId int64, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e := p.entry(roomId)
+	return e.historyLoadedAt.IsZero() || now.Sub(e.historyLoadedAt) > historyTTL
+}
+
+// SetHistory 设置房间的历史开播时间
+func (p *PollScheduler) SetHistory(roomId int64, openTimes []int64, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e := p.entry(roomId)
+	e.openTimes = openTimes
+	e.historyLoadedAt = now
+}
+
+// Schedule 完成一次检查后安排下一次检查，返回本次使用的间隔
+func (p *PollScheduler) Schedule(roomId int64, now time.Time, intervals pollIntervals) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e := p.entry(roomId)
+	interval := computeInterval(e.openTimes, now, intervals)
+	e.nextCheck = now.Add(interval)
+	return interval
+}
+
+// NextCheck 房间下一次检查时间
+func (p *PollScheduler) NextCheck(roomId int64) time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.entry(roomId).nextCheck
+}
+
+// Forget 清除房间的调度状态，下次扫描时立即检查并重新加载历史
+func (p *PollScheduler) Forget(roomId int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.entries, roomId)
+}
+
+// Backoff 触发限流退避，连续触发时指数增长，返回退避时长
+func (p *PollScheduler) Backoff(now time.Time) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// 退避期间的重复触发不再叠加
+	if now.Before(p.backoffUntil) {
+		return p.backoffUntil.Sub(now)
+	}
+	wait := backoffBase << p.backoffLevel
+	if wait > backoffMax || wait <= 0 {
+		wait = backoffMax
+	} else {
+		p.backoffLevel++
+	}
+	p.backoffUntil = now.Add(wait)
+	return wait
+}
+
+// ResetBackoff 请求恢复正常后重置退避等级
+func (p *PollScheduler) ResetBackoff() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.backoffLevel = 0
+}
+
+// BackoffUntil 退避截止时间
+func (p *PollScheduler) BackoffUntil() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.backoffUntil
+}
+
+// computeInterval 根据开播历史计算轮询间隔
+//  1. 没有历史：基础间隔
+//  2. 长时间未开播：最大间隔
+//  3. 当前时间临近历史上任意一次的开播时刻（按一天中的时刻比较）：最小间隔
+//  4. 其他：基础间隔
+func computeInterval(openTimes []int64, now time.Time, intervals pollIntervals) time.Duration {
+	if len(openTimes) == 0 {
+		return intervals.Base
+	}
+
+	var latest int64
+	for _, t := range openTimes {
+		if t > latest {
+			latest = t
+		}
+	}
+	if now.Sub(time.Unix(latest, 0)) > rareLiveThreshold {
+		return intervals.Max
+	}
+
+	const day = 24 * time.Hour
+	nowOfDay := sinceMidnight(now)
+	for _, t := range openTimes {
+		openOfDay := sinceMidnight(time.Unix(t, 0).In(now.Location()))
+		// diff > 0 表示当前时间在常规开播时刻之后
+		diff := nowOfDay - openOfDay
+		if diff > day/2 {
+			diff -= day
+		} else if diff < -day/2 {
+			diff += day
+		}
+		if diff >= -nearOpenBefore && diff <= nearOpenAfter {
+			return intervals.Min
+		}
+	}
+	return intervals.Base
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+var testIntervals = pollIntervals{
+	Base: 60 * time.Second,
+	Min:  15 * time.Second,
+	Max:  300 * time.Second,
+}
+
+func TestComputeInterval(t *testing.T) {
+	now := time.Date(2026, 1, 10, 20, 0, 0, 0, time.Local)
+	at := func(daysAgo int, hour, minute int) int64 {
+		return time.Date(2026, 1, 10-daysAgo, hour, minute, 0, 0, time.Local).Unix()
+	}
+
+	cases := []struct {
+		name      string
+		openTimes []int64
+		want      time.Duration
+	}{
+		{"no history", nil, testIntervals.Base},
+		{"rarely live", []int64{at(30, 20, 0)}, testIntervals.Max},
+		{"usual start soon", []int64{at(1, 20, 20), at(2, 20, 25)}, testIntervals.Min},
+		{"usual start just passed", []int64{at(1, 19, 10)}, testIntervals.Min},
+		{"far from usual start", []int64{at(1, 10, 0), at(3, 11, 0)}, testIntervals.Base},
+		{"usual start across midnight", []int64{at(1, 0, 10)}, testIntervals.Base},
+	}
+	for _, c := range cases {
+		if got := computeInterval(c.openTimes, now, testIntervals); got != c.want {
+			t.Errorf("%s: computeInterval = %s, want %s", c.name, got, c.want)
+		}
+	}
+
+	midnight := time.Date(2026, 1, 10, 23, 50, 0, 0, time.Local)
+	if got := computeInterval([]int64{at(1, 0, 10)}, midnight, testIntervals); got != testIntervals.Min {
+		t.Errorf("across midnight: computeInterval = %s, want %s", got, testIntervals.Min)
+	}
+}
+
+func TestPollScheduler_Schedule(t *testing.T) {
+	p := NewPollScheduler()
+	now := time.Now()
+
+	if !p.IsDue(1, now) {
+		t.Fatal("unchecked room should be due")
+	}
+	p.Schedule(1, now, testIntervals)
+	if p.IsDue(1, now.Add(30*time.Second)) {
+		t.Fatal("room should not be due before interval")
+	}
+	if !p.IsDue(1, now.Add(testIntervals.Base)) {
+		t.Fatal("room should be due after interval")
+	}
+
+	p.Forget(1)
+	if !p.IsDue(1, now) || !p.NeedHistory(1, now) {
+		t.Fatal("forgotten room should be due and reload history")
+	}
+}
+
+func TestPollScheduler_Backoff(t *testing.T) {
+	p := NewPollScheduler()
+	now := time.Now()
+
+	if wait := p.Backoff(now); wait != backoffBase {
+		t.Fatalf("first backoff = %s, want %s", wait, backoffBase)
+	}
+	// 退避期间重复触发不叠加
+	if wait := p.Backoff(now.Add(time.Second)); wait != backoffBase-time.Second {
+		t.Fatalf("repeated backoff = %s", wait)
+	}
+	if wait := p.Backoff(now.Add(backoffBase)); wait != 2*backoffBase {
+		t.Fatalf("second backoff = %s, want %s", wait, 2*backoffBase)
+	}
+
+	next := now.Add(time.Hour)
+	for i := 0; i < 10; i++ {
+		next = next.Add(p.Backoff(next))
+	}
+	if wait := p.Backoff(next); wait != backoffMax {
+		t.Fatalf("backoff should be capped at %s, got %s", backoffMax, wait)
+	}
+
+	p.ResetBackoff()
+	if wait := p.Backoff(next.Add(backoffMax)); wait != backoffBase {
+		t.Fatalf("backoff after reset = %s, want %s", wait, backoffBase)
+	}
+}
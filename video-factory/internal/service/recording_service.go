@@ -0,0 +1,127 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"video-factory/internal/domain/model"
+	"video-factory/internal/domain/vo"
+	"video-factory/internal/repository"
+	"video-factory/pkg/util"
+
+	"github.com/rs/zerolog/log"
+)
+
+type RecordingService struct {
+	recordRepo *repository.RecordingRepository
+}
+
+func NewRecordingService(recordRepo *repository.RecordingRepository) *RecordingService {
+	return &RecordingService{
+		recordRepo: recordRepo,
+	}
+}
+
+// ListRecordings 获取录制记录列表，不含采样序列
+func (r *RecordingService) ListRecordings(roomId int64, limit int) ([]vo.RecordingVO, error) {
+	recordings, err := r.recordRepo.ListRecordings(roomId, limit)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]vo.RecordingVO, 0, len(recordings))
+	for i := range recordings {
+		list = append(list, *toRecordingVO(&recordings[i]))
+	}
+	return list, nil
+}
+
+// GetRecording 获取录制记录详情，包含采样序列
+func (r *RecordingService) GetRecording(id int64) (*vo.RecordingVO, error) {
+	if id == 0 {
+		return nil, errors.New("id 为空")
+	}
+	recording, err := r.recordRepo.GetRecordingById(id)
+	if err != nil {
+		return nil, err
+	}
+	if recording == nil {
+		return nil, errors.New("录制记录不存在")
+	}
+	return toRecordingVO(recording), nil
+}
+
+func toRecordingVO(recording *model.Recording) *vo.RecordingVO {
+	recordingVo := &vo.RecordingVO{
+		ID:             recording.ID,
+		RoomID:         recording.RoomID,
+		SessionID:      recording.SessionID,
+		Filename:       recording.Filename,
+		Filesize:       recording.Filesize,
+		FilesizeStr:    util.FormatFilesize(int(recording.Filesize)),
+		Duration:       recording.Duration,
+		DurationStr:    util.FormatDuration(recording.Duration),
+		StartTime:      util.MillisToTime(recording.StartTime),
+		EndTime:        util.MillisToTime(recording.EndTime),
+		AvgBitrate:     recording.AvgBitrate,
+		AvgFps:         recording.AvgFps,
+		GapCount:       recording.GapCount,
+		StallCount:     recording.StallCount,
+		URLSwitchCount: recording.URLSwitchCount,
+		ThumbStatus:    recording.ThumbStatus,
+	}
+	if recording.Lines != "" {
+		if err := json.Unmarshal([]byte(recording.Lines), &recordingVo.Lines); err != nil {
+			log.Err(err).Int64("id", recording.ID).Msg("解析线路统计失败")
+		}
+	}
+	if recording.Samples != "" {
+		if err := json.Unmarshal([]byte(recording.Samples), &recordingVo.Samples); err != nil {
+			log.Err(err).Int64("id", recording.ID).Msg("解析健康采样失败")
+		}
+	}
+	var thumbs []string
+	if recording.Thumbnails != "" {
+		if err := json.Unmarshal([]byte(recording.Thumbnails), &thumbs); err != nil {
+			log.Err(err).Int64("id", recording.ID).Msg("解析缩略图失败")
+		}
+	}
+	recordingVo.ThumbnailURLs = make([]string, 0, len(thumbs))
+	for i := range thumbs {
+		recordingVo.ThumbnailURLs = append(recordingVo.ThumbnailURLs, fmt.Sprintf("/api/v1/recording/%d/thumbnail/%d", recording.ID, i))
+	}
+	if recording.ContactSheet != "" {
+		recordingVo.ContactSheet = fmt.Sprintf("/api/v1/recording/%d/sheet", recording.ID)
+	}
+	return recordingVo
+}
+
+// GetThumbnailPath 获取第 index 张缩略图的文件路径
+func (r *RecordingService) GetThumbnailPath(id int64, index int) (string, error) {
+	recording, err := r.recordRepo.GetRecordingById(id)
+	if err != nil {
+		return "", err
+	}
+	if recording == nil || recording.Thumbnails == "" {
+		return "", errors.New("缩略图不存在")
+	}
+	var thumbs []string
+	if err := json.Unmarshal([]byte(recording.Thumbnails), &thumbs); err != nil {
+		return "", err
+	}
+	if index < 0 || index >= len(thumbs) {
+		return "", errors.New("缩略图不存在")
+	}
+	return thumbs[index], nil
+}
+
+// GetContactSheetPath 获取拼图的文件路径
+func (r *RecordingService) GetContactSheetPath(id int64) (string, error) {
+	recording, err := r.recordRepo.GetRecordingById(id)
+	if err != nil {
+		return "", err
+	}
+	if recording == nil || recording.ContactSheet == "" {
+		return "", errors.New("拼图不存在")
+	}
+	return recording.ContactSheet, nil
+}
@@ -3,16 +3,21 @@ package service
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strconv"
-	"sync"
+	"strings"
 	"time"
+	"unicode/utf8"
 	"video-factory/internal/common/consts"
 	"video-factory/internal/domain/model"
 	"video-factory/internal/domain/vo"
+	"video-factory/internal/recorder"
 	"video-factory/internal/repository"
 	"video-factory/internal/site/bili"
 	"video-factory/internal/site/missevan"
 	"video-factory/pkg/config"
+	"video-factory/pkg/logger"
 	"video-factory/pkg/pool"
 	"video-factory/pkg/util"
 
@@ -95,6 +100,7 @@ func (r *RoomService) AddRoom(roomInput string, platform string, config *config.
 		AnchorName:   roomAddVO.AnchorName,
 		AnchorAvatar: roomAddVO.AnchorAvatar,
 		Status:       0,
+		RecordMode:   consts.RecordModeVideo,
 		CreateTime:   time.Now().UnixMilli(),
 		UpdateTime:   time.Now().UnixMilli(),
 	}
@@ -113,87 +119,148 @@ func (r *RoomService) CheckRoomExist(realId string) (*model.Room, error) {
 	return room, nil
 }
 
-func (r *RoomService) ListRooms() ([]vo.RoomVO, error) {
-	rooms, err := r.roomRepo.ListRooms()
+// ListRooms 按条件查询房间，返回当前页与总数
+// 开播状态取自监控的轮询结果，按开播状态过滤时在内存中分页
+func (r *RoomService) ListRooms(query *vo.RoomQueryVO) ([]vo.RoomVO, int64, error) {
+	filter, err := toRoomFilter(query)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+	if query.LiveStatus == nil {
+		rooms, total, err := r.roomRepo.QueryRooms(filter, query.Page, query.PageSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		respList := make([]vo.RoomVO, len(rooms))
+		for i := range rooms {
+			respList[i] = *r.toRoomVO(&rooms[i])
+		}
+		return respList, total, nil
 	}
 
-	var wg sync.WaitGroup
-	var statusMutex sync.Mutex
-	statusMap := make(map[int64]int, len(rooms))
+	rooms, _, err := r.roomRepo.QueryRooms(filter, 0, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	respList := make([]vo.RoomVO, 0, len(rooms))
+	for i := range rooms {
+		roomVo := r.toRoomVO(&rooms[i])
+		if roomVo.LiveStatus == *query.LiveStatus {
+			respList = append(respList, *roomVo)
+		}
+	}
+	return util.Paginate(respList, query.Page, query.PageSize), int64(len(respList)), nil
+}
 
+// ListFacets 所有分组与标签及其房间数，按房间数倒序
+func (r *RoomService) ListFacets() ([]vo.RoomFacetVO, []vo.RoomFacetVO, error) {
+	rooms, err := r.roomRepo.ListRooms()
+	if err != nil {
+		return nil, nil, err
+	}
+	groupCount := make(map[string]int)
+	tagCount := make(map[string]int)
 	for _, room := range rooms {
-		wg.Add(1) // 增加计数器
-		go func(room *model.Room) {
-			defer wg.Done()
-			// 调用接口获取实时状态
-			status, statusErr := r.GetRoomLiveStatus(room)
-			if statusErr != nil {
-				log.Warn().Msgf("警告: 获取房间[%d]直播状态失败: %v\n", room.ID, statusErr)
-				// 失败默认离线
-				status = 0
-			}
-			statusMutex.Lock()
-			statusMap[room.ID] = status
-			statusMutex.Unlock()
-		}(&room)
-	}
-	wg.Wait()
-
-	// poolSnapshot := pool.Snapshot()
-	respList := make([]vo.RoomVO, len(rooms))
-	for i, room := range rooms {
-		// streamStatus := 0
-		// m := poolSnapshot[room.ID]
-		// lastRefreshTime := util.MillisToTime(room.UpdateTime)
-		// if m != nil {
-		// 	streamStatus = 1
-		// 	lastRefreshTime = m.GetLastRefreshTime()
-		// }
-		liveStatus, ok := statusMap[room.ID]
-		if !ok {
-			liveStatus = 0
+		if room.GroupName != "" {
+			groupCount[room.GroupName]++
 		}
-		respList[i] = vo.RoomVO{
-			ID:           strconv.FormatInt(room.ID, 10),
-			Platform:     room.Platform,
-			ShortID:      room.ShortID,
-			RealID:       room.RealID,
-			Name:         room.Name,
-			URL:          room.URL,
-			CoverURL:     room.CoverURL,
-			ProxyURL:     room.ProxyURL,
-			AnchorName:   room.AnchorName,
-			AnchorAvatar: room.AnchorAvatar,
-			LiveStatus:   liveStatus,
-			Status:       room.Status,
-			RecordStatus: room.RecordStatus,
-			CreateTime:   util.MillisToTime(room.CreateTime),
-			UpdateTime:   util.MillisToTime(room.UpdateTime),
+		for _, tag := range decodeTags(room.Tags) {
+			tagCount[tag]++
 		}
 	}
-	return respList, nil
+	return toFacets(groupCount), toFacets(tagCount), nil
 }
 
-func (r *RoomService) RemoveRoom(rid int64) error {
-	// tlxTODO: clear manager by status
-	return r.roomRepo.RemoveRoom(rid)
+// SetRoomLabels 设置房间的分组与标签
+func (r *RoomService) SetRoomLabels(roomIdStr string, group string, tags []string) error {
+	roomId, err := strconv.ParseInt(roomIdStr, 10, 64)
+	if err != nil {
+		return errors.New("入参格式有误")
+	}
+	group = strings.TrimSpace(group)
+	if utf8.RuneCountInString(group) > maxLabelLength {
+		return fmt.Errorf("分组名称不能超过 %d 个字符", maxLabelLength)
+	}
+	normalized, err := normalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	return r.roomRepo.UpdateRoomById(roomId, map[string]any{
+		"group_name": group,
+		"tags":       encodeTags(normalized),
+	})
 }
 
-func (r *RoomService) GetRoom(roomId int64) (*model.Room, error) {
-	if roomId == 0 {
-		return nil, errors.New("roomId 为空")
+// BulkAction 对符合条件的房间批量执行 enable | disable | record_on | record_off
+// 已处于目标状态的房间跳过，单个房间失败不影响其他房间
+func (r *RoomService) BulkAction(query *vo.RoomQueryVO, action string) (*vo.RoomBulkResultVO, error) {
+	op, ok := r.bulkOps()[action]
+	if !ok {
+		return nil, errors.New("不支持的操作")
 	}
-	return r.roomRepo.GetRoomById(roomId)
-}
 
-func (r *RoomService) GetRoomVO(roomId int64) (*vo.RoomVO, error) {
-	room, err := r.GetRoom(roomId)
+	// 批量操作忽略分页，作用于全部符合条件的房间
+	all := *query
+	all.Page, all.PageSize = 0, 0
+	if len(all.RoomIDs) == 0 && isEmptyQuery(&all) {
+		return nil, errors.New("请指定房间或筛选条件")
+	}
+	rooms, _, err := r.ListRooms(&all)
 	if err != nil {
-		log.Err(err)
 		return nil, err
 	}
+
+	result := &vo.RoomBulkResultVO{Matched: len(rooms), Failed: []string{}}
+	for _, roomVo := range rooms {
+		roomId, _ := strconv.ParseInt(roomVo.ID, 10, 64)
+		room, err := r.roomRepo.GetRoomById(roomId)
+		if err != nil || room == nil {
+			result.Failed = append(result.Failed, roomVo.ID)
+			continue
+		}
+		if op.done(room) {
+			continue
+		}
+		if err := op.run(room); err != nil {
+			log.Err(err).Int64("roomId", roomId).Str("action", action).Msg("批量操作房间失败")
+			result.Failed = append(result.Failed, roomVo.ID)
+			continue
+		}
+		result.Changed++
+	}
+	log.Info().Str("action", action).Int("matched", result.Matched).Int("changed", result.Changed).
+		Int("failed", len(result.Failed)).Msg("批量操作房间完成")
+	return result, nil
+}
+
+// bulkOp 批量操作，done 表示房间已处于目标状态
+type bulkOp struct {
+	done func(room *model.Room) bool
+	run  func(room *model.Room) error
+}
+
+func (r *RoomService) bulkOps() map[string]bulkOp {
+	return map[string]bulkOp{
+		"enable": {
+			done: func(room *model.Room) bool { return room.Status == 1 },
+			run:  r.EnableRoom,
+		},
+		"disable": {
+			done: func(room *model.Room) bool { return room.Status == 0 },
+			run:  r.DisableRoom,
+		},
+		"record_on": {
+			done: func(room *model.Room) bool { return room.RecordStatus == 1 },
+			run:  r.EnableRecord,
+		},
+		"record_off": {
+			done: func(room *model.Room) bool { return room.RecordStatus == 0 },
+			run:  r.DisableRecord,
+		},
+	}
+}
+
+func (r *RoomService) toRoomVO(room *model.Room) *vo.RoomVO {
 	return &vo.RoomVO{
 		ID:           strconv.FormatInt(room.ID, 10),
 		Platform:     room.Platform,
@@ -201,16 +268,51 @@ func (r *RoomService) GetRoomVO(roomId int64) (*vo.RoomVO, error) {
 		RealID:       room.RealID,
 		Name:         room.Name,
 		URL:          room.URL,
-		CoverURL:     room.CoverURL,
+		CoverURL:     cachedImageURL(room.CoverCache, room.CoverURL),
 		ProxyURL:     room.ProxyURL,
 		AnchorName:   room.AnchorName,
-		AnchorAvatar: room.AnchorAvatar,
-		// LiveStatus:      liveStatus,
+		AnchorAvatar: cachedImageURL(room.AvatarCache, room.AnchorAvatar),
+		LiveStatus:   r.monitorService.LiveStatus(room.ID),
 		Status:       room.Status,
 		RecordStatus: room.RecordStatus,
+		RecordMode:   room.RecordMode,
+		RecordEngine: room.RecordEngine,
+		Group:        room.GroupName,
+		Tags:         decodeTags(room.Tags),
 		CreateTime:   util.MillisToTime(room.CreateTime),
 		UpdateTime:   util.MillisToTime(room.UpdateTime),
-	}, nil
+	}
+}
+
+func (r *RoomService) RemoveRoom(rid int64) error {
+	// tlxTODO: clear manager by status
+	if err := r.roomRepo.RemoveRoom(rid); err != nil {
+		return err
+	}
+	// 录制中的房间在 Manager 停止时关闭
+	if _, running := r.pool.Get(rid); !running {
+		logger.CloseRoom(rid)
+	}
+	return nil
+}
+
+func (r *RoomService) GetRoom(roomId int64) (*model.Room, error) {
+	if roomId == 0 {
+		return nil, errors.New("roomId 为空")
+	}
+	return r.roomRepo.GetRoomById(roomId)
+}
+
+func (r *RoomService) GetRoomVO(roomId int64) (*vo.RoomVO, error) {
+	room, err := r.GetRoom(roomId)
+	if err != nil {
+		log.Err(err)
+		return nil, err
+	}
+	if room == nil {
+		return nil, errors.New("房间不存在")
+	}
+	return r.toRoomVO(room), nil
 }
 
 func (r *RoomService) GetRoomLiveStatus(room *model.Room) (int, error) {
@@ -391,3 +493,161 @@ func (r *RoomService) DisableRecord(room *model.Room) error {
 
 	return nil
 }
+
+// ChangeRecordMode 修改房间录制模式，正在录制时以新模式重新开始录制
+func (r *RoomService) ChangeRecordMode(roomIdStr string, mode string) error {
+	if roomIdStr == "" {
+		return errors.New("入参为空")
+	}
+	if mode != consts.RecordModeVideo && mode != consts.RecordModeAudio {
+		return errors.New("录制模式有误")
+	}
+	roomId, err := strconv.ParseInt(roomIdStr, 10, 64)
+	if err != nil {
+		log.Err(err).Msgf("入参转换类型失败: %s", roomIdStr)
+		return errors.New("入参格式有误")
+	}
+	room, err := r.roomRepo.GetRoomById(roomId)
+	if err != nil || room == nil {
+		return errors.New("未查询到房间信息")
+	}
+	if room.RecordMode == mode {
+		return errors.New("录制模式与目标模式一致")
+	}
+
+	err = r.roomRepo.UpdateRoomById(room.ID, map[string]any{
+		"record_mode": mode,
+	})
+	if err != nil {
+		return err
+	}
+
+	if managerPtr, ok := r.pool.Get(room.ID); ok {
+		managerPtr.SetRecordMode(mode)
+	}
+
+	return nil
+}
+
+// ChangeRecordEngine 修改房间的录制引擎，为空时使用 ffmpeg，正在录制时以新引擎重新开始录制
+func (r *RoomService) ChangeRecordEngine(roomIdStr string, engine string) error {
+	if roomIdStr == "" {
+		return errors.New("入参为空")
+	}
+	if engine != "" && !slices.Contains(recorder.EngineNames(), engine) {
+		return errors.New("录制引擎不存在")
+	}
+	roomId, err := strconv.ParseInt(roomIdStr, 10, 64)
+	if err != nil {
+		log.Err(err).Msgf("入参转换类型失败: %s", roomIdStr)
+		return errors.New("入参格式有误")
+	}
+	room, err := r.roomRepo.GetRoomById(roomId)
+	if err != nil || room == nil {
+		return errors.New("未查询到房间信息")
+	}
+	if room.RecordEngine == engine {
+		return nil
+	}
+
+	err = r.roomRepo.UpdateRoomById(room.ID, map[string]any{
+		"record_engine": engine,
+	})
+	if err != nil {
+		return err
+	}
+
+	if managerPtr, ok := r.pool.Get(room.ID); ok {
+		managerPtr.SetRecordEngine(engine)
+	}
+
+	return nil
+}
+
+// maxLabelLength 分组名与单个标签的最大长度，maxTags 单个房间最多的标签数
+const (
+	maxLabelLength = 32
+	maxTags        = 20
+)
+
+// toRoomFilter 查询条件转换为数据库过滤条件
+func toRoomFilter(query *vo.RoomQueryVO) (repository.RoomFilter, error) {
+	filter := repository.RoomFilter{
+		Keyword:      strings.TrimSpace(query.Keyword),
+		Platform:     query.Platform,
+		Status:       query.Status,
+		RecordStatus: query.RecordStatus,
+		Group:        query.Group,
+		Tag:          strings.TrimSpace(query.Tag),
+	}
+	for _, idStr := range query.RoomIDs {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("房间 id 格式有误: %s", idStr)
+		}
+		filter.IDs = append(filter.IDs, id)
+	}
+	return filter, nil
+}
+
+// isEmptyQuery 没有任何筛选条件
+func isEmptyQuery(query *vo.RoomQueryVO) bool {
+	return strings.TrimSpace(query.Keyword) == "" && query.Platform == "" && query.Status == nil &&
+		query.RecordStatus == nil && query.LiveStatus == nil && query.Group == "" && strings.TrimSpace(query.Tag) == ""
+}
+
+// normalizeTags 去除空白与重复的标签，标签中不能包含逗号
+func normalizeTags(tags []string) ([]string, error) {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if strings.Contains(tag, ",") {
+			return nil, fmt.Errorf("标签不能包含逗号: %s", tag)
+		}
+		if utf8.RuneCountInString(tag) > maxLabelLength {
+			return nil, fmt.Errorf("标签不能超过 %d 个字符: %s", maxLabelLength, tag)
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	if len(result) > maxTags {
+		return nil, fmt.Errorf("标签不能超过 %d 个", maxTags)
+	}
+	return result, nil
+}
+
+// encodeTags 保存为 ,a,b, 形式，按标签查询时匹配 %,tag,%
+func encodeTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return "," + strings.Join(tags, ",") + ","
+}
+
+func decodeTags(tags string) []string {
+	result := []string{}
+	for _, tag := range strings.Split(tags, ",") {
+		if tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+func toFacets(counts map[string]int) []vo.RoomFacetVO {
+	facets := make([]vo.RoomFacetVO, 0, len(counts))
+	for name, count := range counts {
+		facets = append(facets, vo.RoomFacetVO{Name: name, Count: count})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Name < facets[j].Name
+	})
+	return facets
+}
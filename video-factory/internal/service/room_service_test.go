@@ -0,0 +1,91 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"video-factory/internal/domain/model"
+	"video-factory/internal/repository"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := normalizeTags([]string{" 唱歌 ", "", "游戏", "唱歌"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags, []string{"唱歌", "游戏"}) {
+		t.Errorf("normalizeTags() = %v", tags)
+	}
+
+	if _, err := normalizeTags([]string{"a,b"}); err == nil {
+		t.Error("tag with comma should fail")
+	}
+	if _, err := normalizeTags([]string{strings.Repeat("长", maxLabelLength+1)}); err == nil {
+		t.Error("long tag should fail")
+	}
+}
+
+func TestEncodeTags(t *testing.T) {
+	encoded := encodeTags([]string{"唱歌", "游戏"})
+	if encoded != ",唱歌,游戏," {
+		t.Errorf("encodeTags() = %q", encoded)
+	}
+	if got := decodeTags(encoded); !reflect.DeepEqual(got, []string{"唱歌", "游戏"}) {
+		t.Errorf("decodeTags() = %v", got)
+	}
+	if encodeTags(nil) != "" || len(decodeTags("")) != 0 {
+		t.Error("empty tags should round trip")
+	}
+}
+
+func TestToFacets(t *testing.T) {
+	facets := toFacets(map[string]int{"b": 1, "a": 1, "c": 3})
+	var names []string
+	for _, f := range facets {
+		names = append(names, f.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"c", "a", "b"}) {
+		t.Errorf("toFacets() order = %v", names)
+	}
+}
+
+func TestRoomFilterEscape(t *testing.T) {
+	db := newTestDB(t, &model.Room{})
+	repo := repository.NewRoomRepository(db)
+	for i, room := range []model.Room{
+		{ID: 1, Name: "100%还原", Tags: encodeTags([]string{"唱歌"})},
+		{ID: 2, Name: "1000还原", Tags: encodeTags([]string{"唱歌跳舞"})},
+		{ID: 3, Name: `a_b\c`, Tags: encodeTags([]string{"a_b"})},
+		{ID: 4, Name: "axb", Tags: encodeTags([]string{"axb"})},
+	} {
+		if err := repo.AddRoom(&room); err != nil {
+			t.Fatalf("room %d: %v", i, err)
+		}
+	}
+
+	cases := []struct {
+		filter repository.RoomFilter
+		want   []int64
+	}{
+		{repository.RoomFilter{Keyword: "100%"}, []int64{1}},
+		{repository.RoomFilter{Keyword: "还原"}, []int64{1, 2}},
+		{repository.RoomFilter{Keyword: "a_b"}, []int64{3}},
+		{repository.RoomFilter{Keyword: `b\c`}, []int64{3}},
+		{repository.RoomFilter{Tag: "唱歌"}, []int64{1}},
+		{repository.RoomFilter{Tag: "a%"}, nil},
+		{repository.RoomFilter{Tag: "a_b"}, []int64{3}},
+	}
+	for _, c := range cases {
+		rooms, _, err := repo.QueryRooms(c.filter, 1, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []int64
+		for _, room := range rooms {
+			got = append(got, room.ID)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("filter %+v = %v, want %v", c.filter, got, c.want)
+		}
+	}
+}
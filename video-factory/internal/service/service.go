@@ -7,18 +7,53 @@ import (
 )
 
 type Service struct {
-	RoomService    *RoomService
-	ConfigService  *ConfigService
-	MonitorService *MonitorService
+	RoomService      *RoomService
+	ConfigService    *ConfigService
+	MonitorService   *MonitorService
+	RecordingService *RecordingService
+	ThumbnailService *ThumbnailService
+	ClipService      *ClipService
+	FileService      *FileService
+	StorageService   *StorageService
+	UploadService    *UploadService
+	MetadataService  *MetadataService
+	NotifyService    *NotifyService
+	BackupService    *BackupService
+	ClusterService   *ClusterService
 }
 
 func NewService(pool *pool.ManagerPool, config *config.AppConfig, repo *repository.Repository) *Service {
 
-	monitorService := NewMonitorService(pool, config, repo.Room)
+	monitorService := NewMonitorService(pool, config, repo.Room, repo.LiveSession, repo.Recording)
+	thumbnailService := NewThumbnailService(config, repo.Recording)
+	monitorService.OnRecordingSaved(thumbnailService.Enqueue)
+	// 在缩略图之后注册，删除本地文件前能看到缩略图任务的状态
+	uploadService := NewUploadService(config, repo.Storage, repo.UploadRule, repo.Upload, repo.Recording)
+	monitorService.OnRecordingSaved(uploadService.Enqueue)
+	metadataService := NewMetadataService(pool, config, repo.Room, repo.RoomTitle)
+	monitorService.OnManagerStart(metadataService.OnManagerStart)
+	// 在刷新房间信息之后注册，开播通知使用最新的标题
+	notifyService := NewNotifyService(config, repo.NotifyChannel, repo.NotifySub, repo.Room)
+	monitorService.OnManagerStart(notifyService.OnManagerStart)
+	monitorService.OnRecordFailed(notifyService.OnRecordFailed)
+	clusterService := NewClusterService(pool, config, repo.Cluster, repo.Room)
+	monitorService.SetOwnerCheck(clusterService.CheckOwner)
+	backupService := NewBackupService(config, repo.Backup)
+	backupService.SetLeaderCheck(clusterService.IsLeader)
 
 	return &Service{
-		RoomService:    NewRoomService(pool, config, repo.Room, monitorService),
-		ConfigService:  NewConfigService(pool, config, repo.Config),
-		MonitorService: monitorService,
+		RoomService:      NewRoomService(pool, config, repo.Room, monitorService),
+		ConfigService:    NewConfigService(pool, config, repo.Config, repo.ConfigHistory),
+		MonitorService:   monitorService,
+		RecordingService: NewRecordingService(repo.Recording),
+		ThumbnailService: thumbnailService,
+		ClipService:      NewClipService(pool, config, repo.Clip, repo.Recording),
+		FileService:      NewFileService(config, repo.Recording),
+		StorageService:   NewStorageService(repo.Storage, repo.UploadRule),
+		UploadService:    uploadService,
+		MetadataService:  metadataService,
+		NotifyService:    notifyService,
+		BackupService:    backupService,
+		ClusterService:   clusterService,
 	}
 }
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"video-factory/internal/domain/model"
+	"video-factory/internal/domain/vo"
+	"video-factory/internal/repository"
+	"video-factory/internal/storage"
+	"video-factory/pkg/config"
+	"video-factory/pkg/util"
+)
+
+// secretFields 存储、通知渠道配置中需要脱敏的字段，脱敏规则与配置项相同，更新时原样提交表示不修改
+var secretFields = []string{"secret_key", "password", "token"}
+
+// StorageService 管理上传目标与房间上传规则
+type StorageService struct {
+	storageRepo *repository.StorageRepository
+	ruleRepo    *repository.UploadRuleRepository
+}
+
+func NewStorageService(storageRepo *repository.StorageRepository, ruleRepo *repository.UploadRuleRepository) *StorageService {
+	return &StorageService{
+		storageRepo: storageRepo,
+		ruleRepo:    ruleRepo,
+	}
+}
+
+// ListStorages 获取存储列表，密钥已脱敏
+func (s *StorageService) ListStorages() ([]vo.StorageVO, error) {
+	storages, err := s.storageRepo.ListStorages()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]vo.StorageVO, 0, len(storages))
+	for i := range storages {
+		list = append(list, *toStorageVO(&storages[i]))
+	}
+	return list, nil
+}
+
+// AddStorage 添加存储，保存前校验配置
+func (s *StorageService) AddStorage(name string, storageType string, cfg map[string]any) (*vo.StorageVO, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("名称不能为空")
+	}
+	raw, err := checkStorageConfig(storageType, cfg)
+	if err != nil {
+		return nil, err
+	}
+	st := &model.Storage{
+		ID:     util.MustNextID(),
+		Name:   name,
+		Type:   storageType,
+		Config: raw,
+	}
+	if err := s.storageRepo.AddStorage(st); err != nil {
+		return nil, err
+	}
+	return toStorageVO(st), nil
+}
+
+// UpdateStorage 更新存储，密钥为占位符时保留原值
+func (s *StorageService) UpdateStorage(idStr string, name string, cfg map[string]any) error {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return errors.New("存储 id 格式有误")
+	}
+	st, err := s.storageRepo.GetStorageById(id)
+	if err != nil {
+		return err
+	}
+	if st == nil {
+		return errors.New("存储不存在")
+	}
+
+	keepSecrets(cfg, st.Config)
+	raw, err := checkStorageConfig(st.Type, cfg)
+	if err != nil {
+		return err
+	}
+	updateMap := map[string]any{"config": raw}
+	if strings.TrimSpace(name) != "" {
+		updateMap["name"] = name
+	}
+	return s.storageRepo.UpdateStorageById(id, updateMap)
+}
+
+// RemoveStorage 删除存储，仍被上传规则引用时不允许删除
+func (s *StorageService) RemoveStorage(idStr string) error {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return errors.New("存储 id 格式有误")
+	}
+	count, err := s.ruleRepo.CountByStorageId(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("存储仍被上传规则使用")
+	}
+	return s.storageRepo.RemoveStorage(id)
+}
+
+// ListRules 获取上传规则列表
+func (s *StorageService) ListRules() ([]vo.UploadRuleVO, error) {
+	rules, err := s.ruleRepo.ListUploadRules()
+	if err != nil {
+		return nil, err
+	}
+	storages, err := s.storageRepo.ListStorages()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(storages))
+	for _, st := range storages {
+		names[st.ID] = st.Name
+	}
+	list := make([]vo.UploadRuleVO, 0, len(rules))
+	for _, rule := range rules {
+		list = append(list, vo.UploadRuleVO{
+			ID:          rule.ID,
+			RoomID:      rule.RoomID,
+			StorageID:   rule.StorageID,
+			StorageName: names[rule.StorageID],
+			PathPrefix:  rule.PathPrefix,
+			DeleteLocal: rule.DeleteLocal,
+			Enabled:     rule.Enabled,
+		})
+	}
+	return list, nil
+}
+
+// SaveRule 保存房间的上传规则，每个房间只有一条，roomId 为 0 时为默认规则
+func (s *StorageService) SaveRule(roomIdStr string, storageIdStr string, pathPrefix string, deleteLocal int, enabled int) error {
+	roomId, err := strconv.ParseInt(roomIdStr, 10, 64)
+	if err != nil {
+		return errors.New("房间 id 格式有误")
+	}
+	storageId, err := strconv.ParseInt(storageIdStr, 10, 64)
+	if err != nil {
+		return errors.New("存储 id 格式有误")
+	}
+	st, err := s.storageRepo.GetStorageById(storageId)
+	if err != nil {
+		return err
+	}
+	if st == nil {
+		return errors.New("存储不存在")
+	}
+	prefix := strings.Trim(pathPrefix, "/")
+	if prefix != "" {
+		if _, err := storage.CleanKey(prefix); err != nil {
+			return err
+		}
+	}
+
+	rule, err := s.ruleRepo.GetUploadRuleByRoomId(roomId)
+	if err != nil {
+		return err
+	}
+	if rule == nil {
+		rule = &model.UploadRule{ID: util.MustNextID(), RoomID: roomId}
+	}
+	rule.StorageID = storageId
+	rule.PathPrefix = prefix
+	rule.DeleteLocal = deleteLocal
+	rule.Enabled = enabled
+	return s.ruleRepo.SaveUploadRule(rule)
+}
+
+// RemoveRule 删除上传规则
+func (s *StorageService) RemoveRule(idStr string) error {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return errors.New("规则 id 格式有误")
+	}
+	return s.ruleRepo.RemoveUploadRule(id)
+}
+
+// checkStorageConfig 创建一次存储以校验配置，返回保存用的 JSON
+func checkStorageConfig(storageType string, cfg map[string]any) (string, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	if _, err := storage.New(storageType, string(data)); err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// keepSecrets 提交的密钥为脱敏值时替换为原配置中的值
+func keepSecrets(cfg map[string]any, oldRaw string) {
+	var old map[string]any
+	_ = json.Unmarshal([]byte(oldRaw), &old)
+	for _, field := range secretFields {
+		original, _ := old[field].(string)
+		if value, ok := cfg[field].(string); ok && config.IsMaskedSecret(original, value) {
+			cfg[field] = original
+		}
+	}
+}
+
+// maskSecrets 解析 JSON 配置并将密钥脱敏
+func maskSecrets(raw string) map[string]any {
+	cfg := map[string]any{}
+	_ = json.Unmarshal([]byte(raw), &cfg)
+	for _, field := range secretFields {
+		if v, ok := cfg[field].(string); ok && v != "" {
+			cfg[field] = config.MaskSecret(v)
+		}
+	}
+	return cfg
+}
+
+func toStorageVO(st *model.Storage) *vo.StorageVO {
+	return &vo.StorageVO{
+		ID:         st.ID,
+		Name:       st.Name,
+		Type:       st.Type,
+		Config:     maskSecrets(st.Config),
+		CreateTime: util.MillisToTime(st.CreateTime),
+	}
+}
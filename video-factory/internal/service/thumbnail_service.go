@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"video-factory/internal/common/consts"
+	"video-factory/internal/domain/model"
+	"video-factory/internal/repository"
+	"video-factory/internal/thumbnail"
+	"video-factory/pkg/config"
+
+	"github.com/rs/zerolog/log"
+)
+
+// thumbnailQueueSize 缩略图任务队列长度，队列满时丢弃任务
+const thumbnailQueueSize = 64
+
+// ThumbnailService 录制文件完成后异步生成缩略图与拼图
+type ThumbnailService struct {
+	config     *config.AppConfig
+	recordRepo *repository.RecordingRepository
+	queue      chan *model.Recording
+}
+
+func NewThumbnailService(config *config.AppConfig, recordRepo *repository.RecordingRepository) *ThumbnailService {
+	t := &ThumbnailService{
+		config:     config,
+		recordRepo: recordRepo,
+		queue:      make(chan *model.Recording, thumbnailQueueSize),
+	}
+	go t.worker()
+	return t
+}
+
+// Enqueue 提交缩略图任务，不阻塞
+func (t *ThumbnailService) Enqueue(recording *model.Recording) {
+	if t.config.Thumbnail == nil || !t.config.Thumbnail.Enabled {
+		return
+	}
+	if isAudioFile(recording.Filename) {
+		t.updateStatus(recording.ID, model.ThumbStatusSkipped)
+		return
+	}
+	if thumbnail.FfmpegPath() == "" {
+		log.Warn().Str("file", recording.Filename).Msg("[Thumbnail] 未安装 ffmpeg，跳过缩略图生成")
+		t.updateStatus(recording.ID, model.ThumbStatusSkipped)
+		return
+	}
+
+	if !t.submit(recording) {
+		log.Warn().Str("file", recording.Filename).Msg("[Thumbnail] 任务队列已满，丢弃缩略图任务")
+	}
+}
+
+// Regenerate 重新生成某个录制文件的缩略图
+func (t *ThumbnailService) Regenerate(id int64) error {
+	if thumbnail.FfmpegPath() == "" {
+		return errors.New("未安装 ffmpeg")
+	}
+	recording, err := t.recordRepo.GetRecordingById(id)
+	if err != nil {
+		return err
+	}
+	if recording == nil {
+		return errors.New("录制记录不存在")
+	}
+	if isAudioFile(recording.Filename) {
+		return errors.New("纯音频文件没有画面")
+	}
+	if !t.submit(recording) {
+		return errors.New("任务队列已满，请稍后再试")
+	}
+	return nil
+}
+
+// submit 提交任务，入队前先标记为等待生成，避免 worker 已完成后又被覆盖为 pending
+// 队列已满时恢复原来的状态
+func (t *ThumbnailService) submit(recording *model.Recording) bool {
+	t.updateStatus(recording.ID, model.ThumbStatusPending)
+	select {
+	case t.queue <- recording:
+		return true
+	default:
+		t.updateStatus(recording.ID, recording.ThumbStatus)
+		return false
+	}
+}
+
+func (t *ThumbnailService) worker() {
+	for recording := range t.queue {
+		t.generate(recording)
+	}
+}
+
+func (t *ThumbnailService) generate(recording *model.Recording) {
+	interval := 300
+	if t.config.Thumbnail != nil && t.config.Thumbnail.Interval > 0 {
+		interval = t.config.Thumbnail.Interval
+	}
+
+	result, err := thumbnail.Generate(context.Background(), recording.Filename, recording.Duration, float64(interval))
+	if result == nil {
+		log.Err(err).Str("file", recording.Filename).Msg("[Thumbnail] 生成缩略图失败")
+		t.updateStatus(recording.ID, model.ThumbStatusFailed)
+		return
+	}
+	if err != nil {
+		log.Err(err).Str("file", recording.Filename).Msg("[Thumbnail] 生成拼图失败")
+	}
+
+	thumbs, _ := json.Marshal(result.Thumbnails)
+	if err := t.recordRepo.UpdateRecordingById(recording.ID, map[string]any{
+		"thumb_status":  model.ThumbStatusDone,
+		"thumbnails":    string(thumbs),
+		"contact_sheet": result.ContactSheet,
+	}); err != nil {
+		log.Err(err).Int64("id", recording.ID).Msg("[Thumbnail] 更新录制记录失败")
+		return
+	}
+	log.Info().Str("file", recording.Filename).Int("count", len(result.Thumbnails)).Msg("[Thumbnail] 缩略图已生成")
+}
+
+func (t *ThumbnailService) updateStatus(id int64, status string) {
+	if err := t.recordRepo.UpdateRecordingById(id, map[string]any{"thumb_status": status}); err != nil {
+		log.Err(err).Int64("id", id).Msg("[Thumbnail] 更新缩略图状态失败")
+	}
+}
+
+// isAudioFile 纯音频录制的文件
+func isAudioFile(filename string) bool {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	return ext == consts.AudioFormatAAC || ext == consts.AudioFormatM4A
+}
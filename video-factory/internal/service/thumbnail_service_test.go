@@ -0,0 +1,32 @@
+package service
+
+import (
+	"testing"
+	"video-factory/internal/domain/model"
+	"video-factory/internal/repository"
+	"video-factory/pkg/config"
+)
+
+func TestThumbnailSubmit(t *testing.T) {
+	db := newTestDB(t, &model.Recording{})
+	first := &model.Recording{ID: 1, ThumbStatus: model.ThumbStatusFailed}
+	second := &model.Recording{ID: 2, ThumbStatus: model.ThumbStatusDone}
+	mustCreate(t, db, first)
+	mustCreate(t, db, second)
+
+	// 不启动 worker，队列只能容纳一个任务
+	repo := repository.NewRecordingRepository(db)
+	s := &ThumbnailService{config: &config.AppConfig{}, recordRepo: repo, queue: make(chan *model.Recording, 1)}
+	if !s.submit(first) {
+		t.Fatal("队列未满时需要入队")
+	}
+	if s.submit(second) {
+		t.Fatal("队列已满时不能入队")
+	}
+	for id, want := range map[int64]string{1: model.ThumbStatusPending, 2: model.ThumbStatusDone} {
+		recording, err := repo.GetRecordingById(id)
+		if err != nil || recording.ThumbStatus != want {
+			t.Errorf("recording %d = %+v, err = %v, want %s", id, recording, err, want)
+		}
+	}
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+	"video-factory/internal/domain/model"
+	"video-factory/internal/domain/vo"
+	"video-factory/internal/repository"
+	"video-factory/internal/storage"
+	"video-factory/internal/vod"
+	"video-factory/pkg/config"
+	"video-factory/pkg/util"
+
+	"github.com/avast/retry-go/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// uploadQueueSize 上传队列长度，队列满时任务保持 pending，重启或手动重试时继续
+	uploadQueueSize = 256
+	// thumbWaitTimeout 删除本地文件前等待缩略图生成的最长时间
+	thumbWaitTimeout  = 30 * time.Minute
+	thumbWaitInterval = 10 * time.Second
+)
+
+// UploadService 录制完成后按房间规则上传到存储，上传任务与续传状态保存在数据库中
+type UploadService struct {
+	config      *config.AppConfig
+	storageRepo *repository.StorageRepository
+	ruleRepo    *repository.UploadRuleRepository
+	uploadRepo  *repository.UploadRepository
+	recordRepo  *repository.RecordingRepository
+	queue       chan int64
+}
+
+func NewUploadService(config *config.AppConfig, storageRepo *repository.StorageRepository,
+	ruleRepo *repository.UploadRuleRepository, uploadRepo *repository.UploadRepository,
+	recordRepo *repository.RecordingRepository) *UploadService {
+	u := &UploadService{
+		config:      config,
+		storageRepo: storageRepo,
+		ruleRepo:    ruleRepo,
+		uploadRepo:  uploadRepo,
+		recordRepo:  recordRepo,
+		queue:       make(chan int64, uploadQueueSize),
+	}
+	u.resume()
+	go u.worker()
+	return u
+}
+
+// Enqueue 录制文件完成后按规则创建上传任务，不阻塞
+func (u *UploadService) Enqueue(recording *model.Recording) {
+	if u.config.Uploader == nil || !u.config.Uploader.Enabled {
+		return
+	}
+	rule, err := u.matchRule(recording.RoomID)
+	if err != nil {
+		log.Err(err).Int64("roomId", recording.RoomID).Msg("[Upload] 获取上传规则失败")
+		return
+	}
+	if rule == nil {
+		return
+	}
+	if _, err := u.create(recording, rule.StorageID, rule.PathPrefix, rule.DeleteLocal); err != nil {
+		log.Err(err).Str("file", recording.Filename).Msg("[Upload] 创建上传任务失败")
+	}
+}
+
+// UploadRecording 手动上传某个录制文件，storageId 为 0 时使用房间规则中的存储
+func (u *UploadService) UploadRecording(recordingIdStr string, storageIdStr string) (*vo.UploadVO, error) {
+	recordingId, err := strconv.ParseInt(recordingIdStr, 10, 64)
+	if err != nil {
+		return nil, errors.New("录制记录 id 格式有误")
+	}
+	recording, err := u.recordRepo.GetRecordingById(recordingId)
+	if err != nil {
+		return nil, err
+	}
+	if recording == nil {
+		return nil, errors.New("录制记录不存在")
+	}
+
+	rule, err := u.matchRule(recording.RoomID)
+	if err != nil {
+		return nil, err
+	}
+	prefix, deleteLocal := "", 0
+	var storageId int64
+	if rule != nil {
+		storageId, prefix, deleteLocal = rule.StorageID, rule.PathPrefix, rule.DeleteLocal
+	}
+	if storageIdStr != "" && storageIdStr != "0" {
+		if storageId, err = strconv.ParseInt(storageIdStr, 10, 64); err != nil {
+			return nil, errors.New("存储 id 格式有误")
+		}
+	}
+	if storageId == 0 {
+		return nil, errors.New("房间没有上传规则，请指定存储")
+	}
+	upload, err := u.create(recording, storageId, prefix, deleteLocal)
+	if err != nil {
+		return nil, err
+	}
+	return toUploadVO(upload), nil
+}
+
+// Retry 重新上传失败的任务，已上传的分片会保留
+func (u *UploadService) Retry(id int64) error {
+	upload, err := u.uploadRepo.GetUploadById(id)
+	if err != nil {
+		return err
+	}
+	if upload == nil {
+		return errors.New("上传任务不存在")
+	}
+	if upload.Status != model.UploadStatusFailed {
+		return errors.New("只能重试失败的任务")
+	}
+	if err := u.uploadRepo.UpdateUploadById(id, map[string]any{
+		"status":   model.UploadStatusPending,
+		"attempts": 0,
+		"error":    "",
+	}); err != nil {
+		return err
+	}
+	if !u.submit(id) {
+		return errors.New("上传队列已满，请稍后再试")
+	}
+	return nil
+}
+
+// ListUploads 获取上传任务列表
+func (u *UploadService) ListUploads(roomId int64, status string, limit int) ([]vo.UploadVO, error) {
+	uploads, err := u.uploadRepo.ListUploads(roomId, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]vo.UploadVO, 0, len(uploads))
+	for i := range uploads {
+		list = append(list, *toUploadVO(&uploads[i]))
+	}
+	return list, nil
+}
+
+// matchRule 房间规则优先，没有时使用默认规则；规则关闭时返回 nil
+func (u *UploadService) matchRule(roomId int64) (*model.UploadRule, error) {
+	rule, err := u.ruleRepo.GetUploadRuleByRoomId(roomId)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		if rule, err = u.ruleRepo.GetUploadRuleByRoomId(0); err != nil {
+			return nil, err
+		}
+	}
+	if rule == nil || rule.Enabled != 1 {
+		return nil, nil
+	}
+	return rule, nil
+}
+
+func (u *UploadService) create(recording *model.Recording, storageId int64, prefix string, deleteLocal int) (*model.Upload, error) {
+	st, err := u.storageRepo.GetStorageById(storageId)
+	if err != nil {
+		return nil, err
+	}
+	if st == nil {
+		return nil, errors.New("存储不存在")
+	}
+	exists, err := u.uploadRepo.ExistsActive(recording.ID, storageId)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("该文件已在上传队列中或已上传")
+	}
+
+	upload := &model.Upload{
+		ID:          util.MustNextID(),
+		RecordingID: recording.ID,
+		RoomID:      recording.RoomID,
+		StorageID:   storageId,
+		LocalPath:   recording.Filename,
+		RemoteKey:   u.remoteKey(prefix, recording.Filename),
+		Size:        recording.Filesize,
+		Status:      model.UploadStatusPending,
+		DeleteLocal: deleteLocal,
+	}
+	if err := u.uploadRepo.AddUpload(upload); err != nil {
+		return nil, err
+	}
+	if !u.submit(upload.ID) {
+		log.Warn().Str("file", upload.LocalPath).Msg("[Upload] 上传队列已满，任务将在重启或重试时继续")
+	}
+	return upload, nil
+}
+
+// remoteKey 远端路径为前缀加上相对录制根目录的路径，不在根目录下时使用文件名
+func (u *UploadService) remoteKey(prefix string, filename string) string {
+	root := "."
+	if u.config.Recorder != nil && u.config.Recorder.OutputDir != "" {
+		root = u.config.Recorder.OutputDir
+	}
+	rel, ok := vod.RelPath(root, filename)
+	if !ok {
+		rel = filepath.Base(filename)
+	}
+	return path.Join(prefix, rel)
+}
+
+func (u *UploadService) submit(id int64) bool {
+	select {
+	case u.queue <- id:
+		return true
+	default:
+		return false
+	}
+}
+
+// resume 启动时恢复未完成的任务
+func (u *UploadService) resume() {
+	uploads, err := u.uploadRepo.ListUnfinished()
+	if err != nil {
+		log.Err(err).Msg("[Upload] 获取未完成的上传任务失败")
+		return
+	}
+	for _, upload := range uploads {
+		if !u.submit(upload.ID) {
+			break
+		}
+	}
+	if len(uploads) > 0 {
+		log.Info().Int("count", len(uploads)).Msg("[Upload] 恢复未完成的上传任务")
+	}
+}
+
+func (u *UploadService) worker() {
+	for id := range u.queue {
+		u.process(id)
+	}
+}
+
+func (u *UploadService) process(id int64) {
+	upload, err := u.uploadRepo.GetUploadById(id)
+	if err != nil || upload == nil {
+		log.Err(err).Int64("id", id).Msg("[Upload] 获取上传任务失败")
+		return
+	}
+	if upload.Status == model.UploadStatusDone || upload.Status == model.UploadStatusFailed {
+		return
+	}
+
+	target, err := u.openStorage(upload.StorageID)
+	if err != nil {
+		u.fail(upload, err)
+		return
+	}
+	info, err := os.Stat(upload.LocalPath)
+	if err != nil {
+		u.fail(upload, err)
+		return
+	}
+	if upload.Checksum == "" || upload.Size != info.Size() {
+		sum, err := storage.FileSHA256(upload.LocalPath)
+		if err != nil {
+			u.fail(upload, err)
+			return
+		}
+		// 文件内容变化后原来的分片上传进度不再有效，重新开始上传
+		upload.Size, upload.Checksum, upload.State = info.Size(), sum, ""
+	}
+	u.update(upload.ID, map[string]any{
+		"status":   model.UploadStatusUploading,
+		"size":     upload.Size,
+		"checksum": upload.Checksum,
+		"state":    upload.State,
+	})
+
+	obj := &storage.Object{
+		LocalPath: upload.LocalPath,
+		Key:       upload.RemoteKey,
+		Size:      upload.Size,
+		SHA256:    upload.Checksum,
+		State:     upload.State,
+		SaveState: func(state string) {
+			u.update(upload.ID, map[string]any{"state": state})
+		},
+	}
+
+	maxAttempts, retryDelay := 5, 30
+	if u.config.Uploader != nil {
+		maxAttempts, retryDelay = u.config.Uploader.MaxAttempts, u.config.Uploader.RetryDelay
+	}
+	attempts := max(maxAttempts-upload.Attempts, 1)
+
+	start := time.Now()
+	r := retry.New(
+		retry.Attempts(uint(attempts)),
+		retry.Delay(time.Duration(retryDelay)*time.Second),
+		retry.OnRetry(func(n uint, err error) {
+			log.Err(err).Str("file", upload.LocalPath).Msgf("[Upload] 第%d次重试", n+1)
+		}),
+		retry.RetryIf(func(err error) bool {
+			// 本地文件被删除，重试无意义
+			return !errors.Is(err, os.ErrNotExist)
+		}),
+		retry.Context(context.Background()),
+	)
+	err = r.Do(func() error {
+		upload.Attempts++
+		u.update(upload.ID, map[string]any{"attempts": upload.Attempts})
+		return target.Upload(context.Background(), obj)
+	})
+	if err != nil {
+		u.fail(upload, err)
+		return
+	}
+
+	u.update(upload.ID, map[string]any{
+		"status": model.UploadStatusDone,
+		"state":  "",
+		"error":  "",
+	})
+	log.Info().Str("file", upload.LocalPath).Str("key", upload.RemoteKey).Str("storage", target.Type()).
+		Str("elapsed", time.Since(start).Round(time.Second).String()).Msg("[Upload] 上传完成")
+
+	if upload.DeleteLocal == 1 {
+		go u.deleteLocal(upload)
+	}
+}
+
+func (u *UploadService) openStorage(id int64) (storage.Storage, error) {
+	st, err := u.storageRepo.GetStorageById(id)
+	if err != nil {
+		return nil, err
+	}
+	if st == nil {
+		return nil, errors.New("存储不存在")
+	}
+	return storage.New(st.Type, st.Config)
+}
+
+// deleteLocal 上传成功后删除本地文件，缩略图生成中时等待其完成
+// 同一文件还有上传到其他存储的任务未完成时保留，由最后完成的任务删除
+func (u *UploadService) deleteLocal(upload *model.Upload) {
+	deadline := time.Now().Add(thumbWaitTimeout)
+	for time.Now().Before(deadline) {
+		recording, err := u.recordRepo.GetRecordingById(upload.RecordingID)
+		if err != nil || recording == nil || recording.ThumbStatus != model.ThumbStatusPending {
+			break
+		}
+		time.Sleep(thumbWaitInterval)
+	}
+	if exists, err := u.uploadRepo.ExistsUnfinishedByPath(upload.LocalPath, upload.ID); err != nil || exists {
+		log.Info().Err(err).Str("file", upload.LocalPath).Msg("[Upload] 还有未完成的上传任务，保留本地文件")
+		return
+	}
+	if err := os.Remove(upload.LocalPath); err != nil {
+		log.Err(err).Str("file", upload.LocalPath).Msg("[Upload] 删除本地文件失败")
+		return
+	}
+	log.Info().Str("file", upload.LocalPath).Msg("[Upload] 已删除本地文件")
+}
+
+func (u *UploadService) fail(upload *model.Upload, err error) {
+	log.Err(err).Str("file", upload.LocalPath).Int("attempts", upload.Attempts).Msg("[Upload] 上传失败")
+	u.update(upload.ID, map[string]any{
+		"status": model.UploadStatusFailed,
+		"error":  err.Error(),
+	})
+}
+
+func (u *UploadService) update(id int64, updateMap map[string]any) {
+	if err := u.uploadRepo.UpdateUploadById(id, updateMap); err != nil {
+		log.Err(err).Int64("id", id).Msg("[Upload] 更新上传任务失败")
+	}
+}
+
+func toUploadVO(upload *model.Upload) *vo.UploadVO {
+	return &vo.UploadVO{
+		ID:          upload.ID,
+		RecordingID: upload.RecordingID,
+		RoomID:      upload.RoomID,
+		StorageID:   upload.StorageID,
+		LocalPath:   upload.LocalPath,
+		RemoteKey:   upload.RemoteKey,
+		Size:        upload.Size,
+		SizeStr:     util.FormatFilesize(int(upload.Size)),
+		Checksum:    upload.Checksum,
+		Status:      upload.Status,
+		Attempts:    upload.Attempts,
+		Error:       upload.Error,
+		DeleteLocal: upload.DeleteLocal,
+		CreateTime:  util.MillisToTime(upload.CreateTime),
+		UpdateTime:  util.MillisToTime(upload.UpdateTime),
+	}
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"video-factory/internal/domain/model"
+	"video-factory/internal/repository"
+)
+
+func TestUploadDeleteLocal(t *testing.T) {
+	db := newTestDB(t, &model.Upload{}, &model.Recording{})
+	file := filepath.Join(t.TempDir(), "a.ts")
+	if err := os.WriteFile(file, []byte("x"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	// 同一文件上传到两个存储
+	done := &model.Upload{ID: 1, LocalPath: file, Status: model.UploadStatusDone, DeleteLocal: 1}
+	other := &model.Upload{ID: 2, LocalPath: file, Status: model.UploadStatusUploading, DeleteLocal: 1}
+	mustCreate(t, db, done)
+	mustCreate(t, db, other)
+
+	uploadRepo := repository.NewUploadRepository(db)
+	u := &UploadService{uploadRepo: uploadRepo, recordRepo: repository.NewRecordingRepository(db)}
+	u.deleteLocal(done)
+	if _, err := os.Stat(file); err != nil {
+		t.Fatal("其他存储的上传未完成时不能删除本地文件")
+	}
+
+	if err := uploadRepo.UpdateUploadById(other.ID, map[string]any{"status": model.UploadStatusDone}); err != nil {
+		t.Fatal(err)
+	}
+	u.deleteLocal(other)
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatalf("全部上传完成后需要删除本地文件: %v", err)
+	}
+}
@@ -6,14 +6,56 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"video-factory/internal/common/consts"
 	"video-factory/pkg/fetcher"
 
 	"github.com/rs/zerolog/log"
 )
 
+// 风控相关的业务错误码
+const (
+	codeRequestBlocked = -412 // 请求被拦截
+	codeRiskCheck      = -352 // 风控校验失败
+)
+
+// 默认接口地址
+const (
+	defaultAPIBase = "https://api.live.bilibili.com"
+	defaultWebBase = "https://api.bilibili.com"
+)
+
+var (
+	apiBase = defaultAPIBase // 直播接口地址
+	webBase = defaultWebBase // 主站接口地址（wbi nav）
+)
+
+func init() {
+	fetcher.RegisterHost(consts.PlatformBili, "api.live.bilibili.com", "api.bilibili.com")
+}
+
+// SetBaseURL 替换接口地址，直播与主站接口共用同一地址，为空时恢复默认
+// 用于测试时指向本地模拟服务，或通过反向代理访问
+func SetBaseURL(base string) error {
+	base = strings.TrimRight(strings.TrimSpace(base), "/")
+	// 不同服务的 wbi key 不通用
+	defer wbiProvider.Invalidate()
+	if base == "" {
+		apiBase, webBase = defaultAPIBase, defaultWebBase
+		return nil
+	}
+	u, err := url.Parse(base)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("接口地址格式错误: %s", base)
+	}
+	apiBase, webBase = base, base
+	fetcher.RegisterHost(consts.PlatformBili, u.Hostname())
+	return nil
+}
+
 // FetchRoomInfo 获取直播间信息
 func FetchRoomInfo(roomId string) (*RoomInfoData, error) {
-	apiURL := "https://api.live.bilibili.com/room/v1/Room/get_info"
+	apiURL := apiBase + "/room/v1/Room/get_info"
 
 	params := url.Values{}
 	params.Set("room_id", roomId)
@@ -35,7 +77,7 @@ func FetchRoomInfo(roomId string) (*RoomInfoData, error) {
 
 // FetchRoomInitInfo 获取房间页初始化信息
 func FetchRoomInitInfo(rid string, header http.Header) (*RoomInitData, error) {
-	apiURL := "https://api.live.bilibili.com/room/v1/Room/room_init"
+	apiURL := apiBase + "/room/v1/Room/room_init"
 
 	params := url.Values{}
 	params.Set("id", rid)
@@ -56,7 +98,7 @@ func FetchRoomInitInfo(rid string, header http.Header) (*RoomInitData, error) {
 }
 
 func FetchAnchorInfo(uid string) (*AnchorInfo, error) {
-	apiURL := "https://api.live.bilibili.com/live_user/v1/Master/info"
+	apiURL := apiBase + "/live_user/v1/Master/info"
 
 	params := url.Values{}
 	params.Set("uid", uid)
@@ -78,7 +120,7 @@ func FetchAnchorInfo(uid string) (*AnchorInfo, error) {
 
 // FetchPlayInfo 获取直播间播放信息
 func FetchPlayInfo(roomId string, qn int, header http.Header) (*PlayInfoData, error) {
-	apiURL := "https://api.live.bilibili.com/xlive/web-room/v2/index/getRoomPlayInfo"
+	apiURL := apiBase + "/xlive/web-room/v2/index/getRoomPlayInfo"
 
 	params := url.Values{}
 	params.Set("room_id", roomId)
@@ -103,6 +145,33 @@ func FetchPlayInfo(roomId string, qn int, header http.Header) (*PlayInfoData, er
 	return &data, nil
 }
 
+// FetchStatusInfoByUids 按主播 uid 批量获取直播间状态，返回 uid -> 状态
+func FetchStatusInfoByUids(uids []string) (map[string]RoomStatusInfo, error) {
+	apiURL := apiBase + "/room/v1/Room/get_status_info_by_uids"
+
+	params := url.Values{}
+	for _, uid := range uids {
+		params.Add("uids[]", uid)
+	}
+
+	response, err := Fetch(apiURL, params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// 没有任何结果时 data 为 []
+	result := make(map[string]RoomStatusInfo)
+	if len(response.Data) == 0 || string(response.Data) == "[]" {
+		return result, nil
+	}
+	if err := json.Unmarshal(response.Data, &result); err != nil {
+		log.Err(err).Msgf("RoomStatusInfo 解析失败, response.Data: %s", response.Data)
+		return nil, fmt.Errorf("RoomStatusInfo 解析失败: %v", err)
+	}
+
+	return result, nil
+}
+
 // =====================================================================================================================
 
 func Fetch(baseURL string, params url.Values, header http.Header) (*ApiResponse, error) {
@@ -111,11 +180,18 @@ func Fetch(baseURL string, params url.Values, header http.Header) (*ApiResponse,
 		header.Set("User-Agent", userAgent)
 	}
 
+	// WBI 签名，获取 key 失败时不签名继续请求
+	if signed, err := wbiProvider.Sign(params); err != nil {
+		log.Warn().Err(err).Msg("[bili wbi] 签名失败，使用未签名的请求")
+	} else {
+		params = signed
+	}
+
 	// 发送请求并获取 JSON 响应
 	// log.Debug().Msgf("bili fetch, baseUrl: %s, params: %v, header: %v", baseURL, params, header)
 	body, err := fetcher.FetchBody(baseURL, params, header)
 	if err != nil {
-		return nil, fmt.Errorf("执行请求失败: %v", err)
+		return nil, fmt.Errorf("执行请求失败: %w", err)
 	}
 
 	// 解析响应
@@ -125,6 +201,18 @@ func Fetch(baseURL string, params url.Values, header http.Header) (*ApiResponse,
 		return nil, fmt.Errorf("JSON 解析失败: %v", err)
 	}
 
+	breaker := fetcher.GetBreaker(consts.PlatformBili)
+	if response.Code == codeRequestBlocked || response.Code == codeRiskCheck {
+		riskErr := &fetcher.RiskControlError{Platform: consts.PlatformBili, Code: response.Code, Message: response.Msg}
+		breaker.OnFailure(riskErr)
+		if response.Code == codeRiskCheck {
+			// 可能是 wbi key 已过期，下次请求时刷新
+			wbiProvider.Invalidate()
+		}
+		return nil, riskErr
+	}
+	breaker.OnSuccess()
+
 	if response.Code != 0 {
 		return nil, fmt.Errorf("bili API 错误 (%d): %s", response.Code, response.Msg)
 	}
@@ -156,3 +156,16 @@ type AnchorInfo struct {
 	Face   string `json:"face"`   // 头像
 	Gender int    `json:"gender"` // 性别
 }
+
+// =====================================================================================================================
+
+// RoomStatusInfo 对应 get_status_info_by_uids 接口中单个主播的数据
+type RoomStatusInfo struct {
+	Uid        int    `json:"uid"`         // 主播 mid
+	RoomId     int    `json:"room_id"`     // 直播间长号
+	Title      string `json:"title"`       // 标题
+	LiveStatus int    `json:"live_status"` // 直播状态，0：未开播 1：直播中 2：轮播中
+	LiveTime   int64  `json:"live_time"`   // 开播时间，时间戳（秒）
+	Uname      string `json:"uname"`       // 主播名
+	CoverURL   string `json:"cover_from_user"`
+}
@@ -336,6 +336,33 @@ func GetRoomLiveStatus(rid string) (int, error) {
 	return 1, nil
 }
 
+// batchStatusLimit 批量状态接口单次最多查询的 uid 数量
+const batchStatusLimit = 50
+
+// GetRoomsLiveStatusByUids 批量获取直播状态，返回 uid -> 直播状态 (0/1)
+// 未出现在返回结果中的 uid 视为未开播
+func GetRoomsLiveStatusByUids(uids []string) (map[string]int, error) {
+	statusMap := make(map[string]int, len(uids))
+	for start := 0; start < len(uids); start += batchStatusLimit {
+		end := start + batchStatusLimit
+		if end > len(uids) {
+			end = len(uids)
+		}
+		infoMap, err := FetchStatusInfoByUids(uids[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, uid := range uids[start:end] {
+			if info, ok := infoMap[uid]; ok && info.LiveStatus == 1 {
+				statusMap[uid] = 1
+			} else {
+				statusMap[uid] = 0
+			}
+		}
+	}
+	return statusMap, nil
+}
+
 func GetRoomAddInfo(roomIdStr string) (*vo.RoomAddVO, error) {
 	data, err := FetchRoomInfo(roomIdStr)
 	if err != nil {
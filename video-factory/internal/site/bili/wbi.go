@@ -0,0 +1,205 @@
+package bili
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"video-factory/pkg/fetcher"
+
+	"github.com/rs/zerolog/log"
+)
+
+// WBI 签名
+// https://github.com/SocialSisterYi/bilibili-API-collect/blob/master/docs/misc/sign/wbi.md
+
+const (
+	navPath = "/x/web-interface/nav"
+	// wbiKeyTTL img_key/sub_key 每日更新
+	wbiKeyTTL = 24 * time.Hour
+	// wbiRetryDelay 刷新失败后继续使用旧 key 的时长，避免每次请求都去请求 nav
+	wbiRetryDelay = 5 * time.Minute
+)
+
+var mixinKeyEncTab = [...]int{
+	46, 47, 18, 2, 53, 8, 23, 32,
+	15, 50, 10, 31, 58, 3, 45, 35,
+	27, 43, 5, 49, 33, 9, 42, 19,
+	29, 28, 14, 39, 12, 38, 41, 13,
+	37, 48, 7, 16, 24, 55, 40, 61,
+	26, 17, 0, 1, 60, 51, 30, 4,
+	22, 25, 54, 21, 56, 59, 6, 63,
+	57, 62, 11, 36, 20, 34, 44, 52,
+}
+
+// Nav 对应 nav 接口，只解析 wbi_img 部分
+type Nav struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		WbiImg struct {
+			ImgUrl string `json:"img_url"`
+			SubUrl string `json:"sub_url"`
+		} `json:"wbi_img"`
+	} `json:"data"`
+}
+
+// WbiKeyProvider 负责获取并缓存 img_key/sub_key，过期后自动刷新
+type WbiKeyProvider struct {
+	mu        sync.Mutex
+	imgKey    string
+	subKey    string
+	mixinKey  string
+	updatedAt time.Time
+
+	fetchKeys func() (imgKey string, subKey string, err error)
+	now       func() time.Time
+}
+
+func NewWbiKeyProvider(fetchKeys func() (string, string, error)) *WbiKeyProvider {
+	return &WbiKeyProvider{
+		fetchKeys: fetchKeys,
+		now:       time.Now,
+	}
+}
+
+// wbiProvider 全局 WBI key 提供者，bili.Fetch 使用它为请求签名
+var wbiProvider = NewWbiKeyProvider(FetchWbiKeys)
+
+// SetKeys 直接设置 key，不再请求 nav 接口，直到过期
+func (p *WbiKeyProvider) SetKeys(imgKey string, subKey string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.setKeys(imgKey, subKey)
+}
+
+func (p *WbiKeyProvider) setKeys(imgKey string, subKey string) {
+	p.imgKey = imgKey
+	p.subKey = subKey
+	p.mixinKey = GetMixinKey(imgKey, subKey)
+	p.updatedAt = p.now()
+}
+
+// Invalidate 使缓存的 key 失效，下次签名时重新获取
+func (p *WbiKeyProvider) Invalidate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.updatedAt = time.Time{}
+}
+
+// MixinKey 获取当前的 mixin key，过期则刷新
+// 刷新失败但有旧 key 时继续使用旧 key
+func (p *WbiKeyProvider) MixinKey() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.mixinKey != "" && p.now().Sub(p.updatedAt) < wbiKeyTTL {
+		return p.mixinKey, nil
+	}
+
+	imgKey, subKey, err := p.fetchKeys()
+	if err != nil {
+		if p.mixinKey != "" {
+			log.Warn().Err(err).Msg("[bili wbi] 刷新 wbi key 失败，继续使用旧 key")
+			p.updatedAt = p.now().Add(wbiRetryDelay - wbiKeyTTL)
+			return p.mixinKey, nil
+		}
+		return "", fmt.Errorf("获取 wbi key 失败: %w", err)
+	}
+	p.setKeys(imgKey, subKey)
+	log.Info().Msg("[bili wbi] wbi key 已更新")
+	return p.mixinKey, nil
+}
+
+// Sign 返回带 wts 与 w_rid 的新参数，不修改入参
+func (p *WbiKeyProvider) Sign(params url.Values) (url.Values, error) {
+	mixinKey, err := p.MixinKey()
+	if err != nil {
+		return nil, err
+	}
+	return SignWithMixinKey(params, mixinKey, p.now().Unix()), nil
+}
+
+// GetMixinKey 按固定顺序打乱 img_key + sub_key，取前 32 位
+func GetMixinKey(imgKey string, subKey string) string {
+	raw := imgKey + subKey
+	var b strings.Builder
+	for _, i := range mixinKeyEncTab {
+		if i < len(raw) {
+			b.WriteByte(raw[i])
+		}
+		if b.Len() == 32 {
+			break
+		}
+	}
+	return b.String()
+}
+
+// SignWithMixinKey 使用指定的 mixin key 和时间戳签名
+func SignWithMixinKey(params url.Values, mixinKey string, wts int64) url.Values {
+	signed := make(url.Values, len(params)+2)
+	for key, values := range params {
+		if key == "w_rid" || key == "wts" {
+			continue
+		}
+		for _, value := range values {
+			// 过滤 value 中的 "!'()*" 字符
+			signed.Add(key, strings.Map(func(r rune) rune {
+				if strings.ContainsRune("!'()*", r) {
+					return -1
+				}
+				return r
+			}, value))
+		}
+	}
+	signed.Set("wts", strconv.FormatInt(wts, 10))
+
+	// Encode 会按 key 排序，空格需编码为 %20
+	query := strings.ReplaceAll(signed.Encode(), "+", "%20")
+	hash := md5.Sum([]byte(query + mixinKey))
+	signed.Set("w_rid", hex.EncodeToString(hash[:]))
+	return signed
+}
+
+// FetchWbiKeys 从 nav 接口获取 img_key 与 sub_key
+// 未登录时 code 为 -101，但依然会返回 wbi_img
+func FetchWbiKeys() (string, string, error) {
+	header := make(http.Header)
+	header.Set("User-Agent", userAgent)
+	header.Set("Referer", "https://www.bilibili.com/")
+
+	body, err := fetcher.FetchBody(webBase+navPath, nil, header)
+	if err != nil {
+		return "", "", err
+	}
+
+	var nav Nav
+	if err := json.Unmarshal(body, &nav); err != nil {
+		return "", "", fmt.Errorf("nav 解析失败: %w", err)
+	}
+	if nav.Code != 0 && nav.Code != -101 {
+		return "", "", fmt.Errorf("nav 接口错误 (%d): %s", nav.Code, nav.Message)
+	}
+
+	imgKey := keyFromURL(nav.Data.WbiImg.ImgUrl)
+	subKey := keyFromURL(nav.Data.WbiImg.SubUrl)
+	if imgKey == "" || subKey == "" {
+		return "", "", fmt.Errorf("nav 未返回 wbi_img: %s", body)
+	}
+	return imgKey, subKey, nil
+}
+
+// keyFromURL https://i0.hdslb.com/bfs/wbi/7cd084941338484aae1ad9425b84077c.png -> 7cd084941338484aae1ad9425b84077c
+func keyFromURL(u string) string {
+	name := u[strings.LastIndex(u, "/")+1:]
+	if dot := strings.LastIndex(name, "."); dot != -1 {
+		name = name[:dot]
+	}
+	return name
+}
@@ -1,178 +1,151 @@
 package bili
 
 import (
-	"bytes"
-	"crypto/md5"
-	"encoding/hex"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
+	"errors"
 	"net/url"
-	"strconv"
-	"strings"
 	"testing"
 	"time"
 )
 
-func Test_Sign(t *testing.T) {
-	u, err := url.Parse("https://api.bilibili.com/x/space/wbi/acc/info?mid=1850091")
-	if err != nil {
-		panic(err)
-	}
-	fmt.Printf("orig: %s\n", u.String())
-	err = Sign(u)
-	if err != nil {
-		panic(err)
-	}
-	fmt.Printf("signed: %s\n", u.String())
+// 文档中的示例 key 与签名
+// https://github.com/SocialSisterYi/bilibili-API-collect/blob/master/docs/misc/sign/wbi.md
+const (
+	testImgKey   = "7cd084941338484aae1ad9425b84077c"
+	testSubKey   = "4932caff0ff746eab6f01bf08b70ac45"
+	testMixinKey = "ea1db124af3c7062474693fa704f4ff8"
+)
 
-	// 获取 wbi 时未修改 header
-	// 但实际使用签名后的 url 时发现风控较为严重
+func TestGetMixinKey(t *testing.T) {
+	if got := GetMixinKey(testImgKey, testSubKey); got != testMixinKey {
+		t.Errorf("GetMixinKey() = %s, want %s", got, testMixinKey)
+	}
 }
 
-// Sign 为链接签名
-func Sign(u *url.URL) error {
-	return wbiKeys.Sign(u)
-}
+func TestSignWithMixinKey(t *testing.T) {
+	params := url.Values{}
+	params.Set("foo", "114")
+	params.Set("bar", "514")
+	params.Set("zab", "1919810")
 
-// Update 无视过期时间更新
-func Update() error {
-	return wbiKeys.Update()
+	signed := SignWithMixinKey(params, testMixinKey, 1702204169)
+	if got := signed.Get("w_rid"); got != "8f6f2b5b3d485fe1886cec6a0be8c5d4" {
+		t.Errorf("w_rid = %s, want 8f6f2b5b3d485fe1886cec6a0be8c5d4", got)
+	}
+	if got := signed.Get("wts"); got != "1702204169" {
+		t.Errorf("wts = %s, want 1702204169", got)
+	}
+	// 不修改入参
+	if params.Has("w_rid") || params.Has("wts") {
+		t.Errorf("入参被修改: %v", params)
+	}
 }
 
-func Get() (wk WbiKeys, err error) {
-	if err = wk.update(false); err != nil {
-		return WbiKeys{}, err
+func TestSignWithMixinKeyResign(t *testing.T) {
+	params := url.Values{}
+	params.Set("foo", "114")
+	params.Set("bar", "514")
+	params.Set("zab", "1919810")
+	first := SignWithMixinKey(params, testMixinKey, 1702204169)
+
+	// 已签名的参数再次签名，旧的 w_rid/wts 不参与计算
+	second := SignWithMixinKey(first, testMixinKey, 1702204169)
+	if first.Get("w_rid") != second.Get("w_rid") {
+		t.Errorf("重复签名结果不一致: %s != %s", first.Get("w_rid"), second.Get("w_rid"))
 	}
-	return wbiKeys, nil
 }
 
-var wbiKeys WbiKeys
-
-type WbiKeys struct {
-	Img            string
-	Sub            string
-	Mixin          string
-	lastUpdateTime time.Time
-}
+func TestSignWithMixinKeyFilterChars(t *testing.T) {
+	dirty := url.Values{}
+	dirty.Set("keyword", "a!b'c(d)e*f g")
+	clean := url.Values{}
+	clean.Set("keyword", "abcdef g")
 
-// Sign 为链接签名
-func (wk *WbiKeys) Sign(u *url.URL) (err error) {
-	if err = wk.update(false); err != nil {
-		return err
+	got := SignWithMixinKey(dirty, testMixinKey, 1702204169)
+	want := SignWithMixinKey(clean, testMixinKey, 1702204169)
+	if got.Get("w_rid") != want.Get("w_rid") {
+		t.Errorf("过滤字符后签名不一致: %s != %s", got.Get("w_rid"), want.Get("w_rid"))
+	}
+	if got.Get("keyword") != "abcdef g" {
+		t.Errorf("keyword = %q, want %q", got.Get("keyword"), "abcdef g")
 	}
-
-	values := u.Query()
-
-	values = removeUnwantedChars(values, '!', '\'', '(', ')', '*') // 必要性存疑?
-
-	values.Set("wts", strconv.FormatInt(time.Now().Unix(), 10))
-
-	// [url.Values.Encode] 内会对参数排序,
-	// 且遍历 map 时本身就是无序的
-	hash := md5.Sum([]byte(values.Encode() + wk.Mixin)) // Calculate w_rid
-	values.Set("w_rid", hex.EncodeToString(hash[:]))
-	u.RawQuery = values.Encode()
-	return nil
-}
-
-// Update 无视过期时间更新
-func (wk *WbiKeys) Update() (err error) {
-	return wk.update(true)
 }
 
-// update 按需更新
-func (wk *WbiKeys) update(purge bool) error {
-	if !purge && time.Since(wk.lastUpdateTime) < time.Hour {
-		return nil
+func TestKeyFromURL(t *testing.T) {
+	got := keyFromURL("https://i0.hdslb.com/bfs/wbi/7cd084941338484aae1ad9425b84077c.png")
+	if got != testImgKey {
+		t.Errorf("keyFromURL() = %s, want %s", got, testImgKey)
 	}
+}
 
-	// 测试下来不用修改 header 也能过
-	resp, err := http.Get("https://api.bilibili.com/x/web-interface/nav")
+func TestWbiKeyProviderCache(t *testing.T) {
+	now := time.Unix(1702204169, 0)
+	fetchCount := 0
+	provider := NewWbiKeyProvider(func() (string, string, error) {
+		fetchCount++
+		return testImgKey, testSubKey, nil
+	})
+	provider.now = func() time.Time { return now }
+
+	params := url.Values{}
+	params.Set("foo", "114")
+	params.Set("bar", "514")
+	params.Set("zab", "1919810")
+
+	signed, err := provider.Sign(params)
 	if err != nil {
-		return err
+		t.Fatal(err)
 	}
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
+	if got := signed.Get("w_rid"); got != "8f6f2b5b3d485fe1886cec6a0be8c5d4" {
+		t.Errorf("w_rid = %s, want 8f6f2b5b3d485fe1886cec6a0be8c5d4", got)
 	}
 
-	nav := Nav{}
-	err = json.Unmarshal(body, &nav)
-	if err != nil {
-		return err
+	// 有效期内不重新获取
+	now = now.Add(time.Hour)
+	if _, err := provider.MixinKey(); err != nil {
+		t.Fatal(err)
+	}
+	if fetchCount != 1 {
+		t.Errorf("fetchCount = %d, want 1", fetchCount)
 	}
 
-	if nav.Code != 0 && nav.Code != -101 { // -101 未登录时也会返回两个 key
-		return fmt.Errorf("unexpected code: %d, message: %s", nav.Code, nav.Message)
+	// 过期后重新获取
+	now = now.Add(wbiKeyTTL)
+	if _, err := provider.MixinKey(); err != nil {
+		t.Fatal(err)
 	}
-	img := nav.Data.WbiImg.ImgUrl
-	sub := nav.Data.WbiImg.SubUrl
-	if img == "" || sub == "" {
-		return fmt.Errorf("empty image or sub url: %s", body)
+	if fetchCount != 2 {
+		t.Errorf("fetchCount = %d, want 2", fetchCount)
 	}
 
-	// https://i0.hdslb.com/bfs/wbi/7cd084941338484aae1ad9425b84077c.png
-	imgParts := strings.Split(img, "/")
-	subParts := strings.Split(sub, "/")
-
-	// 7cd084941338484aae1ad9425b84077c.png
-	imgPng := imgParts[len(imgParts)-1]
-	subPng := subParts[len(subParts)-1]
-
-	// 7cd084941338484aae1ad9425b84077c
-	wbiKeys.Img = strings.TrimSuffix(imgPng, ".png")
-	wbiKeys.Sub = strings.TrimSuffix(subPng, ".png")
-
-	wbiKeys.mixin()
-	wbiKeys.lastUpdateTime = time.Now()
-	return nil
-}
-
-func (wk *WbiKeys) mixin() {
-	var mixin [32]byte
-	wbi := wk.Img + wk.Sub
-	for i := range mixin { // for i := 0; i < len(mixin); i++ {
-		mixin[i] = wbi[mixinKeyEncTab[i]]
+	// 主动失效后重新获取
+	provider.Invalidate()
+	if _, err := provider.MixinKey(); err != nil {
+		t.Fatal(err)
+	}
+	if fetchCount != 3 {
+		t.Errorf("fetchCount = %d, want 3", fetchCount)
 	}
-	wk.Mixin = string(mixin[:])
 }
 
-var mixinKeyEncTab = [...]int{
-	46, 47, 18, 2, 53, 8, 23, 32,
-	15, 50, 10, 31, 58, 3, 45, 35,
-	27, 43, 5, 49, 33, 9, 42, 19,
-	29, 28, 14, 39, 12, 38, 41, 13,
-	37, 48, 7, 16, 24, 55, 40, 61,
-	26, 17, 0, 1, 60, 51, 30, 4,
-	22, 25, 54, 21, 56, 59, 6, 63,
-	57, 62, 11, 36, 20, 34, 44, 52,
-}
+func TestWbiKeyProviderFetchError(t *testing.T) {
+	fetchErr := errors.New("network error")
+	provider := NewWbiKeyProvider(func() (string, string, error) {
+		return "", "", fetchErr
+	})
 
-func removeUnwantedChars(v url.Values, chars ...byte) url.Values {
-	b := []byte(v.Encode())
-	for _, c := range chars {
-		b = bytes.ReplaceAll(b, []byte{c}, nil)
+	// 没有旧 key 时返回错误
+	if _, err := provider.MixinKey(); !errors.Is(err, fetchErr) {
+		t.Errorf("MixinKey() err = %v, want %v", err, fetchErr)
 	}
-	s, err := url.ParseQuery(string(b))
+
+	// 有旧 key 时继续使用旧 key
+	provider.SetKeys(testImgKey, testSubKey)
+	provider.Invalidate()
+	got, err := provider.MixinKey()
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
+	}
+	if got != testMixinKey {
+		t.Errorf("MixinKey() = %s, want %s", got, testMixinKey)
 	}
-	return s
-}
-
-type Nav struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Ttl     int    `json:"ttl"`
-	Data    struct {
-		WbiImg struct {
-			ImgUrl string `json:"img_url"`
-			SubUrl string `json:"sub_url"`
-		} `json:"wbi_img"`
-
-		// ......
-	} `json:"data"`
 }
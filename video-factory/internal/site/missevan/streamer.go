@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"video-factory/internal/common/consts"
 	"video-factory/internal/domain/vo"
@@ -19,10 +20,45 @@ import (
 )
 
 const (
-	getLiveBaseUrl = "https://fm.missevan.com/api/v2/live/"
+	defaultAPIBase = "https://fm.missevan.com"
+	getLivePath    = "/api/v2/live/"
 	userAgent      = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36"
 	refererPrefix  = "https://fm.missevan.com/live/"
 	origin         = "https://fm.missevan.com"
+
+	// LineFlv 线路名：HTTP-FLV 地址
+	LineFlv = "flv"
+	// LineHls 线路名：HLS 地址，用于代理播放
+	LineHls = "hls"
+)
+
+// apiBase 接口地址，测试时可指向本地模拟服务
+var apiBase = defaultAPIBase
+
+func init() {
+	fetcher.RegisterHost(consts.PlatformMissevan, "fm.missevan.com")
+}
+
+// SetBaseURL 替换接口地址，为空时恢复默认
+func SetBaseURL(base string) error {
+	base = strings.TrimRight(strings.TrimSpace(base), "/")
+	if base == "" {
+		apiBase = defaultAPIBase
+		return nil
+	}
+	u, err := url.Parse(base)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("接口地址格式错误: %s", base)
+	}
+	apiBase = base
+	fetcher.RegisterHost(consts.PlatformMissevan, u.Hostname())
+	return nil
+}
+
+const (
+	// originQn 猫耳只提供一种音质，按原画处理
+	originQn   = 10000
+	originName = "原画"
 )
 
 type Streamer struct {
@@ -33,50 +69,43 @@ type Streamer struct {
 	OpenTime   int64  // 开播时间
 	Header     http.Header
 	StreamInfo *iface.StreamInfo
+
+	streamHost string // 拉流请求使用的 Host，为空时使用地址中的域名
+	mu         sync.RWMutex
 }
 
+// GetHeaders 拉流请求头，配置了 stream_host 时通过 Host 改写请求的域名
+// 返回副本，配置热更新不影响正在使用的请求头
 func (s *Streamer) GetHeaders() http.Header {
-	// func (HandlerStrategy) GetExtraHeaders() http.Header {
-	// 	// 猫耳需要特定的 Host
-	// 	header := make(http.Header)
-	// 	header.Set("Host", "d1-missevan04.bilivideo.com")
-	// 	return header
-	// }
-
-	// func (m *Manager) Fetch(ctx context.Context, baseURL string, params url.Values, extraHeader http.Header) (*http.Response, error) {
-	// 	executor := func(method, url string, p url.Values) (*http.Response, error) {
-	// 		// 猫耳需要补充 host
-	// 		baseHeader := m.Manager.Streamer.GetInfo().Header
-	// 		requestHeader := baseHeader.Clone()
-	// 		for k, vv := range extraHeader {
-	// 			requestHeader.Del(k)
-	// 			for _, v := range vv {
-	// 				requestHeader.Add(k, v)
-	// 			}
-	// 		}
-	// 		return fetcher.Fetch(method, url, p, requestHeader)
-	// 	}
-	// 	return fetcher.FetchWithRefresh(ctx, m, executor, "GET", baseURL, params)
-	// }
-	// tlxTODO:
-	return nil
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	header := s.Header.Clone()
+	if s.streamHost != "" {
+		header.Set("Host", s.streamHost)
+	}
+	return header
+}
+
+// apiHeader 接口请求头，不包含拉流使用的 Host
+func (s *Streamer) apiHeader() http.Header {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Header.Clone()
 }
 
 func NewStreamer(realRoomId string, config *config.AppConfig) *Streamer {
 	s := &Streamer{
 		RealRoomId: realRoomId,
 		Platform:   consts.PlatformMissevan,
-		Header:     make(http.Header),
+		Header:     defaultHeader(realRoomId),
 		StreamInfo: &iface.StreamInfo{
 			StreamUrls: map[string]string{},
+			AcceptQns:  []int{originQn},
+			SelectedQn: originQn,
 		},
+		streamHost: strings.TrimSpace(config.Missevan.StreamHost),
 	}
-	// 设置 Header
-	s.Header.Set("User-Agent", userAgent)
-	s.Header.Set("Referer", refererPrefix+realRoomId)
-	s.Header.Set("Origin", origin)
-	s.Header.Set("Accept-Encoding", "identity")
-	cookie := strings.TrimSpace(config.Bili.Cookie)
+	cookie := strings.TrimSpace(config.Missevan.Cookie)
 	if cookie != "" {
 		s.Header.Set("Cookie", cookie)
 	}
@@ -86,22 +115,32 @@ func NewStreamer(realRoomId string, config *config.AppConfig) *Streamer {
 
 func (s *Streamer) OnConfigUpdate(key string, value string) {
 	log.Info().Msgf("[missevan] 配置更新: %s=%s", key, value)
-	if key == "missevan.cookie" {
-		s.Header.Set("Cookie", value)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch key {
+	case "missevan.cookie":
+		if cookie := strings.TrimSpace(value); cookie != "" {
+			s.Header.Set("Cookie", cookie)
+		} else {
+			s.Header.Del("Cookie")
+		}
+	case "missevan.stream_host":
+		s.streamHost = strings.TrimSpace(value)
 	}
 }
 
 // ---------------------------------------------------------------------------------------------------------------------
 
 func (s *Streamer) IsLive() (bool, error) {
-	room, _, err := FetchRoomInfo(s.RealRoomId, s.Header)
+	room, _, err := FetchRoomInfo(s.RealRoomId, s.apiHeader())
 	if err != nil {
 		return false, err
 	}
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if room.Status.Open == 0 {
 		s.LiveStatus = 0
-		log.Error().Msgf("房间[%s]未开播", s.RealRoomId)
 		return false, nil
 	}
 
@@ -110,56 +149,87 @@ func (s *Streamer) IsLive() (bool, error) {
 }
 
 func (s *Streamer) FetchStreamInfo(currentQn int, certainQnFlag bool) (*iface.StreamInfo, error) {
-	room, _, err := FetchRoomInfo(s.RealRoomId, s.Header)
+	room, _, err := FetchRoomInfo(s.RealRoomId, s.apiHeader())
 	if err != nil {
 		return nil, err
 	}
 
 	if room.Status.Open == 0 {
-		log.Error().Msgf("房间[%d]未开播", room.RoomId)
+		log.Info().Msgf("房间[%d]未开播", room.RoomId)
 		return nil, iface.ErrRoomOffline
 	}
+	if room.Channel.FlvPullUrl == "" && room.Channel.HlsPullUrl == "" {
+		return nil, fmt.Errorf("房间[%d]没有可用的直播流地址", room.RoomId)
+	}
 
-	s.StreamInfo.StreamUrls["flv"] = room.Channel.FlvPullUrl
-	s.StreamInfo.StreamUrls["hls"] = room.Channel.HlsPullUrl
+	// 每次刷新重建线路，避免残留已下线的地址
+	streamUrls := make(map[string]string, 2)
+	if room.Channel.FlvPullUrl != "" {
+		streamUrls[LineFlv] = room.Channel.FlvPullUrl
+	}
+	if room.Channel.HlsPullUrl != "" {
+		streamUrls[LineHls] = room.Channel.HlsPullUrl
+	}
+	// 替换为新的 StreamInfo 而不是修改原来的，读取方拿到的副本不会被并发修改
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info := *s.StreamInfo
+	info.StreamUrls = streamUrls
+	info.ActualQn = originQn
+	info.QualityName = originName
+	info.Channel = channelOf(room)
+	s.StreamInfo = &info
+	s.OpenTime = room.Status.OpenTime
 
 	return s.StreamInfo, nil
 }
 
 func (s *Streamer) GetStreamInfo() iface.StreamInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return *s.StreamInfo
 }
 
+// ParseExpiration 解析直播流地址中的 expires 参数（秒级时间戳）
 func (s *Streamer) ParseExpiration(streamUrl string) (time.Time, error) {
 	parsedUrl, err := url.Parse(streamUrl)
 	if err != nil {
-		log.Err(err).Msg("解析 HLS URL 失败")
-		return time.Now(), err
+		return time.Now(), fmt.Errorf("解析直播流地址失败: %w", err)
 	}
 
 	expiresStr := parsedUrl.Query().Get("expires")
-
-	// 1. 将字符串转换为 int64
+	if expiresStr == "" {
+		return time.Now(), fmt.Errorf("直播流地址缺少 expires 参数: %s", streamUrl)
+	}
 	expiresInt, err := strconv.ParseInt(expiresStr, 10, 64)
 	if err != nil {
-		fmt.Println("转换时间戳字符串为整数失败:", err)
-		return time.Now(), err
+		return time.Now(), fmt.Errorf("expires 参数格式错误: %w", err)
 	}
-
-	// 2. 使用 time.Unix() 转换为 time.Time 类型
-	// 第一个参数是秒 (sec)，第二个参数是纳秒 (nsec)，这里设为 0
 	return time.Unix(expiresInt, 0), nil
 }
 
+// GetOpenTime 开播时间，秒
 func (s *Streamer) GetOpenTime() int64 {
-	room, _, err := FetchRoomInfo(s.RealRoomId, nil)
+	room, _, err := FetchRoomInfo(s.RealRoomId, s.apiHeader())
 	if err != nil {
 		return 0
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.OpenTime = room.Status.OpenTime
 	return s.OpenTime / 1000
 }
 
+// channelOf 直播通道，优先使用 HLS 地址的 CDN 域名
+func channelOf(room *Room) string {
+	for _, pullUrl := range []string{room.Channel.HlsPullUrl, room.Channel.FlvPullUrl} {
+		if u, err := url.Parse(pullUrl); err == nil && u.Host != "" {
+			return u.Hostname()
+		}
+	}
+	return ""
+}
+
 // ---------------------------------------------------------------------------------------------------------------------
 
 func CheckAndGetRid(s string) (string, error) {
@@ -182,15 +252,21 @@ func CheckAndGetRid(s string) (string, error) {
 	return "", fmt.Errorf("格式有误，获取rid失败: %s", s)
 }
 
+// defaultHeader 猫耳接口与直播流都需要的请求头
+func defaultHeader(realId string) http.Header {
+	header := make(http.Header)
+	header.Set("User-Agent", userAgent)
+	header.Set("Referer", refererPrefix+realId)
+	header.Set("Origin", origin)
+	header.Set("Accept-Encoding", "identity")
+	return header
+}
+
 func FetchRoomInfo(realId string, header http.Header) (*Room, *Creator, error) {
 	if header == nil {
-		header = make(http.Header)
-		header.Set("User-Agent", userAgent)
-		header.Set("Referer", refererPrefix+realId)
-		header.Set("Origin", origin)
-		header.Set("Accept-Encoding", "identity")
+		header = defaultHeader(realId)
 	}
-	resp, err := fetcher.FetchBody(getLiveBaseUrl+realId, nil, header)
+	resp, err := fetcher.FetchBody(apiBase+getLivePath+realId, nil, header)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -199,6 +275,7 @@ func FetchRoomInfo(realId string, header http.Header) (*Room, *Creator, error) {
 	if err := json.Unmarshal(resp, &response); err != nil {
 		return nil, nil, fmt.Errorf("JSON结构解析失败: %v", err)
 	}
+	fetcher.GetBreaker(consts.PlatformMissevan).OnSuccess()
 	if response.Code != 0 {
 		return nil, nil, fmt.Errorf("API业务错误 (%d)", response.Code)
 	}
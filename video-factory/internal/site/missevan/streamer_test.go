@@ -0,0 +1,199 @@
+package missevan
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+	"video-factory/internal/common/consts"
+	"video-factory/internal/iface"
+	"video-factory/pkg/config"
+	"video-factory/pkg/fetcher"
+)
+
+// fixtureServer 按文件返回 testdata 中的接口数据，并记录最后一次请求
+type fixtureServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	fixture string
+	last    *http.Request
+}
+
+func newFixtureServer(t *testing.T, fixture string) *fixtureServer {
+	t.Helper()
+	fetcher.Init(&config.AppConfig{})
+	f := &fixtureServer{fixture: fixture}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.last = r
+		fixture := f.fixture
+		f.mu.Unlock()
+		data, err := os.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+	if err := SetBaseURL(f.URL); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		f.Close()
+		_ = SetBaseURL("")
+		fetcher.GetBreaker(consts.PlatformMissevan).Reset()
+	})
+	return f
+}
+
+func (f *fixtureServer) setFixture(fixture string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fixture = fixture
+}
+
+func (f *fixtureServer) lastRequest() *http.Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.last
+}
+
+func newTestStreamer() *Streamer {
+	cfg := &config.AppConfig{}
+	cfg.Bili.Cookie = "SESSDATA=bili"
+	cfg.Missevan.Cookie = "token=missevan"
+	cfg.Missevan.StreamHost = "d1-missevan04.bilivideo.com"
+	return NewStreamer("109896001", cfg)
+}
+
+func TestFetchStreamInfo(t *testing.T) {
+	f := newFixtureServer(t, "live_room.json")
+	s := newTestStreamer()
+
+	info, err := s.FetchStreamInfo(0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.StreamUrls) != 2 || info.StreamUrls[LineFlv] == "" || info.StreamUrls[LineHls] == "" {
+		t.Fatalf("stream urls = %v", info.StreamUrls)
+	}
+	if info.ActualQn != originQn || info.QualityName != originName || info.Channel != "d1-missevan04.bilivideo.com" {
+		t.Fatalf("info = %+v", info)
+	}
+	if s.GetStreamInfo().ActualQn != originQn {
+		t.Fatal("GetStreamInfo should return the refreshed info")
+	}
+
+	// 接口请求使用猫耳自己的 Cookie，不带拉流的 Host
+	req := f.lastRequest()
+	if got := req.Header.Get("Cookie"); got != "token=missevan" {
+		t.Errorf("cookie = %q", got)
+	}
+	if got := req.Header.Get("Referer"); got != refererPrefix+"109896001" {
+		t.Errorf("referer = %q", got)
+	}
+	if req.Host != f.Listener.Addr().String() {
+		t.Errorf("api host = %q", req.Host)
+	}
+
+	// 拉流请求头带 Host，返回副本
+	header := s.GetHeaders()
+	if header.Get("Host") != "d1-missevan04.bilivideo.com" || header.Get("Cookie") != "token=missevan" {
+		t.Errorf("headers = %v", header)
+	}
+	header.Set("Cookie", "changed")
+	if s.GetHeaders().Get("Cookie") != "token=missevan" {
+		t.Error("GetHeaders should return a copy")
+	}
+
+	// 配置热更新
+	s.OnConfigUpdate("missevan.cookie", "token=new")
+	s.OnConfigUpdate("missevan.stream_host", "")
+	if header := s.GetHeaders(); header.Get("Cookie") != "token=new" || header.Get("Host") != "" {
+		t.Errorf("headers after update = %v", header)
+	}
+
+	// 下播
+	f.setFixture("live_offline.json")
+	if _, err := s.FetchStreamInfo(0, false); !errors.Is(err, iface.ErrRoomOffline) {
+		t.Fatalf("err = %v, want ErrRoomOffline", err)
+	}
+	if live, err := s.IsLive(); err != nil || live {
+		t.Fatalf("live = %v, err = %v", live, err)
+	}
+}
+
+// TestStreamInfoConcurrent 刷新与读取同时进行，配合 -race 检查
+func TestStreamInfoConcurrent(t *testing.T) {
+	newFixtureServer(t, "live_room.json")
+	s := newTestStreamer()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _ = s.FetchStreamInfo(0, false)
+			_ = s.GetOpenTime()
+		}()
+		go func() {
+			defer wg.Done()
+			for _, u := range s.GetStreamInfo().StreamUrls {
+				_ = u
+			}
+		}()
+	}
+	wg.Wait()
+	if len(s.GetStreamInfo().StreamUrls) != 2 {
+		t.Fatalf("info = %+v", s.GetStreamInfo())
+	}
+}
+
+func TestStreamHostRewrite(t *testing.T) {
+	var host string
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+	}))
+	defer cdn.Close()
+	fetcher.Init(&config.AppConfig{})
+
+	s := newTestStreamer()
+	resp, err := fetcher.Fetch(http.MethodGet, cdn.URL+"/live-bvc/109896001/index.m3u8", nil, s.GetHeaders())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if host != "d1-missevan04.bilivideo.com" {
+		t.Fatalf("host = %q", host)
+	}
+}
+
+func TestParseExpiration(t *testing.T) {
+	newFixtureServer(t, "live_room.json")
+	s := newTestStreamer()
+	info, err := s.FetchStreamInfo(0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{LineFlv, LineHls} {
+		expire, err := s.ParseExpiration(info.StreamUrls[line])
+		if err != nil || !expire.Equal(time.Unix(1767225600, 0)) {
+			t.Errorf("%s: expire = %v, err = %v", line, expire, err)
+		}
+	}
+
+	for _, streamURL := range []string{
+		"https://d1-missevan04.bilivideo.com/live-bvc/109896001.flv",
+		"https://d1-missevan04.bilivideo.com/live-bvc/109896001.flv?expires=abc",
+		"://bad",
+	} {
+		if _, err := s.ParseExpiration(streamURL); err == nil {
+			t.Errorf("%s: want error", streamURL)
+		}
+	}
+}
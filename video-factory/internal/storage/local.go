@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// LocalConfig 本地目录或挂载的 NAS
+type LocalConfig struct {
+	Dir string `json:"dir"`
+}
+
+// Local 复制到本地目录，先写 .part 文件，中断后从已写入的位置继续
+type Local struct {
+	dir string
+}
+
+func NewLocal(cfg LocalConfig) (*Local, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("目录不能为空")
+	}
+	return &Local{dir: cfg.Dir}, nil
+}
+
+func (l *Local) Type() string {
+	return TypeLocal
+}
+
+func (l *Local) Upload(ctx context.Context, obj *Object) error {
+	key, err := CleanKey(obj.Key)
+	if err != nil {
+		return err
+	}
+	target := filepath.Join(l.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(target), 0777); err != nil {
+		return fmt.Errorf("mkdir all: %w", err)
+	}
+
+	src, err := os.Open(obj.LocalPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	part := target + ".part"
+	dst, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	// 续传：.part 的长度即已完成的部分
+	offset, err := dst.Seek(0, io.SeekEnd)
+	if err != nil {
+		dst.Close()
+		return err
+	}
+	if offset > obj.Size {
+		offset = 0
+		if err := dst.Truncate(0); err != nil {
+			dst.Close()
+			return err
+		}
+		if _, err := dst.Seek(0, io.SeekStart); err != nil {
+			dst.Close()
+			return err
+		}
+	}
+	if _, err := src.Seek(offset, io.SeekStart); err != nil {
+		dst.Close()
+		return err
+	}
+
+	_, err = io.Copy(dst, &contextReader{ctx: ctx, r: src})
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	sum, err := FileSHA256(part)
+	if err != nil {
+		return err
+	}
+	if obj.SHA256 != "" && sum != obj.SHA256 {
+		// 从头重新上传
+		_ = os.Remove(part)
+		return ErrChecksumMismatch
+	}
+	return os.Rename(part, target)
+}
+
+// contextReader 读取时检查 ctx，使大文件复制可以被取消
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
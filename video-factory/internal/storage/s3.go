@@ -0,0 +1,353 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// minPartSize S3 要求除最后一个分片外不小于 5MB
+	minPartSize     = 5 * 1024 * 1024
+	defaultPartSize = 16 * 1024 * 1024
+	// maxParts S3 单个对象最多 10000 个分片
+	maxParts = 10000
+	// metaSHA256 保存在对象元数据中的 sha256，仅作记录，服务端不会校验
+	metaSHA256 = "x-amz-meta-sha256"
+)
+
+// S3Config S3 兼容存储，如 AWS S3、MinIO、各云厂商对象存储
+type S3Config struct {
+	Endpoint  string `json:"endpoint"` // 如 https://s3.amazonaws.com、http://127.0.0.1:9000
+	Region    string `json:"region"`
+	Bucket    string `json:"bucket"`
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+	PathStyle bool   `json:"path_style"` // 使用 endpoint/bucket/key 形式的地址，MinIO 需要开启
+	PartSize  int64  `json:"part_size"`  // 分片大小（字节），默认 16MB
+}
+
+// S3 分片上传，进度（UploadId 与已完成的分片）保存在 Object.State 中，中断后继续上传
+type S3 struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// s3State 分片上传的续传状态
+type s3State struct {
+	UploadID string   `json:"uploadId"`
+	PartSize int64    `json:"partSize"`
+	Parts    []s3Part `json:"parts"`
+}
+
+type s3Part struct {
+	PartNumber int    `xml:"PartNumber" json:"partNumber"`
+	ETag       string `xml:"ETag" json:"etag"`
+}
+
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("endpoint 与 bucket 不能为空")
+	}
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New("access_key 与 secret_key 不能为空")
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("endpoint 格式有误: %s", cfg.Endpoint)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.PartSize <= 0 {
+		cfg.PartSize = defaultPartSize
+	}
+	cfg.PartSize = max(cfg.PartSize, minPartSize)
+	return &S3{
+		cfg:      cfg,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 10 * time.Minute},
+		now:      time.Now,
+	}, nil
+}
+
+func (s *S3) Type() string {
+	return TypeS3
+}
+
+func (s *S3) Upload(ctx context.Context, obj *Object) error {
+	key, err := CleanKey(obj.Key)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(obj.LocalPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var partSize int64
+	if obj.Size <= s.cfg.PartSize {
+		err = s.putObject(ctx, key, file, obj)
+	} else {
+		partSize, err = s.multipartUpload(ctx, key, file, obj)
+	}
+	if err != nil {
+		return err
+	}
+	etag, err := localETag(file, obj.Size, partSize)
+	if err != nil {
+		return err
+	}
+	return s.verify(ctx, key, obj, etag)
+}
+
+// putObject 小文件直接上传，Content-MD5 与 x-amz-content-sha256 由服务端校验
+func (s *S3) putObject(ctx context.Context, key string, file *os.File, obj *Object) error {
+	data, err := io.ReadAll(io.LimitReader(file, obj.Size))
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	if obj.SHA256 != "" {
+		header.Set(metaSHA256, obj.SHA256)
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, nil, header, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// multipartUpload 分片上传，返回使用的分片大小
+func (s *S3) multipartUpload(ctx context.Context, key string, file *os.File, obj *Object) (int64, error) {
+	var state s3State
+	if obj.State != "" {
+		if err := json.Unmarshal([]byte(obj.State), &state); err != nil {
+			state = s3State{}
+		}
+	}
+	if state.UploadID == "" {
+		uploadID, err := s.createMultipartUpload(ctx, key, obj)
+		if err != nil {
+			return 0, err
+		}
+		partSize := s.cfg.PartSize
+		// 分片数超过上限时增大分片
+		if n := (obj.Size + partSize - 1) / partSize; n > maxParts {
+			partSize = (obj.Size + maxParts - 1) / maxParts
+		}
+		state = s3State{UploadID: uploadID, PartSize: partSize}
+		s.saveState(obj, &state)
+	}
+
+	buf := make([]byte, state.PartSize)
+	for partNumber := len(state.Parts) + 1; int64(partNumber-1)*state.PartSize < obj.Size; partNumber++ {
+		offset := int64(partNumber-1) * state.PartSize
+		n, err := file.ReadAt(buf[:min(state.PartSize, obj.Size-offset)], offset)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		query := url.Values{
+			"partNumber": {strconv.Itoa(partNumber)},
+			"uploadId":   {state.UploadID},
+		}
+		resp, err := s.do(ctx, http.MethodPut, key, query, nil, buf[:n])
+		if err != nil {
+			if isS3Error(err, "NoSuchUpload") {
+				// 分片上传已失效（过期或被清理），下次重试从头开始
+				obj.saveState("")
+			}
+			return 0, err
+		}
+		resp.Body.Close()
+		state.Parts = append(state.Parts, s3Part{PartNumber: partNumber, ETag: resp.Header.Get("ETag")})
+		s.saveState(obj, &state)
+	}
+	return state.PartSize, s.completeMultipartUpload(ctx, key, &state, obj)
+}
+
+func (s *S3) createMultipartUpload(ctx context.Context, key string, obj *Object) (string, error) {
+	header := http.Header{}
+	if obj.SHA256 != "" {
+		header.Set(metaSHA256, obj.SHA256)
+	}
+	resp, err := s.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, header, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("解析 UploadId 失败: %w", err)
+	}
+	if result.UploadID == "" {
+		return "", errors.New("UploadId 为空")
+	}
+	return result.UploadID, nil
+}
+
+func (s *S3) completeMultipartUpload(ctx context.Context, key string, state *s3State, obj *Object) error {
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []s3Part `xml:"Part"`
+	}{Parts: state.Parts})
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodPost, key, url.Values{"uploadId": {state.UploadID}}, nil, body)
+	if err != nil {
+		if isS3Error(err, "NoSuchUpload") || isS3Error(err, "InvalidPart") {
+			obj.saveState("")
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	// 完成请求可能返回 200 但响应体是错误
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if bytes.Contains(data, []byte("<Error>")) {
+		obj.saveState("")
+		return parseS3Error(resp.StatusCode, data)
+	}
+	obj.saveState("")
+	return nil
+}
+
+// verify 检查远端对象的大小与 ETag
+// 未加密或 SSE-S3 加密的对象，ETag 为内容的 MD5，分片上传时为各分片 MD5 拼接后的 MD5 加 -分片数；
+// SSE-KMS 与 SSE-C 加密的对象 ETag 不是 MD5，只检查大小
+func (s *S3) verify(ctx context.Context, key string, obj *Object, etag string) error {
+	resp, err := s.do(ctx, http.MethodHead, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.ContentLength != obj.Size {
+		return fmt.Errorf("%w: size %d != %d", ErrChecksumMismatch, resp.ContentLength, obj.Size)
+	}
+	if resp.Header.Get("x-amz-server-side-encryption") == "aws:kms" ||
+		resp.Header.Get("x-amz-server-side-encryption-customer-algorithm") != "" {
+		return nil
+	}
+	if remote := strings.Trim(resp.Header.Get("ETag"), `"`); remote != etag {
+		return fmt.Errorf("%w: etag %s != %s", ErrChecksumMismatch, remote, etag)
+	}
+	return nil
+}
+
+// localETag 按 S3 的规则计算本地文件的 ETag，partSize 为 0 表示单次上传
+func localETag(file *os.File, size int64, partSize int64) (string, error) {
+	if partSize <= 0 {
+		h := md5.New()
+		if _, err := io.Copy(h, io.NewSectionReader(file, 0, size)); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+	var sums []byte
+	parts := 0
+	for offset := int64(0); offset < size; offset += partSize {
+		h := md5.New()
+		if _, err := io.Copy(h, io.NewSectionReader(file, offset, min(partSize, size-offset))); err != nil {
+			return "", err
+		}
+		sums = h.Sum(sums)
+		parts++
+	}
+	sum := md5.Sum(sums)
+	return hex.EncodeToString(sum[:]) + "-" + strconv.Itoa(parts), nil
+}
+
+func (s *S3) saveState(obj *Object, state *s3State) {
+	data, _ := json.Marshal(state)
+	obj.saveState(string(data))
+}
+
+// objectURL 对象地址，路径风格为 endpoint/bucket/key，否则为 bucket.endpoint/key
+func (s *S3) objectURL(key string, query url.Values) *url.URL {
+	u := *s.endpoint
+	rawPath := "/" + awsEscape(key, false)
+	if s.cfg.PathStyle {
+		rawPath = strings.TrimSuffix(u.EscapedPath(), "/") + "/" + awsEscape(s.cfg.Bucket, true) + rawPath
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+	}
+	u.Path, _ = url.PathUnescape(rawPath)
+	u.RawPath = rawPath
+	u.RawQuery = query.Encode()
+	return &u
+}
+
+// do 发送签名请求，非 2xx 响应解析为 s3Error
+func (s *S3) do(ctx context.Context, method string, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key, query).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	payloadHash := emptyPayloadHash
+	if len(body) > 0 {
+		sum := md5.Sum(body)
+		req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+		payloadHash = hexSHA256(body)
+	}
+	signV4(req, s.cfg.AccessKey, s.cfg.SecretKey, s.cfg.Region, payloadHash, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		return nil, parseS3Error(resp.StatusCode, data)
+	}
+	return resp, nil
+}
+
+// s3Error S3 错误响应
+type s3Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (e *s3Error) Error() string {
+	return fmt.Sprintf("s3 error %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+func parseS3Error(statusCode int, data []byte) error {
+	e := &s3Error{StatusCode: statusCode}
+	_ = xml.Unmarshal(data, e)
+	return e
+}
+
+func isS3Error(err error, code string) bool {
+	var e *s3Error
+	return errors.As(err, &e) && e.Code == code
+}
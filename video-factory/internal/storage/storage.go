@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// 存储类型
+const (
+	TypeLocal  = "local"
+	TypeS3     = "s3"
+	TypeWebDAV = "webdav"
+)
+
+// ErrChecksumMismatch 上传后校验失败
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// Object 待上传的文件
+type Object struct {
+	LocalPath string
+	Key       string // 远端路径，/ 分隔
+	Size      int64
+	SHA256    string // 本地文件的 sha256，hex
+	// State 断点续传状态，格式由存储后端定义，为空表示从头上传
+	State string
+	// SaveState 上传进度变化时回调，用于持久化 State，可为 nil
+	SaveState func(state string)
+}
+
+func (o *Object) saveState(state string) {
+	o.State = state
+	if o.SaveState != nil {
+		o.SaveState(state)
+	}
+}
+
+// Storage 上传目标
+type Storage interface {
+	// Type 存储类型
+	Type() string
+	// Upload 上传文件并校验，支持续传的后端会根据 Object.State 继续上传
+	Upload(ctx context.Context, obj *Object) error
+}
+
+// New 根据类型与 JSON 配置创建存储
+func New(storageType string, rawConfig string) (Storage, error) {
+	switch storageType {
+	case TypeLocal:
+		var cfg LocalConfig
+		if err := decodeConfig(rawConfig, &cfg); err != nil {
+			return nil, err
+		}
+		return NewLocal(cfg)
+	case TypeS3:
+		var cfg S3Config
+		if err := decodeConfig(rawConfig, &cfg); err != nil {
+			return nil, err
+		}
+		return NewS3(cfg)
+	case TypeWebDAV:
+		var cfg WebDAVConfig
+		if err := decodeConfig(rawConfig, &cfg); err != nil {
+			return nil, err
+		}
+		return NewWebDAV(cfg)
+	}
+	return nil, fmt.Errorf("不支持的存储类型: %s", storageType)
+}
+
+func decodeConfig(rawConfig string, v any) error {
+	if strings.TrimSpace(rawConfig) == "" {
+		return errors.New("存储配置为空")
+	}
+	if err := json.Unmarshal([]byte(rawConfig), v); err != nil {
+		return fmt.Errorf("存储配置格式有误: %w", err)
+	}
+	return nil
+}
+
+// CleanKey 规范化远端路径，拒绝 ..
+func CleanKey(key string) (string, error) {
+	key = strings.ReplaceAll(key, `\`, "/")
+	for _, segment := range strings.Split(key, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("远端路径不能包含 ..: %s", key)
+		}
+	}
+	key = strings.TrimPrefix(path.Clean("/"+key), "/")
+	if key == "" {
+		return "", errors.New("远端路径为空")
+	}
+	return key, nil
+}
+
+// FileSHA256 计算文件的 sha256
+func FileSHA256(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
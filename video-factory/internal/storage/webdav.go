@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// webdavRequestTimeout 创建目录、查询大小等请求的超时时间，也是上传超时的基础值
+	webdavRequestTimeout = time.Minute
+	// minUploadRate 计算上传超时使用的最低速率（字节/秒），低于该速率视为连接卡住
+	minUploadRate = 256 * 1024
+)
+
+// WebDAVConfig WebDAV 服务，如 NAS、Nextcloud、Alist
+type WebDAVConfig struct {
+	URL      string `json:"url"` // 上传根目录，如 https://nas.local/dav/records
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// WebDAV 整个文件 PUT 上传，协议没有通用的续传方式，失败后从头重传
+// 上传后检查远端大小，并写入 .sha256 文件供校验
+type WebDAV struct {
+	cfg    WebDAVConfig
+	base   *url.URL
+	client *http.Client
+}
+
+func NewWebDAV(cfg WebDAVConfig) (*WebDAV, error) {
+	base, err := url.Parse(strings.TrimSuffix(cfg.URL, "/"))
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("url 格式有误: %s", cfg.URL)
+	}
+	return &WebDAV{
+		cfg:    cfg,
+		base:   base,
+		client: &http.Client{},
+	}, nil
+}
+
+func (w *WebDAV) Type() string {
+	return TypeWebDAV
+}
+
+func (w *WebDAV) Upload(ctx context.Context, obj *Object) error {
+	key, err := CleanKey(obj.Key)
+	if err != nil {
+		return err
+	}
+	if err := w.mkdirAll(ctx, path.Dir(key)); err != nil {
+		return err
+	}
+
+	file, err := os.Open(obj.LocalPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := w.put(ctx, key, file, obj.Size); err != nil {
+		return err
+	}
+
+	// 校验远端大小
+	headCtx, cancel := context.WithTimeout(ctx, webdavRequestTimeout)
+	defer cancel()
+	resp, err := w.do(headCtx, http.MethodHead, key, nil, -1, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.ContentLength >= 0 && resp.ContentLength != obj.Size {
+		return fmt.Errorf("%w: size %d != %d", ErrChecksumMismatch, resp.ContentLength, obj.Size)
+	}
+
+	if obj.SHA256 != "" {
+		sum := obj.SHA256 + "  " + path.Base(key) + "\n"
+		if err := w.put(ctx, key+".sha256", strings.NewReader(sum), int64(len(sum))); err != nil {
+			return fmt.Errorf("写入 sha256 文件失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// put 上传，超时按大小计算，避免大文件被固定的超时中断
+func (w *WebDAV) put(ctx context.Context, key string, body io.Reader, size int64) error {
+	ctx, cancel := context.WithTimeout(ctx, uploadTimeout(size))
+	defer cancel()
+	resp, err := w.do(ctx, http.MethodPut, key, body, size, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// mkdirAll 逐级创建目录，已存在时服务端返回 405
+func (w *WebDAV) mkdirAll(ctx context.Context, dir string) error {
+	if dir == "." || dir == "/" || dir == "" {
+		return nil
+	}
+	current := ""
+	for _, segment := range strings.Split(dir, "/") {
+		current = path.Join(current, segment)
+		if err := w.mkcol(ctx, current); err != nil {
+			return fmt.Errorf("创建目录 %s 失败: %w", current, err)
+		}
+	}
+	return nil
+}
+
+func (w *WebDAV) mkcol(ctx context.Context, dir string) error {
+	ctx, cancel := context.WithTimeout(ctx, webdavRequestTimeout)
+	defer cancel()
+	resp, err := w.do(ctx, "MKCOL", dir+"/", nil, 0, []int{http.StatusMethodNotAllowed})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// uploadTimeout 上传 size 字节的超时时间
+func uploadTimeout(size int64) time.Duration {
+	return webdavRequestTimeout + time.Duration(size/minUploadRate)*time.Second
+}
+
+// do 发送请求，2xx 与 allowed 中的状态码视为成功
+func (w *WebDAV) do(ctx context.Context, method string, key string, body io.Reader, size int64, allowed []int) (*http.Response, error) {
+	u := *w.base
+	rawPath := strings.TrimSuffix(u.EscapedPath(), "/") + "/" + awsEscape(key, false)
+	u.Path, _ = url.PathUnescape(rawPath)
+	u.RawPath = rawPath
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if size >= 0 && body != nil {
+		req.ContentLength = size
+	}
+	if w.cfg.Username != "" {
+		req.SetBasicAuth(w.cfg.Username, w.cfg.Password)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	for _, code := range allowed {
+		if resp.StatusCode == code {
+			return resp, nil
+		}
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, errors.New("WebDAV 认证失败")
+	}
+	return nil, fmt.Errorf("webdav %s %s: unexpected status %d", method, key, resp.StatusCode)
+}
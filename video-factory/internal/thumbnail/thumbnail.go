@@ -0,0 +1,164 @@
+package thumbnail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// thumbWidth 缩略图宽度，高度按比例
+	thumbWidth = 320
+	// maxThumbs 单个文件最多生成的缩略图数
+	maxThumbs = 36
+	// sheetColumns 拼图每行的缩略图数
+	sheetColumns = 6
+	// ffmpegTimeout 单次 ffmpeg 调用的超时
+	ffmpegTimeout = 2 * time.Minute
+)
+
+// ErrFfmpegNotFound 未安装 ffmpeg
+var ErrFfmpegNotFound = errors.New("ffmpeg not found")
+
+var (
+	ffmpegOnce sync.Once
+	ffmpegPath string
+)
+
+// FfmpegPath 查找 ffmpeg，未安装返回空字符串
+func FfmpegPath() string {
+	ffmpegOnce.Do(func() {
+		ffmpegPath, _ = exec.LookPath("ffmpeg")
+	})
+	return ffmpegPath
+}
+
+// Result 生成结果，路径均为绝对路径或相对程序运行目录的路径
+type Result struct {
+	Thumbnails   []string
+	ContactSheet string
+}
+
+// Dir 缩略图目录：与录制文件同目录，以文件名加 .thumbs 命名
+func Dir(file string) string {
+	return strings.TrimSuffix(file, filepath.Ext(file)) + ".thumbs"
+}
+
+// SheetPath 拼图路径：与录制文件同目录
+func SheetPath(file string) string {
+	return strings.TrimSuffix(file, filepath.Ext(file)) + ".sheet.jpg"
+}
+
+// Timestamps 按间隔计算截图时间点（秒），数量超过上限时拉大间隔
+func Timestamps(duration float64, interval float64) []float64 {
+	if interval <= 0 {
+		interval = 300
+	}
+	if duration <= 0 {
+		return []float64{0}
+	}
+	count := int(math.Ceil(duration / interval))
+	if count > maxThumbs {
+		count = maxThumbs
+		interval = duration / float64(count)
+	}
+	// 从每个区间的中点截取，避开开头的黑屏
+	points := make([]float64, 0, count)
+	for i := 0; i < count; i++ {
+		t := interval*float64(i) + interval/2
+		if t >= duration {
+			t = duration / 2
+		}
+		points = append(points, t)
+	}
+	return points
+}
+
+// Generate 为录制文件按间隔截取关键帧缩略图并拼成拼图
+func Generate(ctx context.Context, file string, duration float64, interval float64) (*Result, error) {
+	ffmpeg := FfmpegPath()
+	if ffmpeg == "" {
+		return nil, ErrFfmpegNotFound
+	}
+
+	// 拼图按编号读取缩略图，清掉上次生成的文件，避免混入旧的截图
+	dir := Dir(file)
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("clear thumbs: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, fmt.Errorf("mkdir thumbs: %w", err)
+	}
+
+	result := &Result{}
+	for _, t := range Timestamps(duration, interval) {
+		out := filepath.Join(dir, fmt.Sprintf("thumb_%03d.jpg", len(result.Thumbnails)+1))
+		err := run(ctx, ffmpeg,
+			"-y", "-hide_banner", "-loglevel", "error",
+			"-skip_frame", "nokey", // 只解码关键帧
+			"-ss", strconv.FormatFloat(t, 'f', 2, 64),
+			"-i", file,
+			"-frames:v", "1",
+			"-vf", fmt.Sprintf("scale=%d:-2", thumbWidth),
+			"-q:v", "4",
+			out,
+		)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			// 单个时间点失败时跳过，文件末尾损坏等情况下仍保留其他缩略图
+			log.Warn().Err(err).Str("file", file).Float64("at", t).Msg("[Thumbnail] 截取缩略图失败，跳过")
+			_ = os.Remove(out)
+			continue
+		}
+		// 截取位置超出实际时长时 ffmpeg 不会输出文件
+		if _, err := os.Stat(out); err == nil {
+			result.Thumbnails = append(result.Thumbnails, out)
+		}
+	}
+	if len(result.Thumbnails) == 0 {
+		return nil, errors.New("未能截取到任何关键帧")
+	}
+
+	rows := (len(result.Thumbnails) + sheetColumns - 1) / sheetColumns
+	columns := sheetColumns
+	if len(result.Thumbnails) < columns {
+		columns = len(result.Thumbnails)
+	}
+	sheet := SheetPath(file)
+	err := run(ctx, ffmpeg,
+		"-y", "-hide_banner", "-loglevel", "error",
+		"-i", filepath.Join(dir, "thumb_%03d.jpg"),
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("tile=%dx%d:padding=4:margin=4", columns, rows),
+		"-q:v", "4",
+		sheet,
+	)
+	if err != nil {
+		// 拼图失败不影响已生成的缩略图
+		return result, fmt.Errorf("contact sheet: %w", err)
+	}
+	result.ContactSheet = sheet
+	return result, nil
+}
+
+func run(ctx context.Context, ffmpeg string, args ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, ffmpegTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, ffmpeg, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
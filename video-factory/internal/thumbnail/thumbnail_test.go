@@ -0,0 +1,94 @@
+package thumbnail
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestTimestamps(t *testing.T) {
+	points := Timestamps(900, 300)
+	want := []float64{150, 450, 750}
+	if len(points) != len(want) {
+		t.Fatalf("Timestamps() = %v, want %v", points, want)
+	}
+	for i := range want {
+		if points[i] != want[i] {
+			t.Errorf("Timestamps()[%d] = %v, want %v", i, points[i], want[i])
+		}
+	}
+
+	if got := Timestamps(0, 300); len(got) != 1 || got[0] != 0 {
+		t.Errorf("Timestamps(0) = %v, want [0]", got)
+	}
+	if got := Timestamps(100000, 10); len(got) != maxThumbs {
+		t.Errorf("len(Timestamps) = %d, want %d", len(got), maxThumbs)
+	}
+	for _, p := range Timestamps(60, 300) {
+		if p >= 60 {
+			t.Errorf("timestamp %v beyond duration", p)
+		}
+	}
+}
+
+func TestPaths(t *testing.T) {
+	file := filepath.Join("records", "abc_001.ts")
+	if got, want := Dir(file), filepath.Join("records", "abc_001.thumbs"); got != want {
+		t.Errorf("Dir() = %q, want %q", got, want)
+	}
+	if got, want := SheetPath(file), filepath.Join("records", "abc_001.sheet.jpg"); got != want {
+		t.Errorf("SheetPath() = %q, want %q", got, want)
+	}
+}
+
+func TestGenerateWithoutFfmpeg(t *testing.T) {
+	if FfmpegPath() != "" {
+		t.Skip("ffmpeg installed")
+	}
+	if _, err := Generate(context.Background(), "none.ts", 60, 10); !errors.Is(err, ErrFfmpegNotFound) {
+		t.Errorf("Generate() error = %v, want ErrFfmpegNotFound", err)
+	}
+}
+
+// fakeFfmpeg 用脚本代替 ffmpeg：在 450 秒处截图失败，其余调用创建最后一个参数指定的文件
+func fakeFfmpeg(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ffmpeg is a shell script")
+	}
+	script := filepath.Join(t.TempDir(), "ffmpeg")
+	content := "#!/bin/sh\ncase \"$*\" in *\"-ss 450.00\"*) echo seek failed >&2; exit 1;; esac\nfor last; do :; done\ntouch \"$last\"\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	FfmpegPath()
+	old := ffmpegPath
+	ffmpegPath = script
+	t.Cleanup(func() { ffmpegPath = old })
+}
+
+func TestGenerateSkipsFailedSeek(t *testing.T) {
+	fakeFfmpeg(t)
+	file := filepath.Join(t.TempDir(), "abc_001.ts")
+	// 上次生成留下的多余缩略图
+	if err := os.MkdirAll(Dir(file), 0777); err != nil {
+		t.Fatal(err)
+	}
+	stale := filepath.Join(Dir(file), "thumb_009.jpg")
+	if err := os.WriteFile(stale, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Generate(context.Background(), file, 900, 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Thumbnails) != 2 || result.ContactSheet == "" {
+		t.Errorf("result = %+v", result)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale thumbnail not removed, err = %v", err)
+	}
+}
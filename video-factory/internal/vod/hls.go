@@ -0,0 +1,271 @@
+package vod
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tsPacketSize = 188
+	// ptsClock PTS 时钟频率
+	ptsClock = 90000
+	// ptsWrap PTS 为 33 位，超出后回绕
+	ptsWrap = 1 << 33
+	// targetSegment 生成 VOD 播放列表时每个分片的目标时长（秒）
+	targetSegment = 6.0
+	// byteSegmentSize 找不到关键帧时按字节切分的分片大小
+	byteSegmentSize = 2 * 1024 * 1024
+	// maxCachedIndexes 缓存的文件索引数
+	maxCachedIndexes = 32
+)
+
+// Keyframe 关键帧在 TS 文件中的位置
+type Keyframe struct {
+	Offset int64
+	PTS    int64 // 90kHz
+}
+
+// Segment VOD 播放列表中的一个字节范围分片
+type Segment struct {
+	Offset   int64
+	Length   int64
+	Duration float64
+}
+
+// ScanKeyframes 顺序扫描 TS 文件，找出视频关键帧（随机访问点）的位置与 PTS
+func ScanKeyframes(r io.Reader) ([]Keyframe, int64, error) {
+	reader := bufio.NewReaderSize(r, 256*tsPacketSize)
+	packet := make([]byte, tsPacketSize)
+	var (
+		keyframes []Keyframe
+		offset    int64
+		lastPTS   int64 = -1
+		videoPID        = -1
+	)
+	for {
+		if _, err := io.ReadFull(reader, packet); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return keyframes, lastPTS, nil
+			}
+			return nil, 0, err
+		}
+		packetOffset := offset
+		offset += tsPacketSize
+		if packet[0] != 0x47 {
+			// 不同步时逐字节重新寻找同步字节
+			skipped, err := resync(reader)
+			offset += skipped
+			if err != nil {
+				return keyframes, lastPTS, nil
+			}
+			continue
+		}
+
+		pid := int(packet[1]&0x1F)<<8 | int(packet[2])
+		pusi := packet[1]&0x40 != 0
+		afc := (packet[3] >> 4) & 0x03
+		payload := 4
+		randomAccess := false
+		if afc&0x02 != 0 {
+			length := int(packet[4])
+			if length > 0 {
+				randomAccess = packet[5]&0x40 != 0
+			}
+			payload = 5 + length
+		}
+		if !pusi || afc&0x01 == 0 || payload+14 > tsPacketSize {
+			continue
+		}
+
+		// PES 头：00 00 01 stream_id，视频流 0xE0 ~ 0xEF
+		pes := packet[payload:]
+		if pes[0] != 0 || pes[1] != 0 || pes[2] != 1 || pes[3]&0xF0 != 0xE0 {
+			continue
+		}
+		if videoPID == -1 {
+			videoPID = pid
+		} else if pid != videoPID {
+			continue
+		}
+		if pes[7]&0x80 == 0 {
+			continue
+		}
+		pts := int64(pes[9]>>1&0x07)<<30 | int64(pes[10])<<22 | int64(pes[11]>>1)<<15 |
+			int64(pes[12])<<7 | int64(pes[13]>>1)
+		lastPTS = pts
+		if randomAccess {
+			keyframes = append(keyframes, Keyframe{Offset: packetOffset, PTS: pts})
+		}
+	}
+}
+
+// resync 跳到下一个同步字节，返回跳过的字节数
+func resync(reader *bufio.Reader) (int64, error) {
+	var skipped int64
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			return skipped, err
+		}
+		if b[0] == 0x47 {
+			return skipped, nil
+		}
+		_, _ = reader.Discard(1)
+		skipped++
+	}
+}
+
+// ptsDiff 计算两个 PTS 之间的秒数，处理回绕
+func ptsDiff(from int64, to int64) float64 {
+	d := to - from
+	if d < 0 {
+		d += ptsWrap
+	}
+	return float64(d) / ptsClock
+}
+
+// BuildSegments 按关键帧切分，每段不短于目标时长；没有关键帧时按字节切分并按比例估算时长
+func BuildSegments(keyframes []Keyframe, lastPTS int64, size int64, duration float64) []Segment {
+	if len(keyframes) == 0 {
+		return byteSegments(size, duration)
+	}
+
+	var segments []Segment
+	start := keyframes[0]
+	for _, kf := range keyframes[1:] {
+		d := ptsDiff(start.PTS, kf.PTS)
+		if d < targetSegment {
+			continue
+		}
+		segments = append(segments, Segment{Offset: start.Offset, Length: kf.Offset - start.Offset, Duration: d})
+		start = kf
+	}
+	last := Segment{Offset: start.Offset, Length: size - start.Offset}
+	if lastPTS >= 0 {
+		last.Duration = ptsDiff(start.PTS, lastPTS)
+	}
+	if last.Duration <= 0 {
+		last.Duration = targetSegment
+	}
+	if last.Length > 0 {
+		segments = append(segments, last)
+	}
+	// 第一个关键帧之前的数据无法独立解码，直接丢弃
+	return segments
+}
+
+func byteSegments(size int64, duration float64) []Segment {
+	if size <= 0 {
+		return nil
+	}
+	count := (size + byteSegmentSize - 1) / byteSegmentSize
+	var segments []Segment
+	for i := int64(0); i < count; i++ {
+		length := min(int64(byteSegmentSize), size-i*byteSegmentSize)
+		d := targetSegment
+		if duration > 0 {
+			d = duration * float64(length) / float64(size)
+		}
+		segments = append(segments, Segment{Offset: i * byteSegmentSize, Length: length, Duration: d})
+	}
+	return segments
+}
+
+// Playlist 生成使用 EXT-X-BYTERANGE 的 VOD 播放列表，所有分片指向同一个文件地址
+func Playlist(fileURL string, segments []Segment) string {
+	target := 1
+	for _, s := range segments {
+		target = max(target, int(math.Ceil(s.Duration)))
+	}
+
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	b.WriteString("#EXT-X-VERSION:4\n")
+	b.WriteString("#EXT-X-PLAYLIST-TYPE:VOD\n")
+	fmt.Fprintf(&b, "#EXT-X-TARGETDURATION:%d\n", target)
+	b.WriteString("#EXT-X-MEDIA-SEQUENCE:0\n")
+	for _, s := range segments {
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\n", s.Duration)
+		fmt.Fprintf(&b, "#EXT-X-BYTERANGE:%d@%d\n", s.Length, s.Offset)
+		b.WriteString(fileURL + "\n")
+	}
+	b.WriteString("#EXT-X-ENDLIST\n")
+	return b.String()
+}
+
+// EscapePath 转义相对路径用于 URL，保留 /
+func EscapePath(rel string) string {
+	parts := strings.Split(rel, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+type cachedIndex struct {
+	size     int64
+	modTime  time.Time
+	segments []Segment
+	usedAt   time.Time
+}
+
+var (
+	indexCache   = make(map[string]*cachedIndex)
+	indexCacheMu sync.Mutex
+)
+
+// IndexFile 生成文件的分片索引，按文件大小与修改时间缓存
+// 正在录制的文件大小会变化，每次请求都会重新扫描
+func IndexFile(file string, duration float64) ([]Segment, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+
+	indexCacheMu.Lock()
+	if cached, ok := indexCache[file]; ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		cached.usedAt = time.Now()
+		indexCacheMu.Unlock()
+		return cached.segments, nil
+	}
+	indexCacheMu.Unlock()
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	keyframes, lastPTS, err := ScanKeyframes(io.LimitReader(f, info.Size()))
+	if err != nil {
+		return nil, err
+	}
+	segments := BuildSegments(keyframes, lastPTS, info.Size(), duration)
+
+	indexCacheMu.Lock()
+	defer indexCacheMu.Unlock()
+	if len(indexCache) >= maxCachedIndexes {
+		evictOldest()
+	}
+	indexCache[file] = &cachedIndex{size: info.Size(), modTime: info.ModTime(), segments: segments, usedAt: time.Now()}
+	return segments, nil
+}
+
+func evictOldest() {
+	var (
+		oldestKey string
+		oldestAt  time.Time
+	)
+	for key, cached := range indexCache {
+		if oldestKey == "" || cached.usedAt.Before(oldestAt) {
+			oldestKey, oldestAt = key, cached.usedAt
+		}
+	}
+	delete(indexCache, oldestKey)
+}
@@ -0,0 +1,142 @@
+package vod
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrOutsideRoot 请求的路径不在录制根目录下
+var ErrOutsideRoot = errors.New("路径不在录制目录下")
+
+// mediaExts 允许浏览与下载的文件类型，其他文件（如数据库、配置）不对外暴露
+var mediaExts = map[string]bool{
+	".ts":  true,
+	".flv": true,
+	".mp4": true,
+	".m4a": true,
+	".aac": true,
+}
+
+// IsMedia 是否为允许访问的录制文件
+func IsMedia(name string) bool {
+	return mediaExts[strings.ToLower(filepath.Ext(name))]
+}
+
+// SafeJoin 将相对路径拼接到 root 下，拒绝 ..、绝对路径以及指向 root 之外的符号链接
+func SafeJoin(root string, rel string) (string, error) {
+	rootAbs, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	// 不接受 \ 分隔符与 ..，避免不同系统上的解析差异
+	if strings.ContainsRune(rel, 0) || strings.Contains(rel, `\`) || filepath.IsAbs(rel) {
+		return "", ErrOutsideRoot
+	}
+	for _, segment := range strings.Split(rel, "/") {
+		if segment == ".." {
+			return "", ErrOutsideRoot
+		}
+	}
+	full := filepath.Join(rootAbs, filepath.FromSlash(path.Clean("/"+rel)))
+
+	// 解析符号链接后再次检查
+	realRoot, err := filepath.EvalSymlinks(rootAbs)
+	if err != nil {
+		return "", err
+	}
+	realFull, err := filepath.EvalSymlinks(full)
+	if err != nil {
+		return "", err
+	}
+	if !within(realRoot, realFull) {
+		return "", ErrOutsideRoot
+	}
+	return realFull, nil
+}
+
+// RelPath 文件相对 root 的路径，使用 / 分隔，不在 root 下时返回 false
+func RelPath(root string, file string) (string, bool) {
+	rootAbs, err := filepath.Abs(root)
+	if err != nil {
+		return "", false
+	}
+	fileAbs, err := filepath.Abs(file)
+	if err != nil {
+		return "", false
+	}
+	if !within(rootAbs, fileAbs) {
+		return "", false
+	}
+	rel, err := filepath.Rel(rootAbs, fileAbs)
+	if err != nil {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+func within(root string, target string) bool {
+	rel, err := filepath.Rel(root, target)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// Entry 目录中的一项
+type Entry struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"` // 相对录制根目录，/ 分隔
+	IsDir   bool      `json:"isDir"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// ListDir 列出目录下的子目录与录制文件，目录在前，文件按修改时间倒序
+func ListDir(root string, rel string) ([]Entry, error) {
+	dir, err := SafeJoin(root, rel)
+	if err != nil {
+		return nil, err
+	}
+	items, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	base := strings.Trim(path.Clean("/"+rel), "/")
+
+	entries := make([]Entry, 0, len(items))
+	for _, item := range items {
+		// 隐藏文件与缩略图目录不展示
+		if strings.HasPrefix(item.Name(), ".") || strings.HasSuffix(item.Name(), ".thumbs") {
+			continue
+		}
+		if !item.IsDir() && !IsMedia(item.Name()) {
+			continue
+		}
+		info, err := item.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, Entry{
+			Name:    item.Name(),
+			Path:    path.Join(base, item.Name()),
+			IsDir:   item.IsDir(),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].IsDir != entries[j].IsDir {
+			return entries[i].IsDir
+		}
+		if entries[i].IsDir {
+			return entries[i].Name < entries[j].Name
+		}
+		return entries[i].ModTime.After(entries[j].ModTime)
+	})
+	return entries, nil
+}
@@ -0,0 +1,132 @@
+package vod
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// videoPacket 构造带 PES 头的视频 TS 包
+func videoPacket(pts int64, keyframe bool) []byte {
+	p := make([]byte, tsPacketSize)
+	p[0] = 0x47
+	p[1] = 0x40 | 0x01 // PUSI，PID 0x100
+	p[2] = 0x00
+	p[3] = 0x30 // 自适应字段 + 负载
+	p[4] = 1    // 自适应字段长度
+	if keyframe {
+		p[5] = 0x40
+	}
+	pes := p[6:]
+	copy(pes, []byte{0, 0, 1, 0xE0, 0, 0, 0x80, 0x80, 5})
+	pes[9] = byte(0x21 | (pts>>29)&0x0E)
+	pes[10] = byte(pts >> 22)
+	pes[11] = byte((pts>>14)&0xFE | 1)
+	pes[12] = byte(pts >> 7)
+	pes[13] = byte((pts<<1)&0xFE | 1)
+	return p
+}
+
+func fillerPacket() []byte {
+	p := bytes.Repeat([]byte{0xFF}, tsPacketSize)
+	p[0], p[1], p[2], p[3] = 0x47, 0x01, 0x01, 0x10
+	return p
+}
+
+func TestScanKeyframesAndSegments(t *testing.T) {
+	var data []byte
+	// 每秒 1 帧，每 2 秒一个关键帧，共 20 秒
+	for i := 0; i < 20; i++ {
+		data = append(data, videoPacket(int64(i)*ptsClock, i%2 == 0)...)
+		data = append(data, fillerPacket()...)
+	}
+	keyframes, lastPTS, err := ScanKeyframes(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keyframes) != 10 {
+		t.Fatalf("len(keyframes) = %d, want 10", len(keyframes))
+	}
+	if keyframes[1].Offset != 4*tsPacketSize || keyframes[1].PTS != 2*ptsClock {
+		t.Errorf("keyframes[1] = %+v", keyframes[1])
+	}
+	if lastPTS != 19*ptsClock {
+		t.Errorf("lastPTS = %d", lastPTS)
+	}
+
+	segments := BuildSegments(keyframes, lastPTS, int64(len(data)), 0)
+	var total int64
+	for i, s := range segments {
+		if i < len(segments)-1 && s.Duration < targetSegment {
+			t.Errorf("segments[%d].Duration = %v, want >= %v", i, s.Duration, targetSegment)
+		}
+		if s.Offset != total {
+			t.Errorf("segments[%d].Offset = %d, want %d", i, s.Offset, total)
+		}
+		total += s.Length
+	}
+	if total != int64(len(data)) {
+		t.Errorf("segments cover %d bytes, want %d", total, len(data))
+	}
+
+	playlist := Playlist("/api/v1/files/stream/a.ts", segments)
+	for _, want := range []string{"#EXT-X-PLAYLIST-TYPE:VOD", "#EXT-X-BYTERANGE:", "#EXT-X-ENDLIST", "#EXT-X-VERSION:4"} {
+		if !strings.Contains(playlist, want) {
+			t.Errorf("playlist missing %q", want)
+		}
+	}
+}
+
+func TestBuildSegmentsByBytes(t *testing.T) {
+	segments := BuildSegments(nil, -1, 5*1024*1024, 50)
+	if len(segments) != 3 {
+		t.Fatalf("len(segments) = %d, want 3", len(segments))
+	}
+	if segments[2].Length != 1024*1024 || segments[2].Duration != 10 {
+		t.Errorf("segments[2] = %+v", segments[2])
+	}
+}
+
+func TestSafeJoin(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "room", "2024"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "room", "2024", "a.ts"), []byte("x"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.ts"), []byte("x"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Skip("symlink not supported")
+	}
+
+	if _, err := SafeJoin(root, "room/2024/a.ts"); err != nil {
+		t.Errorf("SafeJoin(valid) error = %v", err)
+	}
+	for _, rel := range []string{"../secret.ts", "room/../../secret.ts", "/etc/passwd", `room\..\..\x`, "link/secret.ts"} {
+		if _, err := SafeJoin(root, rel); !errors.Is(err, ErrOutsideRoot) {
+			t.Errorf("SafeJoin(%q) error = %v, want ErrOutsideRoot", rel, err)
+		}
+	}
+
+	entries, err := ListDir(root, "room")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !entries[0].IsDir || entries[0].Path != "room/2024" {
+		t.Errorf("ListDir() = %+v", entries)
+	}
+
+	if rel, ok := RelPath(root, filepath.Join(root, "room", "2024", "a.ts")); !ok || rel != "room/2024/a.ts" {
+		t.Errorf("RelPath() = %q, %v", rel, ok)
+	}
+	if _, ok := RelPath(root, filepath.Join(outside, "secret.ts")); ok {
+		t.Error("RelPath should reject files outside root")
+	}
+}
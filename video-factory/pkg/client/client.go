@@ -0,0 +1,129 @@
+// Package client video-factory /api/v1 接口的 Go 客户端
+//
+// 接口方法与数据类型由 docs/openapi.json 生成，见 client_gen.go
+package client
+
+//go:generate go run ../../cmd/openapi-gen -spec ../../docs/openapi.json -client client_gen.go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client 接口客户端，BaseURL 为服务地址，如 http://127.0.0.1:8080
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Page 列表接口的分页数据
+type Page[T any] struct {
+	List     []T   `json:"list"`
+	Total    int64 `json:"total"`
+	Page     int   `json:"page"`
+	PageSize int   `json:"pageSize"`
+}
+
+// Error 接口返回的错误，HTTP 状态码非 200 或业务状态码非 0
+type Error struct {
+	StatusCode int
+	Code       int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.StatusCode != http.StatusOK {
+		return fmt.Sprintf("video-factory: HTTP %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("video-factory: code %d: %s", e.Code, e.Message)
+}
+
+type envelope struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// do 请求 JSON 接口，成功时将 data 解析到 out
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body any, out any) error {
+	resp, err := c.raw(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result envelope
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Code != 0 {
+		return &Error{StatusCode: resp.StatusCode, Code: result.Code, Message: result.Message}
+	}
+	if out == nil || len(result.Data) == 0 || string(result.Data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		return fmt.Errorf("解析响应数据失败: %w", err)
+	}
+	return nil
+}
+
+// raw 发送请求并返回原始响应，状态码非 2xx 时返回 *Error
+func (c *Client) raw(ctx context.Context, method, path string, query url.Values, body any) (*http.Response, error) {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, &Error{StatusCode: resp.StatusCode, Code: -1, Message: strings.TrimSpace(string(msg))}
+	}
+	return resp, nil
+}
+
+// escapePath 转义路径参数，保留其中的 / 以支持 *file 这类通配参数
+func escapePath(s string) string {
+	segments := strings.Split(strings.TrimPrefix(s, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,1419 @@
+// Code generated by openapi-gen from docs/openapi.json. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type BackupVO struct {
+	Name        string    `json:"name"`
+	Filesize    int64     `json:"filesize"`
+	FilesizeStr string    `json:"filesizeStr"`
+	CreateTime  time.Time `json:"createTime"`
+}
+
+type BreakerState struct {
+	Platform  string     `json:"platform"`
+	State     string     `json:"state"`
+	Level     int        `json:"level"`
+	Trips     int        `json:"trips"`
+	OpenUntil *time.Time `json:"openUntil"`
+	LastTrip  *time.Time `json:"lastTrip"`
+	LastError string     `json:"lastError"`
+}
+
+type ClipLiveVO struct {
+	RoomID  string `json:"roomId"`
+	Seconds int    `json:"seconds"`
+}
+
+type ClipRecordingVO struct {
+	RecordingID string `json:"recordingId"`
+	Start       string `json:"start"`
+	End         string `json:"end"`
+}
+
+type ClipVO struct {
+	ID          int64     `json:"id,string"`
+	RoomID      int64     `json:"roomId,string"`
+	SessionID   int64     `json:"sessionId,string"`
+	RecordingID int64     `json:"recordingId,string"`
+	Source      string    `json:"source"`
+	Start       float64   `json:"start"`
+	StartStr    string    `json:"startStr"`
+	Duration    float64   `json:"duration"`
+	DurationStr string    `json:"durationStr"`
+	Filename    string    `json:"filename"`
+	Filesize    int64     `json:"filesize"`
+	FilesizeStr string    `json:"filesizeStr"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error"`
+	FileURL     string    `json:"fileUrl"`
+	CreateTime  time.Time `json:"createTime"`
+}
+
+type ClusterNodeVO struct {
+	ID            string    `json:"id"`
+	WorkerID      int64     `json:"workerId"`
+	Addr          string    `json:"addr"`
+	StartTime     time.Time `json:"startTime"`
+	HeartbeatTime time.Time `json:"heartbeatTime"`
+	Alive         bool      `json:"alive"`
+	Self          bool      `json:"self"`
+	Rooms         int       `json:"rooms"`
+}
+
+type ConfigAddVO struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description"`
+}
+
+type ConfigHistoryVO struct {
+	ID          int64     `json:"id,string"`
+	ConfigID    int64     `json:"configId,string"`
+	Key         string    `json:"key"`
+	OldValue    string    `json:"oldValue"`
+	NewValue    string    `json:"newValue"`
+	Action      string    `json:"action"`
+	Create_time time.Time `json:"create_time"`
+}
+
+type ConfigRollbackVO struct {
+	HistoryID int64 `json:"historyId,string"`
+}
+
+type ConfigUpdateVO struct {
+	ID          int64  `json:"id,string"`
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description"`
+}
+
+type ConfigVO struct {
+	ID              int64     `json:"id,string"`
+	Key             string    `json:"key"`
+	Value           string    `json:"value"`
+	Description     string    `json:"description"`
+	Type            string    `json:"type"`
+	Secret          bool      `json:"secret"`
+	RequiresRestart bool      `json:"requiresRestart"`
+	Create_time     time.Time `json:"create_time"`
+	Update_time     time.Time `json:"update_time"`
+}
+
+type Entry struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	IsDir   bool      `json:"isDir"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+type FieldSchema struct {
+	Key             string   `json:"key"`
+	Type            string   `json:"type"`
+	Default         string   `json:"default"`
+	Min             *int64   `json:"min"`
+	Max             *int64   `json:"max"`
+	Enum            []string `json:"enum"`
+	RequiresRestart bool     `json:"requiresRestart"`
+	Secret          bool     `json:"secret"`
+	Description     string   `json:"description"`
+}
+
+type FilenamePreviewResultVO struct {
+	Filename string `json:"filename"`
+}
+
+type FilenamePreviewVO struct {
+	Pattern string `json:"pattern"`
+}
+
+type HealthSampleVO struct {
+	At      int64   `json:"at"`
+	Bitrate float64 `json:"bitrate"`
+	Fps     float64 `json:"fps"`
+	Speed   float64 `json:"speed"`
+	Line    string  `json:"line"`
+}
+
+type HealthVO struct {
+	StartTime      int64            `json:"startTime"`
+	Bitrate        float64          `json:"bitrate"`
+	AvgBitrate     float64          `json:"avgBitrate"`
+	FfmpegBitrate  float64          `json:"ffmpegBitrate"`
+	Fps            float64          `json:"fps"`
+	AvgFps         float64          `json:"avgFps"`
+	Speed          float64          `json:"speed"`
+	GapCount       int              `json:"gapCount"`
+	StallCount     int              `json:"stallCount"`
+	URLSwitchCount int              `json:"urlSwitchCount"`
+	Lines          []LineStatsVO    `json:"lines"`
+	Samples        []HealthSampleVO `json:"samples"`
+}
+
+type LineStatsVO struct {
+	Name     string  `json:"name"`
+	Bytes    int64   `json:"bytes"`
+	Seconds  float64 `json:"seconds"`
+	Bitrate  float64 `json:"bitrate"`
+	Stalls   int     `json:"stalls"`
+	Gaps     int     `json:"gaps"`
+	Failures int     `json:"failures"`
+}
+
+type ManagerVO struct {
+	RoomID            int64      `json:"roomId"`
+	RealID            string     `json:"realId"`
+	Platform          string     `json:"platform"`
+	Name              string     `json:"name"`
+	Cover_url         string     `json:"cover_url"`
+	AnchorName        string     `json:"anchorName"`
+	AnchorID          string     `json:"anchorId"`
+	Anchor_avatar     string     `json:"anchor_avatar"`
+	LiveStatus        int        `json:"liveStatus"`
+	SessionID         int64      `json:"sessionId,string"`
+	State             string     `json:"state"`
+	StateSince        *time.Time `json:"stateSince"`
+	URL               string     `json:"url"`
+	ProxyURL          string     `json:"proxyUrl"`
+	CurrentURL        string     `json:"currentUrl"`
+	CurrentLine       string     `json:"currentLine"`
+	Quality           string     `json:"quality"`
+	Channel           string     `json:"channel"`
+	LastRefresh       *time.Time `json:"lastRefresh"`
+	ExpireTime        *time.Time `json:"expireTime"`
+	RecordStatus      int        `json:"recordStatus"`
+	RecordFile        string     `json:"recordFile"`
+	RecordSize        int        `json:"recordSize"`
+	RecordSizeStr     string     `json:"recordSizeStr"`
+	RecordDuration    float64    `json:"recordDuration"`
+	RecordDurationStr string     `json:"recordDurationStr"`
+	RecordLine        string     `json:"recordLine"`
+	RecordHealth      HealthVO   `json:"recordHealth"`
+	NodeID            string     `json:"nodeId"`
+}
+
+type NotifyChannelAddVO struct {
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	Config    map[string]any    `json:"config"`
+	Templates map[string]string `json:"templates"`
+}
+
+type NotifyChannelUpdateVO struct {
+	Name      string            `json:"name"`
+	Config    map[string]any    `json:"config"`
+	Templates map[string]string `json:"templates"`
+	Enabled   *int              `json:"enabled"`
+}
+
+type NotifyChannelVO struct {
+	ID         int64             `json:"id,string"`
+	Name       string            `json:"name"`
+	Type       string            `json:"type"`
+	Config     map[string]any    `json:"config"`
+	Templates  map[string]string `json:"templates"`
+	Enabled    int               `json:"enabled"`
+	CreateTime time.Time         `json:"createTime"`
+}
+
+type NotifySubscriptionSaveVO struct {
+	ChannelID string   `json:"channelId"`
+	RoomID    string   `json:"roomId"`
+	Events    []string `json:"events"`
+}
+
+type NotifySubscriptionVO struct {
+	ID          int64    `json:"id,string"`
+	ChannelID   int64    `json:"channelId,string"`
+	ChannelName string   `json:"channelName"`
+	RoomID      int64    `json:"roomId,string"`
+	AnchorName  string   `json:"anchorName"`
+	Events      []string `json:"events"`
+}
+
+type PlatformStatusVO struct {
+	Breakers            []BreakerState `json:"breakers"`
+	MonitorBackoffUntil *time.Time     `json:"monitorBackoffUntil"`
+}
+
+type RecordingFileVO struct {
+	ID          int64     `json:"id,string"`
+	RoomID      int64     `json:"roomId,string"`
+	Date        string    `json:"date"`
+	Name        string    `json:"name"`
+	Path        string    `json:"path"`
+	Filesize    int64     `json:"filesize"`
+	FilesizeStr string    `json:"filesizeStr"`
+	Duration    float64   `json:"duration"`
+	DurationStr string    `json:"durationStr"`
+	StartTime   time.Time `json:"startTime"`
+	Exists      bool      `json:"exists"`
+	StreamURL   string    `json:"streamUrl"`
+	PlaylistURL string    `json:"playlistUrl"`
+}
+
+type RecordingVO struct {
+	ID              int64            `json:"id,string"`
+	RoomID          int64            `json:"roomId,string"`
+	SessionID       int64            `json:"sessionId,string"`
+	Filename        string           `json:"filename"`
+	Filesize        int64            `json:"filesize"`
+	FilesizeStr     string           `json:"filesizeStr"`
+	Duration        float64          `json:"duration"`
+	DurationStr     string           `json:"durationStr"`
+	StartTime       time.Time        `json:"startTime"`
+	EndTime         time.Time        `json:"endTime"`
+	AvgBitrate      float64          `json:"avgBitrate"`
+	AvgFps          float64          `json:"avgFps"`
+	GapCount        int              `json:"gapCount"`
+	StallCount      int              `json:"stallCount"`
+	URLSwitchCount  int              `json:"urlSwitchCount"`
+	Lines           []LineStatsVO    `json:"lines"`
+	Samples         []HealthSampleVO `json:"samples"`
+	ThumbStatus     string           `json:"thumbStatus"`
+	ThumbnailUrls   []string         `json:"thumbnailUrls"`
+	ContactSheetURL string           `json:"contactSheetUrl"`
+}
+
+type RoomBulkResultVO struct {
+	Matched int      `json:"matched"`
+	Changed int      `json:"changed"`
+	Failed  []string `json:"failed"`
+}
+
+type RoomBulkVO struct {
+	Action string      `json:"action"`
+	Filter RoomQueryVO `json:"filter"`
+}
+
+type RoomCreateVO struct {
+	RoomInput string `json:"roomInput"`
+	Platform  string `json:"platform"`
+}
+
+type RoomFacetVO struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type RoomFacetsVO struct {
+	Groups []RoomFacetVO `json:"groups"`
+	Tags   []RoomFacetVO `json:"tags"`
+}
+
+type RoomLabelsVO struct {
+	RoomID string   `json:"roomId"`
+	Group  string   `json:"group"`
+	Tags   []string `json:"tags"`
+}
+
+type RoomQueryVO struct {
+	RoomIDs      []string `json:"roomIds"`
+	Keyword      string   `json:"keyword"`
+	Platform     string   `json:"platform"`
+	Status       *int     `json:"status"`
+	RecordStatus *int     `json:"recordStatus"`
+	LiveStatus   *int     `json:"liveStatus"`
+	Group        string   `json:"group"`
+	Tag          string   `json:"tag"`
+}
+
+type RoomRecordEngineVO struct {
+	RoomID string `json:"roomId"`
+	Engine string `json:"engine"`
+}
+
+type RoomRecordModeVO struct {
+	RoomID string `json:"roomId"`
+	Mode   string `json:"mode"`
+}
+
+type RoomStatusVO struct {
+	RoomID string `json:"roomId"`
+	Status int    `json:"status"`
+}
+
+type RoomTitleVO struct {
+	SessionID  int64     `json:"sessionId,string"`
+	Title      string    `json:"title"`
+	CreateTime time.Time `json:"createTime"`
+}
+
+type RoomVO struct {
+	ID           string    `json:"id"`
+	Platform     string    `json:"platform"`
+	ShortID      string    `json:"shortId"`
+	RealID       string    `json:"realId"`
+	Name         string    `json:"name"`
+	URL          string    `json:"url"`
+	CoverURL     string    `json:"coverUrl"`
+	ProxyURL     string    `json:"proxyUrl"`
+	AnchorName   string    `json:"anchorName"`
+	AnchorAvatar string    `json:"anchorAvatar"`
+	LiveStatus   int       `json:"liveStatus"`
+	Status       int       `json:"status"`
+	RecordStatus int       `json:"recordStatus"`
+	RecordMode   string    `json:"recordMode"`
+	RecordEngine string    `json:"recordEngine"`
+	Group        string    `json:"group"`
+	Tags         []string  `json:"tags"`
+	CreateTime   time.Time `json:"createTime"`
+	UpdateTime   time.Time `json:"updateTime"`
+}
+
+type Score struct {
+	Platform    string  `json:"platform"`
+	Host        string  `json:"host"`
+	Ttfb        float64 `json:"ttfb"`
+	Throughput  float64 `json:"throughput"`
+	Successes   int     `json:"successes"`
+	Failures    int     `json:"failures"`
+	LastProbe   int64   `json:"lastProbe"`
+	LastFailure int64   `json:"lastFailure"`
+}
+
+type SessionGapVO struct {
+	StartTime int64 `json:"startTime"`
+	EndTime   int64 `json:"endTime"`
+}
+
+type SessionVO struct {
+	ID        int64          `json:"id,string"`
+	RoomID    int64          `json:"roomId,string"`
+	OpenTime  int64          `json:"openTime"`
+	StartTime int64          `json:"startTime"`
+	EndTime   int64          `json:"endTime"`
+	Sequence  int            `json:"sequence"`
+	Gaps      []SessionGapVO `json:"gaps"`
+}
+
+type StorageAddVO struct {
+	Name   string         `json:"name"`
+	Type   string         `json:"type"`
+	Config map[string]any `json:"config"`
+}
+
+type StorageUpdateVO struct {
+	Name   string         `json:"name"`
+	Config map[string]any `json:"config"`
+}
+
+type StorageVO struct {
+	ID         int64          `json:"id,string"`
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Config     map[string]any `json:"config"`
+	CreateTime time.Time      `json:"createTime"`
+}
+
+type TransitionVO struct {
+	ID     int64  `json:"id,string"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+	Time   int64  `json:"time"`
+}
+
+type UploadRecordingVO struct {
+	RecordingID string `json:"recordingId"`
+	StorageID   string `json:"storageId"`
+}
+
+type UploadRuleSaveVO struct {
+	RoomID      string `json:"roomId"`
+	StorageID   string `json:"storageId"`
+	PathPrefix  string `json:"pathPrefix"`
+	DeleteLocal int    `json:"deleteLocal"`
+	Enabled     int    `json:"enabled"`
+}
+
+type UploadRuleVO struct {
+	ID          int64  `json:"id,string"`
+	RoomID      int64  `json:"roomId,string"`
+	StorageID   int64  `json:"storageId,string"`
+	StorageName string `json:"storageName"`
+	PathPrefix  string `json:"pathPrefix"`
+	DeleteLocal int    `json:"deleteLocal"`
+	Enabled     int    `json:"enabled"`
+}
+
+type UploadVO struct {
+	ID          int64     `json:"id,string"`
+	RecordingID int64     `json:"recordingId,string"`
+	RoomID      int64     `json:"roomId,string"`
+	StorageID   int64     `json:"storageId,string"`
+	LocalPath   string    `json:"localPath"`
+	RemoteKey   string    `json:"remoteKey"`
+	Size        int64     `json:"size"`
+	SizeStr     string    `json:"sizeStr"`
+	Checksum    string    `json:"checksum"`
+	Status      string    `json:"status"`
+	Attempts    int       `json:"attempts"`
+	Error       string    `json:"error"`
+	DeleteLocal int       `json:"deleteLocal"`
+	CreateTime  time.Time `json:"createTime"`
+	UpdateTime  time.Time `json:"updateTime"`
+}
+
+// AddConfig 添加配置
+//
+// POST /api/v1/config/add
+func (c *Client) AddConfig(ctx context.Context, body *ConfigAddVO) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/config/add", nil, body, nil)
+}
+
+// AddNotifyChannel 添加通知渠道
+//
+// POST /api/v1/notify/channel/add
+func (c *Client) AddNotifyChannel(ctx context.Context, body *NotifyChannelAddVO) (*NotifyChannelVO, error) {
+	var out NotifyChannelVO
+	if err := c.do(ctx, http.MethodPost, "/api/v1/notify/channel/add", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AddRoom 添加房间
+//
+// POST /api/v1/room/add
+func (c *Client) AddRoom(ctx context.Context, body *RoomCreateVO) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/room/add", nil, body, nil)
+}
+
+// AddStorage 添加存储
+//
+// POST /api/v1/storage/add
+func (c *Client) AddStorage(ctx context.Context, body *StorageAddVO) (*StorageVO, error) {
+	var out StorageVO
+	if err := c.do(ctx, http.MethodPost, "/api/v1/storage/add", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BrowseFilesParams 查询参数，零值的字段不发送
+type BrowseFilesParams struct {
+	// 相对录制根目录的路径
+	Path string
+}
+
+func (p *BrowseFilesParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.Path != "" {
+		q.Set("path", p.Path)
+	}
+	return q
+}
+
+// BrowseFiles 浏览录制目录
+//
+// GET /api/v1/files/browse
+func (c *Client) BrowseFiles(ctx context.Context, params *BrowseFilesParams) (*Page[Entry], error) {
+	var out Page[Entry]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/files/browse", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BulkRooms 对符合条件的房间批量启用、停用、开关录制
+//
+// POST /api/v1/room/bulk
+func (c *Client) BulkRooms(ctx context.Context, body *RoomBulkVO) (*RoomBulkResultVO, error) {
+	var out RoomBulkResultVO
+	if err := c.do(ctx, http.MethodPost, "/api/v1/room/bulk", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ClipLive 保存直播中最近一段时间的片段
+//
+// POST /api/v1/clip/live
+func (c *Client) ClipLive(ctx context.Context, body *ClipLiveVO) (*ClipVO, error) {
+	var out ClipVO
+	if err := c.do(ctx, http.MethodPost, "/api/v1/clip/live", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ClipRecording 从录制文件中截取片段
+//
+// POST /api/v1/clip/recording
+func (c *Client) ClipRecording(ctx context.Context, body *ClipRecordingVO) (*ClipVO, error) {
+	var out ClipVO
+	if err := c.do(ctx, http.MethodPost, "/api/v1/clip/recording", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateBackup 立即备份数据库
+//
+// POST /api/v1/backup/create
+func (c *Client) CreateBackup(ctx context.Context) (*BackupVO, error) {
+	var out BackupVO
+	if err := c.do(ctx, http.MethodPost, "/api/v1/backup/create", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DownloadBackup 下载备份文件
+//
+// GET /api/v1/backup/{name}/file
+//
+// 调用方需要关闭返回的 Body
+func (c *Client) DownloadBackup(ctx context.Context, name string) (*http.Response, error) {
+	return c.raw(ctx, http.MethodGet, "/api/v1/backup/"+escapePath(name)+"/file", nil, nil)
+}
+
+// DownloadClip 下载片段文件
+//
+// GET /api/v1/clip/{id}/file
+//
+// 调用方需要关闭返回的 Body
+func (c *Client) DownloadClip(ctx context.Context, id int64) (*http.Response, error) {
+	return c.raw(ctx, http.MethodGet, "/api/v1/clip/"+strconv.FormatInt(id, 10)+"/file", nil, nil)
+}
+
+// GetClip 片段详情
+//
+// GET /api/v1/clip/{id}
+func (c *Client) GetClip(ctx context.Context, id int64) (*ClipVO, error) {
+	var out ClipVO
+	if err := c.do(ctx, http.MethodGet, "/api/v1/clip/"+strconv.FormatInt(id, 10), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetContactSheet 录制文件的缩略图拼图
+//
+// GET /api/v1/recording/{id}/sheet
+//
+// 调用方需要关闭返回的 Body
+func (c *Client) GetContactSheet(ctx context.Context, id int64) (*http.Response, error) {
+	return c.raw(ctx, http.MethodGet, "/api/v1/recording/"+strconv.FormatInt(id, 10)+"/sheet", nil, nil)
+}
+
+// GetDVR 回看的播放列表 index.m3u8 及其中的分片
+//
+// GET /api/v1/stream/dvr/{managerId}/{file}
+//
+// 调用方需要关闭返回的 Body
+func (c *Client) GetDVR(ctx context.Context, managerID int64, file string) (*http.Response, error) {
+	return c.raw(ctx, http.MethodGet, "/api/v1/stream/dvr/"+strconv.FormatInt(managerID, 10)+"/"+escapePath(file), nil, nil)
+}
+
+// GetFilePlaylist TS 文件的 HLS 播放列表
+//
+// GET /api/v1/files/hls/{path}
+//
+// 调用方需要关闭返回的 Body
+func (c *Client) GetFilePlaylist(ctx context.Context, path string) (*http.Response, error) {
+	return c.raw(ctx, http.MethodGet, "/api/v1/files/hls/"+escapePath(path), nil, nil)
+}
+
+// GetImage 本地缓存的封面与头像
+//
+// GET /api/v1/image/{name}
+//
+// 调用方需要关闭返回的 Body
+func (c *Client) GetImage(ctx context.Context, name string) (*http.Response, error) {
+	return c.raw(ctx, http.MethodGet, "/api/v1/image/"+escapePath(name), nil, nil)
+}
+
+// GetPlatformStatus 各平台的熔断与退避状态
+//
+// GET /api/v1/monitor/platform
+func (c *Client) GetPlatformStatus(ctx context.Context) (*PlatformStatusVO, error) {
+	var out PlatformStatusVO
+	if err := c.do(ctx, http.MethodGet, "/api/v1/monitor/platform", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetRecording 录制记录详情
+//
+// GET /api/v1/recording/{id}
+func (c *Client) GetRecording(ctx context.Context, id int64) (*RecordingVO, error) {
+	var out RecordingVO
+	if err := c.do(ctx, http.MethodGet, "/api/v1/recording/"+strconv.FormatInt(id, 10), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetRoom 房间详情
+//
+// GET /api/v1/room/{roomId}
+func (c *Client) GetRoom(ctx context.Context, roomID int64) (*RoomVO, error) {
+	var out RoomVO
+	if err := c.do(ctx, http.MethodGet, "/api/v1/room/"+strconv.FormatInt(roomID, 10), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetRoomFacets 所有分组与标签及其房间数
+//
+// GET /api/v1/room/facets
+func (c *Client) GetRoomFacets(ctx context.Context) (*RoomFacetsVO, error) {
+	var out RoomFacetsVO
+	if err := c.do(ctx, http.MethodGet, "/api/v1/room/facets", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSession 开播记录及断流区间
+//
+// GET /api/v1/monitor/session/{sessionId}
+func (c *Client) GetSession(ctx context.Context, sessionID int64) (*SessionVO, error) {
+	var out SessionVO
+	if err := c.do(ctx, http.MethodGet, "/api/v1/monitor/session/"+strconv.FormatInt(sessionID, 10), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetThumbnail 录制文件的缩略图
+//
+// GET /api/v1/recording/{id}/thumbnail/{index}
+//
+// 调用方需要关闭返回的 Body
+func (c *Client) GetThumbnail(ctx context.Context, id int64, index int) (*http.Response, error) {
+	return c.raw(ctx, http.MethodGet, "/api/v1/recording/"+strconv.FormatInt(id, 10)+"/thumbnail/"+strconv.Itoa(index), nil, nil)
+}
+
+// ListBackups 数据库备份列表
+//
+// GET /api/v1/backup/list
+func (c *Client) ListBackups(ctx context.Context) (*Page[BackupVO], error) {
+	var out Page[BackupVO]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/backup/list", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListClipsParams 查询参数，零值的字段不发送
+type ListClipsParams struct {
+	// 房间 ID，0 表示全部
+	RoomID int64
+	// 最多返回条数
+	Limit int
+}
+
+func (p *ListClipsParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.RoomID != 0 {
+		q.Set("roomId", strconv.FormatInt(p.RoomID, 10))
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return q
+}
+
+// ListClips 片段列表
+//
+// GET /api/v1/clip/list
+func (c *Client) ListClips(ctx context.Context, params *ListClipsParams) (*Page[ClipVO], error) {
+	var out Page[ClipVO]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/clip/list", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListClusterNodes 集群节点及其持有的房间数
+//
+// GET /api/v1/cluster/nodes
+func (c *Client) ListClusterNodes(ctx context.Context) (*Page[ClusterNodeVO], error) {
+	var out Page[ClusterNodeVO]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/cluster/nodes", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListConfigHistoriesParams 查询参数，零值的字段不发送
+type ListConfigHistoriesParams struct {
+	Key string
+	// 最多返回条数
+	Limit int
+}
+
+func (p *ListConfigHistoriesParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.Key != "" {
+		q.Set("key", p.Key)
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return q
+}
+
+// ListConfigHistories 配置变更历史
+//
+// GET /api/v1/config/history
+func (c *Client) ListConfigHistories(ctx context.Context, params *ListConfigHistoriesParams) (*Page[ConfigHistoryVO], error) {
+	var out Page[ConfigHistoryVO]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/config/history", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListConfigSchemas 所有配置项的声明
+//
+// GET /api/v1/config/schema
+func (c *Client) ListConfigSchemas(ctx context.Context) (*Page[FieldSchema], error) {
+	var out Page[FieldSchema]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/config/schema", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListConfigs 配置列表，敏感项脱敏
+//
+// GET /api/v1/config/list
+func (c *Client) ListConfigs(ctx context.Context) (*Page[ConfigVO], error) {
+	var out Page[ConfigVO]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/config/list", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListLineScores 各平台 CDN 线路评分
+//
+// GET /api/v1/monitor/lines
+func (c *Client) ListLineScores(ctx context.Context) (*Page[Score], error) {
+	var out Page[Score]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/monitor/lines", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListManagersParams 查询参数，零值的字段不发送
+type ListManagersParams struct {
+	Page     int
+	PageSize int
+	// 集群模式下只返回本节点的状态
+	Local bool
+}
+
+func (p *ListManagersParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.Page != 0 {
+		q.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PageSize != 0 {
+		q.Set("pageSize", strconv.Itoa(p.PageSize))
+	}
+	if p.Local {
+		q.Set("local", strconv.FormatBool(p.Local))
+	}
+	return q
+}
+
+// ListManagers 所有启用房间的 Manager 状态，集群模式下汇总各节点
+//
+// GET /api/v1/stream/list
+func (c *Client) ListManagers(ctx context.Context, params *ListManagersParams) (*Page[ManagerVO], error) {
+	var out Page[ManagerVO]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/stream/list", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListNotifyChannels 通知渠道列表
+//
+// GET /api/v1/notify/channel/list
+func (c *Client) ListNotifyChannels(ctx context.Context) (*Page[NotifyChannelVO], error) {
+	var out Page[NotifyChannelVO]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/notify/channel/list", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListNotifySubscriptionsParams 查询参数，零值的字段不发送
+type ListNotifySubscriptionsParams struct {
+	// 渠道 ID，0 表示全部
+	ChannelID int64
+}
+
+func (p *ListNotifySubscriptionsParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.ChannelID != 0 {
+		q.Set("channelId", strconv.FormatInt(p.ChannelID, 10))
+	}
+	return q
+}
+
+// ListNotifySubscriptions 订阅列表
+//
+// GET /api/v1/notify/subscription/list
+func (c *Client) ListNotifySubscriptions(ctx context.Context, params *ListNotifySubscriptionsParams) (*Page[NotifySubscriptionVO], error) {
+	var out Page[NotifySubscriptionVO]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/notify/subscription/list", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListRecordEngines 可用的录制引擎
+//
+// GET /api/v1/room/recordEngines
+func (c *Client) ListRecordEngines(ctx context.Context) ([]string, error) {
+	var out []string
+	if err := c.do(ctx, http.MethodGet, "/api/v1/room/recordEngines", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListRecordingFilesParams 查询参数，零值的字段不发送
+type ListRecordingFilesParams struct {
+	// 房间 ID，0 表示全部
+	RoomID int64
+	// 录制日期 2006-01-02
+	Date string
+	// 最多返回条数
+	Limit int
+}
+
+func (p *ListRecordingFilesParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.RoomID != 0 {
+		q.Set("roomId", strconv.FormatInt(p.RoomID, 10))
+	}
+	if p.Date != "" {
+		q.Set("date", p.Date)
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return q
+}
+
+// ListRecordingFiles 可在线播放的录制文件
+//
+// GET /api/v1/files/list
+func (c *Client) ListRecordingFiles(ctx context.Context, params *ListRecordingFilesParams) (*Page[RecordingFileVO], error) {
+	var out Page[RecordingFileVO]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/files/list", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListRecordingsParams 查询参数，零值的字段不发送
+type ListRecordingsParams struct {
+	// 房间 ID，0 表示全部
+	RoomID int64
+	// 最多返回条数
+	Limit int
+}
+
+func (p *ListRecordingsParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.RoomID != 0 {
+		q.Set("roomId", strconv.FormatInt(p.RoomID, 10))
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return q
+}
+
+// ListRecordings 录制记录
+//
+// GET /api/v1/recording/list
+func (c *Client) ListRecordings(ctx context.Context, params *ListRecordingsParams) (*Page[RecordingVO], error) {
+	var out Page[RecordingVO]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/recording/list", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListRoomTitlesParams 查询参数，零值的字段不发送
+type ListRoomTitlesParams struct {
+	// 开播记录 ID，0 表示全部
+	SessionID int64
+	// 最多返回条数
+	Limit int
+}
+
+func (p *ListRoomTitlesParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.SessionID != 0 {
+		q.Set("sessionId", strconv.FormatInt(p.SessionID, 10))
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return q
+}
+
+// ListRoomTitles 房间的标题变更记录
+//
+// GET /api/v1/room/{roomId}/titles
+func (c *Client) ListRoomTitles(ctx context.Context, roomID int64, params *ListRoomTitlesParams) (*Page[RoomTitleVO], error) {
+	var out Page[RoomTitleVO]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/room/"+strconv.FormatInt(roomID, 10)+"/titles", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListRoomsParams 查询参数，零值的字段不发送
+type ListRoomsParams struct {
+	Keyword      string
+	Platform     string
+	Status       *int
+	RecordStatus *int
+	LiveStatus   *int
+	Group        string
+	Tag          string
+	Page         int
+	PageSize     int
+}
+
+func (p *ListRoomsParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.Keyword != "" {
+		q.Set("keyword", p.Keyword)
+	}
+	if p.Platform != "" {
+		q.Set("platform", p.Platform)
+	}
+	if p.Status != nil {
+		q.Set("status", strconv.Itoa(*p.Status))
+	}
+	if p.RecordStatus != nil {
+		q.Set("recordStatus", strconv.Itoa(*p.RecordStatus))
+	}
+	if p.LiveStatus != nil {
+		q.Set("liveStatus", strconv.Itoa(*p.LiveStatus))
+	}
+	if p.Group != "" {
+		q.Set("group", p.Group)
+	}
+	if p.Tag != "" {
+		q.Set("tag", p.Tag)
+	}
+	if p.Page != 0 {
+		q.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PageSize != 0 {
+		q.Set("pageSize", strconv.Itoa(p.PageSize))
+	}
+	return q
+}
+
+// ListRooms 按条件分页查询房间
+//
+// GET /api/v1/room/list
+func (c *Client) ListRooms(ctx context.Context, params *ListRoomsParams) (*Page[RoomVO], error) {
+	var out Page[RoomVO]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/room/list", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListSessionTransitions 开播记录的状态变更历史
+//
+// GET /api/v1/monitor/session/{sessionId}/transitions
+func (c *Client) ListSessionTransitions(ctx context.Context, sessionID int64) (*Page[TransitionVO], error) {
+	var out Page[TransitionVO]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/monitor/session/"+strconv.FormatInt(sessionID, 10)+"/transitions", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListStorages 存储列表
+//
+// GET /api/v1/storage/list
+func (c *Client) ListStorages(ctx context.Context) (*Page[StorageVO], error) {
+	var out Page[StorageVO]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/storage/list", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListUploadRules 上传规则列表
+//
+// GET /api/v1/upload/rule/list
+func (c *Client) ListUploadRules(ctx context.Context) (*Page[UploadRuleVO], error) {
+	var out Page[UploadRuleVO]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/upload/rule/list", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListUploadsParams 查询参数，零值的字段不发送
+type ListUploadsParams struct {
+	// 房间 ID，0 表示全部
+	RoomID int64
+	Status string
+	// 最多返回条数
+	Limit int
+}
+
+func (p *ListUploadsParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.RoomID != 0 {
+		q.Set("roomId", strconv.FormatInt(p.RoomID, 10))
+	}
+	if p.Status != "" {
+		q.Set("status", p.Status)
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return q
+}
+
+// ListUploads 上传任务
+//
+// GET /api/v1/upload/list
+func (c *Client) ListUploads(ctx context.Context, params *ListUploadsParams) (*Page[UploadVO], error) {
+	var out Page[UploadVO]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/upload/list", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PreviewFilename 预览录制文件名
+//
+// POST /api/v1/config/filename/preview
+func (c *Client) PreviewFilename(ctx context.Context, body *FilenamePreviewVO) (*FilenamePreviewResultVO, error) {
+	var out FilenamePreviewResultVO
+	if err := c.do(ctx, http.MethodPost, "/api/v1/config/filename/preview", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ProxyStream 代理直播流，file 为播放列表或分片
+//
+// GET /api/v1/stream/proxy/{managerId}/{file}
+//
+// 调用方需要关闭返回的 Body
+func (c *Client) ProxyStream(ctx context.Context, managerID int64, file string) (*http.Response, error) {
+	return c.raw(ctx, http.MethodGet, "/api/v1/stream/proxy/"+strconv.FormatInt(managerID, 10)+"/"+escapePath(file), nil, nil)
+}
+
+// RefreshMonitor 立即检查所有房间的开播状态
+//
+// POST /api/v1/monitor/refresh
+func (c *Client) RefreshMonitor(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/monitor/refresh", nil, nil, nil)
+}
+
+// RefreshRoom 立即刷新房间标题、封面、主播名与头像
+//
+// POST /api/v1/room/{roomId}/refresh
+func (c *Client) RefreshRoom(ctx context.Context, roomID int64) (*RoomVO, error) {
+	var out RoomVO
+	if err := c.do(ctx, http.MethodPost, "/api/v1/room/"+strconv.FormatInt(roomID, 10)+"/refresh", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RefreshStream 立即刷新直播流地址
+//
+// POST /api/v1/stream/refresh/{roomId}
+func (c *Client) RefreshStream(ctx context.Context, roomID int64) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/stream/refresh/"+strconv.FormatInt(roomID, 10), nil, nil, nil)
+}
+
+// RegenerateThumbnails 重新生成缩略图
+//
+// POST /api/v1/recording/{id}/thumbnails
+func (c *Client) RegenerateThumbnails(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/recording/"+strconv.FormatInt(id, 10)+"/thumbnails", nil, nil, nil)
+}
+
+// RemoveNotifyChannel 删除通知渠道
+//
+// DELETE /api/v1/notify/channel/{id}
+func (c *Client) RemoveNotifyChannel(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/notify/channel/"+strconv.FormatInt(id, 10), nil, nil, nil)
+}
+
+// RemoveNotifySubscription 删除订阅
+//
+// DELETE /api/v1/notify/subscription/{id}
+func (c *Client) RemoveNotifySubscription(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/notify/subscription/"+strconv.FormatInt(id, 10), nil, nil, nil)
+}
+
+// RemoveRoom 删除房间
+//
+// DELETE /api/v1/room/{roomId}
+func (c *Client) RemoveRoom(ctx context.Context, roomID int64) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/room/"+strconv.FormatInt(roomID, 10), nil, nil, nil)
+}
+
+// RemoveStorage 删除存储
+//
+// DELETE /api/v1/storage/{id}
+func (c *Client) RemoveStorage(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/storage/"+strconv.FormatInt(id, 10), nil, nil, nil)
+}
+
+// RemoveUploadRule 删除上传规则
+//
+// DELETE /api/v1/upload/rule/{id}
+func (c *Client) RemoveUploadRule(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/upload/rule/"+strconv.FormatInt(id, 10), nil, nil, nil)
+}
+
+// RestartMonitor 重启开播监控
+//
+// POST /api/v1/monitor/restart
+func (c *Client) RestartMonitor(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/monitor/restart", nil, nil, nil)
+}
+
+// RetryUpload 重试失败的上传任务
+//
+// POST /api/v1/upload/{id}/retry
+func (c *Client) RetryUpload(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/upload/"+strconv.FormatInt(id, 10)+"/retry", nil, nil, nil)
+}
+
+// RollbackConfig 回滚到某条变更记录之前的值
+//
+// POST /api/v1/config/rollback
+func (c *Client) RollbackConfig(ctx context.Context, body *ConfigRollbackVO) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/config/rollback", nil, body, nil)
+}
+
+// SaveNotifySubscription 保存渠道对房间的订阅
+//
+// POST /api/v1/notify/subscription
+func (c *Client) SaveNotifySubscription(ctx context.Context, body *NotifySubscriptionSaveVO) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/notify/subscription", nil, body, nil)
+}
+
+// SaveUploadRule 保存房间的上传规则
+//
+// POST /api/v1/upload/rule
+func (c *Client) SaveUploadRule(ctx context.Context, body *UploadRuleSaveVO) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/upload/rule", nil, body, nil)
+}
+
+// SetRoomLabels 设置房间的分组与标签
+//
+// POST /api/v1/room/labels
+func (c *Client) SetRoomLabels(ctx context.Context, body *RoomLabelsVO) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/room/labels", nil, body, nil)
+}
+
+// SetRoomRecordEngine 修改房间的录制引擎
+//
+// POST /api/v1/room/recordEngine
+func (c *Client) SetRoomRecordEngine(ctx context.Context, body *RoomRecordEngineVO) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/room/recordEngine", nil, body, nil)
+}
+
+// SetRoomRecordMode 修改房间的录制模式
+//
+// POST /api/v1/room/recordMode
+func (c *Client) SetRoomRecordMode(ctx context.Context, body *RoomRecordModeVO) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/room/recordMode", nil, body, nil)
+}
+
+// SetRoomRecordStatus 开启或关闭房间录制
+//
+// POST /api/v1/room/recordStatus
+func (c *Client) SetRoomRecordStatus(ctx context.Context, body *RoomStatusVO) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/room/recordStatus", nil, body, nil)
+}
+
+// SetRoomStatus 启用或停用房间
+//
+// POST /api/v1/room/status
+func (c *Client) SetRoomStatus(ctx context.Context, body *RoomStatusVO) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/room/status", nil, body, nil)
+}
+
+// StartMonitor 启动开播监控
+//
+// POST /api/v1/monitor/start
+func (c *Client) StartMonitor(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/monitor/start", nil, nil, nil)
+}
+
+// StartStream 启动房间的 Manager
+//
+// POST /api/v1/stream/start/{roomId}
+func (c *Client) StartStream(ctx context.Context, roomID int64) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/stream/start/"+strconv.FormatInt(roomID, 10), nil, nil, nil)
+}
+
+// StopMonitor 停止开播监控
+//
+// POST /api/v1/monitor/stop
+func (c *Client) StopMonitor(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/monitor/stop", nil, nil, nil)
+}
+
+// StopStream 停止房间的 Manager
+//
+// POST /api/v1/stream/stop/{roomId}
+func (c *Client) StopStream(ctx context.Context, roomID int64) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/stream/stop/"+strconv.FormatInt(roomID, 10), nil, nil, nil)
+}
+
+// StreamFile 播放录制文件，支持 Range 请求
+//
+// GET /api/v1/files/stream/{path}
+//
+// 调用方需要关闭返回的 Body
+func (c *Client) StreamFile(ctx context.Context, path string) (*http.Response, error) {
+	return c.raw(ctx, http.MethodGet, "/api/v1/files/stream/"+escapePath(path), nil, nil)
+}
+
+// TailRoomLogsParams 查询参数，零值的字段不发送
+type TailRoomLogsParams struct {
+	// 行数，默认 200
+	Lines int
+}
+
+func (p *TailRoomLogsParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.Lines != 0 {
+		q.Set("lines", strconv.Itoa(p.Lines))
+	}
+	return q
+}
+
+// TailRoomLogs 房间日志文件的最后若干行，包含 ffmpeg 输出
+//
+// GET /api/v1/room/{roomId}/logs
+func (c *Client) TailRoomLogs(ctx context.Context, roomID int64, params *TailRoomLogsParams) (*Page[json.RawMessage], error) {
+	var out Page[json.RawMessage]
+	if err := c.do(ctx, http.MethodGet, "/api/v1/room/"+strconv.FormatInt(roomID, 10)+"/logs", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TestNotifyChannelParams 查询参数，零值的字段不发送
+type TestNotifyChannelParams struct {
+	// 使用该事件的模板
+	Event string
+}
+
+func (p *TestNotifyChannelParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.Event != "" {
+		q.Set("event", p.Event)
+	}
+	return q
+}
+
+// TestNotifyChannel 发送测试通知
+//
+// POST /api/v1/notify/channel/{id}/test
+func (c *Client) TestNotifyChannel(ctx context.Context, id int64, params *TestNotifyChannelParams) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/notify/channel/"+strconv.FormatInt(id, 10)+"/test", params.values(), nil, nil)
+}
+
+// UpdateConfig 修改配置
+//
+// POST /api/v1/config/update
+func (c *Client) UpdateConfig(ctx context.Context, body *ConfigUpdateVO) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/config/update", nil, body, nil)
+}
+
+// UpdateNotifyChannel 修改通知渠道
+//
+// POST /api/v1/notify/channel/{id}
+func (c *Client) UpdateNotifyChannel(ctx context.Context, id int64, body *NotifyChannelUpdateVO) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/notify/channel/"+strconv.FormatInt(id, 10), nil, body, nil)
+}
+
+// UpdateStorage 修改存储
+//
+// POST /api/v1/storage/{id}
+func (c *Client) UpdateStorage(ctx context.Context, id int64, body *StorageUpdateVO) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/storage/"+strconv.FormatInt(id, 10), nil, body, nil)
+}
+
+// UploadRecording 手动上传录制文件
+//
+// POST /api/v1/upload/recording
+func (c *Client) UploadRecording(ctx context.Context, body *UploadRecordingVO) (*UploadVO, error) {
+	var out UploadVO
+	if err := c.do(ctx, http.MethodPost, "/api/v1/upload/recording", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/room/list":
+			if r.URL.Query().Get("platform") != "bili" || r.URL.Query().Has("keyword") {
+				t.Errorf("query = %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"code":0,"message":"ok","data":{"list":[{"id":"1","name":"a","tags":["x"]}],"total":1,"page":0,"pageSize":0}}`))
+		case "/api/v1/room/labels":
+			var body RoomLabelsVO
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.RoomID != "1" {
+				t.Errorf("body = %+v, err = %v", body, err)
+			}
+			w.Write([]byte(`{"code":-1,"message":"房间不存在","data":null}`))
+		case "/api/v1/recording/12/thumbnail/3":
+			w.Write([]byte("jpeg"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL + "/")
+	ctx := context.Background()
+
+	page, err := c.ListRooms(ctx, &ListRoomsParams{Platform: "bili"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 1 || len(page.List) != 1 || page.List[0].Name != "a" || page.List[0].Tags[0] != "x" {
+		t.Errorf("page = %+v", page)
+	}
+
+	err = c.SetRoomLabels(ctx, &RoomLabelsVO{RoomID: "1", Group: "g"})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != -1 || apiErr.Message != "房间不存在" {
+		t.Errorf("err = %v", err)
+	}
+
+	resp, err := c.GetThumbnail(ctx, 12, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if err := c.StartMonitor(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestEscapePath(t *testing.T) {
+	if got := escapePath("/2024/a b/c.flv"); got != "2024/a%20b/c.flv" {
+		t.Errorf("escapePath = %s", got)
+	}
+}
@@ -31,18 +31,107 @@ type AppConfig struct {
 		Password    string `json:"password" mapstructure:"password"`
 	} `json:"proxy" mapstructure:"proxy"`
 	Bili struct {
-		Cookie string `json:"cookie" mapstructure:"cookie"` // B站 Cookie
+		Cookie  string `json:"cookie" mapstructure:"cookie"`     // B站 Cookie
+		APIBase string `json:"api_base" mapstructure:"api_base"` // 接口地址，为空时使用官方地址
 	} `json:"bili"`
 	Missevan struct {
-		Cookie string `json:"cookie" mapstructure:"cookie"` // 猫耳 Cookie
+		Cookie     string `json:"cookie" mapstructure:"cookie"`           // 猫耳 Cookie
+		APIBase    string `json:"api_base" mapstructure:"api_base"`       // 接口地址，为空时使用官方地址
+		StreamHost string `json:"stream_host" mapstructure:"stream_host"` // 拉流请求使用的 Host，为空时使用地址中的域名
 	} `json:"missevan" mapstructure:"missevan"`
-	Recorder *Recorder `json:"recorder" mapstructure:"recorder"`
+	Recorder  *Recorder  `json:"recorder" mapstructure:"recorder"`
+	Monitor   *Monitor   `json:"monitor" mapstructure:"monitor"`
+	Fetcher   *Fetcher   `json:"fetcher" mapstructure:"fetcher"`
+	Thumbnail *Thumbnail `json:"thumbnail" mapstructure:"thumbnail"`
+	Clip      *Clip      `json:"clip" mapstructure:"clip"`
+	DVR       *DVR       `json:"dvr" mapstructure:"dvr"`
+	Uploader  *Uploader  `json:"uploader" mapstructure:"uploader"`
+	Metadata  *Metadata  `json:"metadata" mapstructure:"metadata"`
+	Notify    *Notify    `json:"notify" mapstructure:"notify"`
+	Log       *Log       `json:"log" mapstructure:"log"`
+	Backup    *Backup    `json:"backup" mapstructure:"backup"`
+	Cluster   *Cluster   `json:"cluster" mapstructure:"cluster"`
 }
 
 type Recorder struct {
 	FilenamePattern string `json:"filename_pattern" mapstructure:"filename_pattern"` // 文件名格式
 	MaxFilesize     int    `json:"max_filesize" mapstructure:"max_filesize"`         // 最大文件大小
 	MaxDuration     int    `json:"max_duration" mapstructure:"max_duration"`         // 最大录制时长
+	AudioFormat     string `json:"audio_format" mapstructure:"audio_format"`         // 纯音频录制的输出格式 aac | m4a
+	OutputDir       string `json:"output_dir" mapstructure:"output_dir"`             // 录制文件根目录
+}
+
+type Monitor struct {
+	Interval       int `json:"interval" mapstructure:"interval"`               // 基础轮询间隔（秒）
+	MinInterval    int `json:"min_interval" mapstructure:"min_interval"`       // 临近常规开播时间时的轮询间隔（秒）
+	MaxInterval    int `json:"max_interval" mapstructure:"max_interval"`       // 很少开播的房间的轮询间隔（秒）
+	Concurrency    int `json:"concurrency" mapstructure:"concurrency"`         // 同时进行的状态查询数
+	QPS            int `json:"qps" mapstructure:"qps"`                         // 每秒最多发起的状态查询数
+	OfflineGrace   int `json:"offline_grace" mapstructure:"offline_grace"`     // 平台返回未开播后继续检查的时长（秒）
+	OfflineRecheck int `json:"offline_recheck" mapstructure:"offline_recheck"` // 宽限期内重新检查的间隔（秒）
+	StitchWindow   int `json:"stitch_window" mapstructure:"stitch_window"`     // 下播后多久内重新开播视为同一场（分钟）
+}
+
+type Fetcher struct {
+	HostQPS int `json:"host_qps" mapstructure:"host_qps"` // 单个平台 API 域名每秒最多请求数
+}
+
+type Thumbnail struct {
+	Enabled  bool `json:"enabled" mapstructure:"enabled"`   // 录制完成后是否生成缩略图
+	Interval int  `json:"interval" mapstructure:"interval"` // 截图间隔（秒）
+}
+
+type Clip struct {
+	BufferSeconds int    `json:"buffer_seconds" mapstructure:"buffer_seconds"` // 直播中每个房间保留的最近时长（秒），0 表示关闭
+	BufferMaxMB   int    `json:"buffer_max_mb" mapstructure:"buffer_max_mb"`   // 每个房间缓冲的最大内存（MB）
+	OutputDir     string `json:"output_dir" mapstructure:"output_dir"`         // 片段输出目录
+}
+
+type DVR struct {
+	Enabled bool   `json:"enabled" mapstructure:"enabled"` // 是否将直播分片保存到本地以支持回看
+	Window  int    `json:"window" mapstructure:"window"`   // 回看窗口（分钟）
+	Dir     string `json:"dir" mapstructure:"dir"`         // 分片保存目录
+}
+
+type Uploader struct {
+	Enabled     bool `json:"enabled" mapstructure:"enabled"`           // 录制完成后是否按规则自动上传
+	MaxAttempts int  `json:"max_attempts" mapstructure:"max_attempts"` // 单个文件最多尝试次数
+	RetryDelay  int  `json:"retry_delay" mapstructure:"retry_delay"`   // 重试间隔（秒）
+}
+
+type Metadata struct {
+	Interval int    `json:"interval" mapstructure:"interval"`   // 定期刷新房间信息的间隔（分钟），0 表示只在开播时刷新
+	CacheDir string `json:"cache_dir" mapstructure:"cache_dir"` // 封面、头像缓存目录
+}
+
+type Notify struct {
+	MinInterval int `json:"min_interval" mapstructure:"min_interval"` // 同一渠道同一房间同一事件的最小通知间隔（秒）
+	MaxPerHour  int `json:"max_per_hour" mapstructure:"max_per_hour"` // 单个渠道每小时最多发送条数，0 表示不限
+	DiskLowGB   int `json:"disk_low_gb" mapstructure:"disk_low_gb"`   // 录制目录剩余空间低于该值（GB）时通知，0 表示关闭
+}
+
+type Log struct {
+	Level      string `json:"level" mapstructure:"level"`             // 日志级别 debug | info | warn | error
+	Format     string `json:"format" mapstructure:"format"`           // 输出格式 console | json
+	Dir        string `json:"dir" mapstructure:"dir"`                 // 日志文件目录，为空时只输出到控制台
+	MaxSize    int    `json:"max_size" mapstructure:"max_size"`       // 单个日志文件的最大大小（MB）
+	MaxBackups int    `json:"max_backups" mapstructure:"max_backups"` // 保留的历史日志文件数
+	MaxAge     int    `json:"max_age" mapstructure:"max_age"`         // 历史日志文件保留天数
+	Compress   bool   `json:"compress" mapstructure:"compress"`       // 是否 gzip 压缩历史日志文件
+	RoomLog    bool   `json:"room_log" mapstructure:"room_log"`       // 是否为每个房间单独记录日志（含 ffmpeg 输出）
+}
+
+type Backup struct {
+	Enabled  bool   `json:"enabled" mapstructure:"enabled"`   // 是否定期备份数据库
+	Interval int    `json:"interval" mapstructure:"interval"` // 定期备份的间隔（小时）
+	Keep     int    `json:"keep" mapstructure:"keep"`         // 保留的备份数，0 表示不限
+	Dir      string `json:"dir" mapstructure:"dir"`           // 备份目录
+}
+
+// Cluster 集群模式的公共参数，节点名与地址通过启动参数指定
+type Cluster struct {
+	Heartbeat int `json:"heartbeat" mapstructure:"heartbeat"` // 心跳与续约的间隔（秒）
+	LeaseTTL  int `json:"lease_ttl" mapstructure:"lease_ttl"` // 租约有效期（秒），节点超过该时长没有心跳视为下线
 }
 
 // GlobalConfig 存储加载后的配置实例
@@ -65,16 +154,93 @@ func (config *AppConfig) MarshalZerologObject(e *zerolog.Event) {
 
 	// 嵌套打印 Bili 信息
 	e.Dict("bili", zerolog.Dict().
-		Str("cookie", config.Bili.Cookie))
+		Str("cookie", maskSecret(config.Bili.Cookie)).
+		Str("api_base", config.Bili.APIBase))
 
 	// 嵌套打印 Missevan 信息
 	e.Dict("missevan", zerolog.Dict().
-		Str("cookie", config.Missevan.Cookie))
+		Str("cookie", maskSecret(config.Missevan.Cookie)).
+		Str("api_base", config.Missevan.APIBase).
+		Str("stream_host", config.Missevan.StreamHost))
 
 	e.Dict("recorder", zerolog.Dict().
 		Str("filename_pattern", config.Recorder.FilenamePattern).
 		Str("max_filesize", strconv.Itoa(config.Recorder.MaxFilesize)).
-		Str("max_duration", strconv.Itoa(config.Recorder.MaxDuration)),
+		Str("max_duration", strconv.Itoa(config.Recorder.MaxDuration)).
+		Str("audio_format", config.Recorder.AudioFormat).
+		Str("output_dir", config.Recorder.OutputDir),
+	)
+
+	e.Dict("monitor", zerolog.Dict().
+		Int("interval", config.Monitor.Interval).
+		Int("min_interval", config.Monitor.MinInterval).
+		Int("max_interval", config.Monitor.MaxInterval).
+		Int("concurrency", config.Monitor.Concurrency).
+		Int("qps", config.Monitor.QPS).
+		Int("offline_grace", config.Monitor.OfflineGrace).
+		Int("offline_recheck", config.Monitor.OfflineRecheck).
+		Int("stitch_window", config.Monitor.StitchWindow),
+	)
+
+	e.Dict("fetcher", zerolog.Dict().
+		Int("host_qps", config.Fetcher.HostQPS),
+	)
+
+	e.Dict("thumbnail", zerolog.Dict().
+		Bool("enabled", config.Thumbnail.Enabled).
+		Int("interval", config.Thumbnail.Interval),
+	)
+
+	e.Dict("clip", zerolog.Dict().
+		Int("buffer_seconds", config.Clip.BufferSeconds).
+		Int("buffer_max_mb", config.Clip.BufferMaxMB).
+		Str("output_dir", config.Clip.OutputDir),
+	)
+
+	e.Dict("dvr", zerolog.Dict().
+		Bool("enabled", config.DVR.Enabled).
+		Int("window", config.DVR.Window).
+		Str("dir", config.DVR.Dir),
+	)
+
+	e.Dict("uploader", zerolog.Dict().
+		Bool("enabled", config.Uploader.Enabled).
+		Int("max_attempts", config.Uploader.MaxAttempts).
+		Int("retry_delay", config.Uploader.RetryDelay),
+	)
+
+	e.Dict("metadata", zerolog.Dict().
+		Int("interval", config.Metadata.Interval).
+		Str("cache_dir", config.Metadata.CacheDir),
+	)
+
+	e.Dict("notify", zerolog.Dict().
+		Int("min_interval", config.Notify.MinInterval).
+		Int("max_per_hour", config.Notify.MaxPerHour).
+		Int("disk_low_gb", config.Notify.DiskLowGB),
+	)
+
+	e.Dict("log", zerolog.Dict().
+		Str("level", config.Log.Level).
+		Str("format", config.Log.Format).
+		Str("dir", config.Log.Dir).
+		Int("max_size", config.Log.MaxSize).
+		Int("max_backups", config.Log.MaxBackups).
+		Int("max_age", config.Log.MaxAge).
+		Bool("compress", config.Log.Compress).
+		Bool("room_log", config.Log.RoomLog),
+	)
+
+	e.Dict("backup", zerolog.Dict().
+		Bool("enabled", config.Backup.Enabled).
+		Int("interval", config.Backup.Interval).
+		Int("keep", config.Backup.Keep).
+		Str("dir", config.Backup.Dir),
+	)
+
+	e.Dict("cluster", zerolog.Dict().
+		Int("heartbeat", config.Cluster.Heartbeat).
+		Int("lease_ttl", config.Cluster.LeaseTTL),
 	)
 }
 
@@ -84,19 +250,23 @@ func (config *AppConfig) AddSubscriber(subscriber iface.ConfigSubscriber) {
 }
 
 func (config *AppConfig) OnUpdate(key string, value string) error {
-	log.Info().Msgf("[Config] 更新配置, key: %s, value: %s", key, value)
-	config.Viper.Set(key, value)
+	log.Info().Msgf("[Config] 更新配置, key: %s, value: %s", key, MaskValue(key, value))
+	typedValue, err := ParseValue(key, value)
+	if err != nil {
+		return err
+	}
+	config.Viper.Set(key, typedValue)
 	if err := config.Viper.Unmarshal(&GlobalConfig); err != nil {
 		log.Error().Err(err).Msgf("[config] 反序列化更新失败, key: %s", key)
 		return fmt.Errorf("反序列化更新失败: %w", err)
 	}
 
 	// 通知所有订阅者
-	log.Info().Msgf("[config] 通知订阅者, key: %s, value: %s", key, value)
+	log.Info().Msgf("[config] 通知订阅者, key: %s, value: %s", key, MaskValue(key, value))
 	for _, subscriber := range config.subscribers {
 		subscriber.OnConfigUpdate(key, value)
 	}
-	log.Info().Msgf("[config] 配置更新成功: %s = %v", key, value)
+	log.Info().Msgf("[config] 配置更新成功: %s = %v", key, MaskValue(key, value))
 	log.Warn().Object("config", &GlobalConfig).Msg("[config] 配置更新成功")
 	return nil
 }
@@ -106,9 +276,9 @@ func InitViper(configFilePath string, cmdFlags map[string]interface{}, configMap
 	v := viper.New()
 
 	// 1. 设置默认值 (最低优先级)
-	v.SetDefault("port", 8090)
-	v.SetDefault("bili.cookie", "")
-	v.SetDefault("missevan.cookie", "")
+	for key, schema := range Schemas {
+		v.SetDefault(key, schema.Default)
+	}
 
 	// 从数据库加载配置
 	for key, value := range configMap {
@@ -258,6 +428,18 @@ func setFieldValue(field reflect.Value, value string) error {
 			return err
 		}
 		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
 	case reflect.Bool:
 		b, err := strconv.ParseBool(value)
 		if err != nil {
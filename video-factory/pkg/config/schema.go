@@ -0,0 +1,391 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 配置项值类型
+const (
+	TypeString = "string"
+	TypeInt    = "int"
+	TypeBool   = "bool"
+	TypeEnum   = "enum"
+)
+
+// FieldSchema 描述单个配置项的约束
+type FieldSchema struct {
+	Key             string   `json:"key"`
+	Type            string   `json:"type"`            // string | int | bool | enum
+	Default         string   `json:"default"`         // 默认值
+	Min             *int64   `json:"min,omitempty"`   // 仅 int 生效
+	Max             *int64   `json:"max,omitempty"`   // 仅 int 生效
+	Enum            []string `json:"enum,omitempty"`  // 仅 enum 生效
+	RequiresRestart bool     `json:"requiresRestart"` // 修改后是否需要重启才能生效
+	Secret          bool     `json:"secret"`          // 是否为敏感信息，列表中脱敏展示
+	Description     string   `json:"description"`
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+// Schemas 所有已知配置项的声明，新增配置项必须在这里登记
+var Schemas = map[string]FieldSchema{
+	"port": {
+		Type: TypeInt, Default: "8090", Min: int64Ptr(1), Max: int64Ptr(65535), RequiresRestart: true,
+		Description: "程序端口，下次启动生效，优先级在命令行和配置文件之后",
+	},
+	"gin_log_mode": {
+		Type: TypeEnum, Default: "release", Enum: []string{"debug", "release", "test"}, RequiresRestart: true,
+		Description: "gin 日志模式，debug | release",
+	},
+	"proxy.enabled": {
+		Type: TypeBool, Default: "false", RequiresRestart: true,
+		Description: "是否使用代理",
+	},
+	"proxy.system_proxy": {
+		Type: TypeBool, Default: "false", RequiresRestart: true,
+		Description: "是否使用系统代理，仅在enabled=true时生效",
+	},
+	"proxy.protocol": {
+		Type: TypeEnum, Default: "http", Enum: []string{"http", "https", "socks5"}, RequiresRestart: true,
+		Description: "代理协议，仅在enabled=true时生效",
+	},
+	"proxy.host": {
+		Type: TypeString, Default: "127.0.0.1", RequiresRestart: true,
+		Description: "代理服务器地址，仅在enabled=true且systemProxy=false时生效",
+	},
+	"proxy.port": {
+		Type: TypeInt, Default: "7890", Min: int64Ptr(1), Max: int64Ptr(65535), RequiresRestart: true,
+		Description: "代理服务器端口，仅在enabled=true且systemProxy=false时生效",
+	},
+	"proxy.username": {
+		Type: TypeString, Default: "", RequiresRestart: true,
+		Description: "代理服务器验证用户名，仅在enabled=true且systemProxy=false时生效",
+	},
+	"proxy.password": {
+		Type: TypeString, Default: "", RequiresRestart: true, Secret: true,
+		Description: "代理服务器验证密码，仅在enabled=true且systemProxy=false时生效",
+	},
+	"bili.cookie": {
+		Type: TypeString, Default: "", Secret: true,
+		Description: "b站cookie，有些直播间需要cookie才能看高清晰度",
+	},
+	"missevan.cookie": {
+		Type: TypeString, Default: "", Secret: true,
+		Description: "猫耳的cookie，没有也行",
+	},
+	"bili.api_base": {
+		Type: TypeString, Default: "", RequiresRestart: true,
+		Description: "b站接口地址，为空时使用官方地址，可指向反向代理或本地模拟服务，下次启动生效",
+	},
+	"missevan.api_base": {
+		Type: TypeString, Default: "", RequiresRestart: true,
+		Description: "猫耳接口地址，为空时使用官方地址，下次启动生效",
+	},
+	"missevan.stream_host": {
+		Type: TypeString, Default: "",
+		Description: "猫耳拉流请求使用的 Host，拉流地址为 IP 或备用域名时填写 CDN 域名，为空时使用地址中的域名",
+	},
+	"recorder.filename_pattern": {
+		Type:        TypeString,
+		Default:     "{{.Username}}_{{.Year}}-{{.Month}}-{{.Day}}_{{.Hour}}-{{.Minute}}-{{.Second}}_{{.Sequence}}",
+		Description: "录制文件名格式",
+	},
+	"recorder.output_dir": {
+		Type: TypeString, Default: "",
+		Description: "录制文件根目录，为空时为程序运行目录，文件名格式中的路径相对于该目录",
+	},
+	"recorder.max_filesize": {
+		Type: TypeInt, Default: "0", Min: int64Ptr(0),
+		Description: "单个录制文件最大大小（MB），0 表示不限制",
+	},
+	"recorder.max_duration": {
+		Type: TypeInt, Default: "0", Min: int64Ptr(0),
+		Description: "单个录制文件最大时长（分钟），0 表示不限制",
+	},
+	"recorder.audio_format": {
+		Type: TypeEnum, Default: "m4a", Enum: []string{"aac", "m4a"},
+		Description: "纯音频录制的输出格式，aac 为直接保存的 ADTS 流，m4a 为每个文件完成后无损封装",
+	},
+	"thumbnail.enabled": {
+		Type: TypeBool, Default: "true",
+		Description: "录制文件完成后是否生成缩略图与拼图，需要安装 ffmpeg",
+	},
+	"thumbnail.interval": {
+		Type: TypeInt, Default: "300", Min: int64Ptr(10), Max: int64Ptr(3600),
+		Description: "缩略图截图间隔（秒），单个文件最多 36 张",
+	},
+	"clip.buffer_seconds": {
+		Type: TypeInt, Default: "0", Min: int64Ptr(0), Max: int64Ptr(1800),
+		Description: "直播中每个房间在内存中保留的最近时长（秒），用于保存最近片段，0 表示关闭，对新启动的房间生效",
+	},
+	"clip.buffer_max_mb": {
+		Type: TypeInt, Default: "32", Min: int64Ptr(16), Max: int64Ptr(4096),
+		Description: "每个房间片段缓冲的最大内存（MB），高码率直播实际保留的时长会少于设置的时长",
+	},
+	"clip.output_dir": {
+		Type: TypeString, Default: "clips",
+		Description: "片段输出目录",
+	},
+	"dvr.enabled": {
+		Type: TypeBool, Default: "false",
+		Description: "是否将直播分片保存到本地，提供可回看的播放列表，对新启动的房间生效",
+	},
+	"dvr.window": {
+		Type: TypeInt, Default: "120", Min: int64Ptr(1), Max: int64Ptr(1440),
+		Description: "回看窗口（分钟），超出的分片会被删除",
+	},
+	"dvr.dir": {
+		Type: TypeString, Default: "dvr",
+		Description: "回看分片保存目录，下播后清理",
+	},
+	"uploader.enabled": {
+		Type: TypeBool, Default: "true",
+		Description: "录制文件完成后是否按上传规则自动上传",
+	},
+	"uploader.max_attempts": {
+		Type: TypeInt, Default: "5", Min: int64Ptr(1), Max: int64Ptr(20),
+		Description: "单个文件上传最多尝试次数，超过后标记为失败，可手动重试",
+	},
+	"uploader.retry_delay": {
+		Type: TypeInt, Default: "30", Min: int64Ptr(1), Max: int64Ptr(3600),
+		Description: "上传失败后的重试间隔（秒）",
+	},
+	"metadata.interval": {
+		Type: TypeInt, Default: "60", Min: int64Ptr(0), Max: int64Ptr(1440),
+		Description: "定期刷新房间标题、封面、头像的间隔（分钟），0 表示只在开播时刷新",
+	},
+	"metadata.cache_dir": {
+		Type: TypeString, Default: "cache/images",
+		Description: "封面、头像的本地缓存目录",
+	},
+	"notify.min_interval": {
+		Type: TypeInt, Default: "600", Min: int64Ptr(0), Max: int64Ptr(86400),
+		Description: "同一渠道对同一房间同一事件的最小通知间隔（秒），避免刷屏",
+	},
+	"notify.max_per_hour": {
+		Type: TypeInt, Default: "30", Min: int64Ptr(0), Max: int64Ptr(3600),
+		Description: "单个通知渠道每小时最多发送条数，0 表示不限",
+	},
+	"notify.disk_low_gb": {
+		Type: TypeInt, Default: "10", Min: int64Ptr(0), Max: int64Ptr(100000),
+		Description: "录制目录剩余空间低于该值（GB）时发送通知，0 表示关闭",
+	},
+	"monitor.interval": {
+		Type: TypeInt, Default: "60", Min: int64Ptr(5), Max: int64Ptr(3600),
+		Description: "开播状态基础轮询间隔（秒）",
+	},
+	"monitor.min_interval": {
+		Type: TypeInt, Default: "15", Min: int64Ptr(5), Max: int64Ptr(3600),
+		Description: "临近常规开播时间时的轮询间隔（秒）",
+	},
+	"monitor.max_interval": {
+		Type: TypeInt, Default: "300", Min: int64Ptr(5), Max: int64Ptr(86400),
+		Description: "很少开播的房间的轮询间隔（秒）",
+	},
+	"monitor.concurrency": {
+		Type: TypeInt, Default: "4", Min: int64Ptr(1), Max: int64Ptr(64),
+		Description: "同时进行的开播状态查询数",
+	},
+	"monitor.qps": {
+		Type: TypeInt, Default: "2", Min: int64Ptr(0), Max: int64Ptr(100),
+		Description: "每秒最多发起的开播状态查询数，0 表示不限制",
+	},
+	"monitor.offline_grace": {
+		Type: TypeInt, Default: "60", Min: int64Ptr(0), Max: int64Ptr(3600),
+		Description: "平台返回未开播后继续检查的时长（秒），期间恢复直播不会结束本场，0 表示立即停止",
+	},
+	"monitor.offline_recheck": {
+		Type: TypeInt, Default: "10", Min: int64Ptr(1), Max: int64Ptr(300),
+		Description: "下播宽限期内重新检查开播状态的间隔（秒）",
+	},
+	"monitor.stitch_window": {
+		Type: TypeInt, Default: "5", Min: int64Ptr(0), Max: int64Ptr(720),
+		Description: "下播后在该时长（分钟）内重新开播视为同一场，沿用开播记录与文件序号，0 表示不续接",
+	},
+	"fetcher.host_qps": {
+		Type: TypeInt, Default: "5", Min: int64Ptr(0), Max: int64Ptr(100),
+		Description: "单个平台 API 域名每秒最多请求数，0 表示不限制",
+	},
+	"log.level": {
+		Type: TypeEnum, Default: "info", Enum: []string{"debug", "info", "warn", "error"},
+		Description: "控制台与日志文件的日志级别，房间日志始终记录 debug 级别以保留 ffmpeg 输出",
+	},
+	"log.format": {
+		Type: TypeEnum, Default: "console", Enum: []string{"console", "json"}, RequiresRestart: true,
+		Description: "控制台日志格式，console 为易读格式，json 为结构化格式，日志文件始终为 json，下次启动生效",
+	},
+	"log.dir": {
+		Type: TypeString, Default: "logs", RequiresRestart: true,
+		Description: "日志文件目录，为空时只输出到控制台，下次启动生效",
+	},
+	"log.max_size": {
+		Type: TypeInt, Default: "50", Min: int64Ptr(1), Max: int64Ptr(1024), RequiresRestart: true,
+		Description: "单个日志文件的最大大小（MB），超过后切分，另外每天切分一次，下次启动生效",
+	},
+	"log.max_backups": {
+		Type: TypeInt, Default: "10", Min: int64Ptr(0), Max: int64Ptr(1000), RequiresRestart: true,
+		Description: "每个日志保留的历史文件数，0 表示不限制，下次启动生效",
+	},
+	"log.max_age": {
+		Type: TypeInt, Default: "30", Min: int64Ptr(0), Max: int64Ptr(3650), RequiresRestart: true,
+		Description: "历史日志文件保留天数，0 表示不限制，下次启动生效",
+	},
+	"log.compress": {
+		Type: TypeBool, Default: "true", RequiresRestart: true,
+		Description: "是否 gzip 压缩历史日志文件，下次启动生效",
+	},
+	"log.room_log": {
+		Type: TypeBool, Default: "true", RequiresRestart: true,
+		Description: "是否为每个房间单独记录日志（含 ffmpeg 输出），可在接口中查看，下次启动生效",
+	},
+	"backup.enabled": {
+		Type: TypeBool, Default: "true",
+		Description: "是否定期备份数据库",
+	},
+	"backup.interval": {
+		Type: TypeInt, Default: "24", Min: int64Ptr(1), Max: int64Ptr(720),
+		Description: "定期备份数据库的间隔（小时）",
+	},
+	"backup.keep": {
+		Type: TypeInt, Default: "7", Min: int64Ptr(0), Max: int64Ptr(1000),
+		Description: "保留的数据库备份数，超出时删除最旧的备份，0 表示不限",
+	},
+	"backup.dir": {
+		Type: TypeString, Default: "",
+		Description: "数据库备份目录，为空时使用数据库文件所在目录下的 backup 目录",
+	},
+	"cluster.heartbeat": {
+		Type: TypeInt, Default: "5", Min: int64Ptr(1), Max: int64Ptr(60),
+		Description: "集群模式下节点心跳与续约的间隔（秒）",
+	},
+	"cluster.lease_ttl": {
+		Type: TypeInt, Default: "30", Min: int64Ptr(5), Max: int64Ptr(600),
+		Description: "集群模式下房间租约的有效期（秒），节点超过该时长没有心跳时由其他节点接管，需大于心跳间隔",
+	},
+}
+
+func init() {
+	// 回填 Key，避免声明时重复书写
+	for key, schema := range Schemas {
+		schema.Key = key
+		Schemas[key] = schema
+	}
+}
+
+// LookupSchema 获取配置项声明
+func LookupSchema(key string) (FieldSchema, bool) {
+	schema, ok := Schemas[key]
+	return schema, ok
+}
+
+// ListSchemas 按 key 排序返回所有配置项声明
+func ListSchemas() []FieldSchema {
+	list := make([]FieldSchema, 0, len(Schemas))
+	for _, schema := range Schemas {
+		list = append(list, schema)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Key < list[j].Key
+	})
+	return list
+}
+
+var (
+	validatorsMu sync.RWMutex
+	validators   = make(map[string]func(value string) error)
+)
+
+// RegisterValidator 为配置项登记额外的校验，用于 config 包无法感知的业务规则（如文件名模板）
+func RegisterValidator(key string, validator func(value string) error) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	validators[key] = validator
+}
+
+// Validate 校验配置项的 key 与 value 是否符合声明
+func Validate(key string, value string) error {
+	if _, err := ParseValue(key, value); err != nil {
+		return err
+	}
+
+	validatorsMu.RLock()
+	validator, ok := validators[key]
+	validatorsMu.RUnlock()
+	if ok {
+		if err := validator(value); err != nil {
+			return fmt.Errorf("配置项 %s 校验失败: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// ParseValue 按声明将字符串转换为对应类型的值，用于写入 viper
+func ParseValue(key string, value string) (interface{}, error) {
+	schema, ok := LookupSchema(key)
+	if !ok {
+		return nil, fmt.Errorf("未知配置项: %s", key)
+	}
+
+	switch schema.Type {
+	case TypeInt:
+		i, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("配置项 %s 需要整数，实际为: %q", key, value)
+		}
+		if schema.Min != nil && i < *schema.Min {
+			return nil, fmt.Errorf("配置项 %s 不能小于 %d", key, *schema.Min)
+		}
+		if schema.Max != nil && i > *schema.Max {
+			return nil, fmt.Errorf("配置项 %s 不能大于 %d", key, *schema.Max)
+		}
+		return int(i), nil
+	case TypeBool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("配置项 %s 需要布尔值，实际为: %q", key, value)
+		}
+		return b, nil
+	case TypeEnum:
+		for _, option := range schema.Enum {
+			if option == value {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("配置项 %s 可选值为 [%s]，实际为: %q", key, strings.Join(schema.Enum, ", "), value)
+	default:
+		return value, nil
+	}
+}
+
+// MaskValue 敏感配置项脱敏，非敏感项原样返回
+func MaskValue(key string, value string) string {
+	if schema, ok := LookupSchema(key); ok && schema.Secret {
+		return maskSecret(value)
+	}
+	return value
+}
+
+// IsMasked 判断 value 是否为原值脱敏后的结果，用于识别前端原样回传的脱敏值
+func IsMasked(key string, original string, value string) bool {
+	schema, ok := LookupSchema(key)
+	if !ok || !schema.Secret {
+		return false
+	}
+	return IsMaskedSecret(original, value)
+}
+
+// MaskSecret 按配置项相同的规则脱敏，用于存储、通知渠道等 JSON 配置中的密钥字段
+func MaskSecret(value string) string {
+	return maskSecret(value)
+}
+
+// IsMaskedSecret 判断 value 是否为 original 脱敏后的结果，原值为空时不视为脱敏
+func IsMaskedSecret(original string, value string) bool {
+	return original != "" && value == maskSecret(original)
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{"port", "8090", false},
+		{"port", "abc", true},
+		{"port", "70000", true},
+		{"proxy.port", "abc", true},
+		{"proxy.enabled", "true", false},
+		{"proxy.enabled", "yes", true},
+		{"gin_log_mode", "debug", false},
+		{"gin_log_mode", "verbose", true},
+		{"recorder.max_filesize", "-1", true},
+		{"bili.cookie", "", false},
+		{"proxy.prot", "7890", true},
+	}
+
+	for _, c := range cases {
+		err := Validate(c.key, c.value)
+		if (err != nil) != c.wantErr {
+			t.Errorf("Validate(%q, %q) err = %v, wantErr %v", c.key, c.value, err, c.wantErr)
+		}
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	v, err := ParseValue("proxy.port", " 7890 ")
+	if err != nil {
+		t.Fatalf("ParseValue failed: %v", err)
+	}
+	if i, ok := v.(int); !ok || i != 7890 {
+		t.Errorf("ParseValue returned %#v, want int 7890", v)
+	}
+
+	v, err = ParseValue("proxy.system_proxy", "true")
+	if err != nil {
+		t.Fatalf("ParseValue failed: %v", err)
+	}
+	if b, ok := v.(bool); !ok || !b {
+		t.Errorf("ParseValue returned %#v, want bool true", v)
+	}
+}
+
+func TestMaskValue(t *testing.T) {
+	if got := MaskValue("bili.cookie", "SESSDATA=abcdef"); got != "SE******ef" {
+		t.Errorf("MaskValue secret = %q", got)
+	}
+	if got := MaskValue("proxy.host", "127.0.0.1"); got != "127.0.0.1" {
+		t.Errorf("MaskValue plain = %q", got)
+	}
+	if !IsMasked("bili.cookie", "SESSDATA=abcdef", "SE******ef") {
+		t.Error("IsMasked should recognize masked cookie")
+	}
+	if IsMasked("proxy.host", "127.0.0.1", "127.0.0.1") {
+		t.Error("IsMasked should ignore non-secret keys")
+	}
+}
+
+func TestSchemaDefaultsValid(t *testing.T) {
+	for key, schema := range Schemas {
+		if schema.Key != key {
+			t.Errorf("schema key mismatch: %s != %s", schema.Key, key)
+		}
+		if err := Validate(key, schema.Default); err != nil {
+			t.Errorf("default of %s invalid: %v", key, err)
+		}
+	}
+}
+
+func TestRegisterValidator(t *testing.T) {
+	RegisterValidator("recorder.output_dir", func(value string) error {
+		if value == "bad" {
+			return fmt.Errorf("bad dir")
+		}
+		return nil
+	})
+	defer RegisterValidator("recorder.output_dir", func(string) error { return nil })
+
+	if err := Validate("recorder.output_dir", "./records"); err != nil {
+		t.Errorf("Validate() err = %v", err)
+	}
+	if err := Validate("recorder.output_dir", "bad"); err == nil {
+		t.Error("Validate() 应当返回自定义校验的错误")
+	}
+}
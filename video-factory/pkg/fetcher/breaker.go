@@ -0,0 +1,201 @@
+package fetcher
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// 熔断器状态
+const (
+	BreakerClosed   = "closed"    // 正常
+	BreakerOpen     = "open"      // 熔断中，拒绝所有请求
+	BreakerHalfOpen = "half-open" // 冷却结束，放行一个探测请求
+)
+
+const (
+	// 首次熔断的冷却时间，之后每次连续熔断翻倍
+	breakerBaseCooldown = 1 * time.Minute
+	breakerMaxCooldown  = 30 * time.Minute
+	// 探测请求迟迟没有结果（如网络错误）时，允许再次探测
+	breakerProbeTimeout = 30 * time.Second
+)
+
+// CircuitBreaker 平台级熔断器，风控或限流时暂停该平台的所有请求，并指数增加冷却时间
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	platform  string
+	state     string
+	level     int       // 连续熔断次数，用于计算冷却时间
+	openUntil time.Time // 冷却截止时间
+	trips     int       // 累计熔断次数
+	lastError string
+	lastTrip  time.Time
+	probeAt   time.Time // 半开状态下探测请求的发出时间
+}
+
+// BreakerState 熔断器快照，用于接口展示
+type BreakerState struct {
+	Platform  string     `json:"platform"`
+	State     string     `json:"state"`
+	Level     int        `json:"level"`
+	Trips     int        `json:"trips"`
+	OpenUntil *time.Time `json:"openUntil"`
+	LastTrip  *time.Time `json:"lastTrip"`
+	LastError string     `json:"lastError"`
+}
+
+var (
+	breakersMu    sync.Mutex
+	breakers      = make(map[string]*CircuitBreaker)
+	platformHosts = make(map[string]string) // host -> platform
+)
+
+// RegisterHost 登记平台 API 域名，fetcher 会对这些域名的请求应用对应平台的熔断器
+func RegisterHost(platform string, hosts ...string) {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	for _, host := range hosts {
+		platformHosts[strings.ToLower(host)] = platform
+	}
+}
+
+// PlatformOfHost 根据域名查找所属平台，未登记返回空字符串
+func PlatformOfHost(host string) string {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	return platformHosts[strings.ToLower(host)]
+}
+
+// GetBreaker 获取平台熔断器，不存在则创建
+func GetBreaker(platform string) *CircuitBreaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	b, ok := breakers[platform]
+	if !ok {
+		b = &CircuitBreaker{platform: platform, state: BreakerClosed}
+		breakers[platform] = b
+	}
+	return b
+}
+
+// Breakers 所有熔断器的快照
+func Breakers() []BreakerState {
+	breakersMu.Lock()
+	list := make([]*CircuitBreaker, 0, len(breakers))
+	for _, b := range breakers {
+		list = append(list, b)
+	}
+	breakersMu.Unlock()
+
+	states := make([]BreakerState, 0, len(list))
+	for _, b := range list {
+		states = append(states, b.Snapshot())
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Platform < states[j].Platform
+	})
+	return states
+}
+
+// Allow 判断当前是否允许请求，熔断中返回 CircuitOpenError
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Now().Before(b.openUntil) {
+			return &CircuitOpenError{Platform: b.platform, Until: b.openUntil}
+		}
+		// 冷却结束，放行一个探测请求
+		b.state = BreakerHalfOpen
+		b.probeAt = time.Now()
+		log.Info().Str("platform", b.platform).Msg("[Breaker] 冷却结束，尝试探测请求")
+		return nil
+	case BreakerHalfOpen:
+		// 探测请求尚未返回结果，其他请求继续等待
+		if time.Since(b.probeAt) < breakerProbeTimeout {
+			return &CircuitOpenError{Platform: b.platform, Until: b.probeAt.Add(breakerProbeTimeout)}
+		}
+		b.probeAt = time.Now()
+		return nil
+	default:
+		return nil
+	}
+}
+
+// OnSuccess 请求成功，半开状态下恢复正常
+func (b *CircuitBreaker) OnSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerClosed && b.level == 0 {
+		return
+	}
+	if b.state != BreakerOpen {
+		log.Info().Str("platform", b.platform).Msg("[Breaker] 请求恢复正常，关闭熔断")
+		b.state = BreakerClosed
+		b.level = 0
+	}
+}
+
+// OnFailure 请求失败，仅限流/风控类错误会触发熔断
+func (b *CircuitBreaker) OnFailure(err error) {
+	if !IsRateLimited(err) {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	// 熔断期间的并发请求陆续返回，不重复计算
+	if b.state == BreakerOpen && now.Before(b.openUntil) {
+		return
+	}
+	cooldown := breakerBaseCooldown << b.level
+	if cooldown > breakerMaxCooldown || cooldown <= 0 {
+		cooldown = breakerMaxCooldown
+	} else {
+		b.level++
+	}
+	b.state = BreakerOpen
+	b.openUntil = now.Add(cooldown)
+	b.trips++
+	b.lastError = err.Error()
+	b.lastTrip = now
+	log.Warn().Err(err).Str("platform", b.platform).Dur("cooldown", cooldown).Msg("[Breaker] 触发熔断，暂停该平台所有请求")
+}
+
+// Reset 立即恢复正常，清空连续熔断次数
+func (b *CircuitBreaker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = BreakerClosed
+	b.level = 0
+	b.openUntil = time.Time{}
+}
+
+// Snapshot 当前状态快照
+func (b *CircuitBreaker) Snapshot() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state := BreakerState{
+		Platform:  b.platform,
+		State:     b.state,
+		Level:     b.level,
+		Trips:     b.trips,
+		LastError: b.lastError,
+	}
+	if b.state == BreakerOpen {
+		openUntil := b.openUntil
+		state.OpenUntil = &openUntil
+	}
+	if !b.lastTrip.IsZero() {
+		lastTrip := b.lastTrip
+		state.LastTrip = &lastTrip
+	}
+	return state
+}
@@ -0,0 +1,75 @@
+package fetcher
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIsRateLimited(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{&StatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{&StatusError{StatusCode: http.StatusPreconditionFailed}, true},
+		{&StatusError{StatusCode: http.StatusForbidden}, false},
+		{fmt.Errorf("wrapped: %w", &RiskControlError{Platform: "bili", Code: -352}), true},
+		{&CircuitOpenError{Platform: "bili"}, true},
+		{errors.New("timeout"), false},
+	}
+	for _, c := range cases {
+		if got := IsRateLimited(c.err); got != c.want {
+			t.Errorf("IsRateLimited(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	b := &CircuitBreaker{platform: "test", state: BreakerClosed}
+
+	// 普通错误不会触发熔断
+	b.OnFailure(errors.New("network error"))
+	if err := b.Allow(); err != nil {
+		t.Fatalf("breaker should stay closed on plain errors: %v", err)
+	}
+
+	b.OnFailure(&RiskControlError{Platform: "test", Code: -412})
+	var openErr *CircuitOpenError
+	if err := b.Allow(); !errors.As(err, &openErr) {
+		t.Fatalf("breaker should be open after risk control, got %v", err)
+	}
+	first := b.Snapshot()
+	if first.State != BreakerOpen || first.Trips != 1 || first.Level != 1 {
+		t.Fatalf("unexpected snapshot: %+v", first)
+	}
+
+	// 冷却结束后放行一个探测请求，探测未返回前其余请求被拒绝
+	b.openUntil = time.Now().Add(-time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe request should be allowed after cooldown: %v", err)
+	}
+	if err := b.Allow(); err == nil {
+		t.Fatal("only one probe request should be allowed")
+	}
+
+	// 探测失败，冷却时间翻倍
+	b.OnFailure(&StatusError{StatusCode: http.StatusTooManyRequests})
+	second := b.Snapshot()
+	if second.Level != 2 || second.OpenUntil == nil ||
+		second.OpenUntil.Sub(*second.LastTrip) != 2*breakerBaseCooldown {
+		t.Fatalf("cooldown should double: %+v", second)
+	}
+
+	// 探测成功，恢复正常
+	b.openUntil = time.Now().Add(-time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe request should be allowed: %v", err)
+	}
+	b.OnSuccess()
+	if s := b.Snapshot(); s.State != BreakerClosed || s.Level != 0 {
+		t.Fatalf("breaker should close after success: %+v", s)
+	}
+}
@@ -0,0 +1,56 @@
+package fetcher
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// StatusError 表示 HTTP 状态码异常
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API 返回错误状态码: %d", e.StatusCode)
+}
+
+// RiskControlError 平台风控响应，例如 B站的 -412 / -352
+type RiskControlError struct {
+	Platform string
+	Code     int
+	Message  string
+}
+
+func (e *RiskControlError) Error() string {
+	return fmt.Sprintf("%s 触发风控 (%d): %s", e.Platform, e.Code, e.Message)
+}
+
+// CircuitOpenError 平台熔断中，请求未发出
+type CircuitOpenError struct {
+	Platform string
+	Until    time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s 熔断中，%s 后恢复", e.Platform, e.Until.Format(time.DateTime))
+}
+
+// IsRateLimited 判断是否为平台限流或风控 (HTTP 412/429、风控业务码、熔断中)
+func IsRateLimited(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return isRateLimitStatus(statusErr.StatusCode)
+	}
+	var riskErr *RiskControlError
+	if errors.As(err, &riskErr) {
+		return true
+	}
+	var openErr *CircuitOpenError
+	return errors.As(err, &openErr)
+}
+
+func isRateLimitStatus(statusCode int) bool {
+	return statusCode == http.StatusPreconditionFailed || statusCode == http.StatusTooManyRequests
+}
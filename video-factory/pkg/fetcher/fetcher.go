@@ -24,7 +24,11 @@ type RequestExecutor func(method, baseURL string, params url.Values) (*http.Resp
 // GlobalClient 是一个通用的 HTTP 客户端实例
 var GlobalClient *http.Client
 
+// appConfig 用于读取限流等可热更新的配置
+var appConfig *config.AppConfig
+
 func Init(cfg *config.AppConfig) {
+	appConfig = cfg
 	transport := &http.Transport{}
 
 	if cfg.Proxy.Protocol == "" {
@@ -86,14 +90,38 @@ func Fetch(method string, baseURL string, params url.Values, header http.Header)
 	}
 
 	// 设置 Header
-	request.Header = header
-	// 特殊处理 host
+	ApplyHeader(request, header)
+
+	// 平台熔断中，直接拒绝，避免继续触发风控
+	platform := PlatformOfHost(parsedURL.Hostname())
+	if platform != "" {
+		if err := GetBreaker(platform).Allow(); err != nil {
+			return nil, retry.Unrecoverable(err)
+		}
+	}
+	// 按域名限流
+	if err := waitHost(context.Background(), parsedURL.Hostname()); err != nil {
+		return nil, err
+	}
+
+	response, err := GlobalClient.Do(request)
+	if err == nil && platform != "" && isRateLimitStatus(response.StatusCode) {
+		GetBreaker(platform).OnFailure(&StatusError{StatusCode: response.StatusCode})
+	}
+	return response, err
+}
+
+// ApplyHeader 设置请求头，Header 中的 Host 用于改写请求的 Host（如 CDN 地址为 IP 或备用域名时）
+// 会复制一份 header，调用方的 header 可以在多个请求间复用
+func ApplyHeader(request *http.Request, header http.Header) {
+	if header == nil {
+		return
+	}
+	request.Header = header.Clone()
 	if host := request.Header.Get("Host"); host != "" {
 		request.Host = host
 		request.Header.Del("Host")
 	}
-
-	return GlobalClient.Do(request)
 }
 
 // FetchBody 用于获取并读取 responseBody
@@ -101,13 +129,13 @@ func FetchBody(baseURL string, params url.Values, header http.Header) ([]byte, e
 	// log.Debug().Msgf("FetchBody, baseUrl: %s, params: %v, header: %v", baseURL, params, header)
 	response, err := Fetch(http.MethodGet, baseURL, params, header)
 	if err != nil {
-		return nil, fmt.Errorf("执行请求失败: %v", err)
+		return nil, fmt.Errorf("执行请求失败: %w", err)
 	}
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotModified {
 		// 这里可以打印更详细的错误日志
-		return nil, fmt.Errorf("API 返回错误状态码: %d", response.StatusCode)
+		return nil, &StatusError{StatusCode: response.StatusCode}
 	}
 
 	bodyBytes, readErr := io.ReadAll(response.Body)
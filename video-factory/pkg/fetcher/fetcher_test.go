@@ -0,0 +1,28 @@
+package fetcher
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestApplyHeader(t *testing.T) {
+	header := http.Header{}
+	header.Set("Referer", "https://fm.missevan.com/live/1")
+	header.Set("Host", "cdn.example.com")
+
+	req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1/live.m3u8", nil)
+	ApplyHeader(req, header)
+	if req.Host != "cdn.example.com" || req.Header.Get("Host") != "" || req.Header.Get("Referer") == "" {
+		t.Fatalf("host = %q, header = %v", req.Host, req.Header)
+	}
+	// 调用方的 header 可以继续用于下一个请求
+	if header.Get("Host") != "cdn.example.com" {
+		t.Fatal("ApplyHeader should not modify the caller's header")
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "http://127.0.0.1/live.m3u8", nil)
+	ApplyHeader(req, nil)
+	if req.Host != "127.0.0.1" || req.Header == nil {
+		t.Fatalf("host = %q, header = %v", req.Host, req.Header)
+	}
+}
@@ -0,0 +1,41 @@
+package fetcher
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"video-factory/pkg/limiter"
+)
+
+var (
+	hostLimitersMu sync.Mutex
+	hostLimiters   = make(map[string]*limiter.TokenBucket)
+)
+
+// hostLimiter 获取域名对应的令牌桶，速率取自配置 fetcher.host_qps
+func hostLimiter(host string) *limiter.TokenBucket {
+	qps := 0
+	if appConfig != nil && appConfig.Fetcher != nil {
+		qps = appConfig.Fetcher.HostQPS
+	}
+
+	hostLimitersMu.Lock()
+	defer hostLimitersMu.Unlock()
+	host = strings.ToLower(host)
+	b, ok := hostLimiters[host]
+	if !ok {
+		b = limiter.NewTokenBucket(float64(qps), qps)
+		hostLimiters[host] = b
+	} else if b.Rate() != float64(qps) {
+		b.SetRate(float64(qps), qps)
+	}
+	return b
+}
+
+// waitHost 按域名限流，只对登记过平台的 API 域名生效，直播流 CDN 不受限制
+func waitHost(ctx context.Context, host string) error {
+	if PlatformOfHost(host) == "" {
+		return nil
+	}
+	return hostLimiter(host).Wait(ctx)
+}
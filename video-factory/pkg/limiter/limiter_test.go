@@ -0,0 +1,43 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket_Allow(t *testing.T) {
+	b := NewTokenBucket(1, 2)
+	now := time.Now()
+	b.last = now
+
+	if b.reserve(now) != 0 || b.reserve(now) != 0 {
+		t.Fatal("burst tokens should be available immediately")
+	}
+	if wait := b.reserve(now); wait <= 0 {
+		t.Fatalf("third token should require waiting, got %s", wait)
+	}
+	if wait := b.reserve(now.Add(1500 * time.Millisecond)); wait != 0 {
+		t.Fatalf("token should be refilled after 1.5s, got wait %s", wait)
+	}
+}
+
+func TestTokenBucket_Unlimited(t *testing.T) {
+	b := NewTokenBucket(0, 1)
+	for i := 0; i < 100; i++ {
+		if !b.Allow() {
+			t.Fatal("rate <= 0 should never limit")
+		}
+	}
+}
+
+func TestTokenBucket_WaitCanceled(t *testing.T) {
+	b := NewTokenBucket(0.001, 1)
+	b.Allow()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := b.Wait(ctx); err == nil {
+		t.Fatal("Wait should return ctx error when canceled")
+	}
+}
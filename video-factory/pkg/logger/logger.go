@@ -2,25 +2,53 @@ package logger
 
 import (
 	"fmt"
-	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
+	"video-factory/pkg/config"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// appLogName 程序日志文件名
+	appLogName = "video-factory.log"
+	// roomLogDir 房间日志所在的子目录
+	roomLogDir = "rooms"
+)
+
+var (
+	// level 控制台与程序日志文件的级别，可在运行时修改，房间日志不受影响
+	level atomic.Int32
+	// output 控制台与程序日志文件，按 level 过滤
+	output io.Writer = os.Stderr
+	// setting 当前的日志配置，Setup 后生效
+	setting *config.Log
 )
 
 // InitLogger 初始化 zerolog，实现类似 Spring Boot 的控制台格式
+// 读取配置前使用，配置加载后由 Setup 按配置重新初始化
 func InitLogger() {
 	// 设置全局日志级别 (例如：Debug 或 info)
 	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	level.Store(int32(zerolog.DebugLevel))
 
 	// 禁用默认的时间戳字段名 (默认为 "time")
 	zerolog.TimestampFieldName = "timestamp"
 	// 设置时间格式，与 Spring Boot 默认的 ISO 8601 兼容
 	zerolog.TimeFieldFormat = time.RFC3339Nano
 
-	// 配置 ConsoleWriter 以输出人类可读的格式
-	consoleWriter := zerolog.ConsoleWriter{
+	output = levelWriter{w: newConsoleWriter()}
+	log.Logger = newLogger(output)
+}
+
+// newConsoleWriter 配置 ConsoleWriter 以输出人类可读的格式
+func newConsoleWriter() zerolog.ConsoleWriter {
+	return zerolog.ConsoleWriter{
 		Out:        os.Stderr,                 // 默认输出到标准错误
 		NoColor:    false,                     // 启用颜色，让日志更醒目
 		TimeFormat: "2006-01-02 15:04:05.000", // 自定义日期时间格式
@@ -45,12 +73,97 @@ func InitLogger() {
 			return fmt.Sprintf(" : %s", i.(string))
 		},
 	}
+}
 
-	log.Logger = zerolog.New(consoleWriter).
+func newLogger(w io.Writer) zerolog.Logger {
+	return zerolog.New(w).
 		Level(zerolog.DebugLevel).
 		With().
 		Timestamp().
 		CallerWithSkipFrameCount(2). // 设置跳过帧数，以正确显示调用代码的文件名
 		Logger()
+}
+
+// Setup 按配置初始化日志：控制台格式、程序日志文件与房间日志
+// 日志文件始终为 JSON，便于检索与接口查看
+func Setup(cfg *config.Log) error {
+	if cfg == nil {
+		return nil
+	}
+	if err := SetLevel(cfg.Level); err != nil {
+		return err
+	}
+
+	var console io.Writer = newConsoleWriter()
+	if cfg.Format == "json" {
+		console = os.Stderr
+	}
+	writers := []io.Writer{levelWriter{w: console}}
+	if cfg.Dir != "" {
+		writers = append(writers, levelWriter{w: newRotatingFile(cfg, filepath.Join(cfg.Dir, appLogName))})
+	}
+	output = zerolog.MultiLevelWriter(writers...)
+	setting = cfg
+	log.Logger = newLogger(output)
+	log.Info().Str("logLevel", cfg.Level).Str("format", cfg.Format).Str("dir", cfg.Dir).Bool("roomLog", cfg.RoomLog).
+		Msg("[Logger] 日志初始化完成")
+	return nil
+}
 
+func newRotatingFile(cfg *config.Log, filename string) *RotatingFile {
+	return &RotatingFile{
+		Filename:   filename,
+		MaxSize:    int64(cfg.MaxSize) * 1024 * 1024,
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAge,
+		Compress:   cfg.Compress,
+	}
+}
+
+// SetLevel 修改控制台与程序日志文件的级别，立即生效
+func SetLevel(levelStr string) error {
+	if levelStr == "" {
+		levelStr = zerolog.InfoLevel.String()
+	}
+	lvl, err := zerolog.ParseLevel(levelStr)
+	if err != nil {
+		return fmt.Errorf("日志级别错误: %s", levelStr)
+	}
+	level.Store(int32(lvl))
+	return nil
+}
+
+// Level 当前的日志级别
+func Level() zerolog.Level {
+	return zerolog.Level(level.Load())
+}
+
+// levelWriter 按运行时级别过滤，全局级别保持 debug，房间日志始终能收到 ffmpeg 输出
+type levelWriter struct {
+	w io.Writer
+}
+
+func (l levelWriter) Write(p []byte) (int, error) {
+	return l.w.Write(p)
+}
+
+func (l levelWriter) WriteLevel(lvl zerolog.Level, p []byte) (int, error) {
+	if lvl < Level() {
+		return len(p), nil
+	}
+	return l.w.Write(p)
+}
+
+// Subscriber 接收配置更新，log.level 修改后立即生效
+type Subscriber struct{}
+
+func (Subscriber) OnConfigUpdate(key string, value string) {
+	if key != "log.level" {
+		return
+	}
+	if err := SetLevel(value); err != nil {
+		log.Err(err).Msg("[Logger] 修改日志级别失败")
+		return
+	}
+	log.Info().Str("logLevel", value).Msg("[Logger] 日志级别已修改")
 }
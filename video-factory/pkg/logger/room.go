@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// maxTailLines 单次最多查看的日志行数
+const maxTailLines = 2000
+
+// roomFiles 房间日志文件，按房间 ID 缓存
+var roomFiles sync.Map // map[int64]*RotatingFile
+
+// Room 房间日志，写入程序日志的同时写入 rooms/<roomId>.log，包含全部级别（含 ffmpeg 输出）
+// 未开启房间日志时返回带 roomId 字段的全局日志
+func Room(roomId int64) zerolog.Logger {
+	cfg := setting
+	if cfg == nil || !cfg.RoomLog || cfg.Dir == "" {
+		return log.Logger.With().Int64("roomId", roomId).Logger()
+	}
+	file, _ := roomFiles.LoadOrStore(roomId, newRotatingFile(cfg, RoomLogFile(roomId)))
+	return newLogger(zerolog.MultiLevelWriter(output, file.(*RotatingFile))).With().Int64("roomId", roomId).Logger()
+}
+
+// CloseRoom 关闭房间日志文件，Manager 停止或房间删除后调用，下次获取房间日志时重新打开
+func CloseRoom(roomId int64) {
+	if file, ok := roomFiles.LoadAndDelete(roomId); ok {
+		_ = file.(*RotatingFile).Close()
+	}
+}
+
+// RoomLogFile 房间日志文件路径，未开启房间日志时返回空字符串
+func RoomLogFile(roomId int64) string {
+	cfg := setting
+	if cfg == nil || !cfg.RoomLog || cfg.Dir == "" {
+		return ""
+	}
+	return filepath.Join(cfg.Dir, roomLogDir, strconv.FormatInt(roomId, 10)+".log")
+}
+
+// TailRoom 读取房间当前日志文件的最后 n 行，文件不存在时返回空列表
+func TailRoom(roomId int64, n int) ([]json.RawMessage, error) {
+	filename := RoomLogFile(roomId)
+	if filename == "" {
+		return nil, errors.New("未开启房间日志")
+	}
+	if n <= 0 || n > maxTailLines {
+		n = maxTailLines
+	}
+	file, err := os.Open(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return []json.RawMessage{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines, err := tail(file, n)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]json.RawMessage, 0, len(lines))
+	for _, line := range lines {
+		// 跳过写入中断等原因产生的残缺行
+		if json.Valid(line) {
+			result = append(result, line)
+		}
+	}
+	return result, nil
+}
+
+// tail 保留最后 n 行，只占用 n 行的内存
+func tail(r io.Reader, n int) ([][]byte, error) {
+	ring := make([][]byte, 0, n)
+	start := 0
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := append([]byte(nil), scanner.Bytes()...)
+		if len(ring) < n {
+			ring = append(ring, line)
+			continue
+		}
+		ring[start] = line
+		start = (start + 1) % n
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return append(ring[start:], ring[:start]...), nil
+}
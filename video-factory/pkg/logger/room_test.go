@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"video-factory/pkg/config"
+
+	"github.com/rs/zerolog/log"
+)
+
+func TestRoomLog(t *testing.T) {
+	dir := t.TempDir()
+	if err := Setup(&config.Log{Level: "info", Format: "json", Dir: dir, MaxSize: 1, RoomLog: true}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		setting = nil
+		InitLogger()
+	})
+
+	room := Room(42)
+	room.Debug().Str("ffmpeg", "stderr").Msg("frame=1")
+	room.Info().Msg("开始录制")
+	log.Debug().Msg("全局 debug")
+
+	// 房间日志包含全部级别，程序日志按级别过滤
+	lines, err := TailRoom(42, 10)
+	if err != nil || len(lines) != 2 {
+		t.Fatalf("lines = %s, err = %v", lines, err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(lines[1], &entry); err != nil || entry["message"] != "开始录制" || entry["roomId"] != float64(42) {
+		t.Fatalf("entry = %v, err = %v", entry, err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, appLogName))
+	if strings.Contains(string(data), "frame=1") || strings.Contains(string(data), "全局 debug") || !strings.Contains(string(data), "开始录制") {
+		t.Fatalf("app log = %s", data)
+	}
+
+	// 运行时修改级别
+	Subscriber{}.OnConfigUpdate("log.level", "debug")
+	log.Debug().Msg("全局 debug")
+	data, _ = os.ReadFile(filepath.Join(dir, appLogName))
+	if !strings.Contains(string(data), "全局 debug") {
+		t.Fatalf("app log = %s", data)
+	}
+	if err := SetLevel("verbose"); err == nil {
+		t.Fatal("应当拒绝错误的级别")
+	}
+
+	// 只返回最后 n 行，没有日志的房间返回空列表
+	if lines, _ := TailRoom(42, 1); len(lines) != 1 || !strings.Contains(string(lines[0]), "开始录制") {
+		t.Fatalf("lines = %s", lines)
+	}
+	if lines, err := TailRoom(43, 10); err != nil || len(lines) != 0 {
+		t.Fatalf("lines = %s, err = %v", lines, err)
+	}
+}
+
+func TestCloseRoom(t *testing.T) {
+	dir := t.TempDir()
+	if err := Setup(&config.Log{Level: "info", Format: "json", Dir: dir, MaxSize: 1, RoomLog: true}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		setting = nil
+		InitLogger()
+	})
+
+	room := Room(7)
+	room.Info().Msg("第一场")
+	value, ok := roomFiles.Load(int64(7))
+	if !ok {
+		t.Fatal("room file not cached")
+	}
+	file := value.(*RotatingFile)
+	if err := file.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 关闭文件与清理协程，并移出缓存
+	CloseRoom(7)
+	if _, ok := roomFiles.Load(int64(7)); ok {
+		t.Error("room file still cached")
+	}
+	file.mu.Lock()
+	closed := file.file == nil && file.clean == nil
+	file.mu.Unlock()
+	if !closed {
+		t.Error("room file not closed")
+	}
+
+	// 重新开播时重新打开，第一场的日志已切分到历史文件
+	room = Room(7)
+	room.Info().Msg("第二场")
+	lines, err := TailRoom(7, 10)
+	if err != nil || len(lines) != 1 || !strings.Contains(string(lines[0]), "第二场") {
+		t.Fatalf("lines = %s, err = %v", lines, err)
+	}
+	CloseRoom(7)
+}
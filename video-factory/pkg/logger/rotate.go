@@ -0,0 +1,226 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat 历史日志文件名中的时间格式
+const backupTimeFormat = "20060102-150405.000"
+
+// RotatingFile 按大小和日期切分的日志文件
+// 当前文件为 Filename，切分后重命名为 name-时间.ext，可选 gzip 压缩，按数量和天数清理
+type RotatingFile struct {
+	Filename   string
+	MaxSize    int64 // 单个文件最大字节数，0 表示不按大小切分
+	MaxBackups int   // 保留的历史文件数，0 表示不限制
+	MaxAge     int   // 历史文件保留天数，0 表示不限制
+	Compress   bool  // 是否压缩历史文件
+
+	file    *os.File
+	size    int64
+	openDay string // 当前文件的日期，跨天时切分
+	mu      sync.Mutex
+	clean   chan struct{} // 通知后台协程清理历史文件，协程未启动时为 nil
+}
+
+// Write 写入当前文件，超过大小或跨天时先切分
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	if (r.MaxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.MaxSize) || r.openDay != today() {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close 关闭当前文件并停止后台清理协程，之后写入时重新打开
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.clean != nil {
+		close(r.clean)
+		r.clean = nil
+	}
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// Rotate 立即切分当前文件
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rotate()
+}
+
+func today() string {
+	return time.Now().Format(time.DateOnly)
+}
+
+// open 打开当前文件，追加写入
+func (r *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.Filename), 0755); err != nil {
+		return fmt.Errorf("创建日志目录失败: %w", err)
+	}
+	file, err := os.OpenFile(r.Filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开日志文件失败: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file = file
+	r.size = info.Size()
+	r.openDay = info.ModTime().Format(time.DateOnly)
+	if r.size == 0 {
+		r.openDay = today()
+	}
+	return nil
+}
+
+// rotate 重命名当前文件并打开新文件，压缩与清理在后台进行
+func (r *RotatingFile) rotate() error {
+	if r.file != nil {
+		if err := r.file.Close(); err != nil {
+			return err
+		}
+		r.file = nil
+	}
+	if info, err := os.Stat(r.Filename); err == nil && info.Size() > 0 {
+		if err := os.Rename(r.Filename, r.backupName(time.Now())); err != nil {
+			return fmt.Errorf("重命名日志文件失败: %w", err)
+		}
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+	r.startCleaner()
+	select {
+	case r.clean <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (r *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(r.Filename)
+	prefix := strings.TrimSuffix(r.Filename, ext)
+	return fmt.Sprintf("%s-%s%s", prefix, t.Format(backupTimeFormat), ext)
+}
+
+// startCleaner 启动后台协程，每次切分后压缩并清理历史文件，避免阻塞写日志
+// 调用方需持有 r.mu，Close 时关闭通道使协程退出
+func (r *RotatingFile) startCleaner() {
+	if r.clean != nil {
+		return
+	}
+	clean := make(chan struct{}, 1)
+	r.clean = clean
+	go func() {
+		for range clean {
+			r.cleanup()
+		}
+	}()
+}
+
+// backups 历史文件，按时间从新到旧排序
+func (r *RotatingFile) backups() ([]string, error) {
+	ext := filepath.Ext(r.Filename)
+	prefix := filepath.Base(strings.TrimSuffix(r.Filename, ext)) + "-"
+	entries, err := os.ReadDir(filepath.Dir(r.Filename))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if stamp := strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ext); len(stamp)-len(prefix) == len(backupTimeFormat) {
+			names = append(names, name)
+		}
+	}
+	// 文件名中的时间格式固定，按名称倒序即为从新到旧
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names, nil
+}
+
+// cleanup 压缩并清理超出数量或天数的历史文件
+func (r *RotatingFile) cleanup() {
+	names, err := r.backups()
+	if err != nil {
+		return
+	}
+	dir := filepath.Dir(r.Filename)
+	ext := filepath.Ext(r.Filename)
+	prefix := filepath.Base(strings.TrimSuffix(r.Filename, ext)) + "-"
+	cutoff := time.Now().AddDate(0, 0, -r.MaxAge)
+	for i, name := range names {
+		path := filepath.Join(dir, name)
+		stamp := strings.TrimPrefix(strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ext), prefix)
+		rotatedAt, _ := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if (r.MaxBackups > 0 && i >= r.MaxBackups) || (r.MaxAge > 0 && rotatedAt.Before(cutoff)) {
+			_ = os.Remove(path)
+			continue
+		}
+		if r.Compress && !strings.HasSuffix(name, ".gz") {
+			if err := compressFile(path); err != nil {
+				fmt.Fprintf(os.Stderr, "压缩日志文件失败: %v\n", err)
+			}
+		}
+	}
+}
+
+// compressFile 压缩为 .gz 后删除原文件
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
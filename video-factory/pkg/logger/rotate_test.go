@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotateBySize(t *testing.T) {
+	dir := t.TempDir()
+	r := &RotatingFile{Filename: filepath.Join(dir, "app.log"), MaxSize: 20}
+	defer r.Close()
+
+	for _, line := range []string{"0123456789\n", "0123456789\n", "01\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(r.Filename)
+	if err != nil || string(data) != "0123456789\n01\n" {
+		t.Fatalf("current = %q, err = %v", data, err)
+	}
+	backups, err := r.backups()
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("backups = %v, err = %v", backups, err)
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	dir := t.TempDir()
+	r := &RotatingFile{Filename: filepath.Join(dir, "app.log"), MaxBackups: 2, MaxAge: 7, Compress: true}
+	now := time.Now()
+	for _, at := range []time.Time{now, now.Add(-time.Hour), now.Add(-2 * time.Hour), now.AddDate(0, 0, -30)} {
+		if err := os.WriteFile(r.backupName(at), []byte("log\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 名称相近但不是历史文件，不能被清理
+	other := filepath.Join(dir, "app-other.log")
+	if err := os.WriteFile(other, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r.cleanup()
+	backups, err := r.backups()
+	if err != nil || len(backups) != 2 {
+		t.Fatalf("backups = %v, err = %v", backups, err)
+	}
+	for _, name := range backups {
+		if !strings.HasSuffix(name, ".gz") {
+			t.Fatalf("未压缩: %s", name)
+		}
+	}
+	if backups[0] != filepath.Base(r.backupName(now))+".gz" {
+		t.Fatalf("backups = %v", backups)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Fatal(err)
+	}
+}
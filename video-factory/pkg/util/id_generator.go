@@ -17,7 +17,7 @@ var generator IDGenerator
 
 func InitIDGenerator(nodeID int64) {
 	// 初始化 ID 生成器
-	err := Init(1)
+	err := Init(nodeID)
 	if err != nil {
 		log.Fatalf("初始化 ID 生成器失败: %v", err)
 	}
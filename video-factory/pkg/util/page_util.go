@@ -0,0 +1,13 @@
+package util
+
+// Paginate 返回第 page 页（从 1 开始）的数据，pageSize 小于等于 0 时返回全部
+func Paginate[T any](list []T, page int, pageSize int) []T {
+	if pageSize <= 0 {
+		return list
+	}
+	start := (max(page, 1) - 1) * pageSize
+	if start >= len(list) {
+		return []T{}
+	}
+	return list[start:min(start+pageSize, len(list))]
+}
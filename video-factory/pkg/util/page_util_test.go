@@ -0,0 +1,25 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	list := []int{1, 2, 3, 4, 5}
+	cases := []struct {
+		page, pageSize int
+		want           []int
+	}{
+		{0, 0, list},
+		{1, 2, []int{1, 2}},
+		{3, 2, []int{5}},
+		{4, 2, []int{}},
+		{0, 2, []int{1, 2}},
+	}
+	for _, c := range cases {
+		if got := Paginate(list, c.page, c.pageSize); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Paginate(%d, %d) = %v, want %v", c.page, c.pageSize, got, c.want)
+		}
+	}
+}